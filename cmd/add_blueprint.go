@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/fatih/color"
@@ -49,6 +48,9 @@ func init() {
 
 func runAddBlueprint(cmd *cobra.Command, args []string) error {
 	_ = args
+	if err := refuseEnvWrite("lzctl add-blueprint"); err != nil {
+		return err
+	}
 	cfg, err := configCache()
 	if err != nil {
 		return fmt.Errorf("load config: %w (run lzctl init first)", err)
@@ -113,9 +115,9 @@ func runAddBlueprint(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	absRoot, err := filepath.Abs(repoRoot)
+	absRoot, err := absTenantRoot()
 	if err != nil {
-		return fmt.Errorf("resolving repo root: %w", err)
+		return err
 	}
 
 	if dryRun {
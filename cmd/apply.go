@@ -2,16 +2,24 @@ package cmd
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+	"github.com/kjourdan1/lzctl/internal/planrules"
+	"github.com/kjourdan1/lzctl/internal/plansummary"
 	"github.com/kjourdan1/lzctl/internal/planverify"
+	"github.com/kjourdan1/lzctl/internal/runjournal"
 )
 
 var applyCmd = &cobra.Command{
@@ -26,25 +34,95 @@ Runs 'terraform apply' across platform layers in CAF dependency order:
   4. governance          (Azure Policies)
   5. connectivity        (Hub-Spoke or vWAN)
 
-Use --layer to apply a single layer, or omit to apply all activated layers.
-Use --auto-approve to skip the interactive confirmation (for CI/CD).`,
+Landing zones (landing-zones/<zone>) and their blueprints
+(landing-zones/<zone>/blueprint) are applied after the platform layers.
+
+Use --layer to apply a single layer, --zone to apply a landing zone and its
+blueprint, or --blueprint to apply only a zone's blueprint. Omit all three
+to apply every platform layer and landing zone.
+Use --parallelism N to apply up to N independent landing zones at once;
+a failure in any root cancels the others.
+Use --auto-approve to skip the interactive confirmation (for CI/CD).
+With --dry-run, each root is planned instead of applied; --json then prints
+the per-resource change list for every root.
+
+Every apply writes a run journal to .lzctl/runs/<run-id>/journal.json with
+each layer's input hash, plan hash, status and timestamps. If a run fails,
+'lzctl apply --resume <run-id>' applies only the layers that did not
+succeed. Resuming is refused if any layer's Terraform inputs changed since
+the run started. --json prints the journal.
+
+Before anything is applied, the rules in .lzctl/plan-rules.yaml (if
+present) are evaluated against each root's saved tfplan.json; a deny
+finding aborts the apply (see 'lzctl plan check'). With --dry-run the rules
+are evaluated against the dry-run plans instead.
+
+Saved plans signed by 'lzctl plan' (tfplan.sig.json) are verified before
+anything is applied, with the key in LZCTL_PLAN_VERIFY_KEY(_FILE) or else
+the signing key. A signature that does not verify always blocks (exit code
+7). With --require-signed-plan, or in CI mode, unsigned plans and roots
+without a saved plan are refused as well.
+
+Saved plans must only target the subscriptions declared in lzctl.yaml.
+tfplan.json is checked before anything is applied and the binary plan
+again right before each root is applied; a violation, or a plan that
+cannot be rendered, blocks (exit code 7). A root without a saved plan is
+planned first and that plan is applied.
+
+Before a root is initialised, the lease on its state blob is checked. While
+another run holds it, the holder is reported and lzctl waits with backoff
+for up to --lock-wait (exit code 3 when it is still held). With
+--break-stale-lock <age>, a lease whose Terraform lock is older than <age>
+is broken instead.
+
+Each root runs under its timeout from spec.execution in lzctl.yaml, and
+runs failing with transient errors are retried with backoff. An apply that
+already changed resources is never retried: plan the root again first.
+
+With -v, Terraform output is streamed to stderr with a [<root>] prefix.
+Every Terraform invocation is logged next to the run journal, in
+.lzctl/runs/<run-id>/.`,
 	RunE: runApply,
 }
 
 var (
 	applyLayer       string
+	applyZone        string
+	applyBlueprint   string
 	applyAutoApprove bool
+	applyParallelism int
+	applyResume      string
+	applyLabels      []string
+	applyRequireSig  bool
+	applyLockWait    time.Duration
+	applyBreakStale  time.Duration
 )
 
+// applyDryRunPlanFile is a scratch plan used by --dry-run; it never replaces
+// the reviewed tfplan that a real apply consumes.
+const applyDryRunPlanFile = "tfplan.dryrun"
+
+// applyScopePlanFile is the scratch plan applied to a root without a saved
+// plan once its subscription scope has been checked.
+const applyScopePlanFile = "tfplan.apply"
+
 func init() {
 	applyCmd.Flags().StringVar(&applyLayer, "layer", "", "specific layer to apply")
 	applyCmd.Flags().StringVar(&applyLayer, "target", "", "alias for --layer")
+	applyCmd.Flags().StringVar(&applyZone, "zone", "", "landing zone to apply (includes its blueprint)")
+	applyCmd.Flags().StringVar(&applyBlueprint, "blueprint", "", "landing zone whose blueprint to apply")
+	applyCmd.Flags().IntVar(&applyParallelism, "parallelism", 1, "number of independent roots to apply concurrently")
 	applyCmd.Flags().BoolVar(&applyAutoApprove, "auto-approve", false, "skip confirmation (CI only)")
+	applyCmd.Flags().StringVar(&applyResume, "resume", "", "resume a failed apply run by ID, skipping layers it already applied")
+	applyCmd.Flags().BoolVar(&applyRequireSig, "require-signed-plan", false, "refuse to apply plans without a valid signature (implied by --ci)")
+	applyCmd.Flags().StringSliceVar(&applyLabels, "approval-label", nil, "approval label granted for plan rules (repeatable)")
+	applyCmd.Flags().DurationVar(&applyLockWait, "lock-wait", defaultLockWait, "how long to wait for a state lease held by another run (0: fail at once)")
+	applyCmd.Flags().DurationVar(&applyBreakStale, "break-stale-lock", 0, "break state leases whose Terraform lock is older than this (0: never)")
 
 	rootCmd.AddCommand(applyCmd)
 }
 
-func runApply(cmd *cobra.Command, args []string) error {
+func runApply(cmd *cobra.Command, args []string) (err error) {
 	root, err := absRepoRoot()
 	if err != nil {
 		return err
@@ -54,20 +132,76 @@ func runApply(cmd *cobra.Command, args []string) error {
 		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("--ci mode requires --auto-approve for apply"))
 	}
 
-	if err := ensureTerraformInstalled(); err != nil {
+	tf, err := resolveRunner(cmd.Context(), root)
+	if err != nil {
 		return exitcode.Wrap(exitcode.Validation, err)
 	}
 
-	layers, err := resolveLocalLayers(root, applyLayer)
+	var journal *runjournal.Journal
+	var roots []localRoot
+	if applyResume != "" {
+		if applyLayer != "" || applyZone != "" || applyBlueprint != "" {
+			return exitcode.Wrap(exitcode.Validation, fmt.Errorf("--resume cannot be combined with --layer, --zone or --blueprint: the run's own selection is used"))
+		}
+		if dryRun {
+			return exitcode.Wrap(exitcode.Validation, fmt.Errorf("--resume cannot be combined with --dry-run"))
+		}
+		journal, roots, err = loadResumableRun(root, applyResume)
+		if err != nil {
+			return exitcode.Wrap(exitcode.Validation, err)
+		}
+		if journal.Status == runjournal.StatusSucceeded {
+			color.New(color.FgGreen, color.Bold).Fprintf(os.Stderr, "✅ Run %s already completed; nothing to resume.\n", journal.RunID)
+			printApplyJournal(journal)
+			return nil
+		}
+	} else {
+		roots, err = resolveLocalRoots(root, rootSelection{Layer: applyLayer, Zone: applyZone, Blueprint: applyBlueprint})
+		if err != nil {
+			return exitcode.Wrap(exitcode.Validation, err)
+		}
+	}
+	roots, graph, err := orderRoots(root, roots)
 	if err != nil {
 		return exitcode.Wrap(exitcode.Validation, err)
 	}
 
+	var verifyKey *planverify.Key
+	requireSigned := applyRequireSig || effectiveCIMode()
+	if !dryRun {
+		if verifyKey, err = planverify.LoadVerifyKey(); err != nil {
+			return exitcode.Wrap(exitcode.Validation, err)
+		}
+		pending := roots
+		if journal != nil {
+			pending = nil
+			for _, r := range roots {
+				if l, ok := journal.Layer(r.Name); !ok || l.Status != runjournal.StatusSucceeded {
+					pending = append(pending, r)
+				}
+			}
+		}
+		for _, r := range pending {
+			if err := verifyRootPlan(cmd.Context(), root, r, verifyKey, requireSigned); err != nil {
+				return err
+			}
+		}
+		if err := enforcePlanScope(root, pending); err != nil {
+			return err
+		}
+		if err := enforcePlanRules(root, pending, approvalLabels(applyLabels)); err != nil {
+			return err
+		}
+	}
+
 	// Interactive confirmation unless --auto-approve or --dry-run
 	if !applyAutoApprove && !dryRun {
 		yellow := color.New(color.FgYellow, color.Bold)
 		yellow.Fprintln(os.Stderr, "⚠️  You are about to apply platform layer changes")
-		fmt.Fprintf(os.Stderr, "   Layers: %s\n", strings.Join(layers, ", "))
+		fmt.Fprintf(os.Stderr, "   Layers: %s\n", strings.Join(rootNames(roots), ", "))
+		if journal != nil {
+			fmt.Fprintf(os.Stderr, "   Resuming run %s; already applied: %s\n", journal.RunID, strings.Join(completedLayers(journal), ", "))
+		}
 		fmt.Fprintf(os.Stderr, "\n   Type 'yes' to proceed: ")
 		reader := bufio.NewReader(os.Stdin)
 		answer, _ := reader.ReadString('\n')
@@ -81,60 +215,83 @@ func runApply(cmd *cobra.Command, args []string) error {
 
 	bold := color.New(color.Bold)
 	bold.Fprintf(os.Stderr, "🚀 Applying platform layers\n")
-	fmt.Fprintf(os.Stderr, "   Layers: %s\n\n", strings.Join(layers, ", "))
+	fmt.Fprintf(os.Stderr, "   Layers: %s\n\n", strings.Join(rootNames(roots), ", "))
 
-	for _, layer := range layers {
-		dir := filepath.Join(root, "platform", layer)
-		if initOut, initErr := runTerraformCmd(cmd.Context(), dir, "init", "-input=false", "-no-color"); initErr != nil {
-			return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform init failed (output: %s): %w", layer, initOut, initErr))
+	runID := ""
+	if !dryRun {
+		if journal == nil {
+			journal, err = createApplyJournal(root, roots)
+		} else {
+			err = journal.Resume()
 		}
+		if err != nil {
+			return exitcode.Wrap(exitcode.Generic, err)
+		}
+		runID = journal.RunID
+		fmt.Fprintf(os.Stderr, "   Run: %s\n\n", journal.RunID)
+	}
+	ctx, logs := startRunLog(cmd.Context(), root, runID, "apply")
+	defer func() { reportRunLog(root, logs, err) }()
 
-		if dryRun {
-			out, planErr := runTerraformCmd(cmd.Context(), dir, "plan", "-input=false", "-detailed-exitcode", "-no-color")
-			add, change, destroy := parsePlanSummary(out)
-			fmt.Fprintf(os.Stderr, "   ⚡ %-20s +%d ~%d -%d (dry-run)\n", layer, add, change, destroy)
-			if planErr != nil && strings.Contains(out, "Error:") {
-				return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform plan failed (output: %s): %w", layer, out, planErr))
+	summaries := make([]*plansummary.Summary, len(roots))
+	runErr := runRoots(ctx, roots, graph, applyParallelism, func(ctx context.Context, i int) error {
+		r := roots[i]
+		if journal != nil {
+			if l, ok := journal.Layer(r.Name); ok && l.Status == runjournal.StatusSucceeded {
+				outputMu.Lock()
+				fmt.Fprintf(os.Stderr, "   ⏭️  %-20s already applied in run %s\n", r.Name, journal.RunID)
+				outputMu.Unlock()
+				return nil
+			}
+			if err := journal.Start(r.Name, savedPlanHash(filepath.Join(root, r.Dir))); err != nil {
+				return exitcode.Wrap(exitcode.Generic, err)
 			}
-			continue
 		}
-
-		planJSONPath := filepath.Join(dir, "tfplan.json")
-		planBinPath := filepath.Join(dir, "tfplan")
-
-		if fileExistsLocal(planJSONPath) {
-			if violations, verr := planverify.ValidateActions(planJSONPath); verr == nil && len(violations) > 0 {
-				color.New(color.FgRed, color.Bold).Fprintf(os.Stderr,
-					"\n   ⚠️  %d resource(s) will be DESTROYED in %s:\n", len(violations), layer)
-				for _, v := range violations {
-					fmt.Fprintf(os.Stderr, "      - %s (%s)\n", v.ResourceAddr, v.Action)
-				}
-				fmt.Fprintln(os.Stderr)
-				if effectiveCIMode() || applyAutoApprove {
-					return exitcode.Wrap(exitcode.Validation, fmt.Errorf(
-						"layer %s: %d destructive action(s) detected in CI mode; "+
-							"review plan or delete tfplan.json to bypass", layer, len(violations)))
-				}
-				// Interactive: require explicit confirmation
-				color.New(color.FgYellow).Fprintf(os.Stderr, "   Type 'yes' to confirm destruction: ")
-				reader2 := bufio.NewReader(os.Stdin)
-				answer2, _ := reader2.ReadString('\n')
-				if strings.TrimSpace(strings.ToLower(answer2)) != "yes" {
-					return fmt.Errorf("layer %s: apply canceled (destructive actions not confirmed)", layer)
-				}
-				fmt.Fprintln(os.Stderr)
+		summary, err := applyRoot(ctx, tf, root, r, verifyKey, requireSigned)
+		summaries[i] = summary
+		if journal != nil {
+			if jerr := journal.Finish(r.Name, err); jerr != nil && err == nil {
+				return exitcode.Wrap(exitcode.Generic, jerr)
 			}
 		}
+		return err
+	})
+	if journal != nil {
+		if err := journal.Complete(); err != nil && runErr == nil {
+			runErr = exitcode.Wrap(exitcode.Generic, err)
+		}
+		printApplyJournal(journal)
+		if runErr != nil {
+			fmt.Fprintf(os.Stderr, "\n   Journal: %s\n", journal.Path())
+			fmt.Fprintf(os.Stderr, "   Resume with: lzctl apply --resume %s\n", journal.RunID)
+		}
+	}
+	if runErr != nil {
+		sendNotification(cmd.Context(), applyFailedEvent(journal, runErr))
+		return runErr
+	}
 
-		applyArgs := []string{"apply", "-auto-approve", "-input=false", "-no-color"}
-		if fileExistsLocal(planBinPath) {
-			applyArgs = []string{"apply", "-input=false", "-no-color", "tfplan"}
+	if dryRun && jsonOutput {
+		type layerDryRun struct {
+			Layer string `json:"layer"`
+			Kind  string `json:"kind"`
+			*plansummary.Summary
 		}
-		if applyOut, applyErr := runTerraformCmd(cmd.Context(), dir, applyArgs...); applyErr != nil {
-			color.New(color.FgRed).Fprintf(os.Stderr, "   ❌ %-20s failed\n", layer)
-			return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform apply failed (output: %s): %w", layer, applyOut, applyErr))
+		layers := make([]layerDryRun, len(roots))
+		for i, r := range roots {
+			layers[i] = layerDryRun{Layer: r.Name, Kind: r.Kind, Summary: summaries[i]}
+		}
+		data, _ := json.MarshalIndent(map[string]interface{}{
+			"status": "dry-run",
+			"layers": layers,
+		}, "", "  ")
+		fmt.Fprintln(os.Stdout, string(data))
+	}
+
+	if dryRun {
+		if err := checkDryRunPlanRules(roots, summaries); err != nil {
+			return err
 		}
-		color.New(color.FgGreen).Fprintf(os.Stderr, "   ✅ %-20s applied\n", layer)
 	}
 
 	fmt.Fprintln(os.Stderr)
@@ -146,3 +303,225 @@ func runApply(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// checkDryRunPlanRules evaluates the repository's plan rules against the
+// plans produced by a dry run.
+func checkDryRunPlanRules(roots []localRoot, summaries []*plansummary.Summary) error {
+	rs, err := loadPlanRules()
+	if err != nil || rs == nil {
+		return err
+	}
+	layers := make([]planrules.Layer, 0, len(roots))
+	for i, r := range roots {
+		if summaries[i] != nil {
+			layers = append(layers, planrules.Layer{Name: r.Name, Kind: r.Kind, Changes: summaries[i].Resources})
+		}
+	}
+	return reportPlanRules(rs, layers, nil, approvalLabels(applyLabels))
+}
+
+// loadResumableRun loads an apply journal and rebuilds its roots, refusing
+// to resume when any root's Terraform inputs changed since the run started.
+func loadResumableRun(repo, runID string) (*runjournal.Journal, []localRoot, error) {
+	journal, err := runjournal.Load(repo, runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if journal.Command != "apply" {
+		return nil, nil, fmt.Errorf("run %s is a %q run; only apply runs can be resumed", runID, journal.Command)
+	}
+
+	roots := make([]localRoot, 0, len(journal.Layers))
+	var changed []string
+	for _, l := range journal.Layers {
+		r := localRoot{Name: l.Name, Kind: l.Kind, Zone: l.Zone, Dir: l.Dir}
+		roots = append(roots, r)
+		hash, hashErr := hashRootInputs(repo, r)
+		if hashErr != nil || hash != l.InputHash {
+			changed = append(changed, l.Name)
+		}
+	}
+	if len(changed) > 0 && journal.Status != runjournal.StatusSucceeded {
+		return nil, nil, fmt.Errorf("cannot resume run %s: plan inputs changed for %s since the run started; start a new apply",
+			runID, strings.Join(changed, ", "))
+	}
+	return journal, roots, nil
+}
+
+// createApplyJournal starts a journal for roots, recording each root's
+// input hash.
+func createApplyJournal(repo string, roots []localRoot) (*runjournal.Journal, error) {
+	layers := make([]runjournal.Layer, 0, len(roots))
+	for _, r := range roots {
+		hash, err := hashRootInputs(repo, r)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, runjournal.Layer{Name: r.Name, Kind: r.Kind, Zone: r.Zone, Dir: filepath.ToSlash(r.Dir), InputHash: hash})
+	}
+	return runjournal.Create(repo, "apply", layers)
+}
+
+// savedPlanHash hashes the saved tfplan that apply will use, if any.
+func savedPlanHash(dir string) string {
+	hash, err := orchestrator.HashFile(filepath.Join(dir, "tfplan"))
+	if err != nil {
+		return ""
+	}
+	return hash
+}
+
+func completedLayers(journal *runjournal.Journal) []string {
+	var done []string
+	for _, l := range journal.Layers {
+		if l.Status == runjournal.StatusSucceeded {
+			done = append(done, l.Name)
+		}
+	}
+	if len(done) == 0 {
+		return []string{"none"}
+	}
+	return done
+}
+
+func printApplyJournal(journal *runjournal.Journal) {
+	if !jsonOutput {
+		return
+	}
+	status := "ok"
+	if journal.Status != runjournal.StatusSucceeded {
+		status = "failed"
+	}
+	data, _ := json.MarshalIndent(map[string]interface{}{
+		"status":  status,
+		"runId":   journal.RunID,
+		"journal": journal,
+	}, "", "  ")
+	fmt.Fprintln(os.Stdout, string(data))
+}
+
+// applyRoot runs terraform init and apply (or a plan in dry-run mode) for a
+// single root, gating destructive plans behind confirmation. The saved
+// plan's signature is checked again right before it is applied. In dry-run
+// mode it returns the structured plan summary; otherwise the summary is nil.
+func applyRoot(ctx context.Context, tf orchestrator.Runner, repo string, r localRoot, verifyKey *planverify.Key, requireSigned bool) (*plansummary.Summary, error) {
+	layer := r.Name
+	dir := filepath.Join(repo, r.Dir)
+	if err := waitForRootLease(ctx, repo, r, applyLockWait, applyBreakStale); err != nil {
+		return nil, err
+	}
+	initArgs, err := rootInitArgs(repo, r)
+	if err != nil {
+		return nil, err
+	}
+	if initOut, initErr := tf.Run(ctx, dir, initArgs...); initErr != nil {
+		return nil, exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform init failed (output: %s): %w", layer, initOut, initErr))
+	}
+
+	if dryRun {
+		out, planErr := tf.Run(ctx, dir, "plan", "-input=false", "-detailed-exitcode", "-no-color", "-out="+applyDryRunPlanFile)
+		defer os.Remove(filepath.Join(dir, applyDryRunPlanFile))
+		if planErr != nil && strings.Contains(out, "Error:") {
+			return nil, exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform plan failed (output: %s): %w", layer, out, planErr))
+		}
+		summary := summarizePlan(ctx, tf, dir, applyDryRunPlanFile, "", out)
+		outputMu.Lock()
+		fmt.Fprintf(os.Stderr, "   ⚡ %-20s +%d ~%d -%d (dry-run)\n", layer, summary.Add, summary.Change, summary.Destroy)
+		outputMu.Unlock()
+		return summary, verifyRootScope(ctx, tf, repo, r, applyDryRunPlanFile)
+	}
+
+	if err := verifyRootPlan(ctx, repo, r, verifyKey, requireSigned); err != nil {
+		return nil, err
+	}
+
+	// Without a saved plan, a root whose subscriptions are declared applies
+	// a fresh plan instead, so that its scope is checked before anything
+	// changes.
+	planFile := "tfplan"
+	if !fileExistsLocal(filepath.Join(dir, planFile)) {
+		allowed, err := allowedSubscriptions()
+		if err != nil {
+			return nil, err
+		}
+		if len(allowed) > 0 {
+			planFile = applyScopePlanFile
+			out, planErr := tf.Run(ctx, dir, "plan", "-input=false", "-detailed-exitcode", "-no-color", "-out="+planFile)
+			defer os.Remove(filepath.Join(dir, planFile))
+			if planErr != nil && strings.Contains(out, "Error:") {
+				return nil, exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform plan failed (output: %s): %w", layer, out, planErr))
+			}
+		}
+	}
+	planPath := filepath.Join(dir, planFile)
+
+	if err := verifyRootScope(ctx, tf, repo, r, planFile); err != nil {
+		return nil, err
+	}
+
+	// The destructive-action gate reads tfplan.json, or renders the binary
+	// plan when it is missing, so deleting the JSON does not skip it.
+	var planJSON []byte
+	if data, err := os.ReadFile(planverify.JSONFile(planPath)); err == nil && planFile == "tfplan" {
+		planJSON = data
+	} else if fileExistsLocal(planPath) {
+		if out, err := tf.Output(ctx, dir, "show", "-json", planFile); err == nil {
+			planJSON = []byte(out)
+		}
+	}
+	if planJSON != nil {
+		if violations, verr := planverify.ValidateActionsJSON(planJSON); verr == nil && len(violations) > 0 {
+			if err := confirmDestruction(layer, violations); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	applyArgs := []string{"apply", "-auto-approve", "-input=false", "-no-color"}
+	if fileExistsLocal(planPath) {
+		applyArgs = []string{"apply", "-input=false", "-no-color", planFile}
+	}
+	if applyOut, applyErr := tf.Run(ctx, dir, applyArgs...); applyErr != nil {
+		outputMu.Lock()
+		color.New(color.FgRed).Fprintf(os.Stderr, "   ❌ %-20s failed\n", layer)
+		var partial *orchestrator.PartialApplyError
+		if errors.As(applyErr, &partial) {
+			fmt.Fprintf(os.Stderr, "      %s after resources were changed; not retried. Run lzctl plan for %s before applying again.\n", partial.Reason, layer)
+		}
+		outputMu.Unlock()
+		return nil, exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform apply failed (output: %s): %w", layer, applyOut, applyErr))
+	}
+	outputMu.Lock()
+	color.New(color.FgGreen).Fprintf(os.Stderr, "   ✅ %-20s applied\n", layer)
+	outputMu.Unlock()
+	return nil, nil
+}
+
+// confirmDestruction lists destructive actions for a root and requires an
+// interactive "yes"; in CI or with --auto-approve it refuses outright.
+// Holding outputMu keeps concurrent roots from interleaving with the prompt.
+func confirmDestruction(layer string, violations []planverify.ActionViolation) error {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	color.New(color.FgRed, color.Bold).Fprintf(os.Stderr,
+		"\n   ⚠️  %d resource(s) will be DESTROYED in %s:\n", len(violations), layer)
+	for _, v := range violations {
+		fmt.Fprintf(os.Stderr, "      - %s (%s)\n", v.ResourceAddr, v.Action)
+	}
+	fmt.Fprintln(os.Stderr)
+	if effectiveCIMode() || applyAutoApprove {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf(
+			"layer %s: %d destructive action(s) need an interactive confirmation; "+
+				"review the plan and apply without --auto-approve outside CI, or change the configuration to keep the resources", layer, len(violations)))
+	}
+	// Interactive: require explicit confirmation
+	color.New(color.FgYellow).Fprintf(os.Stderr, "   Type 'yes' to confirm destruction: ")
+	reader := bufio.NewReader(os.Stdin)
+	answer, _ := reader.ReadString('\n')
+	if strings.TrimSpace(strings.ToLower(answer)) != "yes" {
+		return fmt.Errorf("layer %s: apply canceled (destructive actions not confirmed)", layer)
+	}
+	fmt.Fprintln(os.Stderr)
+	return nil
+}
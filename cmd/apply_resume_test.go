@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useFailingApplyTerraform installs a fake whose apply fails in the
+// governance layer while *fail is true.
+func useFailingApplyTerraform(t *testing.T, fail *bool) *runnertest.Fake {
+	t.Helper()
+	return useFakeRunner(t, runnertest.New().On("apply", func(c runnertest.Call) (string, error) {
+		if *fail && c.Root() == "governance" {
+			return "Error: boom", &runnertest.ExitError{Code: 1}
+		}
+		return "Apply complete! Resources: 0 added, 0 changed, 0 destroyed.", nil
+	}))
+}
+
+type applyJournalPayload struct {
+	Status  string `json:"status"`
+	RunID   string `json:"runId"`
+	Journal struct {
+		Status string `json:"status"`
+		Layers []struct {
+			Layer     string `json:"layer"`
+			Status    string `json:"status"`
+			InputHash string `json:"inputHash"`
+		} `json:"layers"`
+	} `json:"journal"`
+}
+
+func layerStatuses(p applyJournalPayload) map[string]string {
+	out := map[string]string{}
+	for _, l := range p.Journal.Layers {
+		out[l.Layer] = l.Status
+	}
+	return out
+}
+
+func TestApplyCmd_ResumeSkipsCompletedLayers(t *testing.T) {
+	fail := true
+	tf := useFailingApplyTerraform(t, &fail)
+	repo := initRepoForCommandTests(t)
+
+	stdout, stderr, err := executeCommandWithProcessIO(t, "--json", "apply", "--repo-root", repo, "--auto-approve")
+	require.Error(t, err)
+
+	var first applyJournalPayload
+	require.NoError(t, json.Unmarshal([]byte(stdout), &first))
+	assert.Equal(t, "failed", first.Status)
+	require.NotEmpty(t, first.RunID)
+	assert.Contains(t, stderr, "lzctl apply --resume "+first.RunID)
+	statuses := layerStatuses(first)
+	assert.Equal(t, "succeeded", statuses["management-groups"])
+	assert.Equal(t, "failed", statuses["governance"])
+	assert.Equal(t, "pending", statuses["connectivity"])
+	assert.FileExists(t, filepath.Join(repo, ".lzctl", "runs", first.RunID, "journal.json"))
+
+	fail = false
+	stdout, stderr, err = executeCommandWithProcessIO(t, "--json", "apply", "--repo-root", repo, "--auto-approve", "--resume", first.RunID)
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "management-groups")
+	assert.Contains(t, stderr, "already applied in run "+first.RunID)
+
+	var resumed applyJournalPayload
+	require.NoError(t, json.Unmarshal([]byte(stdout), &resumed))
+	assert.Equal(t, "ok", resumed.Status)
+	assert.Equal(t, first.RunID, resumed.RunID)
+	for layer, status := range layerStatuses(resumed) {
+		assert.Equal(t, "succeeded", status, layer)
+	}
+	applied := 0
+	for _, root := range tf.Roots("apply") {
+		if root == "management-groups" {
+			applied++
+		}
+	}
+	assert.Equal(t, 1, applied, "completed layers must not be re-applied on resume")
+}
+
+func TestApplyCmd_ResumeRefusesChangedInputs(t *testing.T) {
+	fail := true
+	useFailingApplyTerraform(t, &fail)
+	repo := initRepoForCommandTests(t)
+
+	stdout, _, err := executeCommandWithProcessIO(t, "--json", "apply", "--repo-root", repo, "--auto-approve")
+	require.Error(t, err)
+	var first applyJournalPayload
+	require.NoError(t, json.Unmarshal([]byte(stdout), &first))
+
+	mainTF := filepath.Join(repo, "platform", "governance", "main.tf")
+	f, err := os.OpenFile(mainTF, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString("\n# edited\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	_, _, err = executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--auto-approve", "--resume", first.RunID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plan inputs changed for governance")
+}
+
+func TestApplyCmd_ResumeValidation(t *testing.T) {
+	useFakeTerraform(t, "Plan: 0 to add, 0 to change, 0 to destroy", 0)
+	repo := initRepoForCommandTests(t)
+
+	_, _, err := executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--auto-approve", "--resume", "nope")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	_, _, err = executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--auto-approve", "--resume", "nope", "--layer", "identity")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--resume cannot be combined")
+}
@@ -21,8 +21,12 @@ Outputs:
   - Markdown report (default)
   - JSON report with --json
 
-The command is read-only and does not modify Azure resources.`,
-	RunE: runAudit,
+The command is read-only and does not modify Azure resources.
+
+With --tenant, scans with the tenant's credential; a relative --output is
+written under tenants/<tenant>/. With --tenant all, audits every tenant of
+a multi-tenant repository in turn.`,
+	RunE: runForTenants(runAudit),
 }
 
 var (
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+	lztemplate "github.com/kjourdan1/lzctl/internal/template"
+)
+
+// changeReasons maps a root name to the reasons it was selected by
+// --changed-since, in the order they were discovered.
+type changeReasons map[string][]string
+
+func (c changeReasons) add(name, reason string) {
+	for _, r := range c[name] {
+		if r == reason {
+			return
+		}
+	}
+	c[name] = append(c[name], reason)
+}
+
+func (c changeReasons) addAll(roots []localRoot, reason string) {
+	for _, r := range roots {
+		c.add(r.Name, reason)
+	}
+}
+
+// selectChangedRoots narrows roots to those affected by changes between ref
+// and the working tree, plus everything downstream of them in g. Git runs in
+// the tenant root: with --env, the roots live in environments/<env>/ under
+// it and the lzctl.<env>.yaml overlay is part of the configuration.
+func selectChangedRoots(ctx context.Context, repo, ref string, roots []localRoot, g *orchestrator.Graph) ([]localRoot, changeReasons, error) {
+	base, err := absTenantRoot()
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := gitChangedFiles(ctx, base, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	tree, err := filepath.Rel(base, repo)
+	if err != nil {
+		return nil, nil, err
+	}
+	tree = filepath.ToSlash(tree)
+
+	reasons := changeReasons{}
+	configRel := relConfigPath(base)
+	overlayRel := ""
+	if envName != "" {
+		overlayRel = config.OverlayPath(configRel, envName)
+	}
+	configChanged := false
+	for _, f := range files {
+		switch {
+		case f == configRel || (overlayRel != "" && f == overlayRel):
+			configChanged = true
+		case tree == ".":
+			mapChangedFile(roots, f, reasons)
+		case strings.HasPrefix(f, tree+"/"):
+			mapChangedFile(roots, strings.TrimPrefix(f, tree+"/"), reasons)
+		}
+	}
+	if configChanged {
+		oldCfg, err := configAt(ctx, base, ref, configRel, overlayRel)
+		if err != nil {
+			return nil, nil, err
+		}
+		newCfg, err := configCache()
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading config: %w", err)
+		}
+		mapConfigSections(roots, config.ChangedSections(oldCfg, newCfg), reasons)
+	}
+
+	for _, r := range roots {
+		direct, ok := reasons[r.Name]
+		if !ok || len(direct) == 0 {
+			continue
+		}
+		for _, dep := range g.Dependents(r.Name) {
+			reasons.add(dep, "depends on "+r.Name)
+		}
+	}
+
+	selected := make([]localRoot, 0, len(reasons))
+	for _, r := range roots {
+		if len(reasons[r.Name]) > 0 {
+			selected = append(selected, r)
+		}
+	}
+	return selected, reasons, nil
+}
+
+// mapChangedFile records which roots a changed repo-relative file affects.
+func mapChangedFile(roots []localRoot, file string, reasons changeReasons) {
+	file = filepath.ToSlash(file)
+	reason := "changed: " + file
+	parts := strings.Split(file, "/")
+
+	switch {
+	case file == orchestrator.VersionFile:
+		// The pinned tool version applies to every root.
+		reasons.addAll(roots, reason)
+	case len(parts) >= 2 && parts[0] == "platform" && parts[1] == "shared":
+		// Shared backend/provider configuration feeds every platform layer.
+		addKind(roots, rootKindPlatform, reason, reasons)
+	case len(parts) >= 3 && parts[0] == "platform":
+		addNamed(roots, parts[1], reason, reasons)
+	case parts[0] == "policies":
+		addNamed(roots, "governance", reason, reasons)
+	case len(parts) >= 4 && parts[0] == "landing-zones" && parts[2] == "blueprint":
+		addNamed(roots, "lz:"+parts[1]+"-blueprint", reason, reasons)
+	case len(parts) >= 3 && parts[0] == "landing-zones":
+		addNamed(roots, "lz:"+parts[1], reason, reasons)
+	}
+}
+
+// mapConfigSections records which roots each changed lzctl.yaml section
+// affects. CI/CD and execution settings do not feed any Terraform root.
+func mapConfigSections(roots []localRoot, sections []string, reasons changeReasons) {
+	for _, section := range sections {
+		reason := "lzctl.yaml: " + section
+		switch section {
+		case config.SectionMetadata, config.SectionNaming, config.SectionStateBackend, config.SectionTerraform:
+			reasons.addAll(roots, reason)
+		case config.SectionManagementGroups:
+			addNamed(roots, "management-groups", reason, reasons)
+		case config.SectionIdentity:
+			addNamed(roots, "identity", reason, reasons)
+		case config.SectionManagement:
+			addNamed(roots, "management", reason, reasons)
+		case config.SectionGovernance:
+			addNamed(roots, "governance", reason, reasons)
+		case config.SectionConnectivity:
+			addNamed(roots, "connectivity", reason, reasons)
+		default:
+			if name, ok := landingZoneRootForSection(section); ok {
+				addNamed(roots, name, reason, reasons)
+			}
+		}
+	}
+}
+
+// landingZoneRootForSection maps a spec.landingZones[<name>] section (or its
+// .blueprint sub-section) to the root name, using the slugified directory
+// name the generator writes zones to.
+func landingZoneRootForSection(section string) (string, bool) {
+	rest, ok := strings.CutPrefix(section, "spec.landingZones[")
+	if !ok {
+		return "", false
+	}
+	blueprint := strings.HasSuffix(rest, "].blueprint")
+	rest = strings.TrimSuffix(rest, ".blueprint")
+	zone, ok := strings.CutSuffix(rest, "]")
+	if !ok {
+		return "", false
+	}
+	name := "lz:" + lztemplate.Slugify(zone)
+	if blueprint {
+		name += "-blueprint"
+	}
+	return name, true
+}
+
+func addNamed(roots []localRoot, name, reason string, reasons changeReasons) {
+	if containsRoot(roots, name) {
+		reasons.add(name, reason)
+	}
+}
+
+func addKind(roots []localRoot, kind, reason string, reasons changeReasons) {
+	for _, r := range roots {
+		if r.Kind == kind {
+			reasons.add(r.Name, reason)
+		}
+	}
+}
+
+// configAt returns the configuration at ref, merged with the overlay at
+// overlayRel when set, like configCache. It returns nil when the
+// configuration, or the environment, did not exist at ref.
+func configAt(ctx context.Context, base, ref, configRel, overlayRel string) (*config.LZConfig, error) {
+	data, existed, err := gitShowFile(ctx, base, ref, configRel)
+	if err != nil || !existed {
+		return nil, err
+	}
+	var overlay []byte
+	if overlayRel != "" {
+		if overlay, existed, err = gitShowFile(ctx, base, ref, overlayRel); err != nil {
+			return nil, err
+		}
+		if !existed {
+			// Without its overlay, the environment existed only if declared.
+			cfg, err := config.ParseEnv(configRel, "", data, nil)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s at %s: %w", configRel, ref, err)
+			}
+			if _, declared := cfg.EnvironmentNamed(envName); !declared {
+				return nil, nil
+			}
+		}
+	}
+	cfg, err := config.ParseEnv(configRel, envName, data, overlay)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s at %s: %w", configRel, ref, err)
+	}
+	return cfg, nil
+}
+
+func relConfigPath(repo string) string {
+	path := localConfigPath()
+	if !filepath.IsAbs(path) {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+	}
+	rel, err := filepath.Rel(repo, path)
+	if err != nil {
+		return "lzctl.yaml"
+	}
+	return filepath.ToSlash(rel)
+}
+
+// gitChangedFiles lists files that differ between ref and the working tree
+// (including untracked files), relative to repo.
+func gitChangedFiles(ctx context.Context, repo, ref string) ([]string, error) {
+	if _, err := runGit(ctx, repo, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("--changed-since: unknown git ref %q", ref)
+	}
+	diffOut, err := runGit(ctx, repo, "diff", "--name-only", "--relative", ref, "--")
+	if err != nil {
+		return nil, fmt.Errorf("--changed-since: git diff against %s failed: %w", ref, err)
+	}
+	untrackedOut, err := runGit(ctx, repo, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("--changed-since: listing untracked files failed: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var files []string
+	for _, line := range strings.Split(diffOut+"\n"+untrackedOut, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !seen[line] {
+			seen[line] = true
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// gitShowFile returns the content of a repo-relative file at ref, and false
+// when the file did not exist at that ref.
+func gitShowFile(ctx context.Context, repo, ref, rel string) ([]byte, bool, error) {
+	out, err := runGit(ctx, repo, "show", ref+":./"+rel)
+	if err != nil {
+		if strings.Contains(out, "does not exist") || strings.Contains(out, "exists on disk, but not in") {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("git show %s:%s failed: %s", ref, rel, strings.TrimSpace(out))
+	}
+	return []byte(out), true, nil
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+)
+
+func changedSinceTestRoots() []localRoot {
+	return []localRoot{
+		{Name: "management-groups", Kind: rootKindPlatform},
+		{Name: "governance", Kind: rootKindPlatform},
+		{Name: "connectivity", Kind: rootKindPlatform},
+		{Name: "lz:app-one", Kind: rootKindLandingZone, Zone: "app-one"},
+		{Name: "lz:app-one-blueprint", Kind: rootKindBlueprint, Zone: "app-one"},
+	}
+}
+
+func TestMapChangedFile(t *testing.T) {
+	roots := changedSinceTestRoots()
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"platform/connectivity/main.tf", []string{"connectivity"}},
+		{"platform/shared/backend.tf", []string{"management-groups", "governance", "connectivity"}},
+		{"policies/definitions/deny-public-ip.json", []string{"governance"}},
+		{"landing-zones/app-one/main.tf", []string{"lz:app-one"}},
+		{"landing-zones/app-one/blueprint/main.tf", []string{"lz:app-one-blueprint"}},
+		{"landing-zones/unknown/main.tf", nil},
+		{"docs/README.md", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			reasons := changeReasons{}
+			mapChangedFile(roots, tt.file, reasons)
+			var got []string
+			for _, r := range roots {
+				if len(reasons[r.Name]) > 0 {
+					got = append(got, r.Name)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMapConfigSections(t *testing.T) {
+	roots := changedSinceTestRoots()
+	reasons := changeReasons{}
+	mapConfigSections(roots, []string{
+		config.BlueprintSection("App One"),
+		config.SectionCICD,
+		config.SectionConnectivity,
+	}, reasons)
+
+	assert.Equal(t, []string{"lzctl.yaml: spec.platform.connectivity"}, reasons["connectivity"])
+	assert.Equal(t, []string{"lzctl.yaml: spec.landingZones[App One].blueprint"}, reasons["lz:app-one-blueprint"])
+	assert.Empty(t, reasons["lz:app-one"])
+	assert.Empty(t, reasons["governance"])
+
+	reasons = changeReasons{}
+	mapConfigSections(roots, []string{config.SectionNaming}, reasons)
+	assert.Len(t, reasons, len(roots))
+}
+
+// useFakeTerraformWithGit installs the fake terraform runner for tests that
+// also drive a real git repository.
+func useFakeTerraformWithGit(t *testing.T, planLine string, planExitCode int) *runnertest.Fake {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	return useFakeTerraform(t, planLine, planExitCode)
+}
+
+// commitAllForTest makes repo a git repository with everything committed.
+func commitAllForTest(t *testing.T, repo string) {
+	t.Helper()
+	for _, args := range [][]string{{"init", "-q"}, {"add", "-A"}, {"commit", "-q", "-m", "baseline"}} {
+		c := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		c.Dir = repo
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+}
+
+func TestPlanCmd_ChangedSince_SelectsAffectedRoots(t *testing.T) {
+	tf := useFakeTerraformWithGit(t, "Plan: 1 to add, 0 to change, 0 to destroy", 2)
+	repo := initRepoForCommandTests(t)
+	for _, zone := range []string{"app-one", "app-two"} {
+		dir := filepath.Join(repo, "landing-zones", zone)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("# "+zone+"\n"), 0o644))
+	}
+
+	commitAllForTest(t, repo)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "landing-zones", "app-two", "main.tf"), []byte("# changed\n"), 0o644))
+
+	stdout, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--changed-since", "HEAD", "--json")
+	require.NoError(t, err)
+
+	var payload struct {
+		ChangedSince string `json:"changedSince"`
+		Layers       []struct {
+			Layer   string   `json:"layer"`
+			Reasons []string `json:"reasons"`
+		} `json:"layers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.Equal(t, "HEAD", payload.ChangedSince)
+	require.Len(t, payload.Layers, 1)
+	assert.Equal(t, "lz:app-two", payload.Layers[0].Layer)
+	assert.Equal(t, []string{"changed: landing-zones/app-two/main.tf"}, payload.Layers[0].Reasons)
+	assert.Equal(t, []string{"app-two"}, tf.Roots("plan"))
+}
+
+func TestPlanCmd_ChangedSince_UnknownRef(t *testing.T) {
+	useFakeTerraformWithGit(t, "Plan: 0 to add, 0 to change, 0 to destroy", 0)
+	repo := initRepoForCommandTests(t)
+	c := exec.Command("git", "init", "-q")
+	c.Dir = repo
+	require.NoError(t, c.Run())
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--changed-since", "does-not-exist")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown git ref")
+}
+
+func TestPlanCmd_ChangedSince_Env(t *testing.T) {
+	useFakeTerraformWithGit(t, "Plan: 1 to add, 0 to change, 0 to destroy", 2)
+	repo := initEnvRepoForCommandTests(t)
+	commitAllForTest(t, repo)
+
+	// Only the overlay's hub address space and one file of each tree change.
+	overlay := strings.Replace(devOverlay, "10.10.0.0/16", "10.11.0.0/16", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "lzctl.dev.yaml"), []byte(overlay), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "environments", "dev", "platform", "connectivity", "extra.tf"), []byte("# dev tree\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "platform", "identity", "extra.tf"), []byte("# base tree\n"), 0o644))
+
+	stdout, _, err := executeCommandWithProcessIO(t, "plan", "--env", "dev", "--repo-root", repo, "--changed-since", "HEAD", "--json")
+	require.NoError(t, err)
+
+	var payload struct {
+		Layers []struct {
+			Layer   string   `json:"layer"`
+			Reasons []string `json:"reasons"`
+		} `json:"layers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	require.Len(t, payload.Layers, 1, "unchanged overlay keys and the base tree select nothing")
+	assert.Equal(t, "connectivity", payload.Layers[0].Layer)
+	assert.Equal(t, []string{"changed: platform/connectivity/extra.tf", "lzctl.yaml: spec.platform.connectivity"}, payload.Layers[0].Reasons)
+}
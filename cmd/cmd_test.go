@@ -30,6 +30,11 @@ func executeCommand(args ...string) (string, string, error) {
 	// Reset all flag defaults to avoid state leaking between tests.
 	resetFlags := func(cmd *cobra.Command) {
 		cmd.Flags().VisitAll(func(f *pflag.Flag) {
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				_ = sv.Replace(nil)
+				f.Changed = false
+				return
+			}
 			_ = f.Value.Set(f.DefValue)
 			f.Changed = false
 		})
@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+)
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate lzctl.yaml to the current apiVersion",
+	Long: `Upgrades lzctl.yaml from an older apiVersion to the one this version of
+lzctl uses, by running the registered migration steps in order
+(lzctl/v1 → lzctl/v2 → ...). The lzctl.<env>.yaml overlays next to it are
+migrated with it. Comments and key order are kept.
+
+Commands loading an older lzctl.yaml migrate it in memory and warn; this
+command updates the files.
+
+Use --dry-run to print the steps and a diff of each file without writing.
+
+Examples:
+  lzctl config migrate --dry-run
+  lzctl config migrate
+  lzctl config migrate --tenant contoso`,
+	Args: cobra.NoArgs,
+	RunE: runConfigMigrate,
+}
+
+func init() {
+	configCmd.AddCommand(configMigrateCmd)
+}
+
+// migratedFile is a configuration file and its migrated content.
+type migratedFile struct {
+	Path   string
+	Before []byte
+	After  []byte
+}
+
+func runConfigMigrate(_ *cobra.Command, _ []string) error {
+	if _, err := absTenantRoot(); err != nil {
+		return err
+	}
+	if envName != "" {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("config migrate migrates lzctl.yaml together with every overlay: run it without --env"))
+	}
+
+	path := localConfigPath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("reading config file %s: %w", path, err))
+	}
+	migrated, steps, err := config.SchemaMigrations.MigrateYAML(data, "")
+	if err != nil {
+		if errors.Is(err, config.ErrUnknownAPIVersion) {
+			return exitcode.Wrap(exitcode.Validation, fmt.Errorf("%s: %w", path, err))
+		}
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	current := config.SchemaMigrations.Current()
+
+	if len(steps) == 0 {
+		if jsonOutput {
+			return printConfigMigrateJSON("up-to-date", "", current, nil, nil)
+		}
+		color.New(color.FgGreen, color.Bold).Fprintf(os.Stderr, "✅ %s is already at %s\n", path, current)
+		return nil
+	}
+
+	from := steps[0].From
+	files := []migratedFile{{Path: path, Before: data, After: migrated}}
+	overlays, err := config.OverlayPaths(path)
+	if err != nil {
+		return err
+	}
+	for _, overlay := range overlays {
+		before, readErr := os.ReadFile(overlay)
+		if readErr != nil {
+			return fmt.Errorf("reading overlay %s: %w", overlay, readErr)
+		}
+		after, _, migrateErr := config.SchemaMigrations.MigrateYAML(before, from)
+		if migrateErr != nil {
+			return fmt.Errorf("%s: %w", overlay, migrateErr)
+		}
+		if !bytes.Equal(before, after) {
+			files = append(files, migratedFile{Path: overlay, Before: before, After: after})
+		}
+	}
+
+	if !dryRun {
+		for _, f := range files {
+			mode := os.FileMode(0o600)
+			if info, statErr := os.Stat(f.Path); statErr == nil {
+				mode = info.Mode().Perm()
+			}
+			if writeErr := os.WriteFile(f.Path, f.After, mode); writeErr != nil {
+				return fmt.Errorf("writing %s: %w", f.Path, writeErr)
+			}
+		}
+	}
+
+	if jsonOutput {
+		status := "migrated"
+		if dryRun {
+			status = "dry-run"
+		}
+		return printConfigMigrateJSON(status, from, current, steps, files)
+	}
+
+	bold := color.New(color.Bold)
+	if dryRun {
+		color.New(color.FgYellow).Fprintf(os.Stderr, "⚡ [DRY-RUN] %s would be migrated from %s to %s\n\n", path, from, current)
+	} else {
+		bold.Fprintf(os.Stderr, "🔄 Migrating %s from %s to %s\n\n", path, from, current)
+	}
+	for _, step := range steps {
+		fmt.Fprintf(os.Stderr, "  • %s → %s: %s\n", step.From, step.To, step.Description)
+	}
+	fmt.Fprintln(os.Stderr)
+
+	if dryRun {
+		for _, f := range files {
+			fmt.Fprint(os.Stdout, configDiff(f))
+		}
+		return nil
+	}
+	for _, f := range files {
+		fmt.Fprintf(os.Stderr, "  📝 %s\n", f.Path)
+	}
+	color.New(color.FgGreen, color.Bold).Fprintf(os.Stderr, "\n✅ %d file(s) migrated to %s — run lzctl validate, then commit the change\n", len(files), current)
+	return nil
+}
+
+// configDiff returns the unified diff of a migrated file.
+func configDiff(f migratedFile) string {
+	name := filepath.ToSlash(f.Path)
+	return unifiedDiff("a/"+name, "b/"+name, string(f.Before), string(f.After), 3)
+}
+
+// diffLine is one line of a line diff: kept (' '), removed ('-') or added
+// ('+'), with the 0-based positions in both files before it.
+type diffLine struct {
+	op   byte
+	text string
+	a, b int
+}
+
+// unifiedDiff returns the unified diff of before and after with context
+// lines around each change, or "" when they are equal. Configuration files
+// are small: the longest common subsequence is computed in full.
+func unifiedDiff(fromFile, toFile, before, after string, context int) string {
+	a, b := splitDiffLines(before), splitDiffLines(after)
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i], i, j})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i], i, j})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j], i, j})
+			j++
+		}
+	}
+
+	var out strings.Builder
+	for k := 0; k < len(lines); {
+		if lines[k].op == ' ' {
+			k++
+			continue
+		}
+		// A hunk runs until a stretch of more than 2*context kept lines.
+		start, end := max(k-context, 0), k
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next == len(lines) || next-end > 2*context {
+				end = min(end+context, len(lines))
+				break
+			}
+			end = next
+		}
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromFile, toFile)
+		}
+		hunk := lines[start:end]
+		var aLen, bLen int
+		for _, l := range hunk {
+			if l.op != '+' {
+				aLen++
+			}
+			if l.op != '-' {
+				bLen++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", diffRange(hunk[0].a, aLen), diffRange(hunk[0].b, bLen))
+		for _, l := range hunk {
+			out.WriteByte(l.op)
+			out.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				out.WriteString("\n")
+			}
+		}
+		k = end
+	}
+	return out.String()
+}
+
+// diffRange formats a hunk range: 1-based start and length, the length
+// omitted when it is 1 and the start being the line before an empty range.
+func diffRange(start, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// splitDiffLines splits s into lines, each keeping its newline.
+func splitDiffLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func printConfigMigrateJSON(status, from, to string, steps []config.MigrationStep, files []migratedFile) error {
+	type stepJSON struct {
+		From        string `json:"from"`
+		To          string `json:"to"`
+		Description string `json:"description"`
+	}
+	type fileJSON struct {
+		Path string `json:"path"`
+		Diff string `json:"diff"`
+	}
+	stepList := make([]stepJSON, 0, len(steps))
+	for _, s := range steps {
+		stepList = append(stepList, stepJSON{From: s.From, To: s.To, Description: s.Description})
+	}
+	fileList := make([]fileJSON, 0, len(files))
+	for _, f := range files {
+		fileList = append(fileList, fileJSON{Path: f.Path, Diff: configDiff(f)})
+	}
+	data, _ := json.MarshalIndent(map[string]interface{}{
+		"status": status,
+		"from":   from,
+		"to":     to,
+		"steps":  stepList,
+		"files":  fileList,
+	}, "", "  ")
+	fmt.Fprintln(os.Stdout, string(data))
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+)
+
+const v0Config = `apiVersion: lzctl/v0
+kind: LandingZone
+metadata:
+  name: contoso # kept
+spec:
+  state:
+    storageAccount: sttfstate
+`
+
+// useV0Migration registers a lzctl/v0 → current step renaming spec.state
+// to spec.stateBackend for the duration of the test.
+func useV0Migration(t *testing.T) {
+	t.Helper()
+	orig := config.SchemaMigrations
+	config.SchemaMigrations = config.NewMigrations(config.CurrentAPIVersion)
+	config.SchemaMigrations.Register(config.MigrationStep{
+		From:        "lzctl/v0",
+		To:          config.CurrentAPIVersion,
+		Description: "spec.state becomes spec.stateBackend",
+		Apply: func(doc *config.Document) error {
+			_, err := doc.Move("spec.state", "spec.stateBackend")
+			return err
+		},
+	})
+	t.Cleanup(func() { config.SchemaMigrations = orig })
+}
+
+func writeV0Repo(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "lzctl.yaml"), []byte(v0Config), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "lzctl.dev.yaml"), []byte("spec:\n  state:\n    storageAccount: sttfstatedev\n"), 0o644))
+	return repo
+}
+
+func TestConfigMigrate_DryRunShowsDiff(t *testing.T) {
+	useV0Migration(t)
+	repo := writeV0Repo(t)
+
+	stdout, stderr, err := executeCommandWithProcessIO(t, "config", "migrate", "--dry-run", "--repo-root", repo)
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "lzctl/v0 → lzctl/v1: spec.state becomes spec.stateBackend")
+	assert.Contains(t, stdout, "-apiVersion: lzctl/v0")
+	assert.Contains(t, stdout, "+apiVersion: lzctl/v1")
+	assert.Contains(t, stdout, "+  stateBackend:")
+	assert.Contains(t, stdout, "lzctl.dev.yaml")
+	assert.Equal(t, v0Config, readRepoFile(t, repo, "lzctl.yaml"), "--dry-run writes nothing")
+}
+
+func TestConfigMigrate_WritesBaseAndOverlays(t *testing.T) {
+	useV0Migration(t)
+	repo := writeV0Repo(t)
+
+	stdout, _, err := executeCommandWithProcessIO(t, "config", "migrate", "--repo-root", repo, "--json")
+	require.NoError(t, err)
+	var payload struct {
+		Status string `json:"status"`
+		From   string `json:"from"`
+		To     string `json:"to"`
+		Files  []struct {
+			Path string `json:"path"`
+		} `json:"files"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.Equal(t, "migrated", payload.Status)
+	assert.Equal(t, "lzctl/v0", payload.From)
+	assert.Equal(t, config.CurrentAPIVersion, payload.To)
+	assert.Len(t, payload.Files, 2)
+
+	assert.Equal(t, "apiVersion: lzctl/v1\nkind: LandingZone\nmetadata:\n  name: contoso # kept\nspec:\n  stateBackend:\n    storageAccount: sttfstate\n", readRepoFile(t, repo, "lzctl.yaml"))
+	assert.Equal(t, "spec:\n  stateBackend:\n    storageAccount: sttfstatedev\n", readRepoFile(t, repo, "lzctl.dev.yaml"))
+
+	_, stderr, err := executeCommandWithProcessIO(t, "config", "migrate", "--repo-root", repo)
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "is already at lzctl/v1")
+}
+
+func TestConfigMigrate_Refusals(t *testing.T) {
+	useV0Migration(t)
+	repo := writeV0Repo(t)
+
+	_, _, err := executeCommandWithProcessIO(t, "config", "migrate", "--env", "dev", "--repo-root", repo)
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "lzctl.yaml"), []byte("apiVersion: lzctl/v9\n"), 0o644))
+	_, _, err = executeCommandWithProcessIO(t, "config", "migrate", "--repo-root", repo)
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+	assert.ErrorIs(t, err, config.ErrUnknownAPIVersion)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	after := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+
+	assert.Equal(t, "--- a/x\n+++ b/x\n"+
+		"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n"+
+		"@@ -9,3 +9,4 @@\n i\n j\n k\n+l\n",
+		unifiedDiff("a/x", "b/x", before, after, 3))
+	assert.Empty(t, unifiedDiff("a/x", "b/x", before, before, 3))
+	assert.Equal(t, "--- a/x\n+++ b/x\n@@ -0,0 +1 @@\n+a\n", unifiedDiff("a/x", "b/x", "", "a", 3))
+}
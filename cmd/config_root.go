@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the lzctl.yaml configuration file",
+	Long: `Maintain lzctl.yaml itself, rather than the infrastructure it describes.
+
+  migrate   Upgrade lzctl.yaml and its overlays to the current apiVersion`,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
@@ -13,9 +13,13 @@ import (
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
 	Short: "Check prerequisites and environment readiness",
-	Long: `Verify that all required tools (terraform, az, git), Azure session,
+	Long: `Verify that all required tools (terraform or tofu, az, git), Azure session,
 and resource providers are correctly configured.
 
+The Terraform check targets the tool selected for the repository
+(spec.terraform in lzctl.yaml, then .terraform-version) and enforces the
+same minimum version as plan, apply and drift.
+
 Each check reports ✅ (pass), ❌ (fail), or ⚠️ (warning) with an
 actionable fix suggestion.
 
@@ -30,9 +34,18 @@ func init() {
 func runDoctor(cmd *cobra.Command, _ []string) error {
 	output.Init(verbosity > 0, jsonOutput)
 
+	root, err := absRepoRoot()
+	if err != nil {
+		return err
+	}
+	tool, err := toolSpecFor(root)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+
 	ctx := context.Background()
 	executor := doctor.NewRealExecutor()
-	summary := doctor.RunAll(ctx, executor)
+	summary := doctor.RunAllFor(ctx, executor, tool)
 
 	doctor.PrintResults(summary)
 
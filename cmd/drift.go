@@ -1,55 +1,104 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/notify"
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+	"github.com/kjourdan1/lzctl/internal/plansummary"
 )
 
 var driftCmd = &cobra.Command{
 	Use:   "drift",
 	Short: "Detect configuration drift between desired state and actual Azure state",
-	Long: `Runs 'terraform plan' across platform layers to detect drift
-(manual changes made outside of IaC).
+	Long: `Detects drift (manual changes made outside of IaC) across platform layers.
 
-Reports per-layer:
-  - Resources to add (missing from Azure)
-  - Resources to change (modified outside Terraform)
-  - Resources to destroy (unexpected in state)
+Each layer is planned twice:
+  - 'terraform plan -refresh-only' isolates out-of-band changes: resources
+    modified or deleted in Azure since the last apply
+  - 'terraform plan -refresh=false' isolates pending code changes: changes
+    in the repository that have not been applied yet
 
-Use --layer to check a single layer, or omit to check all.
-Use --json for machine-readable output.`,
-	RunE: runDrift,
+Each layer is reported as in-sync, drifted, pending-changes or both. Only
+true drift makes the command fail; pending code changes are reported but
+are not drift.
+
+Landing zones and their blueprints are checked after the platform layers.
+
+Use --layer to check a single layer, --zone to check a landing zone and its
+blueprint, or --blueprint to check only a zone's blueprint. Omit all three
+to check everything. Use --parallelism N to check up to N independent
+landing zones at once.
+Use --json for machine-readable output, including the drifted resources
+with their attribute-level differences (sensitive values masked) and the
+pending change list, both derived from 'terraform show -json'. Use -v to
+print the attribute differences.
+
+With --tenant all, checks every tenant of a multi-tenant repository in turn,
+each with its own credential, and reports the highest exit code.`,
+	RunE: runForTenants(runDrift),
 }
 
 var (
-	driftLayer string
+	driftLayer       string
+	driftZone        string
+	driftBlueprint   string
+	driftParallelism int
+)
+
+// Scratch plans used only to read the drift as JSON; they are kept apart
+// from tfplan so a drift check never replaces a reviewed plan.
+const (
+	driftPlanFile        = "tfplan.drift"
+	driftPendingPlanFile = "tfplan.pending"
+)
+
+// Layer classifications reported by drift.
+const (
+	driftInSync         = "in-sync"
+	driftDrifted        = "drifted"
+	driftPendingChanges = "pending-changes"
+	driftBoth           = "both"
+	driftError          = "error"
 )
 
 func init() {
 	driftCmd.Flags().StringVar(&driftLayer, "layer", "", "specific layer to check")
+	driftCmd.Flags().StringVar(&driftZone, "zone", "", "landing zone to check (includes its blueprint)")
+	driftCmd.Flags().StringVar(&driftBlueprint, "blueprint", "", "landing zone whose blueprint to check")
+	driftCmd.Flags().IntVar(&driftParallelism, "parallelism", 1, "number of independent roots to check concurrently")
 
 	rootCmd.AddCommand(driftCmd)
 }
 
-func runDrift(cmd *cobra.Command, args []string) error {
+func runDrift(cmd *cobra.Command, args []string) (err error) {
 	root, err := absRepoRoot()
 	if err != nil {
 		return err
 	}
 
-	if err := ensureTerraformInstalled(); err != nil {
+	tf, err := resolveRunner(cmd.Context(), root)
+	if err != nil {
 		return exitcode.Wrap(exitcode.Validation, err)
 	}
+	ctx, logs := startRunLog(cmd.Context(), root, "", "drift")
+	defer func() { reportRunLog(root, logs, err) }()
 
-	layers, err := resolveLocalLayers(root, driftLayer)
+	roots, err := resolveLocalRoots(root, rootSelection{Layer: driftLayer, Zone: driftZone, Blueprint: driftBlueprint})
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	roots, graph, err := orderRoots(root, roots)
 	if err != nil {
 		return exitcode.Wrap(exitcode.Validation, err)
 	}
@@ -59,63 +108,35 @@ func runDrift(cmd *cobra.Command, args []string) error {
 		bold.Fprintf(os.Stderr, "🔄 Detecting drift across platform layers\n\n")
 	}
 
-	type layerDrift struct {
-		Layer   string `json:"layer"`
-		Add     int    `json:"add"`
-		Change  int    `json:"change"`
-		Destroy int    `json:"destroy"`
-		Total   int    `json:"total"`
-		Error   string `json:"error,omitempty"`
+	results, runErr := checkDrift(ctx, tf, root, roots, graph, driftParallelism, !jsonOutput)
+	if runErr != nil {
+		return exitcode.Wrap(exitcode.Terraform, runErr)
 	}
-	results := make([]layerDrift, 0, len(layers))
-	totalDrift := 0
 
-	for _, layer := range layers {
-		dir := filepath.Join(root, "platform", layer)
-		ld := layerDrift{Layer: layer}
-
-		if initOut, initErr := runTerraformCmd(cmd.Context(), dir, "init", "-input=false", "-no-color"); initErr != nil {
-			ld.Error = fmt.Sprintf("terraform init failed: %s", initOut)
-			results = append(results, ld)
-			if !jsonOutput {
-				color.New(color.FgRed).Fprintf(os.Stderr, "   ❌ %-20s init failed\n", layer)
-			}
-			continue
-		}
-
-		out, planErr := runTerraformCmd(cmd.Context(), dir, "plan", "-input=false", "-detailed-exitcode", "-no-color")
-		add, change, destroy := parsePlanSummary(out)
-		ld.Add = add
-		ld.Change = change
-		ld.Destroy = destroy
-		ld.Total = add + change + destroy
+	totalDrift, totalPending := 0, 0
+	for _, ld := range results {
 		totalDrift += ld.Total
-
-		if planErr != nil && strings.Contains(out, "Error:") {
-			ld.Error = "terraform plan failed"
-		}
-
-		results = append(results, ld)
-
-		if !jsonOutput {
-			if ld.Total == 0 {
-				color.New(color.FgGreen).Fprintf(os.Stderr, "   ✅ %-20s no drift\n", layer)
-			} else {
-				color.New(color.FgYellow).Fprintf(os.Stderr, "   ⚠️  %-20s +%d ~%d -%d\n", layer, add, change, destroy)
-			}
-		}
+		totalPending += ld.Pending.Total
 	}
 
 	if jsonOutput {
 		status := "ok"
-		if totalDrift > 0 {
+		switch {
+		case totalDrift > 0:
 			status = "drift-detected"
+		case totalPending > 0:
+			status = driftPendingChanges
+		}
+		payload := map[string]interface{}{
+			"status":       status,
+			"totalDrift":   totalDrift,
+			"totalPending": totalPending,
+			"layers":       results,
 		}
-		data, _ := json.MarshalIndent(map[string]interface{}{
-			"status":     status,
-			"totalDrift": totalDrift,
-			"layers":     results,
-		}, "", "  ")
+		if tenantName != "" {
+			payload["tenant"] = tenantName
+		}
+		data, _ := json.MarshalIndent(payload, "", "  ")
 		fmt.Fprintln(os.Stdout, string(data))
 	} else {
 		fmt.Fprintln(os.Stderr)
@@ -123,9 +144,28 @@ func runDrift(cmd *cobra.Command, args []string) error {
 			color.New(color.FgGreen, color.Bold).Fprintln(os.Stderr, "✅ No drift detected!")
 		} else {
 			color.New(color.FgYellow, color.Bold).Fprintf(os.Stderr,
-				"⚠️  %d drift item(s) detected across %d layer(s)\n", totalDrift, len(layers))
+				"⚠️  %d drift item(s) detected across %d layer(s)\n", totalDrift, len(roots))
 			fmt.Fprintln(os.Stderr, "   Run: lzctl apply  to reconcile drift")
 		}
+		if totalPending > 0 {
+			fmt.Fprintf(os.Stderr, "   %d pending change(s) in code are not applied yet (see: lzctl plan)\n", totalPending)
+		}
+	}
+
+	if totalDrift > 0 {
+		e := notify.Event{
+			Type:     notify.EventDrift,
+			Severity: notify.SeverityWarning,
+			Title:    "Drift detected",
+			Summary:  fmt.Sprintf("%d drift item(s) detected outside Terraform.", totalDrift),
+			Details:  map[string]interface{}{"totalDrift": totalDrift, "totalPending": totalPending, "layers": results},
+		}
+		for _, ld := range results {
+			if ld.Status == driftDrifted || ld.Status == driftBoth {
+				e.Facts = append(e.Facts, notify.Fact{Name: ld.Layer, Value: fmt.Sprintf("%s ~%d -%d", ld.Status, ld.Change, ld.Destroy)})
+			}
+		}
+		sendNotification(cmd.Context(), e)
 	}
 
 	if totalDrift > 0 {
@@ -134,3 +174,189 @@ func runDrift(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// driftPending describes a layer's unapplied code changes.
+type driftPending struct {
+	Add       int                          `json:"add"`
+	Change    int                          `json:"change"`
+	Destroy   int                          `json:"destroy"`
+	Total     int                          `json:"total"`
+	Resources []plansummary.ResourceChange `json:"resources,omitempty"`
+}
+
+// layerDrift is the drift check result of one root. Add, Change, Destroy,
+// Total and Resources describe the drift itself.
+type layerDrift struct {
+	Layer   string `json:"layer"`
+	Kind    string `json:"kind"`
+	Status  string `json:"status"`
+	Add     int    `json:"add"`
+	Change  int    `json:"change"`
+	Destroy int    `json:"destroy"`
+	Total   int    `json:"total"`
+	Error   string `json:"error,omitempty"`
+
+	Resources []plansummary.ResourceChange `json:"resources,omitempty"`
+	Pending   driftPending                 `json:"pending"`
+
+	Duration time.Duration `json:"-"` // time spent planning the root
+}
+
+// checkDrift runs the drift check on roots, in dependency order, and
+// returns one result per root. Per-root failures are recorded on the
+// result; the error is only set when the run itself stops. With progress,
+// a line per root is printed to stderr.
+func checkDrift(ctx context.Context, tf orchestrator.Runner, repo string, roots []localRoot, graph *orchestrator.Graph, parallelism int, progress bool) ([]layerDrift, error) {
+	results := make([]layerDrift, len(roots))
+
+	err := runRoots(ctx, roots, graph, parallelism, func(ctx context.Context, i int) error {
+		r := roots[i]
+		layer := r.Name
+		dir := filepath.Join(repo, r.Dir)
+		ld := layerDrift{Layer: layer, Kind: r.Kind, Status: driftError}
+		start := time.Now()
+
+		initArgs, err := rootInitArgs(repo, r)
+		if err != nil {
+			return err
+		}
+		if initOut, initErr := tf.Run(ctx, dir, initArgs...); initErr != nil {
+			ld.Error = fmt.Sprintf("terraform init failed: %s", initOut)
+			ld.Duration = time.Since(start)
+			results[i] = ld
+			if progress {
+				outputMu.Lock()
+				color.New(color.FgRed).Fprintf(os.Stderr, "   ❌ %-20s init failed\n", layer)
+				outputMu.Unlock()
+			}
+			return nil
+		}
+
+		drift, pending, planErr := planDrift(ctx, tf, dir)
+		if planErr != nil {
+			ld.Error = planErr.Error()
+		} else {
+			ld.Add, ld.Change, ld.Destroy, ld.Resources = drift.Add, drift.Change, drift.Destroy, drift.Resources
+			ld.Total = drift.Total()
+			ld.Pending = driftPending{
+				Add: pending.Add, Change: pending.Change, Destroy: pending.Destroy,
+				Total: pending.Total(), Resources: pending.Resources,
+			}
+			ld.Status = classifyDrift(drift.HasChanges(), pending.HasChanges())
+		}
+		ld.Duration = time.Since(start)
+
+		results[i] = ld
+
+		if progress {
+			outputMu.Lock()
+			defer outputMu.Unlock()
+			p := ld.Pending
+			switch ld.Status {
+			case driftError:
+				color.New(color.FgRed).Fprintf(os.Stderr, "   ❌ %-20s %s\n", layer, ld.Error)
+			case driftInSync:
+				color.New(color.FgGreen).Fprintf(os.Stderr, "   ✅ %-20s in sync\n", layer)
+			case driftPendingChanges:
+				color.New(color.FgCyan).Fprintf(os.Stderr, "   📝 %-20s pending changes +%d ~%d -%d (not applied yet)\n", layer, p.Add, p.Change, p.Destroy)
+			case driftDrifted:
+				color.New(color.FgYellow).Fprintf(os.Stderr, "   ⚠️  %-20s drifted ~%d -%d\n", layer, ld.Change, ld.Destroy)
+			case driftBoth:
+				color.New(color.FgYellow).Fprintf(os.Stderr, "   ⚠️  %-20s drifted ~%d -%d, pending changes +%d ~%d -%d\n",
+					layer, ld.Change, ld.Destroy, p.Add, p.Change, p.Destroy)
+			}
+			if verbosity > 0 {
+				printDriftAttributes(ld.Resources)
+			}
+		}
+		return nil
+	})
+	return results, err
+}
+
+// planDrift runs the two scratch plans of a drift check in dir: a
+// refresh-only plan for out-of-band changes and a plan without refresh for
+// pending code changes. Counts come from the JSON plans, falling back to the
+// human summary line.
+func planDrift(ctx context.Context, tf orchestrator.Runner, dir string) (drift, pending *plansummary.Summary, err error) {
+	defer func() {
+		_ = os.Remove(filepath.Join(dir, driftPlanFile))
+		_ = os.Remove(filepath.Join(dir, driftPendingPlanFile))
+	}()
+
+	out, planErr := tf.Run(ctx, dir, "plan", "-refresh-only", "-input=false", "-detailed-exitcode", "-no-color", "-out="+driftPlanFile)
+	if planErr != nil && strings.Contains(out, "Error:") {
+		return nil, nil, fmt.Errorf("terraform plan -refresh-only failed")
+	}
+	drift = summarizeDrift(ctx, tf, dir, driftPlanFile, out)
+
+	out, planErr = tf.Run(ctx, dir, "plan", "-refresh=false", "-input=false", "-detailed-exitcode", "-no-color", "-out="+driftPendingPlanFile)
+	if planErr != nil && strings.Contains(out, "Error:") {
+		return nil, nil, fmt.Errorf("terraform plan -refresh=false failed")
+	}
+	pending = summarizePlan(ctx, tf, dir, driftPendingPlanFile, "", out)
+	return drift, pending, nil
+}
+
+// summarizeDrift is summarizePlan for refresh-only plans: it reads the
+// resource_drift section of the JSON plan.
+func summarizeDrift(ctx context.Context, tf orchestrator.Runner, dir, planFile, planOutput string) *plansummary.Summary {
+	if jsonOut, err := tf.Output(ctx, dir, "show", "-json", planFile); err == nil {
+		if s, parseErr := plansummary.ParseDrift([]byte(jsonOut)); parseErr == nil {
+			return s
+		}
+	}
+	add, change, destroy := parsePlanSummary(planOutput)
+	return &plansummary.Summary{Add: add, Change: change, Destroy: destroy, Resources: []plansummary.ResourceChange{}}
+}
+
+// classifyDrift names a layer's state from whether it has out-of-band
+// changes and whether it has unapplied code changes.
+func classifyDrift(drifted, pending bool) string {
+	switch {
+	case drifted && pending:
+		return driftBoth
+	case drifted:
+		return driftDrifted
+	case pending:
+		return driftPendingChanges
+	}
+	return driftInSync
+}
+
+// printDriftAttributes lists the attribute differences of drifted resources.
+func printDriftAttributes(resources []plansummary.ResourceChange) {
+	for _, rc := range resources {
+		fmt.Fprintf(os.Stderr, "      %s %s\n", driftActionSymbol(rc.Action), rc.Address)
+		for _, a := range rc.Attributes {
+			fmt.Fprintf(os.Stderr, "          %s: %s → %s\n", a.Path, formatDriftValue(a.Before), formatDriftValue(a.After))
+		}
+	}
+}
+
+func driftActionSymbol(action string) string {
+	switch action {
+	case plansummary.ActionDelete:
+		return "-"
+	case plansummary.ActionCreate:
+		return "+"
+	}
+	return "~"
+}
+
+func formatDriftValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if val == plansummary.SensitiveValue {
+			return val
+		}
+		return fmt.Sprintf("%q", val)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeDriftShowJSON = `{"format_version":"1.2","resource_drift":[` +
+	`{"address":"azurerm_virtual_network.hub","type":"azurerm_virtual_network","change":{"actions":["update"],` +
+	`"before":{"tags":{"env":"prod"},"admin_password":"s3cret"},` +
+	`"after":{"tags":{"env":"dev"},"admin_password":"changed"},` +
+	`"before_sensitive":{"admin_password":true},"after_sensitive":{"admin_password":true}}}]}`
+
+const noChangesShowJSON = `{"format_version":"1.2"}`
+
+// useFakeDriftTerraform installs a fake whose refresh-only plan reports
+// drift and whose -refresh=false plan reports pending code changes, as
+// requested.
+func useFakeDriftTerraform(t *testing.T, drifted, pending bool) *runnertest.Fake {
+	t.Helper()
+	return useFakeRunner(t, runnertest.New().
+		On("plan", func(c runnertest.Call) (string, error) {
+			if slices.Contains(c.Args, "-refresh-only") {
+				if drifted {
+					return "Terraform detected the following changes made outside of Terraform", &runnertest.ExitError{Code: 2}
+				}
+				return "No changes.", nil
+			}
+			if pending {
+				return "Plan: 1 to add, 0 to change, 0 to destroy.", &runnertest.ExitError{Code: 2}
+			}
+			return "No changes.", nil
+		}).
+		On("show", func(c runnertest.Call) (string, error) {
+			switch planFile := c.Args[len(c.Args)-1]; {
+			case planFile == driftPlanFile && drifted:
+				return fakeDriftShowJSON, nil
+			case planFile == driftPendingPlanFile && pending:
+				return fakeShowJSON, nil
+			}
+			return noChangesShowJSON, nil
+		}))
+}
+
+type driftPayload struct {
+	Status       string `json:"status"`
+	TotalDrift   int    `json:"totalDrift"`
+	TotalPending int    `json:"totalPending"`
+	Layers       []struct {
+		Status    string `json:"status"`
+		Change    int    `json:"change"`
+		Resources []struct {
+			Address    string `json:"address"`
+			Attributes []struct {
+				Path      string `json:"path"`
+				Before    any    `json:"before"`
+				After     any    `json:"after"`
+				Sensitive bool   `json:"sensitive"`
+			} `json:"attributes"`
+		} `json:"resources"`
+		Pending struct {
+			Add   int `json:"add"`
+			Total int `json:"total"`
+		} `json:"pending"`
+	} `json:"layers"`
+}
+
+func TestDriftCmd_ClassifiesLayers(t *testing.T) {
+	cases := []struct {
+		name            string
+		drifted         bool
+		pending         bool
+		wantLayerStatus string
+		wantStatus      string
+		wantErr         bool
+	}{
+		{"in sync", false, false, driftInSync, "ok", false},
+		{"drifted", true, false, driftDrifted, "drift-detected", true},
+		{"pending changes", false, true, driftPendingChanges, "pending-changes", false},
+		{"both", true, true, driftBoth, "drift-detected", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			useFakeDriftTerraform(t, tc.drifted, tc.pending)
+			repo := initRepoForCommandTests(t)
+
+			stdout, _, err := executeCommandWithProcessIO(t, "drift", "--repo-root", repo, "--layer", "identity", "--json")
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err, "pending code changes are not drift")
+			}
+
+			var payload driftPayload
+			require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+			assert.Equal(t, tc.wantStatus, payload.Status)
+			require.Len(t, payload.Layers, 1)
+			assert.Equal(t, tc.wantLayerStatus, payload.Layers[0].Status)
+			if tc.pending {
+				assert.Equal(t, 3, payload.TotalPending)
+			}
+		})
+	}
+}
+
+func TestDriftCmd_UsesRefreshOnlyAndNoRefreshPlans(t *testing.T) {
+	tf := useFakeDriftTerraform(t, false, false)
+	repo := initRepoForCommandTests(t)
+
+	_, _, err := executeCommandWithProcessIO(t, "drift", "--repo-root", repo, "--layer", "identity", "--json")
+	require.NoError(t, err)
+
+	calls := tf.Calls("plan")
+	require.Len(t, calls, 2)
+	assert.Contains(t, calls[0].Args, "-refresh-only")
+	assert.Contains(t, calls[1].Args, "-refresh=false")
+}
+
+func TestDriftCmd_ReportsAttributeDiffsWithSensitiveMasked(t *testing.T) {
+	useFakeDriftTerraform(t, true, false)
+	repo := initRepoForCommandTests(t)
+
+	stdout, _, err := executeCommandWithProcessIO(t, "drift", "--repo-root", repo, "--layer", "identity", "--json")
+	require.Error(t, err)
+	assert.NotContains(t, stdout, "s3cret")
+	assert.NotContains(t, stdout, "changed")
+
+	var payload driftPayload
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	require.Len(t, payload.Layers, 1)
+	require.Len(t, payload.Layers[0].Resources, 1)
+	attrs := payload.Layers[0].Resources[0].Attributes
+	require.Len(t, attrs, 2)
+	assert.Equal(t, "admin_password", attrs[0].Path)
+	assert.True(t, attrs[0].Sensitive)
+	assert.Equal(t, "tags.env", attrs[1].Path)
+	assert.Equal(t, "prod", attrs[1].Before)
+	assert.Equal(t, "dev", attrs[1].After)
+}
+
+func TestDriftCmd_VerbosePrintsAttributeDiffs(t *testing.T) {
+	useFakeDriftTerraform(t, true, false)
+	repo := initRepoForCommandTests(t)
+
+	_, stderr, err := executeCommandWithProcessIO(t, "drift", "-v", "--repo-root", repo, "--layer", "identity")
+	require.Error(t, err)
+	assert.Contains(t, stderr, "azurerm_virtual_network.hub")
+	assert.Contains(t, stderr, `tags.env: "prod" → "dev"`)
+	assert.False(t, strings.Contains(stderr, "s3cret"), "sensitive values are masked")
+}
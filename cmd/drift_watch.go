@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kjourdan1/lzctl/internal/driftwatch"
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+)
+
+var driftWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Run drift detection periodically and expose Prometheus metrics",
+	Long: `Runs the drift check across all roots every --interval and keeps the last
+result of each root in memory. Results are served over HTTP:
+
+  /metrics  Prometheus metrics: drift items and pending changes per root,
+            root status, last successful check, plan duration, and
+            errors per root
+  /healthz  200 while checks keep completing, 503 when the last check
+            finished more than two intervals ago
+
+The watcher runs until interrupted (Ctrl-C or SIGTERM). It does not send
+notifications; alert on the metrics instead.`,
+	RunE: runDriftWatch,
+}
+
+var (
+	driftWatchInterval    time.Duration
+	driftWatchListen      string
+	driftWatchParallelism int
+)
+
+func init() {
+	driftWatchCmd.Flags().DurationVar(&driftWatchInterval, "interval", time.Hour, "time between drift checks")
+	driftWatchCmd.Flags().StringVar(&driftWatchListen, "listen", ":9464", "address to serve /metrics and /healthz on")
+	driftWatchCmd.Flags().IntVar(&driftWatchParallelism, "parallelism", 1, "number of independent roots to check concurrently")
+
+	driftCmd.AddCommand(driftWatchCmd)
+}
+
+func runDriftWatch(cmd *cobra.Command, args []string) error {
+	if driftWatchInterval <= 0 {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("--interval must be positive"))
+	}
+	root, err := absRepoRoot()
+	if err != nil {
+		return err
+	}
+	tf, err := resolveRunner(cmd.Context(), root)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+
+	ln, err := net.Listen("tcp", driftWatchListen)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Generic, fmt.Errorf("listening on %s: %w", driftWatchListen, err))
+	}
+	store := driftwatch.NewStore(driftWatchInterval)
+	srv := &http.Server{Handler: store.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if serveErr := srv.Serve(ln); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			color.New(color.FgRed).Fprintf(os.Stderr, "❌ metrics server stopped: %v\n", serveErr)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	color.New(color.Bold).Fprintf(os.Stderr, "👀 Watching drift every %s\n", driftWatchInterval)
+	fmt.Fprintf(os.Stderr, "   Metrics: http://%s/metrics\n", ln.Addr())
+	fmt.Fprintf(os.Stderr, "   Health:  http://%s/healthz\n\n", ln.Addr())
+
+	for {
+		watchDriftOnce(ctx, tf, root, driftWatchParallelism, store)
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(os.Stderr, "\n🛑 Stopping drift watch")
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return srv.Shutdown(shutdownCtx)
+		case <-time.After(driftWatchInterval):
+		}
+	}
+}
+
+// watchDriftOnce runs one drift check across all roots of repo, records it
+// in store and logs a one-line summary.
+func watchDriftOnce(ctx context.Context, tf orchestrator.Runner, repo string, parallelism int, store *driftwatch.Store) {
+	start := time.Now()
+	results, err := checkAllRootsForDrift(ctx, tf, repo, parallelism)
+	if err != nil {
+		store.Record(nil, err)
+		color.New(color.FgRed).Fprintf(os.Stderr, "%s ❌ drift check failed: %v\n", start.Format(time.RFC3339), err)
+		return
+	}
+
+	layers := make([]driftwatch.LayerResult, len(results))
+	drift, failed := 0, 0
+	for i, ld := range results {
+		layers[i] = driftwatch.LayerResult{
+			Layer:          ld.Layer,
+			Kind:           ld.Kind,
+			Status:         ld.Status,
+			DriftItems:     ld.Total,
+			PendingChanges: ld.Pending.Total,
+			Duration:       ld.Duration,
+			Error:          ld.Error,
+		}
+		drift += ld.Total
+		if ld.Error != "" {
+			failed++
+		}
+	}
+	store.Record(layers, nil)
+	fmt.Fprintf(os.Stderr, "%s drift check: %d root(s), %d drift item(s), %d error(s) in %s\n",
+		start.Format(time.RFC3339), len(results), drift, failed, time.Since(start).Round(time.Second))
+}
+
+func checkAllRootsForDrift(ctx context.Context, tf orchestrator.Runner, repo string, parallelism int) ([]layerDrift, error) {
+	roots, err := resolveLocalRoots(repo, rootSelection{})
+	if err != nil {
+		return nil, err
+	}
+	roots, graph, err := orderRoots(repo, roots)
+	if err != nil {
+		return nil, err
+	}
+	return checkDrift(ctx, tf, repo, roots, graph, parallelism, false)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kjourdan1/lzctl/internal/driftwatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchDriftOnce_ExposesMetrics(t *testing.T) {
+	tf := useFakeDriftTerraform(t, true, false)
+	repo := initRepoForCommandTests(t)
+
+	store := driftwatch.NewStore(time.Hour)
+	watchDriftOnce(t.Context(), tf, repo, 2, store)
+
+	srv := httptest.NewServer(store.Handler())
+	defer srv.Close()
+	resp, err := srv.Client().Get(srv.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	metrics := string(body)
+
+	assert.Contains(t, metrics, `lzctl_drift_items{layer="identity",kind="platform"} 1`)
+	assert.Contains(t, metrics, `lzctl_drift_layer_status{layer="connectivity",kind="platform",status="drifted"} 1`)
+	assert.Contains(t, metrics, `lzctl_drift_errors_total{layer="identity",kind="platform"} 0`)
+	assert.Contains(t, metrics, "lzctl_drift_checks_total 1")
+
+	resp, err = srv.Client().Get(srv.URL + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestDriftWatchCmd_RejectsNonPositiveInterval(t *testing.T) {
+	repo := initRepoForCommandTests(t)
+	_, _, err := executeCommandWithProcessIO(t, "drift", "watch", "--repo-root", repo, "--interval", "0s")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--interval must be positive")
+}
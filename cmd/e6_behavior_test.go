@@ -2,71 +2,52 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
-	"runtime"
-	"strconv"
 	"testing"
 
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+	"github.com/kjourdan1/lzctl/internal/planverify"
+	"github.com/kjourdan1/lzctl/internal/state"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func installFakeTerraform(t *testing.T, planLine string, planExitCode int) string {
+// useFakeRunner routes every terraform call made by the commands under test
+// to tf instead of a binary on PATH. The state backend is kept offline too,
+// so plan caching never reaches Azure; tests that need a backend replace
+// newStateManager afterwards.
+func useFakeRunner(t *testing.T, tf *runnertest.Fake) *runnertest.Fake {
 	t.Helper()
-
-	binDir := t.TempDir()
-	var scriptPath string
-
-	if runtime.GOOS == "windows" {
-		scriptPath = filepath.Join(binDir, "terraform.bat")
-		script := "@echo off\r\n" +
-			"if \"%1\"==\"init\" (\r\n" +
-			"  echo Terraform has been successfully initialized!\r\n" +
-			"  exit /b 0\r\n" +
-			")\r\n" +
-			"if \"%1\"==\"validate\" (\r\n" +
-			"  echo Success! The configuration is valid.\r\n" +
-			"  exit /b 0\r\n" +
-			")\r\n" +
-			"if \"%1\"==\"plan\" (\r\n" +
-			"  echo " + planLine + "\r\n" +
-			"  exit /b " + strconv.Itoa(planExitCode) + "\r\n" +
-			")\r\n" +
-			"if \"%1\"==\"apply\" (\r\n" +
-			"  echo Apply complete! Resources: 0 added, 0 changed, 0 destroyed.\r\n" +
-			"  exit /b 0\r\n" +
-			")\r\n" +
-			"exit /b 0\r\n"
-		require.NoError(t, os.WriteFile(scriptPath, []byte(script), 0o644))
-	} else {
-		scriptPath = filepath.Join(binDir, "terraform")
-		script := "#!/bin/sh\n" +
-			"case \"$1\" in\n" +
-			"  init)\n" +
-			"    echo \"Terraform has been successfully initialized!\"\n" +
-			"    exit 0\n" +
-			"    ;;\n" +
-			"  validate)\n" +
-			"    echo \"Success! The configuration is valid.\"\n" +
-			"    exit 0\n" +
-			"    ;;\n" +
-			"  plan)\n" +
-			"    echo \"" + planLine + "\"\n" +
-			"    exit " + strconv.Itoa(planExitCode) + "\n" +
-			"    ;;\n" +
-			"  apply)\n" +
-			"    echo \"Apply complete! Resources: 0 added, 0 changed, 0 destroyed.\"\n" +
-			"    exit 0\n" +
-			"    ;;\n" +
-			"esac\n" +
-			"exit 0\n"
-		require.NoError(t, os.WriteFile(scriptPath, []byte(script), 0o755))
+	// Keep the developer's or CI runner's environment from turning on CI
+	// mode or plan signing in command tests.
+	t.Setenv("CI", "")
+	for _, env := range []string{planverify.EnvSigningKey, planverify.EnvSigningKeyFile, planverify.EnvVerifyKey, planverify.EnvVerifyKeyFile} {
+		t.Setenv(env, "")
 	}
+	origRunner, origState := newRunner, newStateManager
+	newRunner = func(orchestrator.ToolSpec) (orchestrator.Runner, error) { return tf, nil }
+	newStateManager = func(cfg *config.LZConfig) *state.Manager { return state.NewManager(cfg, offlineAzCLI{}) }
+	t.Cleanup(func() { newRunner, newStateManager = origRunner, origState })
+	return tf
+}
+
+// offlineAzCLI fails every az call.
+type offlineAzCLI struct{}
 
-	t.Setenv("PATH", binDir)
-	return scriptPath
+func (offlineAzCLI) Run(args ...string) (string, error) {
+	return "", errors.New("az: state backend not available in tests")
+}
+
+// useFakeTerraform installs a fake whose plan prints planLine and exits with
+// planExitCode (2 means changes are present with -detailed-exitcode).
+func useFakeTerraform(t *testing.T, planLine string, planExitCode int) *runnertest.Fake {
+	t.Helper()
+	return useFakeRunner(t, runnertest.New().Reply("plan", planLine, planExitCode))
 }
 
 func initRepoForCommandTests(t *testing.T) string {
@@ -169,7 +150,7 @@ func TestHistoryCmd_NoAuditEvents(t *testing.T) {
 }
 
 func TestValidateCmd_JsonOutput_OnInitializedRepo(t *testing.T) {
-	installFakeTerraform(t, "Plan: 0 to add, 0 to change, 0 to destroy", 0)
+	useFakeTerraform(t, "Plan: 0 to add, 0 to change, 0 to destroy", 0)
 	repo := initRepoForCommandTests(t)
 
 	stdout, _, err := executeCommandWithProcessIO(t, "validate", "--repo-root", repo, "--json")
@@ -188,7 +169,7 @@ func TestValidateCmd_JsonOutput_OnInitializedRepo(t *testing.T) {
 }
 
 func TestPlanCmd_TargetLayer_AndOutFile(t *testing.T) {
-	installFakeTerraform(t, "Plan: 1 to add, 0 to change, 0 to destroy", 2)
+	useFakeTerraform(t, "Plan: 1 to add, 0 to change, 0 to destroy", 2)
 	repo := initRepoForCommandTests(t)
 	outPath := filepath.Join(t.TempDir(), "plan.out")
 
@@ -202,7 +183,7 @@ func TestPlanCmd_TargetLayer_AndOutFile(t *testing.T) {
 }
 
 func TestApplyCmd_DryRun_TargetLayer(t *testing.T) {
-	installFakeTerraform(t, "Plan: 1 to add, 0 to change, 0 to destroy", 2)
+	useFakeTerraform(t, "Plan: 1 to add, 0 to change, 0 to destroy", 2)
 	repo := initRepoForCommandTests(t)
 
 	_, stderr, err := executeCommandWithProcessIO(t, "--dry-run", "apply", "--repo-root", repo, "--layer", "management-groups")
@@ -212,7 +193,7 @@ func TestApplyCmd_DryRun_TargetLayer(t *testing.T) {
 }
 
 func TestDriftCmd_JsonOutput_WithDetectedDrift(t *testing.T) {
-	installFakeTerraform(t, "Plan: 2 to add, 1 to change, 0 to destroy", 2)
+	useFakeTerraform(t, "Plan: 2 to add, 1 to change, 0 to destroy", 2)
 	repo := initRepoForCommandTests(t)
 
 	stdout, _, err := executeCommandWithProcessIO(t, "drift", "--repo-root", repo, "--layer", "management-groups", "--json")
@@ -224,14 +205,13 @@ func TestDriftCmd_JsonOutput_WithDetectedDrift(t *testing.T) {
 	assert.Equal(t, float64(3), payload["totalDrift"])
 }
 
-func TestRollbackCmd_DryRun_TargetLayer(t *testing.T) {
-	installFakeTerraform(t, "Plan: 0 to add, 1 to change, 0 to destroy", 0)
+func TestRollbackCmd_RequiresTo(t *testing.T) {
+	useFakeTerraform(t, "Plan: 0 to add, 1 to change, 0 to destroy", 0)
 	repo := initRepoForCommandTests(t)
 
-	_, stderr, err := executeCommandWithProcessIO(t, "--dry-run", "rollback", "--repo-root", repo, "--layer", "management-groups", "--auto-approve")
-	require.NoError(t, err)
-	assert.Contains(t, stderr, "management-groups")
-	assert.Contains(t, stderr, "[DRY-RUN]")
+	_, _, err := executeCommandWithProcessIO(t, "--dry-run", "rollback", "--repo-root", repo, "--layer", "management-groups", "--auto-approve")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--to is required")
 }
 
 func TestApplyCmd_CIMode_RequiresAutoApprove(t *testing.T) {
@@ -251,3 +231,65 @@ func TestRollbackCmd_CIMode_RequiresAutoApprove(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "--ci mode requires --auto-approve for rollback")
 }
+
+func TestPlanCmd_ZoneSelection_JSONOutput(t *testing.T) {
+	useFakeTerraform(t, "Plan: 2 to add, 0 to change, 0 to destroy", 2)
+	repo := initRepoForCommandTests(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "landing-zones", "app-one", "blueprint"), 0o755))
+
+	stdout, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--zone", "app-one", "--json")
+	require.NoError(t, err)
+
+	var payload struct {
+		TotalAdd int `json:"totalAdd"`
+		Layers   []struct {
+			Layer string `json:"layer"`
+			Kind  string `json:"kind"`
+		} `json:"layers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	require.Len(t, payload.Layers, 2)
+	assert.Equal(t, "lz:app-one", payload.Layers[0].Layer)
+	assert.Equal(t, "landing-zone", payload.Layers[0].Kind)
+	assert.Equal(t, "blueprint", payload.Layers[1].Kind)
+	assert.Equal(t, 4, payload.TotalAdd)
+}
+
+func TestDriftCmd_Parallelism_KeepsDeterministicOrder(t *testing.T) {
+	useFakeTerraform(t, "Plan: 0 to add, 0 to change, 0 to destroy", 0)
+	repo := initRepoForCommandTests(t)
+	for _, zone := range []string{"zone-c", "zone-a", "zone-b"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(repo, "landing-zones", zone), 0o755))
+	}
+
+	stdout, _, err := executeCommandWithProcessIO(t, "drift", "--repo-root", repo, "--parallelism", "3", "--json")
+	require.NoError(t, err)
+
+	var payload struct {
+		Layers []struct {
+			Layer string `json:"layer"`
+		} `json:"layers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	names := make([]string, 0, len(payload.Layers))
+	for _, l := range payload.Layers {
+		names = append(names, l.Layer)
+	}
+	require.GreaterOrEqual(t, len(names), 3)
+	assert.Equal(t, []string{"lz:zone-a", "lz:zone-b", "lz:zone-c"}, names[len(names)-3:])
+	assert.Equal(t, "management-groups", names[0])
+}
+
+func TestGraphCmd_JSONOutput(t *testing.T) {
+	repo := initRepoForCommandTests(t)
+
+	stdout, _, err := executeCommandWithProcessIO(t, "graph", "--repo-root", repo, "--format", "json")
+	require.NoError(t, err)
+
+	var payload struct {
+		Order []string `json:"order"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	require.NotEmpty(t, payload.Order)
+	assert.Equal(t, "management-groups", payload.Order[0])
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	lztemplate "github.com/kjourdan1/lzctl/internal/template"
+)
+
+// environmentsDir holds the tree generated for each environment selected
+// with --env: environments/<name>/platform, environments/<name>/landing-zones.
+const environmentsDir = "environments"
+
+// envTreeFiles drops the rendered files that belong to the repository rather
+// than to an environment tree: the base lzctl.yaml and the CI/CD pipelines,
+// which deploy every environment.
+func envTreeFiles(files []lztemplate.RenderedFile) []lztemplate.RenderedFile {
+	kept := make([]lztemplate.RenderedFile, 0, len(files))
+	for _, f := range files {
+		if filepath.ToSlash(f.Path) == "lzctl.yaml" || isPipelineFile(f.Path) {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// isPipelineFile reports whether the rendered file at path is a CI/CD
+// pipeline definition, which only runs from the repository root.
+func isPipelineFile(path string) bool {
+	p := filepath.ToSlash(path)
+	return p == "atlantis.yaml" || strings.HasPrefix(p, ".github/") || strings.HasPrefix(p, ".azuredevops/")
+}
+
+// refuseEnvWrite rejects commands that save lzctl.yaml when --env is set:
+// the loaded configuration includes the overlay, and saving it would copy
+// the environment's values into the base file shared by every environment.
+func refuseEnvWrite(command string) error {
+	if envName == "" {
+		return nil
+	}
+	return exitcode.Wrap(exitcode.Validation, fmt.Errorf(
+		"%s updates lzctl.yaml, which every environment shares: run it without --env, and put values specific to %s in lzctl.%s.yaml",
+		command, envName, envName))
+}
+
+// envStateKey returns the state key of a root in the environment selected
+// with --env: environments keep their state under <env>/ so that they can
+// share a state backend.
+func envStateKey(key string) string {
+	if envName == "" {
+		return key
+	}
+	return envName + "/" + key
+}
+
+// terraformInitArgs returns the arguments of terraform init for the root in
+// dir whose state lives at key. A backend.hcl next to the root completes its
+// partial backend block. Under --env the key is passed to the backend, so
+// that the root's state is the environment's one whatever its backend
+// configuration says.
+func terraformInitArgs(dir, key string) []string {
+	args := []string{"init", "-input=false", "-no-color"}
+	if fileExistsLocal(filepath.Join(dir, "backend.hcl")) {
+		args = append(args, "-backend-config=backend.hcl")
+	}
+	if envName != "" && key != "" {
+		args = append(args, "-backend-config=key="+key)
+	}
+	return args
+}
+
+// rootInitArgs returns the arguments of terraform init for r.
+func rootInitArgs(repo string, r localRoot) ([]string, error) {
+	key, err := stateKeyFor(repo, r)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.Validation, fmt.Errorf("layer %s: %w", r.Name, err))
+	}
+	return terraformInitArgs(filepath.Join(repo, r.Dir), key), nil
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+)
+
+// devOverlay gives dev its own hub address space and state storage account.
+const devOverlay = `spec:
+  platform:
+    connectivity:
+      hub:
+        region: westeurope
+        addressSpace: 10.10.0.0/16
+  stateBackend:
+    storageAccount: sttfstatedev
+`
+
+// initEnvRepoForCommandTests initialises a repo with the dev overlay and
+// renders the dev tree.
+func initEnvRepoForCommandTests(t *testing.T) string {
+	t.Helper()
+	repo := initRepoForCommandTests(t)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "lzctl.dev.yaml"), []byte(devOverlay), 0o644))
+	_, _, err := executeCommand("init", "--env", "dev", "--repo-root", repo)
+	require.NoError(t, err)
+	return repo
+}
+
+func TestInitCmd_EnvRendersEnvironmentTree(t *testing.T) {
+	repo := initEnvRepoForCommandTests(t)
+	tree := filepath.Join(repo, "environments", "dev")
+
+	assert.DirExists(t, filepath.Join(tree, "platform", "connectivity"))
+	assert.NoFileExists(t, filepath.Join(tree, "lzctl.yaml"), "the base configuration stays at the repo root")
+	assert.NoDirExists(t, filepath.Join(tree, ".github"), "pipelines stay at the repo root")
+	backend := readRepoFile(t, tree, "platform", "shared", "backend.tf")
+	assert.Contains(t, backend, `"sttfstatedev"`)
+	assert.Contains(t, backend, `"dev/platform/shared/terraform.tfstate"`)
+	assert.Contains(t, readRepoFile(t, tree, "platform", "connectivity", "terraform.tfvars"), "10.10.0.0/16")
+	assert.NotContains(t, readRepoFile(t, repo, "platform", "shared", "backend.tf"), "sttfstatedev", "the base tree is untouched")
+
+	_, _, err := executeCommand("init", "--env", "prod", "--repo-root", repo)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has no overlay")
+}
+
+func TestPlanCmd_EnvUsesEnvironmentTreeAndStateKey(t *testing.T) {
+	repo := initEnvRepoForCommandTests(t)
+	fake := useFakeRunner(t, runnertest.New().Reply("plan", "Plan: 1 to add, 0 to change, 0 to destroy.", 2))
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--env", "dev", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+
+	inits := fake.Calls("init")
+	require.Len(t, inits, 1)
+	assert.Equal(t, filepath.Join(repo, "environments", "dev", "platform", "connectivity"), inits[0].Dir)
+	assert.Contains(t, inits[0].Args, "-backend-config=key=dev/platform-connectivity.tfstate")
+	assert.FileExists(t, filepath.Join(repo, "environments", "dev", "platform", "connectivity", "tfplan"))
+	assert.NoFileExists(t, filepath.Join(repo, "platform", "connectivity", "tfplan"))
+
+	_, _, err = executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+	inits = fake.Calls("init")
+	require.Len(t, inits, 2)
+	assert.Equal(t, filepath.Join(repo, "platform", "connectivity"), inits[1].Dir)
+	for _, arg := range inits[1].Args {
+		assert.False(t, strings.HasPrefix(arg, "-backend-config"), "no key is forced without --env")
+	}
+}
+
+func TestEnv_ConfigWritesRefused(t *testing.T) {
+	repo := initEnvRepoForCommandTests(t)
+	before := readRepoFile(t, repo, "lzctl.yaml")
+
+	_, _, err := executeCommandWithProcessIO(t, "--env", "dev", "workload", "add", "--repo-root", repo, "--name", "app", "--archetype", "corp", "--address-space", "10.1.0.0/24")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "lzctl.dev.yaml")
+	assert.Equal(t, before, readRepoFile(t, repo, "lzctl.yaml"))
+
+	_, _, err = executeCommandWithProcessIO(t, "plan", "--env", "../dev", "--repo-root", repo)
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+}
+
+func TestPlanCmd_InitCompletesBackendWithBackendHCL(t *testing.T) {
+	repo := initRepoForCommandTests(t)
+	zone := filepath.Join(repo, "landing-zones", "app")
+	require.NoError(t, os.MkdirAll(filepath.Join(zone, "blueprint"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(zone, "backend.hcl"), []byte("key = \"landing-zones-app.tfstate\"\n"), 0o644))
+	fake := useFakeRunner(t, runnertest.New().Reply("plan", "Plan: 1 to add, 0 to change, 0 to destroy.", 2))
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--zone", "app")
+	require.NoError(t, err)
+
+	inits := fake.Calls("init")
+	require.Len(t, inits, 2)
+	assert.Equal(t, zone, inits[0].Dir)
+	assert.Contains(t, inits[0].Args, "-backend-config=backend.hcl")
+	assert.NotContains(t, inits[1].Args, "-backend-config=backend.hcl", "the blueprint has no backend.hcl")
+}
+
+func TestApplyCmd_EnvEnforcesRepoPlanRules(t *testing.T) {
+	fail := false
+	tf := useFailingApplyTerraform(t, &fail)
+	repo := initEnvRepoForCommandTests(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".lzctl"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".lzctl", "plan-rules.yaml"), []byte(testPlanRules), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "environments", "dev", "platform", "management-groups", "tfplan.json"), []byte(mgDeletePlanJSON), 0o644))
+
+	_, stderr, err := executeCommandWithProcessIO(t, "apply", "--env", "dev", "--repo-root", repo, "--auto-approve")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Policy, exitcode.Of(err), "the repository's rules apply to every environment")
+	assert.Contains(t, stderr, "protect-management-groups")
+	assert.Empty(t, tf.Calls("apply"))
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/kjourdan1/lzctl/internal/azure"
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+)
+
+// defaultRunRetry applies when spec.execution.retry is not set: ARM
+// throttling usually clears within a minute or two.
+var defaultRunRetry = azure.RetryConfig{MaxAttempts: 3, BaseDelay: 10 * time.Second, MaxDelay: 2 * time.Minute}
+
+// retrySleep waits between retries of transient failures; nil uses a
+// timer. Tests replace it.
+var retrySleep func(ctx context.Context, d time.Duration) error
+
+// executionSettings is spec.execution with its durations parsed.
+type executionSettings struct {
+	timeout  time.Duration
+	timeouts map[string]time.Duration
+	retry    azure.RetryConfig
+}
+
+// loadExecution reads spec.execution from lzctl.yaml. A missing or
+// unreadable config yields the defaults: no timeout and defaultRunRetry.
+func loadExecution() (executionSettings, error) {
+	s := executionSettings{retry: defaultRunRetry}
+	cfg, err := configCache()
+	if err != nil || cfg.Spec.Execution == nil {
+		return s, nil
+	}
+	e := cfg.Spec.Execution
+	if s.timeout, err = parseExecutionDuration("spec.execution.timeout", e.Timeout); err != nil {
+		return s, err
+	}
+	if len(e.Timeouts) > 0 {
+		s.timeouts = make(map[string]time.Duration, len(e.Timeouts))
+		for name, v := range e.Timeouts {
+			if s.timeouts[name], err = parseExecutionDuration("spec.execution.timeouts."+name, v); err != nil {
+				return s, err
+			}
+		}
+	}
+	if r := e.Retry; r != nil {
+		if r.MaxAttempts > 0 {
+			s.retry.MaxAttempts = r.MaxAttempts
+		}
+		if d, err := parseExecutionDuration("spec.execution.retry.baseDelay", r.BaseDelay); err != nil {
+			return s, err
+		} else if d > 0 {
+			s.retry.BaseDelay = d
+		}
+		if d, err := parseExecutionDuration("spec.execution.retry.maxDelay", r.MaxDelay); err != nil {
+			return s, err
+		} else if d > 0 {
+			s.retry.MaxDelay = d
+		}
+	}
+	return s, nil
+}
+
+func parseExecutionDuration(field, v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s: invalid duration %q (e.g. 90s, 45m, 1h30m)", field, v)
+	}
+	return d, nil
+}
+
+// rootTimeout returns the timeout of the root named name: its entry in
+// spec.execution.timeouts, else spec.execution.timeout. 0 means none.
+func (s executionSettings) rootTimeout(name string) time.Duration {
+	if d, ok := s.timeouts[name]; ok {
+		return d
+	}
+	return s.timeout
+}
+
+// withRunRetry wraps tf so that runs failing with transient errors are
+// retried per spec.execution.retry, reporting each retry on stderr.
+func withRunRetry(repo string, tf orchestrator.Runner, s executionSettings) orchestrator.Runner {
+	return orchestrator.WithRetry(tf, orchestrator.RetryPolicy{
+		Retry: s.retry,
+		Sleep: retrySleep,
+		OnRetry: func(e orchestrator.RetryEvent) {
+			root := e.Dir
+			if rel, err := filepath.Rel(repo, e.Dir); err == nil {
+				root = filepath.ToSlash(rel)
+			}
+			outputMu.Lock()
+			defer outputMu.Unlock()
+			color.New(color.FgYellow).Fprintf(os.Stderr, "   🔁 %s: %s failed (%s); retrying in %s (attempt %d/%d)\n",
+				root, e.Subcommand, e.Reason, e.Delay.Round(time.Second), e.Attempt+1, e.MaxAttempts)
+		},
+	})
+}
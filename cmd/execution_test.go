@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+)
+
+// setExecution adds an execution section to the repo's lzctl.yaml and skips
+// the waits between retries.
+func setExecution(t *testing.T, repo, section string) {
+	t.Helper()
+	path := filepath.Join(repo, "lzctl.yaml")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), "\nspec:\n", "\nspec:\n"+section, 1)), 0o644))
+}
+
+func useNoRetrySleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var slept []time.Duration
+	orig := retrySleep
+	retrySleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	t.Cleanup(func() { retrySleep = orig })
+	return &slept
+}
+
+func TestPlanCmd_RetriesThrottledPlan(t *testing.T) {
+	slept := useNoRetrySleep(t)
+	attempts := 0
+	tf := useFakeRunner(t, runnertest.New().On("plan", func(runnertest.Call) (string, error) {
+		attempts++
+		if attempts == 1 {
+			return "Error: listing role assignments: StatusCode=429 -- Original Error: Code=\"TooManyRequests\"", &runnertest.ExitError{Code: 1}
+		}
+		return "No changes. Your infrastructure matches the configuration.", nil
+	}))
+	repo := initRepoForCommandTests(t)
+	setExecution(t, repo, "  execution:\n    retry:\n      maxAttempts: 2\n      baseDelay: 5s\n")
+
+	_, stderr, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+	assert.Len(t, tf.Calls("plan"), 2)
+	require.Len(t, *slept, 1)
+	assert.LessOrEqual(t, (*slept)[0], 5*time.Second)
+	assert.Contains(t, stderr, "platform/connectivity: plan failed (throttled (429)); retrying in")
+	assert.Contains(t, stderr, "(attempt 2/2)")
+}
+
+func TestApplyCmd_DoesNotRetryPartialApply(t *testing.T) {
+	useNoRetrySleep(t)
+	tf := useFakeRunner(t, runnertest.New().Reply("apply",
+		"azurerm_virtual_network.hub: Creating...\nError: creating subnet: StatusCode=429", 1))
+	repo := initRepoForCommandTests(t)
+
+	_, stderr, err := executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Terraform, exitcode.Of(err))
+	assert.Len(t, tf.Calls("apply"), 1)
+	assert.Contains(t, stderr, "Run lzctl plan for connectivity before applying again")
+}
+
+func TestPlanCmd_RootTimeout(t *testing.T) {
+	useFakeRunner(t, runnertest.New().On("init", func(runnertest.Call) (string, error) {
+		time.Sleep(50 * time.Millisecond)
+		return "Terraform has been successfully initialized!", nil
+	}))
+	repo := initRepoForCommandTests(t)
+	setExecution(t, repo, "  execution:\n    timeout: 1h\n    timeouts:\n      connectivity: 10ms\n")
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Terraform, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "layer connectivity: timed out after 10ms")
+}
+
+func TestLoadExecution(t *testing.T) {
+	useFakeRunner(t, runnertest.New())
+	repo := initRepoForCommandTests(t)
+	setExecution(t, repo, "  execution:\n    timeout: 45m\n    timeouts:\n      lz:app: 2h\n    retry:\n      maxAttempts: 5\n")
+	origRepo := repoRoot
+	repoRoot = repo
+	t.Cleanup(func() { repoRoot = origRepo; invalidateConfigCache() })
+	invalidateConfigCache()
+
+	s, err := loadExecution()
+	require.NoError(t, err)
+	assert.Equal(t, 45*time.Minute, s.rootTimeout("connectivity"))
+	assert.Equal(t, 2*time.Hour, s.rootTimeout("lz:app"))
+	assert.Equal(t, 5, s.retry.MaxAttempts)
+	assert.Equal(t, defaultRunRetry.BaseDelay, s.retry.BaseDelay)
+}
+
+func TestPlanCmd_InvalidExecutionDuration(t *testing.T) {
+	useFakeRunner(t, runnertest.New())
+	repo := initRepoForCommandTests(t)
+	setExecution(t, repo, "  execution:\n    timeouts:\n      connectivity: soon\n")
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+	assert.Contains(t, err.Error(), `spec.execution.timeouts.connectivity: invalid duration "soon"`)
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/state"
+)
+
+// fakeAz is a scripted az CLI for command tests, the state backend
+// counterpart of runnertest.Fake. It holds the state blobs of one container
+// in memory: blob show, snapshot, copy start and delete act on them, and
+// lease queries report every blob unlocked. Like Azure, it refuses to
+// delete a blob that has snapshots unless they are deleted with it. Answers can be replaced per
+// operation with On, and every call is recorded.
+//
+//	az := useFakeAz(t, newFakeAz().WithBlobs("landing-zones-app.tfstate"))
+//	...
+//	assert.Len(t, az.Calls("storage blob copy"), 1)
+type fakeAz struct {
+	mu        sync.Mutex
+	blobs     map[string]string // state key → version ID
+	deleted   map[string]bool
+	strict    bool // blobs not in blobs do not exist
+	snapshot  int
+	snapshots map[string]int // state key → number of snapshots
+	handlers  map[string]azHandler
+	calls     [][]string
+}
+
+// azHandler produces the output and error of an az call.
+type azHandler func(args []string) (string, error)
+
+var _ state.AzCLIRunner = (*fakeAz)(nil)
+
+// newFakeAz returns a backend in which every state blob exists at version
+// v1.
+func newFakeAz() *fakeAz {
+	return &fakeAz{blobs: map[string]string{}, deleted: map[string]bool{}, snapshots: map[string]int{}, handlers: map[string]azHandler{}}
+}
+
+// WithBlobs makes keys, at version v1, the only blobs of the backend.
+func (f *fakeAz) WithBlobs(keys ...string) *fakeAz {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.strict = true
+	for _, k := range keys {
+		f.blobs[k] = "v1"
+	}
+	return f
+}
+
+// SetVersion writes the blob at key with version.
+func (f *fakeAz) SetVersion(key, version string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blobs[key] = version
+	delete(f.deleted, key)
+}
+
+// Exists reports whether the blob at key exists.
+func (f *fakeAz) Exists(key string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.version(key)
+	return ok
+}
+
+// Version returns the version of the blob at key, "" when it does not
+// exist.
+func (f *fakeAz) Version(key string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, _ := f.version(key)
+	return v
+}
+
+// AddSnapshot records a snapshot of the blob at key, taken earlier.
+func (f *fakeAz) AddSnapshot(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.snapshots[key]++
+}
+
+// Snapshots returns the number of snapshots of the blob at key.
+func (f *fakeAz) Snapshots(key string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.snapshots[key]
+}
+
+// On registers h for op, the az command without its flags (e.g. "storage
+// blob list"), replacing the built-in answer.
+func (f *fakeAz) On(op string, h azHandler) *fakeAz {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[op] = h
+	return f
+}
+
+// Calls returns the recorded calls whose command starts with prefix (e.g.
+// "storage blob copy"), each joined with spaces.
+func (f *fakeAz) Calls(prefix string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []string
+	for _, c := range f.calls {
+		if joined := strings.Join(c, " "); strings.HasPrefix(joined, prefix) {
+			out = append(out, joined)
+		}
+	}
+	return out
+}
+
+// FlagValues returns the value of flag in every recorded call of op.
+func (f *fakeAz) FlagValues(op, flag string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []string
+	for _, c := range f.calls {
+		if azOp(c) == op {
+			out = append(out, argAfter(c, flag))
+		}
+	}
+	return out
+}
+
+// Run implements state.AzCLIRunner.
+func (f *fakeAz) Run(args ...string) (string, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, append([]string(nil), args...))
+	h := f.handlers[azOp(args)]
+	f.mu.Unlock()
+	if h != nil {
+		return h(args)
+	}
+	return f.Default(args...)
+}
+
+// Default returns the built-in answer to args, for handlers that replace
+// only part of an operation.
+func (f *fakeAz) Default(args ...string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch azOp(args) {
+	case "storage blob show":
+		version, ok := f.version(argAfter(args, "--name"))
+		if !ok {
+			return "", errors.New("ErrorCode:BlobNotFound")
+		}
+		if strings.Contains(argAfter(args, "--query"), "lease") {
+			return `{"status":"unlocked","state":"available"}`, nil
+		}
+		return fmt.Sprintf(`{"versionId":%q}`, version), nil
+	case "storage blob snapshot":
+		name := argAfter(args, "--name")
+		if _, ok := f.version(name); !ok {
+			return "", errors.New("ErrorCode:BlobNotFound")
+		}
+		f.snapshots[name]++
+		f.snapshot++
+		return fmt.Sprintf(`{"snapshot":"2026-02-18T12:00:%02d.0000000Z"}`, f.snapshot), nil
+	case "storage blob copy start":
+		version, ok := f.version(argAfter(args, "--source-blob"))
+		if !ok {
+			return "", errors.New("ErrorCode:BlobNotFound")
+		}
+		dest := argAfter(args, "--destination-blob")
+		f.blobs[dest] = version
+		delete(f.deleted, dest)
+	case "storage blob delete":
+		name := argAfter(args, "--name")
+		if _, ok := f.version(name); !ok {
+			return "", errors.New("ErrorCode:BlobNotFound")
+		}
+		if f.snapshots[name] > 0 && argAfter(args, "--delete-snapshots") != "include" {
+			return "", errors.New("ErrorCode:SnapshotsPresent")
+		}
+		delete(f.snapshots, name)
+		delete(f.blobs, name)
+		f.deleted[name] = true
+	case "storage blob list":
+		return "[]", nil
+	}
+	return "{}", nil
+}
+
+// version returns the version of the blob at key. The caller holds f.mu.
+func (f *fakeAz) version(key string) (string, bool) {
+	if v, ok := f.blobs[key]; ok {
+		return v, true
+	}
+	if f.strict || f.deleted[key] {
+		return "", false
+	}
+	return "v1", true
+}
+
+// useFakeAz makes f the az CLI of the state manager for the duration of the
+// test.
+func useFakeAz(t *testing.T, f *fakeAz) *fakeAz {
+	t.Helper()
+	orig := newStateManager
+	newStateManager = func(cfg *config.LZConfig) *state.Manager { return state.NewManager(cfg, f) }
+	t.Cleanup(func() { newStateManager = orig })
+	return f
+}
+
+// azOp returns the az command of args without its flags, e.g. "storage blob
+// show".
+func azOp(args []string) string {
+	var op []string
+	for _, a := range args {
+		if strings.HasPrefix(a, "--") {
+			break
+		}
+		op = append(op, a)
+	}
+	return strings.Join(op, " ")
+}
+
+func argAfter(args []string, flag string) string {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Show the dependency graph between platform layers and landing zones",
+	Long: `Builds the dependency graph used by plan, apply and drift and prints it.
+
+Edges come from two sources:
+  - remote-state: a root reading another root's state through a
+    terraform_remote_state data source (labelled with the state key)
+  - implicit: landing zones after the platform, blueprints after their
+    landing zone, and the CAF platform order when no platform layer reads
+    another's state (rendered dashed)
+
+The command fails when the graph contains a cycle.
+
+Examples:
+  lzctl graph
+  lzctl graph --format mermaid
+  lzctl graph --format json`,
+	RunE: runGraph,
+}
+
+var graphFormat string
+
+func init() {
+	graphCmd.Flags().StringVar(&graphFormat, "format", "dot", "output format: dot | mermaid | json")
+
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	root, err := absRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	format := strings.ToLower(strings.TrimSpace(graphFormat))
+	if jsonOutput {
+		format = "json"
+	}
+	if format != "dot" && format != "mermaid" && format != "json" {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("invalid --format %q: expected dot, mermaid or json", graphFormat))
+	}
+
+	roots, err := resolveLocalRoots(root, rootSelection{})
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	graph, err := buildRootGraph(root, roots)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+
+	switch format {
+	case "mermaid":
+		fmt.Fprint(os.Stdout, graph.Mermaid())
+	case "json":
+		data, err := graph.JSON()
+		if err != nil {
+			return exitcode.Wrap(exitcode.Validation, err)
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+	default:
+		fmt.Fprint(os.Stdout, graph.DOT())
+	}
+	return nil
+}
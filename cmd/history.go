@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/kjourdan1/lzctl/internal/audit"
+	"github.com/kjourdan1/lzctl/internal/config"
 )
 
 var historyCmd = &cobra.Command{
@@ -16,17 +17,16 @@ var historyCmd = &cobra.Command{
 	Long: `Displays audit events written by lzctl in JSONL format.
 
 By default, reads ~/.lzctl/audit.log and prints the latest events.
-Use --tenant to filter on a specific tenant.`,
+Use the global --tenant and --env flags to show the events of one tenant or
+one environment.`,
 	RunE: runHistory,
 }
 
 var (
-	historyTenant string
-	historyLimit  int
+	historyLimit int
 )
 
 func init() {
-	historyCmd.Flags().StringVar(&historyTenant, "tenant", "", "filter by tenant")
 	historyCmd.Flags().IntVar(&historyLimit, "limit", 20, "max number of events to display")
 	rootCmd.AddCommand(historyCmd)
 }
@@ -43,7 +43,10 @@ func runHistory(cmd *cobra.Command, args []string) error {
 
 	filtered := make([]audit.Event, 0, len(events))
 	for _, event := range events {
-		if historyTenant != "" && event.Tenant != historyTenant {
+		if tenantName != "" && tenantName != config.AllTenants && event.Tenant != tenantName {
+			continue
+		}
+		if envName != "" && event.Environment != envName {
 			continue
 		}
 		filtered = append(filtered, event)
@@ -73,6 +76,9 @@ func runHistory(cmd *cobra.Command, args []string) error {
 		if event.Ring != "" {
 			fmt.Fprintf(os.Stderr, "  ring=%s", event.Ring)
 		}
+		if event.Environment != "" {
+			fmt.Fprintf(os.Stderr, "  env=%s", event.Environment)
+		}
 		fmt.Fprintf(os.Stderr, "  exit=%d  duration=%dms\n", event.ExitCode, event.DurationMs)
 	}
 
@@ -30,6 +30,14 @@ var initCmd = &cobra.Command{
 If --config is provided, loads the configuration file directly. Otherwise,
 launches an interactive wizard to build the configuration.
 
+With --env, loads lzctl.yaml merged with the lzctl.<env>.yaml overlay and
+generates the environment's tree under environments/<env>/ instead; plan,
+apply and drift run with the same --env use that tree.
+
+With --tenant, the project is a tenant of a multi-tenant repository: its
+lzctl.yaml and generated tree live under tenants/<tenant>/. CI/CD pipelines
+are not rendered for a tenant.
+
 Generated structure:
   platform/
     management-groups/    (Resource Organization)
@@ -201,13 +209,16 @@ func runInit(cmd *cobra.Command) error {
 		return err
 	}
 
-	if effectiveCIMode() && cfgFile == "" && strings.TrimSpace(fromFile) == "" && tenantID == "" {
+	if envName != "" && strings.TrimSpace(fromFile) != "" {
+		return fmt.Errorf("--from-file writes the base lzctl.yaml and cannot be combined with --env")
+	}
+	if effectiveCIMode() && cfgFile == "" && envName == "" && strings.TrimSpace(fromFile) == "" && tenantID == "" {
 		return fmt.Errorf("--ci mode requires --tenant-id (or LZCTL_TENANT_ID)")
 	}
 
-	absRoot, err := filepath.Abs(repoRoot)
+	absRoot, err := absTenantRoot()
 	if err != nil {
-		return fmt.Errorf("resolving repo root: %w", err)
+		return err
 	}
 	if strings.TrimSpace(fromFile) != "" {
 		if strings.TrimSpace(cfgFile) != "" {
@@ -230,11 +241,11 @@ func runInit(cmd *cobra.Command) error {
 		if err != nil {
 			return fmt.Errorf("converting --from-file input to lzctl config: %w", err)
 		}
-	} else if cfgFile != "" {
+	} else if cfgFile != "" || envName != "" {
 		invalidateConfigCache() // config file may have been written; force reload
 		cfg, err = configCache()
 		if err != nil {
-			return fmt.Errorf("loading config from --config %q: %w", cfgFile, err)
+			return fmt.Errorf("loading config from %s: %w", localConfigPath(), err)
 		}
 	} else if tenantID != "" {
 		if err := validateInitInputs(mgModel, connectivity, identity, cicdPlatform, stateStrategy); err != nil {
@@ -311,6 +322,20 @@ func runInit(cmd *cobra.Command) error {
 		return fmt.Errorf("rendering templates: %w", err)
 	}
 
+	if tenantName != "" {
+		// CI/CD only picks pipelines up at the repository root; the
+		// pipelines of a multi-tenant repository are the repository's own.
+		files = tenantTreeFiles(files)
+	}
+	if envName != "" {
+		// The environment tree holds the generated roots only: lzctl.yaml
+		// and the pipelines stay at the repo root, shared by every environment.
+		files = envTreeFiles(files)
+		if absRoot, err = absRepoRoot(); err != nil {
+			return err
+		}
+	}
+
 	writer := lztemplate.Writer{DryRun: dryRun}
 	written, err := writer.WriteAll(files, absRoot)
 	if err != nil {
@@ -1,26 +1,29 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+	"github.com/kjourdan1/lzctl/internal/output"
+	"github.com/kjourdan1/lzctl/internal/plansummary"
+	"github.com/kjourdan1/lzctl/internal/runlog"
+	lztemplate "github.com/kjourdan1/lzctl/internal/template"
 )
 
-var localLayerOrder = []string{
-	"management-groups",
-	"identity",
-	"management",
-	"governance",
-	"connectivity",
-}
+// localLayerOrder is the baseline platform layer order; remote-state
+// dependencies derived by buildRootGraph are layered on top of it.
+var localLayerOrder = config.PlatformLayerOrder
 
 var terraformPlanSummaryRegex = regexp.MustCompile(`Plan:\s+(\d+) to add,\s+(\d+) to change,\s+(\d+) to destroy`)
 
@@ -28,9 +31,35 @@ func localConfigPath() string {
 	if strings.TrimSpace(cfgFile) != "" {
 		return cfgFile
 	}
+	if config.ValidTenantName(tenantName) {
+		return config.TenantConfigPath(repoRoot, tenantName)
+	}
 	return filepath.Join(repoRoot, "lzctl.yaml")
 }
 
+// repoFile returns the path of a repository-level file, such as
+// .lzctl/plan-rules.yaml, relative to the tenant root. These files sit next
+// to lzctl.yaml and are shared by all environments: with --env they are not
+// read from the generated environments/<env>/ tree.
+func repoFile(rel string) (string, error) {
+	root, err := absTenantRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, rel), nil
+}
+
+// hashRootInputs returns the input hash of r (see orchestrator.HashInputs).
+// The blueprint inside a landing zone root is a root of its own and is left
+// out of the zone's hash.
+func hashRootInputs(repo string, r localRoot) (string, error) {
+	var nested []string
+	if r.Kind == rootKindLandingZone {
+		nested = append(nested, "blueprint")
+	}
+	return orchestrator.HashInputs(filepath.Join(repo, r.Dir), nested...)
+}
+
 func resolveLocalLayers(root, selected string) ([]string, error) {
 	if strings.TrimSpace(selected) != "" {
 		dir := filepath.Join(root, "platform", selected)
@@ -53,6 +82,357 @@ func resolveLocalLayers(root, selected string) ([]string, error) {
 	return layers, nil
 }
 
+// Root kinds orchestrated by plan, apply and drift.
+const (
+	rootKindPlatform    = "platform"
+	rootKindLandingZone = "landing-zone"
+	rootKindBlueprint   = "blueprint"
+)
+
+// localRoot is a Terraform root module orchestrated by lzctl: a platform
+// layer, a landing zone, or the blueprint attached to a landing zone.
+type localRoot struct {
+	Name string // "connectivity", "lz:app1", "lz:app1-blueprint" (matches state list naming)
+	Kind string // rootKindPlatform | rootKindLandingZone | rootKindBlueprint
+	Zone string // landing-zone slug; empty for platform layers
+	Dir  string // path relative to the repo root
+}
+
+// rootSelection narrows the roots returned by resolveLocalRoots.
+// An empty selection means every platform layer and landing zone.
+type rootSelection struct {
+	Layer     string // platform layer name (--layer)
+	Zone      string // landing zone name; includes its blueprint (--zone)
+	Blueprint string // landing zone name whose blueprint root is selected (--blueprint)
+}
+
+func (s rootSelection) empty() bool {
+	return strings.TrimSpace(s.Layer) == "" && strings.TrimSpace(s.Zone) == "" && strings.TrimSpace(s.Blueprint) == ""
+}
+
+// resolveLocalRoots returns the Terraform roots to orchestrate: platform
+// layers first in CAF order, then each landing zone followed by its
+// blueprint. Landing zones are ordered by directory name for stable output.
+func resolveLocalRoots(root string, sel rootSelection) ([]localRoot, error) {
+	if sel.empty() {
+		var roots []localRoot
+		for _, layer := range localLayerOrder {
+			if dirExists(filepath.Join(root, "platform", layer)) {
+				roots = append(roots, platformRoot(layer))
+			}
+		}
+		zones, err := discoverLandingZones(root)
+		if err != nil {
+			return nil, err
+		}
+		for _, zone := range zones {
+			roots = append(roots, zoneRoots(root, zone)...)
+		}
+		if len(roots) == 0 {
+			return nil, fmt.Errorf("no platform layers or landing zones found under %s", root)
+		}
+		return roots, nil
+	}
+
+	var roots []localRoot
+	if layer := strings.TrimSpace(sel.Layer); layer != "" {
+		layers, err := resolveLocalLayers(root, layer)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, platformRoot(layers[0]))
+	}
+	if zone := strings.TrimSpace(sel.Zone); zone != "" {
+		slug := lztemplate.Slugify(zone)
+		dir := filepath.Join(root, "landing-zones", slug)
+		if !dirExists(dir) {
+			return nil, fmt.Errorf("landing zone directory not found: %s", dir)
+		}
+		roots = append(roots, zoneRoots(root, slug)...)
+	}
+	if zone := strings.TrimSpace(sel.Blueprint); zone != "" {
+		slug := lztemplate.Slugify(zone)
+		dir := filepath.Join(root, "landing-zones", slug, "blueprint")
+		if !dirExists(dir) {
+			return nil, fmt.Errorf("blueprint directory not found: %s", dir)
+		}
+		if !containsRoot(roots, "lz:"+slug+"-blueprint") {
+			roots = append(roots, blueprintRoot(slug))
+		}
+	}
+	return roots, nil
+}
+
+func platformRoot(layer string) localRoot {
+	return localRoot{
+		Name: layer,
+		Kind: rootKindPlatform,
+		Dir:  filepath.Join("platform", layer),
+	}
+}
+
+func blueprintRoot(slug string) localRoot {
+	return localRoot{
+		Name: "lz:" + slug + "-blueprint",
+		Kind: rootKindBlueprint,
+		Zone: slug,
+		Dir:  filepath.Join("landing-zones", slug, "blueprint"),
+	}
+}
+
+// zoneRoots returns the landing zone root followed by its blueprint root
+// when one has been generated.
+func zoneRoots(root, slug string) []localRoot {
+	roots := []localRoot{{
+		Name: "lz:" + slug,
+		Kind: rootKindLandingZone,
+		Zone: slug,
+		Dir:  filepath.Join("landing-zones", slug),
+	}}
+	if dirExists(filepath.Join(root, "landing-zones", slug, "blueprint")) {
+		roots = append(roots, blueprintRoot(slug))
+	}
+	return roots
+}
+
+// discoverLandingZones lists landing zone slugs under landing-zones/, sorted.
+func discoverLandingZones(root string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(root, "landing-zones"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading landing-zones directory: %w", err)
+	}
+	zones := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			zones = append(zones, e.Name())
+		}
+	}
+	sort.Strings(zones)
+	return zones, nil
+}
+
+func containsRoot(roots []localRoot, name string) bool {
+	for _, r := range roots {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func rootNames(roots []localRoot) []string {
+	names := make([]string, len(roots))
+	for i, r := range roots {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// outputMu serialises per-root progress output when roots run concurrently.
+var outputMu sync.Mutex
+
+// rootNodes returns the implicit dependencies between roots. platformDeps
+// holds the dependencies between platform layers derived from their
+// terraform_remote_state data sources; when it is nil, none could be
+// derived and platform layers fall back to the CAF chain, each waiting for
+// the previous selected layer. Landing zones wait for every platform layer
+// that no other platform layer depends on, and each blueprint waits for its
+// landing zone when that zone is also selected.
+func rootNodes(roots []localRoot, platformDeps map[string][]string) []orchestrator.Node {
+	chain := platformDeps == nil
+	if chain {
+		platformDeps = map[string][]string{}
+		prev := ""
+		for _, r := range roots {
+			if r.Kind != rootKindPlatform {
+				continue
+			}
+			if prev != "" {
+				platformDeps[r.Name] = []string{prev}
+			}
+			prev = r.Name
+		}
+	}
+
+	dependedOn := map[string]bool{}
+	for _, deps := range platformDeps {
+		for _, dep := range deps {
+			dependedOn[dep] = true
+		}
+	}
+	var platformTips []string
+	for _, r := range roots {
+		if r.Kind == rootKindPlatform && !dependedOn[r.Name] {
+			platformTips = append(platformTips, r.Name)
+		}
+	}
+
+	nodes := make([]orchestrator.Node, 0, len(roots))
+	for _, r := range roots {
+		n := orchestrator.Node{ID: r.Name}
+		switch r.Kind {
+		case rootKindPlatform:
+			// Derived platform dependencies are remote-state edges, added
+			// by buildRootGraph.
+			if chain {
+				n.Deps = append(n.Deps, platformDeps[r.Name]...)
+			}
+		case rootKindLandingZone:
+			n.Deps = append(n.Deps, platformTips...)
+		case rootKindBlueprint:
+			if zone := "lz:" + r.Zone; containsRoot(roots, zone) {
+				n.Deps = append(n.Deps, zone)
+			} else {
+				n.Deps = append(n.Deps, platformTips...)
+			}
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// rootStateKey returns the conventional backend state key for a root, used
+// when the root does not declare its key in backend.hcl.
+func rootStateKey(r localRoot) string {
+	switch r.Kind {
+	case rootKindLandingZone:
+		return "landing-zones-" + r.Zone + ".tfstate"
+	case rootKindBlueprint:
+		return "landing-zones-" + r.Zone + "-blueprint.tfstate"
+	default:
+		return layerToStateKey(r.Name)
+	}
+}
+
+// stateKeyFor returns the state key r's backend uses: the key declared in
+// its backend configuration, or the conventional key when none is declared,
+// under <env>/ with --env.
+func stateKeyFor(repo string, r localRoot) (string, error) {
+	key, err := orchestrator.ParseBackendKey(filepath.Join(repo, r.Dir))
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		key = envStateKey(rootStateKey(r))
+	}
+	return key, nil
+}
+
+// buildRootGraph combines the dependencies declared by terraform_remote_state
+// data sources in each root with the implicit edges from rootNodes. Platform
+// layers are ordered by the remote-state edges between them, or by the CAF
+// chain when none is declared. Remote-state keys that belong to roots
+// outside the selection are ignored.
+func buildRootGraph(repo string, roots []localRoot) (*orchestrator.Graph, error) {
+	g := orchestrator.NewGraph()
+	owners := make(map[string]string, len(roots))
+	kinds := make(map[string]string, len(roots))
+	for _, r := range roots {
+		g.AddNode(r.Name, r.Kind)
+		kinds[r.Name] = r.Kind
+		key, err := stateKeyFor(repo, r)
+		if err != nil {
+			return nil, err
+		}
+		owners[key] = r.Name
+	}
+
+	var remote []orchestrator.Edge
+	var platformDeps map[string][]string
+	for _, r := range roots {
+		refs, err := orchestrator.ParseRemoteStateRefs(filepath.Join(repo, r.Dir))
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			owner, ok := owners[ref.Key]
+			if !ok || owner == r.Name {
+				continue
+			}
+			remote = append(remote, orchestrator.Edge{From: r.Name, To: owner, Source: orchestrator.EdgeRemoteState, Key: ref.Key})
+			if r.Kind == rootKindPlatform && kinds[owner] == rootKindPlatform {
+				if platformDeps == nil {
+					platformDeps = map[string][]string{}
+				}
+				platformDeps[r.Name] = append(platformDeps[r.Name], owner)
+			}
+		}
+	}
+
+	for _, n := range rootNodes(roots, platformDeps) {
+		for _, dep := range n.Deps {
+			g.AddEdge(orchestrator.Edge{From: n.ID, To: dep, Source: orchestrator.EdgeImplicit})
+		}
+	}
+	for _, e := range remote {
+		g.AddEdge(e)
+	}
+
+	if _, err := g.Order(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// orderRoots builds the dependency graph for roots and returns them sorted
+// in execution order, together with the graph used to schedule them.
+func orderRoots(repo string, roots []localRoot) ([]localRoot, *orchestrator.Graph, error) {
+	g, err := buildRootGraph(repo, roots)
+	if err != nil {
+		return nil, nil, err
+	}
+	order, err := g.Order()
+	if err != nil {
+		return nil, nil, err
+	}
+	byName := make(map[string]localRoot, len(roots))
+	for _, r := range roots {
+		byName[r.Name] = r
+	}
+	ordered := make([]localRoot, 0, len(order))
+	for _, name := range order {
+		ordered = append(ordered, byName[name])
+	}
+	return ordered, g, nil
+}
+
+// runRoots runs fn for every root through the dependency scheduler, with up
+// to parallelism independent roots in flight. fn receives the root index and
+// a context that is canceled as soon as any root returns an error, and
+// that expires after the root's timeout (spec.execution). A root still
+// running at its timeout fails with a Terraform error.
+func runRoots(ctx context.Context, roots []localRoot, g *orchestrator.Graph, parallelism int, fn func(ctx context.Context, i int) error) error {
+	settings, err := loadExecution()
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	index := make(map[string]int, len(roots))
+	for i, r := range roots {
+		index[r.Name] = i
+	}
+	_, err = orchestrator.Execute(ctx, g.Nodes(), parallelism, func(ctx context.Context, id string) error {
+		ctx = runlog.WithLayer(ctx, id)
+		timeout := settings.rootTimeout(id)
+		if timeout <= 0 {
+			return fn(ctx, index[id])
+		}
+		rootCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		err := fn(rootCtx, index[id])
+		if errors.Is(rootCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			if err == nil {
+				err = rootCtx.Err()
+			}
+			return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: timed out after %s: %w", id, timeout, err))
+		}
+		return err
+	})
+	return err
+}
+
 func dirExists(path string) bool {
 	info, err := os.Stat(path)
 	return err == nil && info.IsDir()
@@ -63,28 +443,68 @@ func fileExistsLocal(path string) bool {
 	return err == nil && !info.IsDir()
 }
 
-func ensureTerraformInstalled() error {
-	if _, err := exec.LookPath("terraform"); err != nil {
-		return fmt.Errorf("terraform not found in PATH (install with: winget install Hashicorp.Terraform)")
+// newRunner builds the runner for the selected tool. Tests replace it with
+// a runnertest.Fake.
+var newRunner = func(spec orchestrator.ToolSpec) (orchestrator.Runner, error) {
+	if err := orchestrator.LookPath(spec); err != nil {
+		return nil, err
+	}
+	return orchestrator.NewRunner(spec), nil
+}
+
+// toolSpecFor selects Terraform or OpenTofu for repo: spec.terraform in
+// lzctl.yaml first, then .terraform-version, then terraform. A missing or
+// unreadable lzctl.yaml is not an error here; commands that need the config
+// report it themselves.
+func toolSpecFor(repo string) (orchestrator.ToolSpec, error) {
+	var binary, version string
+	if cfg, err := configCache(); err == nil && cfg.Spec.Terraform != nil {
+		binary, version = cfg.Spec.Terraform.Binary, cfg.Spec.Terraform.Version
+	}
+	return orchestrator.SelectTool(binary, version, repo)
+}
+
+// resolveRunner returns the runner for repo after checking the binary is
+// installed at the minimum version (the same check lzctl doctor runs).
+// Runs failing with transient errors are retried per spec.execution.retry.
+// At -v the output of each run is streamed to stderr, prefixed with its
+// layer, and every call is archived when the context carries a run log.
+func resolveRunner(ctx context.Context, repo string) (orchestrator.Runner, error) {
+	spec, err := toolSpecFor(repo)
+	if err != nil {
+		return nil, err
+	}
+	tf, err := newRunner(spec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := orchestrator.CheckVersion(ctx, tf, spec); err != nil {
+		return nil, err
+	}
+	settings, err := loadExecution()
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	// Log every attempt, so retried runs keep the output of each failure.
+	tf = runlog.Wrap(tf, runLogStream(), &outputMu)
+	return withRunRetry(repo, tf, settings), nil
 }
 
-func runTerraformCmd(ctx context.Context, dir string, args ...string) (string, error) {
-	if ctx == nil {
-		ctx = context.Background()
+// summarizePlan derives a structured summary for planFile (relative to dir)
+// from `show -json`. When jsonPath is set the JSON plan is also written
+// there. If the JSON plan is unavailable or unparsable, counts fall back to
+// the human summary line in planOutput and the resource list is empty.
+func summarizePlan(ctx context.Context, tf orchestrator.Runner, dir, planFile, jsonPath, planOutput string) *plansummary.Summary {
+	if jsonOut, err := tf.Output(ctx, dir, "show", "-json", planFile); err == nil {
+		if jsonPath != "" {
+			_ = os.WriteFile(jsonPath, []byte(jsonOut), 0o644)
+		}
+		if s, parseErr := plansummary.Parse([]byte(jsonOut)); parseErr == nil {
+			return s
+		}
 	}
-	cmd := exec.CommandContext(ctx, "terraform", args...)
-	cmd.Dir = dir
-	// Inherit the full environment and append TF_INPUT=false so that the
-	// process sees the current PATH (needed in tests that override PATH via
-	// t.Setenv to point to a fake terraform binary).
-	cmd.Env = append(os.Environ(), "TF_INPUT=false")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &out
-	err := cmd.Run()
-	return out.String(), err
+	add, change, destroy := parsePlanSummary(planOutput)
+	return &plansummary.Summary{Add: add, Change: change, Destroy: destroy, Resources: []plansummary.ResourceChange{}}
 }
 
 func parsePlanSummary(output string) (int, int, int) {
@@ -113,8 +533,12 @@ var (
 // Commands that need config call this instead of config.Load() directly.
 func configCache() (*config.LZConfig, error) {
 	if !cfgCacheSet {
-		cfgCache, cfgCacheErr = config.Load(localConfigPath())
+		cfgCache, cfgCacheErr = config.LoadEnv(localConfigPath(), envName)
 		cfgCacheSet = true
+		if cfgCache != nil && cfgCache.MigratedFrom != "" {
+			output.Warn(fmt.Sprintf("%s uses apiVersion %s, migrated in memory to %s", localConfigPath(), cfgCache.MigratedFrom, cfgCache.APIVersion),
+				"fix", "run 'lzctl config migrate' to update the file")
+		}
 	}
 	return cfgCache, cfgCacheErr
 }
@@ -7,6 +7,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
 )
 
 func TestParsePlanSummary(t *testing.T) {
@@ -57,3 +61,183 @@ func TestResolveLocalLayers_NoPlatformLayers_ReturnsError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no platform layers found")
 }
+
+func TestResolveLocalRoots_PlatformThenZones(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "platform", "connectivity"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "platform", "management-groups"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "landing-zones", "beta"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "landing-zones", "alpha", "blueprint"), 0o755))
+
+	roots, err := resolveLocalRoots(root, rootSelection{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"management-groups", "connectivity", "lz:alpha", "lz:alpha-blueprint", "lz:beta"}, rootNames(roots))
+	assert.Equal(t, rootKindBlueprint, roots[3].Kind)
+	assert.Equal(t, filepath.Join("landing-zones", "alpha", "blueprint"), roots[3].Dir)
+}
+
+func TestResolveLocalRoots_ZoneIncludesBlueprint(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "landing-zones", "app-one", "blueprint"), 0o755))
+
+	roots, err := resolveLocalRoots(root, rootSelection{Zone: "App One"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lz:app-one", "lz:app-one-blueprint"}, rootNames(roots))
+}
+
+func TestResolveLocalRoots_BlueprintOnly(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "platform", "identity"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "landing-zones", "app", "blueprint"), 0o755))
+
+	roots, err := resolveLocalRoots(root, rootSelection{Layer: "identity", Blueprint: "app"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"identity", "lz:app-blueprint"}, rootNames(roots))
+}
+
+func TestResolveLocalRoots_MissingZone_ReturnsError(t *testing.T) {
+	root := t.TempDir()
+
+	_, err := resolveLocalRoots(root, rootSelection{Zone: "ghost"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "landing zone directory not found")
+
+	_, err = resolveLocalRoots(root, rootSelection{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no platform layers or landing zones found")
+}
+
+func TestRootNodes_ZonesDependOnPlatformAndBlueprintsOnZones(t *testing.T) {
+	roots := []localRoot{
+		platformRoot("management-groups"),
+		platformRoot("connectivity"),
+		{Name: "lz:a", Kind: rootKindLandingZone, Zone: "a"},
+		blueprintRoot("a"),
+		{Name: "lz:b", Kind: rootKindLandingZone, Zone: "b"},
+	}
+
+	nodes := rootNodes(roots, nil)
+	require.Len(t, nodes, 5)
+	assert.Empty(t, nodes[0].Deps)
+	assert.Equal(t, []string{"management-groups"}, nodes[1].Deps)
+	assert.Equal(t, []string{"connectivity"}, nodes[2].Deps)
+	assert.Equal(t, []string{"lz:a"}, nodes[3].Deps)
+	assert.Equal(t, []string{"connectivity"}, nodes[4].Deps)
+}
+
+func TestBuildRootGraph_RemoteStateEdges(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "platform", "connectivity"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "landing-zones", "a"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "landing-zones", "b"), 0o755))
+	// lz:a reads lz:b's state, so b must run first despite alphabetical order.
+	require.NoError(t, os.WriteFile(filepath.Join(root, "landing-zones", "a", "main.tf"), []byte(`
+data "terraform_remote_state" "shared" {
+  backend = "azurerm"
+  config = {
+    key = "landing-zones-b.tfstate"
+  }
+}
+`), 0o644))
+
+	roots, err := resolveLocalRoots(root, rootSelection{})
+	require.NoError(t, err)
+	ordered, g, err := orderRoots(root, roots)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"connectivity", "lz:b", "lz:a"}, rootNames(ordered))
+	assert.Contains(t, g.Edges(), orchestrator.Edge{From: "lz:a", To: "lz:b", Source: orchestrator.EdgeRemoteState, Key: "landing-zones-b.tfstate"})
+}
+
+func TestBuildRootGraph_PlatformEdgesFromRemoteState(t *testing.T) {
+	root := t.TempDir()
+	for _, layer := range []string{"management-groups", "identity", "management", "connectivity"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "platform", layer), 0o755))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "landing-zones", "a"), 0o755))
+	// identity and management both read management-groups; connectivity
+	// reads management. identity no longer waits for anything else.
+	for layer, key := range map[string]string{"identity": "platform-management-groups.tfstate", "management": "platform-management-groups.tfstate", "connectivity": "platform-management.tfstate"} {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "platform", layer, "main.tf"), []byte(`
+data "terraform_remote_state" "upstream" {
+  config = {
+    key = "`+key+`"
+  }
+}
+`), 0o644))
+	}
+
+	roots, err := resolveLocalRoots(root, rootSelection{})
+	require.NoError(t, err)
+	g, err := buildRootGraph(root, roots)
+	require.NoError(t, err)
+
+	deps := map[string][]string{}
+	for _, n := range g.Nodes() {
+		deps[n.ID] = n.Deps
+	}
+	assert.Empty(t, deps["management-groups"])
+	assert.Equal(t, []string{"management-groups"}, deps["identity"])
+	assert.Equal(t, []string{"management-groups"}, deps["management"])
+	assert.Equal(t, []string{"management"}, deps["connectivity"])
+	assert.ElementsMatch(t, []string{"identity", "connectivity"}, deps["lz:a"], "zones wait for the platform layers nothing depends on")
+	for _, e := range g.Edges() {
+		if e.Source == orchestrator.EdgeImplicit {
+			assert.Equal(t, "lz:a", e.From, "no CAF chain edge between platform layers")
+		}
+	}
+}
+
+func TestBuildRootGraph_CycleReturnsError(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "platform", "connectivity"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "landing-zones", "a"), 0o755))
+	// connectivity reading a landing zone's state inverts the implicit edge.
+	require.NoError(t, os.WriteFile(filepath.Join(root, "platform", "connectivity", "main.tf"), []byte(`
+data "terraform_remote_state" "spoke" {
+  config = {
+    key = "landing-zones-a.tfstate"
+  }
+}
+`), 0o644))
+
+	roots, err := resolveLocalRoots(root, rootSelection{})
+	require.NoError(t, err)
+	_, err = buildRootGraph(root, roots)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency cycle detected")
+}
+
+func TestResolveRunner_SelectsOpenTofuFromConfig(t *testing.T) {
+	repo := initRepoForCommandTests(t)
+	cfgPath := filepath.Join(repo, "lzctl.yaml")
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	cfg.Spec.Terraform = &config.Terraform{Binary: "tofu", Version: "1.8.0"}
+	require.NoError(t, config.Save(cfg, cfgPath))
+
+	var selected orchestrator.ToolSpec
+	tf := runnertest.New().WithTool(orchestrator.ToolOpenTofu).Version("1.8.3")
+	orig := newRunner
+	newRunner = func(spec orchestrator.ToolSpec) (orchestrator.Runner, error) {
+		selected = spec
+		return tf, nil
+	}
+	t.Cleanup(func() { newRunner = orig })
+
+	_, _, err = executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "identity")
+	require.NoError(t, err)
+	assert.Equal(t, orchestrator.ToolOpenTofu, selected.Tool)
+	assert.Equal(t, "1.8.0", selected.MinVersion)
+	assert.Equal(t, []string{"identity"}, tf.Roots("plan"))
+}
+
+func TestResolveRunner_RejectsVersionBelowPin(t *testing.T) {
+	repo := initRepoForCommandTests(t)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, orchestrator.VersionFile), []byte("1.9.5\n"), 0o644))
+	tf := useFakeRunner(t, runnertest.New().Version("1.9.0"))
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "terraform 1.9.0 found, but >= 1.9.5 required")
+	assert.Empty(t, tf.Calls("plan"))
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/kjourdan1/lzctl/internal/notify"
+	"github.com/kjourdan1/lzctl/internal/policy"
+	"github.com/kjourdan1/lzctl/internal/runjournal"
+)
+
+// maxFactLength caps fact values such as Terraform error messages, which
+// chat cards do not render well.
+const maxFactLength = 300
+
+// sendNotification posts e to the sinks configured under
+// spec.notifications. Notifications never change a command's outcome:
+// delivery problems are printed as warnings. Nothing is sent in dry-run mode.
+func sendNotification(ctx context.Context, e notify.Event) {
+	if dryRun {
+		return
+	}
+	cfg, err := configCache()
+	if err != nil || cfg.Spec.Notifications == nil {
+		return
+	}
+	n, err := notify.New(cfg.Spec.Notifications)
+	if err == nil {
+		e.Project = cfg.Metadata.Name
+		err = n.Notify(ctx, e)
+	}
+	if err != nil {
+		outputMu.Lock()
+		defer outputMu.Unlock()
+		color.New(color.FgYellow).Fprintf(os.Stderr, "⚠️  Notification failed: %v\n", err)
+	}
+}
+
+// appliedLayer is one root of a failed apply as reported to webhooks.
+type appliedLayer struct {
+	Name   string `json:"layer"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// applyFailedEvent describes a failed apply run from its journal. Errors are
+// cut to one line: Terraform output can reveal resource names, IDs and
+// variable values, and does not belong in a chat channel.
+func applyFailedEvent(journal *runjournal.Journal, runErr error) notify.Event {
+	e := notify.Event{
+		Type:     notify.EventApplyFailed,
+		Severity: notify.SeverityError,
+		Title:    "Apply failed",
+		Summary:  errorLine(runErr.Error()),
+	}
+	if journal == nil {
+		return e
+	}
+	e.Summary = fmt.Sprintf("Run %s failed. Resume with: lzctl apply --resume %s", journal.RunID, journal.RunID)
+	layers := make([]appliedLayer, 0, len(journal.Layers))
+	for _, l := range journal.Layers {
+		layer := appliedLayer{Name: l.Name, Status: l.Status, Error: errorLine(l.Error)}
+		value := layer.Status
+		if layer.Error != "" {
+			value += ": " + layer.Error
+		}
+		e.Facts = append(e.Facts, notify.Fact{Name: l.Name, Value: value})
+		layers = append(layers, layer)
+	}
+	e.Details = map[string]interface{}{"runId": journal.RunID, "status": journal.Status, "layers": layers}
+	return e
+}
+
+// policyAssignmentState returns the workflow state of assignment name, or
+// "" when it is not tracked yet.
+func policyAssignmentState(root, name string) string {
+	status, err := policy.Status(policy.StatusOpts{RepoRoot: root})
+	if err != nil {
+		return ""
+	}
+	for _, a := range status.Assignments {
+		if a.Name == name {
+			return a.State
+		}
+	}
+	return ""
+}
+
+// notifyPolicyStatus sends a policy-status event when assignment name moved
+// from state before to a new workflow state.
+func notifyPolicyStatus(ctx context.Context, root, name, before string) {
+	after := policyAssignmentState(root, name)
+	if after == "" || after == before {
+		return
+	}
+	from := before
+	if from == "" {
+		from = "none"
+	}
+	severity := notify.SeverityInfo
+	if after == "deploy" {
+		severity = notify.SeverityWarning
+	}
+	sendNotification(ctx, notify.Event{
+		Type:     notify.EventPolicyStatus,
+		Severity: severity,
+		Title:    fmt.Sprintf("Policy assignment %s: %s → %s", name, from, after),
+		Summary:  policyStateDescription(after),
+		Facts: []notify.Fact{
+			{Name: "Assignment", Value: name},
+			{Name: "Previous state", Value: from},
+			{Name: "New state", Value: after},
+		},
+	})
+}
+
+func policyStateDescription(state string) string {
+	switch state {
+	case "test":
+		return "Deployed in audit mode (DoNotEnforce)."
+	case "verify":
+		return "Compliance verified and report generated."
+	case "remediate":
+		return "Remediation tasks created for non-compliant resources."
+	case "deploy":
+		return "Switched to Default enforcement: non-compliant deployments are now denied."
+	}
+	return "Workflow state changed."
+}
+
+// errorLine returns the line of a multi-line error worth reporting: the
+// first Terraform "Error:" line, else the first non-empty one, truncated.
+func errorLine(s string) string {
+	first := ""
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "Error:") {
+			return truncateFact(line)
+		}
+		if first == "" {
+			first = line
+		}
+	}
+	return truncateFact(first)
+}
+
+func truncateFact(s string) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) <= maxFactLength {
+		return string(r)
+	}
+	return string(r[:maxFactLength]) + "…"
+}
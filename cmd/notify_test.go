@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookRecorder collects the events posted to a local generic webhook.
+type webhookRecorder struct {
+	mu     sync.Mutex
+	events []notify.Event
+}
+
+func (w *webhookRecorder) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	var e notify.Event
+	if err := json.Unmarshal(body, &e); err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.mu.Lock()
+	w.events = append(w.events, e)
+	w.mu.Unlock()
+}
+
+// withWebhookSink adds a generic webhook sink pointing at a local server to
+// the repository's lzctl.yaml.
+func withWebhookSink(t *testing.T, repo string) *webhookRecorder {
+	t.Helper()
+	rec := &webhookRecorder{}
+	srv := httptest.NewServer(rec)
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(repo, "lzctl.yaml")
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	cfg.Spec.Notifications = &config.Notifications{Sinks: []config.NotificationSink{
+		{Name: "local", Type: notify.FormatWebhook, URL: srv.URL},
+	}}
+	require.NoError(t, config.Save(cfg, path))
+	return rec
+}
+
+func TestDriftCmd_NotifiesOnDrift(t *testing.T) {
+	useFakeDriftTerraform(t, true, false)
+	repo := initRepoForCommandTests(t)
+	rec := withWebhookSink(t, repo)
+
+	_, _, err := executeCommandWithProcessIO(t, "drift", "--repo-root", repo, "--layer", "identity", "--json")
+	require.Error(t, err)
+
+	require.Len(t, rec.events, 1)
+	e := rec.events[0]
+	assert.Equal(t, notify.EventDrift, e.Type)
+	assert.Equal(t, notify.SeverityWarning, e.Severity)
+	assert.NotEmpty(t, e.Project)
+	assert.Equal(t, []notify.Fact{{Name: "identity", Value: "drifted ~1 -0"}}, e.Facts)
+}
+
+func TestDriftCmd_NoNotificationWithoutDrift(t *testing.T) {
+	useFakeDriftTerraform(t, false, true)
+	repo := initRepoForCommandTests(t)
+	rec := withWebhookSink(t, repo)
+
+	_, _, err := executeCommandWithProcessIO(t, "drift", "--repo-root", repo, "--layer", "identity", "--json")
+	require.NoError(t, err)
+	assert.Empty(t, rec.events, "pending code changes are not drift")
+}
+
+func TestApplyCmd_NotifiesOnFailure(t *testing.T) {
+	fail := true
+	useFailingApplyTerraform(t, &fail)
+	repo := initRepoForCommandTests(t)
+	rec := withWebhookSink(t, repo)
+
+	_, _, err := executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--auto-approve")
+	require.Error(t, err)
+
+	require.Len(t, rec.events, 1)
+	e := rec.events[0]
+	assert.Equal(t, notify.EventApplyFailed, e.Type)
+	assert.Equal(t, notify.SeverityError, e.Severity)
+	assert.Contains(t, e.Summary, "lzctl apply --resume")
+	facts := map[string]string{}
+	for _, f := range e.Facts {
+		facts[f.Name] = f.Value
+	}
+	assert.Equal(t, "succeeded", facts["management-groups"])
+	assert.Contains(t, facts["governance"], "failed")
+
+	details, err := json.Marshal(e.Details)
+	require.NoError(t, err)
+	assert.Contains(t, string(details), `"layer":"governance"`)
+	assert.NotContains(t, string(details), "inputHash", "the journal itself is not sent")
+}
+
+func TestErrorLine(t *testing.T) {
+	assert.Equal(t, "Error: creating Resource Group: 403", errorLine("terraform apply: exit status 1\n\nError: creating Resource Group: 403\n\n  with azurerm_resource_group.this,\n  on main.tf line 3"))
+	assert.Equal(t, "exit status 1", errorLine("\n exit status 1\nmore output"))
+	assert.Equal(t, 301, len([]rune(errorLine(strings.Repeat("x", 500)))))
+	assert.Empty(t, errorLine(""))
+}
+
+func TestPolicyTestCmd_NotifiesStatusChange(t *testing.T) {
+	repo := initRepoForCommandTests(t)
+	rec := withWebhookSink(t, repo)
+
+	_, _, err := executeCommand("policy", "create", "--repo-root", repo, "--type", "assignment", "--name", "deny-public-ip", "--scope", "/providers/Microsoft.Management/managementGroups/contoso")
+	require.NoError(t, err)
+
+	_, _, err = executeCommand("policy", "test", "--repo-root", repo, "--name", "deny-public-ip")
+	require.NoError(t, err)
+
+	require.Len(t, rec.events, 1)
+	e := rec.events[0]
+	assert.Equal(t, notify.EventPolicyStatus, e.Type)
+	assert.Equal(t, "Policy assignment deny-public-ip: created → test", e.Title)
+}
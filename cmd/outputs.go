@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/outputs"
+)
+
+var outputsCmd = &cobra.Command{
+	Use:   "outputs",
+	Short: "Collect layer outputs into the output registry",
+	Long: `Collects the outputs of each root into the output registry
+(.lzctl/outputs.json).
+
+Layers share data through terraform_remote_state data sources, so the
+outputs of a layer are a public API for the layers that read them. For each
+root, 'terraform output -json' is read from its state and the name, type and
+sensitivity of every output are recorded. Values are never written.
+
+Only the selected roots are updated; the entries of other roots are kept.
+Use --layer, --zone or --blueprint to collect a subset, and commit the
+registry so that reviews and CI share the same contract.
+
+'lzctl plan' and 'lzctl validate' check the contract against the registry:
+an output that is still read by a downstream root but is no longer declared,
+or whose planned type differs from the registered one, fails the check.
+
+With --dry-run the outputs are collected and reported but the registry is
+not written.`,
+	RunE: runOutputs,
+}
+
+var (
+	outputsLayer       string
+	outputsZone        string
+	outputsBlueprint   string
+	outputsRegistry    string
+	outputsParallelism int
+)
+
+func init() {
+	outputsCmd.Flags().StringVar(&outputsLayer, "layer", "", "specific layer to collect")
+	outputsCmd.Flags().StringVar(&outputsZone, "zone", "", "landing zone to collect (includes its blueprint)")
+	outputsCmd.Flags().StringVar(&outputsBlueprint, "blueprint", "", "landing zone whose blueprint to collect")
+	outputsCmd.Flags().StringVar(&outputsRegistry, "registry", "", "output registry path (default: "+outputs.DefaultPath+")")
+	outputsCmd.Flags().IntVar(&outputsParallelism, "parallelism", 1, "number of independent roots to collect concurrently")
+
+	rootCmd.AddCommand(outputsCmd)
+}
+
+func runOutputs(cmd *cobra.Command, args []string) (err error) {
+	root, err := absRepoRoot()
+	if err != nil {
+		return err
+	}
+	path := outputsRegistry
+	if path == "" {
+		if path, err = repoFile(outputs.DefaultPath); err != nil {
+			return err
+		}
+	}
+
+	reg, err := outputs.Load(path)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	if reg == nil {
+		reg = outputs.New()
+	}
+
+	tf, err := resolveRunner(cmd.Context(), root)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	ctx, logs := startRunLog(cmd.Context(), root, "", "outputs")
+	defer func() { reportRunLog(root, logs, err) }()
+
+	roots, err := resolveLocalRoots(root, rootSelection{Layer: outputsLayer, Zone: outputsZone, Blueprint: outputsBlueprint})
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	roots, graph, err := orderRoots(root, roots)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+
+	if !jsonOutput {
+		color.New(color.Bold).Fprintf(os.Stderr, "📤 Collecting layer outputs\n")
+		fmt.Fprintf(os.Stderr, "   Layers: %s\n\n", strings.Join(rootNames(roots), ", "))
+	}
+
+	now := time.Now().UTC()
+	layers := make([]outputs.Layer, len(roots))
+	runErr := runRoots(ctx, roots, graph, outputsParallelism, func(ctx context.Context, i int) error {
+		r := roots[i]
+		dir := filepath.Join(root, r.Dir)
+		key, err := stateKeyFor(root, r)
+		if err != nil {
+			return exitcode.Wrap(exitcode.Validation, fmt.Errorf("layer %s: %w", r.Name, err))
+		}
+		if initOut, initErr := tf.Run(ctx, dir, terraformInitArgs(dir, key)...); initErr != nil {
+			return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform init failed (output: %s): %w", r.Name, initOut, initErr))
+		}
+		out, err := tf.Output(ctx, dir, "output", "-json")
+		if err != nil {
+			return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform output failed: %w", r.Name, err))
+		}
+		collected, err := outputs.ParseTerraformOutputs([]byte(out))
+		if err != nil {
+			return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: %w", r.Name, err))
+		}
+		layers[i] = outputs.Layer{Key: key, Dir: filepath.ToSlash(r.Dir), UpdatedAt: now, Outputs: collected}
+
+		if !jsonOutput {
+			outputMu.Lock()
+			defer outputMu.Unlock()
+			color.New(color.FgGreen).Fprintf(os.Stderr, "   ✅ %-20s %d output(s)\n", r.Name, len(collected))
+			if verbosity > 0 {
+				names := make([]string, 0, len(collected))
+				for name := range collected {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					fmt.Fprintf(os.Stderr, "      - %s %s\n", name, string(collected[name].Type))
+				}
+			}
+		}
+		return nil
+	})
+	if runErr != nil {
+		return runErr
+	}
+
+	for i, r := range roots {
+		reg.Layers[r.Name] = layers[i]
+	}
+	reg.GeneratedAt = now
+
+	written := false
+	if !dryRun {
+		if err := reg.Save(path); err != nil {
+			return exitcode.Wrap(exitcode.Generic, err)
+		}
+		written = true
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(map[string]interface{}{
+			"status":   "ok",
+			"registry": path,
+			"written":  written,
+			"layers":   reg.Layers,
+		}, "", "  ")
+		fmt.Fprintln(os.Stdout, string(data))
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr)
+	if written {
+		color.New(color.FgGreen, color.Bold).Fprintf(os.Stderr, "✅ Output registry written to: %s\n", path)
+	} else {
+		color.New(color.FgYellow, color.Bold).Fprintf(os.Stderr, "⚡ [DRY-RUN] Output registry not written: %s\n", path)
+	}
+	return nil
+}
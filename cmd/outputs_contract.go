@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/outputs"
+)
+
+// checkOutputContract checks the outputs of producers against the roots of
+// the repository that read them through terraform_remote_state, using the
+// output registry and, where a root has one, the types of its saved
+// tfplan.json.
+func checkOutputContract(repo string, producers []localRoot) ([]outputs.Finding, error) {
+	path, err := repoFile(outputs.DefaultPath)
+	if err != nil {
+		return nil, err
+	}
+	reg, err := outputs.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	checked := make([]outputs.Producer, 0, len(producers))
+	for _, r := range producers {
+		dir := filepath.Join(repo, r.Dir)
+		key, err := stateKeyFor(repo, r)
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", r.Name, err)
+		}
+		declared, err := outputs.Declared(dir)
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", r.Name, err)
+		}
+		p := outputs.Producer{Layer: r.Name, Key: key, Declared: declared}
+		if data, err := os.ReadFile(filepath.Join(dir, "tfplan.json")); err == nil {
+			// A plan JSON that does not parse only loses the type check.
+			p.Kinds, _ = outputs.PlannedKinds(data)
+		}
+		checked = append(checked, p)
+	}
+
+	consumers, err := resolveLocalRoots(repo, rootSelection{})
+	if err != nil {
+		return nil, err
+	}
+	var refs []outputs.Reference
+	for _, r := range consumers {
+		rootRefs, err := outputs.References(filepath.Join(repo, r.Dir), r.Name)
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", r.Name, err)
+		}
+		refs = append(refs, rootRefs...)
+	}
+	return outputs.Check(checked, refs, reg), nil
+}
+
+// contractBreaking reports whether a finding breaks a consumer for sure:
+// the output was registered and is gone, or its type changed. Undeclared
+// outputs are only warned about, since nothing records they ever existed.
+func contractBreaking(f outputs.Finding) bool {
+	return f.Change == outputs.ChangeRemoved || f.Change == outputs.ChangeTypeChanged
+}
+
+// printContractFindings lists the output contract findings.
+func printContractFindings(findings []outputs.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "🔗 Output contract:\n")
+	for _, f := range findings {
+		if contractBreaking(f) {
+			color.New(color.FgRed).Fprintf(os.Stderr, "   ⛔ %s\n", f.Message())
+		} else {
+			color.New(color.FgYellow).Fprintf(os.Stderr, "   ⚠️  %s\n", f.Message())
+		}
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
+// contractError is the Validation error for findings that break consumers.
+func contractError(findings []outputs.Finding) error {
+	var parts []string
+	for _, f := range findings {
+		if contractBreaking(f) {
+			parts = append(parts, fmt.Sprintf("%s.%s (%s, read by %s)", f.Producer, f.Output, f.Change, f.Consumer))
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return exitcode.Wrap(exitcode.Validation, fmt.Errorf(
+		"output contract broken: %s; keep the outputs or update their consumers, then run lzctl outputs",
+		strings.Join(parts, ", ")))
+}
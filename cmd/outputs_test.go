@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+	"github.com/kjourdan1/lzctl/internal/outputs"
+)
+
+const connectivityOutputJSON = `{
+  "hub_vnet_id": {"sensitive": false, "type": "string", "value": "/subscriptions/x/providers/vnet-hub"},
+  "firewall_ip": {"sensitive": true, "type": "string", "value": "10.0.0.4"}
+}`
+
+// writeContractRepo adds outputs to the connectivity layer and a landing zone
+// that reads hub_vnet_id and firewall_ip from it, and registers both outputs
+// as strings.
+func writeContractRepo(t *testing.T, declared string) string {
+	t.Helper()
+	repo := initRepoForCommandTests(t)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "platform", "connectivity", "outputs.tf"), []byte(declared), 0o644))
+
+	zone := filepath.Join(repo, "landing-zones", "app")
+	require.NoError(t, os.MkdirAll(zone, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(zone, "main.tf"), []byte(`data "terraform_remote_state" "connectivity" {
+  backend = "azurerm"
+  config = {
+    key = "platform-connectivity.tfstate"
+  }
+}
+
+locals {
+  hub_vnet_id = data.terraform_remote_state.connectivity.outputs.hub_vnet_id
+  firewall_ip = data.terraform_remote_state.connectivity.outputs.firewall_ip
+}
+`), 0o644))
+
+	reg := outputs.New()
+	reg.Layers["connectivity"] = outputs.Layer{Key: "platform-connectivity.tfstate", Outputs: map[string]outputs.Output{
+		"hub_vnet_id": {Type: json.RawMessage(`"string"`)},
+		"firewall_ip": {Type: json.RawMessage(`"string"`), Sensitive: true},
+	}}
+	require.NoError(t, reg.Save(filepath.Join(repo, outputs.DefaultPath)))
+	return repo
+}
+
+const bothOutputsTF = "output \"hub_vnet_id\" {\n  value = \"x\"\n}\n\noutput \"firewall_ip\" {\n  value = \"y\"\n}\n"
+
+func TestOutputsCmd_WritesRegistryWithoutValues(t *testing.T) {
+	tf := useFakeRunner(t, runnertest.New().Reply("output", connectivityOutputJSON, 0))
+	repo := initRepoForCommandTests(t)
+	path := filepath.Join(repo, outputs.DefaultPath)
+	seed := outputs.New()
+	seed.Layers["identity"] = outputs.Layer{Key: "platform-identity.tfstate", Outputs: map[string]outputs.Output{}}
+	require.NoError(t, seed.Save(path))
+
+	_, _, err := executeCommandWithProcessIO(t, "outputs", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"connectivity"}, tf.Roots("output"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "10.0.0.4")
+	assert.NotContains(t, string(data), "vnet-hub")
+
+	reg, err := outputs.Load(path)
+	require.NoError(t, err)
+	assert.Contains(t, reg.Layers, "identity", "entries of other roots are kept")
+	conn := reg.Layers["connectivity"]
+	assert.Equal(t, "platform-connectivity.tfstate", conn.Key)
+	assert.Equal(t, "platform/connectivity", conn.Dir)
+	assert.JSONEq(t, `"string"`, string(conn.Outputs["hub_vnet_id"].Type))
+	assert.True(t, conn.Outputs["firewall_ip"].Sensitive)
+}
+
+func TestOutputsCmd_DryRunDoesNotWrite(t *testing.T) {
+	useFakeRunner(t, runnertest.New().Reply("output", connectivityOutputJSON, 0))
+	repo := initRepoForCommandTests(t)
+
+	stdout, _, err := executeCommandWithProcessIO(t, "outputs", "--repo-root", repo, "--layer", "connectivity", "--dry-run", "--json")
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(repo, outputs.DefaultPath))
+
+	var payload struct {
+		Written bool                     `json:"written"`
+		Layers  map[string]outputs.Layer `json:"layers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.False(t, payload.Written)
+	assert.Len(t, payload.Layers["connectivity"].Outputs, 2)
+}
+
+func TestPlanCmd_FlagsRemovedOutputStillReferenced(t *testing.T) {
+	useFakeTerraformWithShow(t, `{"format_version":"1.2"}`)
+	repo := writeContractRepo(t, "output \"hub_vnet_id\" {\n  value = \"x\"\n}\n")
+
+	stdout, stderr, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity", "--json")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "connectivity.firewall_ip (removed, read by lz:app)")
+	assert.Contains(t, stderr, "output connectivity.firewall_ip was removed but is still read by lz:app (main.tf:10)")
+
+	var payload struct {
+		Status   string            `json:"status"`
+		Contract []outputs.Finding `json:"outputContract"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.Equal(t, "blocked", payload.Status)
+	require.Len(t, payload.Contract, 1)
+	assert.Equal(t, outputs.ChangeRemoved, payload.Contract[0].Change)
+}
+
+func TestPlanCmd_FlagsTypeChangedOutput(t *testing.T) {
+	useFakeTerraformWithShow(t, `{"format_version":"1.2","planned_values":{"outputs":{`+
+		`"hub_vnet_id":{"sensitive":false,"value":"/subscriptions/x"},`+
+		`"firewall_ip":{"sensitive":true,"type":["list","string"],"value":["10.0.0.4"]}}}}`)
+	repo := writeContractRepo(t, bothOutputsTF)
+
+	_, stderr, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+	assert.Contains(t, stderr, "output connectivity.firewall_ip changes from string to list but is read by lz:app")
+	assert.NotContains(t, stderr, "hub_vnet_id")
+}
+
+func TestPlanCmd_OutputContractHolds(t *testing.T) {
+	useFakeTerraformWithShow(t, `{"format_version":"1.2"}`)
+	repo := writeContractRepo(t, bothOutputsTF)
+
+	_, stderr, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+	assert.NotContains(t, stderr, "Output contract")
+}
+
+func TestValidateCmd_OutputContract(t *testing.T) {
+	useFakeRunner(t, runnertest.New())
+	repo := writeContractRepo(t, "output \"hub_vnet_id\" {\n  value = \"x\"\n}\n")
+
+	stdout, _, err := executeCommandWithProcessIO(t, "validate", "--repo-root", repo, "--json")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+
+	var payload struct {
+		Data struct {
+			Checks []struct {
+				Name    string `json:"name"`
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			} `json:"checks"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	var contract []string
+	for _, c := range payload.Data.Checks {
+		if c.Name == "output-contract" {
+			contract = append(contract, c.Status+": "+c.Message)
+		}
+	}
+	assert.Equal(t, []string{"error: output connectivity.firewall_ip was removed but is still read by lz:app (main.tf:10)"}, contract)
+}
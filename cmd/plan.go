@@ -1,15 +1,21 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"github.com/kjourdan1/lzctl/internal/cost"
 	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/plancache"
+	"github.com/kjourdan1/lzctl/internal/plansummary"
 	"github.com/kjourdan1/lzctl/internal/planverify"
 )
 
@@ -25,45 +31,199 @@ Runs 'terraform plan' across platform layers in CAF dependency order:
   4. governance          (Azure Policies)
   5. connectivity        (Hub-Spoke or vWAN)
 
-Use --layer to plan a single layer, or omit to plan all activated layers.
+Landing zones (landing-zones/<zone>) and their blueprints
+(landing-zones/<zone>/blueprint) are planned after the platform layers.
 
-The plan summary can be saved to a file with --out for CI/CD PR comments.`,
+Use --layer to plan a single layer, --zone to plan a landing zone and its
+blueprint, or --blueprint to plan only a zone's blueprint. Omit all three
+to plan every platform layer and landing zone.
+
+Roots are scheduled from the dependency graph shown by 'lzctl graph':
+platform layers wait for the layers whose state they read through
+terraform_remote_state (or run one after another in CAF order when no
+layer reads another's state), landing zones wait for the platform, and
+blueprints for their zone. --parallelism N plans up to N independent roots
+at once. The summary is always reported in dependency order.
+
+Use --changed-since <git-ref> to plan only the roots affected by changes
+since that ref: files under a root's directory, platform/shared, policies/
+and the lzctl.yaml sections that feed each root, plus every root that
+depends on them. Each planned root is reported with the reason it was
+selected.
+
+Each successful plan is cached next to its tfplan (tfplan.cache.json),
+keyed on the root's Terraform inputs, the shared platform files it reads,
+its provider lock file, its lzctl.yaml slice and the version of its remote
+state blob. When none of these changed, the root is not re-planned and the
+cached summary is reported. --no-cache always re-plans.
+
+Counts and the per-resource change list (address, type, action and the
+attributes forcing a replacement) come from 'terraform show -json', and are
+included in --json output.
+
+When LZCTL_PLAN_SIGNING_KEY or LZCTL_PLAN_SIGNING_KEY_FILE holds an
+ed25519 private key or an HMAC secret, each saved plan is signed into
+tfplan.sig.json, binding its hash to the layer, git commit, lzctl.yaml slice
+and Terraform inputs. 'lzctl apply --require-signed-plan' (implied in CI
+mode) refuses plans whose signature is missing or does not match.
+
+Every plan is checked against the subscriptions declared in lzctl.yaml
+(state backend, platform and landing zone subscriptions). Roots targeting
+any other subscription are listed with their offending addresses, left
+unsigned, and the command fails with a security error.
+
+After planning, the outputs of the planned roots are checked against the
+roots that read them through terraform_remote_state, using the output
+registry written by 'lzctl outputs'. An output still read downstream that is
+no longer declared, or whose planned type differs from the registered one,
+fails the command with a validation error.
+
+--cost estimates the monthly cost delta of each root from the resource
+changes in its tfplan.json, using the price catalog shipped with lzctl
+merged with .lzctl/price-catalog.yaml (or --price-catalog). Estimates are
+per root and per landing zone, and are added to --json output and to the
+markdown summary.
+
+Before a root is planned, the lease on its state blob is checked. While
+another run holds it, the holder (from Terraform's lock metadata) is
+reported and lzctl waits with backoff for up to --lock-wait. With
+--break-stale-lock <age>, a lease whose lock is older than <age> is broken
+instead; leases without lock metadata are never broken.
+
+Each root runs under its timeout from spec.execution in lzctl.yaml, and
+Terraform runs failing with transient errors (ARM throttling, RetryableError,
+context deadline exceeded, state lease contention) are retried with backoff
+per spec.execution.retry.
+
+With -v, Terraform output is streamed to stderr with a [<root>] prefix.
+Every Terraform invocation is logged to .lzctl/runs/<run-id>/, with an
+index.json listing them, for diagnosing failed CI runs.
+
+The plan summary can be saved to a file with --out for CI/CD PR comments.
+--format markdown renders it as a PR-comment table instead of the raw
+Terraform output (printed to stdout when --out is not set).`,
 	RunE: runPlan,
 }
 
 var (
-	planLayer string
-	planOut   string
+	planLayer        string
+	planZone         string
+	planBlueprint    string
+	planOut          string
+	planParallelism  int
+	planChangedSince string
+	planFormat       string
+	planNoCache      bool
+	planCost         bool
+	planPriceCatalog string
+	planLockWait     time.Duration
+	planBreakStale   time.Duration
+)
+
+const (
+	planFormatText     = "text"
+	planFormatMarkdown = "markdown"
 )
 
 func init() {
 	planCmd.Flags().StringVar(&planLayer, "layer", "", "specific layer to plan (default: all activated)")
 	planCmd.Flags().StringVar(&planLayer, "target", "", "alias for --layer")
+	planCmd.Flags().StringVar(&planZone, "zone", "", "landing zone to plan (includes its blueprint)")
+	planCmd.Flags().StringVar(&planBlueprint, "blueprint", "", "landing zone whose blueprint to plan")
+	planCmd.Flags().IntVar(&planParallelism, "parallelism", 1, "number of independent roots to plan concurrently")
+	planCmd.Flags().StringVar(&planChangedSince, "changed-since", "", "plan only roots affected by changes since this git ref")
+	planCmd.Flags().BoolVar(&planNoCache, "no-cache", false, "always re-plan, ignoring cached plans of unchanged roots")
+	planCmd.Flags().BoolVar(&planCost, "cost", false, "estimate monthly cost deltas from the price catalog")
+	planCmd.Flags().StringVar(&planPriceCatalog, "price-catalog", "", "price catalog override (default: "+cost.DefaultOverridePath+")")
+	planCmd.Flags().DurationVar(&planLockWait, "lock-wait", defaultLockWait, "how long to wait for a state lease held by another run (0: fail at once)")
+	planCmd.Flags().DurationVar(&planBreakStale, "break-stale-lock", 0, "break state leases whose Terraform lock is older than this (0: never)")
 	planCmd.Flags().StringVar(&planOut, "out", "", "write plan output summary to file")
+	planCmd.Flags().StringVar(&planFormat, "format", planFormatText, "summary format for --out: text (raw terraform output) or markdown (PR comment)")
 
 	rootCmd.AddCommand(planCmd)
 }
 
-func runPlan(cmd *cobra.Command, args []string) error {
+func runPlan(cmd *cobra.Command, args []string) (err error) {
 	root, err := absRepoRoot()
 	if err != nil {
 		return err
 	}
 
-	if err := ensureTerraformInstalled(); err != nil {
+	if planFormat != planFormatText && planFormat != planFormatMarkdown {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("unsupported --format %q (expected text or markdown)", planFormat))
+	}
+
+	var catalog *cost.Catalog
+	if planCost {
+		catalogPath := planPriceCatalog
+		if catalogPath == "" {
+			if catalogPath, err = repoFile(cost.DefaultOverridePath); err != nil {
+				return err
+			}
+		}
+		if catalog, err = cost.Load(catalogPath); err != nil {
+			return exitcode.Wrap(exitcode.Validation, err)
+		}
+	}
+
+	signingKey, err := planverify.LoadSigningKey()
+	if err != nil {
 		return exitcode.Wrap(exitcode.Validation, err)
 	}
 
-	layers, err := resolveLocalLayers(root, planLayer)
+	tf, err := resolveRunner(cmd.Context(), root)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	ctx, logs := startRunLog(cmd.Context(), root, "", "plan")
+	defer func() { reportRunLog(root, logs, err) }()
+
+	roots, err := resolveLocalRoots(root, rootSelection{Layer: planLayer, Zone: planZone, Blueprint: planBlueprint})
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	roots, graph, err := orderRoots(root, roots)
 	if err != nil {
 		return exitcode.Wrap(exitcode.Validation, err)
 	}
 
+	var reasons changeReasons
+	if ref := strings.TrimSpace(planChangedSince); ref != "" {
+		roots, reasons, err = selectChangedRoots(cmd.Context(), root, ref, roots, graph)
+		if err != nil {
+			return exitcode.Wrap(exitcode.Validation, err)
+		}
+		if roots, graph, err = orderRoots(root, roots); err != nil {
+			return exitcode.Wrap(exitcode.Validation, err)
+		}
+
+		color.New(color.Bold).Fprintf(os.Stderr, "🔍 Changes since %s\n", ref)
+		if len(roots) == 0 {
+			fmt.Fprintf(os.Stderr, "   No roots affected — nothing to plan.\n")
+			if jsonOutput {
+				data, _ := json.MarshalIndent(map[string]interface{}{
+					"status":       "ok",
+					"changedSince": ref,
+					"totalAdd":     0,
+					"totalChange":  0,
+					"totalDestroy": 0,
+					"layers":       []interface{}{},
+				}, "", "  ")
+				fmt.Fprintln(os.Stdout, string(data))
+			}
+			return nil
+		}
+		for _, r := range roots {
+			fmt.Fprintf(os.Stderr, "   • %s: %s\n", r.Name, strings.Join(reasons[r.Name], "; "))
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+
 	if dryRun {
 		bold := color.New(color.Bold)
 		bold.Fprintf(os.Stderr, "⚡ [DRY-RUN] Layer execution order:\n")
-		for i, l := range layers {
-			fmt.Fprintf(os.Stderr, "   %d. %s\n", i+1, l)
+		for i, r := range roots {
+			fmt.Fprintf(os.Stderr, "   %d. %s\n", i+1, r.Name)
 		}
 		color.New(color.FgYellow, color.Bold).Fprintln(os.Stderr, "\n⚡ [DRY-RUN] No terraform calls made.")
 		return nil
@@ -71,47 +231,142 @@ func runPlan(cmd *cobra.Command, args []string) error {
 
 	bold := color.New(color.Bold)
 	bold.Fprintf(os.Stderr, "📐 Planning platform layers\n")
-	fmt.Fprintf(os.Stderr, "   Layers: %s\n\n", strings.Join(layers, ", "))
+	fmt.Fprintf(os.Stderr, "   Layers: %s\n\n", strings.Join(rootNames(roots), ", "))
 
-	totalAdd, totalChange, totalDestroy := 0, 0, 0
-	combined := strings.Builder{}
+	type layerPlan struct {
+		Layer string `json:"layer"`
+		Kind  string `json:"kind"`
+		Dir   string `json:"dir"`
+		*plansummary.Summary
+		Destroying []string                    `json:"destroying,omitempty"`
+		Reasons    []string                    `json:"reasons,omitempty"`
+		Cached     bool                        `json:"cached,omitempty"`
+		Signed     bool                        `json:"signed,omitempty"`
+		Cost       *cost.Estimate              `json:"cost,omitempty"`
+		OutOfScope []planverify.ScopeViolation `json:"outOfScope,omitempty"`
+		output     string
+	}
+	results := make([]layerPlan, len(roots))
+	allowed, err := allowedSubscriptions()
+	if err != nil {
+		return err
+	}
+	if len(allowed) == 0 && verbosity > 0 {
+		fmt.Fprintf(os.Stderr, "   ℹ️  No subscriptions declared in lzctl.yaml; subscription scope not checked\n\n")
+	}
 
-	for _, layer := range layers {
-		dir := filepath.Join(root, "platform", layer)
-		if initOut, initErr := runTerraformCmd(cmd.Context(), dir, "init", "-input=false", "-no-color"); initErr != nil {
-			return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform init failed (output: %s): %w", layer, initOut, initErr))
+	var cache *planCache
+	if !planNoCache {
+		var cacheErr error
+		if cache, cacheErr = newPlanCache(root, tf); cacheErr != nil && verbosity > 0 {
+			fmt.Fprintf(os.Stderr, "   ℹ️  %v\n\n", cacheErr)
 		}
+	}
 
-		out, planErr := runTerraformCmd(cmd.Context(), dir, "plan", "-input=false", "-detailed-exitcode", "-no-color", "-out=tfplan")
-		add, change, destroy := parsePlanSummary(out)
-		totalAdd += add
-		totalChange += change
-		totalDestroy += destroy
-
-		combined.WriteString("## ")
-		combined.WriteString(layer)
-		combined.WriteString("\n")
-		combined.WriteString(out)
-		combined.WriteString("\n\n")
+	runErr := runRoots(ctx, roots, graph, planParallelism, func(ctx context.Context, i int) error {
+		r := roots[i]
+		layer := r.Name
+		dir := filepath.Join(root, r.Dir)
+		planPath := filepath.Join(dir, "tfplan")
+		jsonPath := filepath.Join(dir, "tfplan.json")
 
-		if planErr != nil {
-			// terraform plan detailed-exitcode returns 2 when changes are present.
-			if strings.Contains(out, "Error:") {
-				return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform plan failed (output: %s): %w", layer, out, planErr))
+		var key plancache.Key
+		var keyErr error
+		var summary *plansummary.Summary
+		var out string
+		cached := false
+		if cache != nil {
+			if key, keyErr = cache.key(r); keyErr == nil {
+				if e, hit := plancache.Lookup(planPath, key); hit && fileExistsLocal(jsonPath) {
+					summary, out, cached = e.Summary, e.Output, true
+				}
+			} else if verbosity > 0 {
+				outputMu.Lock()
+				fmt.Fprintf(os.Stderr, "   ℹ️  %s: not cached: %v\n", layer, keyErr)
+				outputMu.Unlock()
 			}
 		}
 
-		// Generate JSON plan and warn on destructive actions (non-blocking)
-		if jsonOut, showErr := runTerraformCmd(cmd.Context(), dir, "show", "-json", "tfplan"); showErr == nil {
-			jsonPath := filepath.Join(dir, "tfplan.json")
-			_ = os.WriteFile(jsonPath, []byte(jsonOut), 0o644)
-			if violations, _ := planverify.ValidateActions(jsonPath); len(violations) > 0 {
-				color.New(color.FgRed).Fprintf(os.Stderr,
-					"   ⚠️  %d resource(s) will be destroyed in %s:\n", len(violations), layer)
-				for _, v := range violations {
-					fmt.Fprintf(os.Stderr, "      - %s (%s)\n", v.ResourceAddr, v.Action)
+		if !cached {
+			// A failed plan must not leave the previous run's artifacts to
+			// be checked, signed or applied in its place.
+			plancache.Invalidate(planPath)
+			os.Remove(planverify.EnvelopeFile(planPath))
+			os.Remove(jsonPath)
+			if err := waitForRootLease(ctx, root, r, planLockWait, planBreakStale); err != nil {
+				return err
+			}
+			initArgs, err := rootInitArgs(root, r)
+			if err != nil {
+				return err
+			}
+			if initOut, initErr := tf.Run(ctx, dir, initArgs...); initErr != nil {
+				return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform init failed (output: %s): %w", layer, initOut, initErr))
+			}
+
+			var planErr error
+			out, planErr = tf.Run(ctx, dir, "plan", "-input=false", "-detailed-exitcode", "-no-color", "-out=tfplan")
+			if planErr != nil {
+				// terraform plan detailed-exitcode returns 2 when changes are present.
+				if strings.Contains(out, "Error:") {
+					return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform plan failed (output: %s): %w", layer, out, planErr))
 				}
 			}
+
+			// Summarize from the JSON plan and warn on destructive actions (non-blocking)
+			summary = summarizePlan(ctx, tf, dir, "tfplan", jsonPath, out)
+			if cache != nil && keyErr == nil && fileExistsLocal(planPath) {
+				_ = plancache.Store(planPath, key, summary, out)
+			}
+		}
+		add, change, destroy := summary.Add, summary.Change, summary.Destroy
+		lp := layerPlan{Layer: layer, Kind: r.Kind, Dir: filepath.ToSlash(r.Dir), Summary: summary, Reasons: reasons[r.Name], Cached: cached, output: out}
+		if len(allowed) > 0 && !fileExistsLocal(jsonPath) {
+			return exitcode.Wrap(exitcode.SecurityBlock, fmt.Errorf(
+				"layer %s: %s show -json produced no tfplan.json; the subscription scope cannot be checked", layer, tf.Binary()))
+		}
+		if len(allowed) > 0 {
+			var scopeErr error
+			if lp.OutOfScope, scopeErr = planverify.ValidateScopeFile(jsonPath, allowed); scopeErr != nil {
+				return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: %w", layer, scopeErr))
+			}
+		}
+		if signingKey != nil && len(lp.OutOfScope) == 0 && fileExistsLocal(planPath) {
+			if err := signRootPlan(ctx, root, r, signingKey); err != nil {
+				return err
+			}
+			lp.Signed = true
+		}
+		if catalog != nil && fileExistsLocal(jsonPath) {
+			if estimate, costErr := cost.EstimateFile(jsonPath, catalog); costErr == nil {
+				lp.Cost = estimate
+			} else if verbosity > 0 {
+				outputMu.Lock()
+				fmt.Fprintf(os.Stderr, "   ℹ️  %s: no cost estimate: %v\n", layer, costErr)
+				outputMu.Unlock()
+			}
+		}
+
+		var violations []planverify.ActionViolation
+		if fileExistsLocal(jsonPath) {
+			violations, _ = planverify.ValidateActions(jsonPath)
+		}
+		for _, v := range violations {
+			lp.Destroying = append(lp.Destroying, v.ResourceAddr)
+		}
+		results[i] = lp
+
+		outputMu.Lock()
+		defer outputMu.Unlock()
+		if len(lp.OutOfScope) > 0 {
+			printScopeViolations(layer, lp.OutOfScope)
+		}
+		if len(violations) > 0 {
+			color.New(color.FgRed).Fprintf(os.Stderr,
+				"   ⚠️  %d resource(s) will be destroyed in %s:\n", len(violations), layer)
+			for _, v := range violations {
+				fmt.Fprintf(os.Stderr, "      - %s (%s)\n", v.ResourceAddr, v.Action)
+			}
 		}
 
 		// Per-layer summary
@@ -119,10 +374,61 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		if add > 0 || change > 0 || destroy > 0 {
 			icon = "📝"
 		}
-		fmt.Fprintf(os.Stderr, "   %s %-20s +%d ~%d -%d\n", icon, layer, add, change, destroy)
+		suffix := ""
+		if cached {
+			suffix = " (cached)"
+		}
+		if lp.Signed {
+			suffix += " 🔏"
+		}
+		fmt.Fprintf(os.Stderr, "   %s %-20s +%d ~%d -%d%s\n", icon, layer, add, change, destroy, suffix)
+		return nil
+	})
+	if runErr != nil {
+		return runErr
+	}
+
+	outOfScope := map[string][]planverify.ScopeViolation{}
+	for _, lp := range results {
+		if len(lp.OutOfScope) > 0 {
+			outOfScope[lp.Layer] = lp.OutOfScope
+		}
+	}
+	scopeErr := scopeError(outOfScope, rootNames(roots))
+
+	findings, err := checkOutputContract(root, roots)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	contractErr := contractError(findings)
+
+	totalAdd, totalChange, totalDestroy := 0, 0, 0
+	combined := strings.Builder{}
+	mdRoots := make([]plansummary.Root, 0, len(results))
+	costRoots := make([]cost.Root, 0, len(results))
+	for i, lp := range results {
+		costRoots = append(costRoots, cost.Root{Name: lp.Layer, Zone: roots[i].Zone, Estimate: lp.Cost})
+		totalAdd += lp.Add
+		totalChange += lp.Change
+		totalDestroy += lp.Destroy
+		mdRoots = append(mdRoots, plansummary.Root{Name: lp.Layer, Summary: lp.Summary})
+
+		combined.WriteString("## ")
+		combined.WriteString(lp.Layer)
+		combined.WriteString("\n")
+		combined.WriteString(lp.output)
+		combined.WriteString("\n\n")
+	}
+	report := combined.String()
+	if planFormat == planFormatMarkdown {
+		report = plansummary.Markdown("lzctl plan", mdRoots)
+		if catalog != nil {
+			report += "\n" + cost.Markdown(costRoots, catalog)
+		}
 	}
 
 	fmt.Fprintln(os.Stderr)
+	printContractFindings(findings)
 	fmt.Fprintf(os.Stderr, "📊 Plan Summary:\n")
 	fmt.Fprintf(os.Stderr, "   Resources to add    : %d\n", totalAdd)
 	fmt.Fprintf(os.Stderr, "   Resources to change : %d\n", totalChange)
@@ -133,14 +439,88 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		fmt.Fprintln(os.Stderr)
 	}
 
+	if catalog != nil {
+		printCostSummary(costRoots, catalog)
+	}
+
 	if strings.TrimSpace(planOut) != "" {
-		if writeErr := os.WriteFile(planOut, []byte(combined.String()), 0o644); writeErr != nil {
+		if writeErr := os.WriteFile(planOut, []byte(report), 0o644); writeErr != nil {
 			return exitcode.Wrap(exitcode.Generic, fmt.Errorf("writing --out file: %w", writeErr))
 		}
 		fmt.Fprintf(os.Stderr, "📁 Plan output written to: %s\n\n", planOut)
+	} else if planFormat == planFormatMarkdown && !jsonOutput {
+		fmt.Fprint(os.Stdout, report)
+	}
+
+	status := "ok"
+	if scopeErr != nil || contractErr != nil {
+		status = "blocked"
+	}
+	if jsonOutput {
+		payload := map[string]interface{}{
+			"status":       status,
+			"totalAdd":     totalAdd,
+			"totalChange":  totalChange,
+			"totalDestroy": totalDestroy,
+			"layers":       results,
+		}
+		if planChangedSince != "" {
+			payload["changedSince"] = strings.TrimSpace(planChangedSince)
+		}
+		if len(findings) > 0 {
+			payload["outputContract"] = findings
+		}
+		if catalog != nil {
+			payload["cost"] = map[string]interface{}{
+				"currency":       catalog.Currency,
+				"catalogVersion": catalog.Version,
+				"override":       catalog.Override,
+				"totalDelta":     cost.Total(costRoots),
+				"zones":          cost.ByZone(costRoots),
+			}
+		}
+		data, _ := json.MarshalIndent(payload, "", "  ")
+		fmt.Fprintln(os.Stdout, string(data))
+	}
+
+	if scopeErr != nil {
+		color.New(color.FgRed, color.Bold).Fprintln(os.Stderr, "⛔ Plan targets subscriptions not declared in lzctl.yaml; lzctl apply will refuse these plans.")
+		return scopeErr
+	}
+	if contractErr != nil {
+		color.New(color.FgRed, color.Bold).Fprintln(os.Stderr, "⛔ Plan removes or changes layer outputs that downstream roots still read.")
+		return contractErr
 	}
 
 	color.New(color.FgGreen, color.Bold).Fprintln(os.Stderr, "✅ Plan complete. Review changes and run: lzctl apply")
 
 	return nil
 }
+
+// printCostSummary prints the monthly cost delta of each priced root and
+// landing zone.
+func printCostSummary(roots []cost.Root, catalog *cost.Catalog) {
+	fmt.Fprintf(os.Stderr, "💰 Estimated monthly cost (price catalog %s):\n", catalog.Version)
+	unpriced := 0
+	for _, r := range roots {
+		if r.Estimate == nil {
+			fmt.Fprintf(os.Stderr, "   %-20s no plan data\n", r.Name)
+			continue
+		}
+		unpriced += r.Estimate.Unpriced()
+		fmt.Fprintf(os.Stderr, "   %-20s %s\n", r.Name, cost.Format(r.Estimate.Delta, catalog.Currency))
+	}
+	zones := cost.ByZone(roots)
+	seen := map[string]bool{}
+	for _, r := range roots {
+		if _, ok := zones[r.Zone]; ok && !seen[r.Zone] {
+			seen[r.Zone] = true
+			fmt.Fprintf(os.Stderr, "   %-20s %s\n", "zone "+r.Zone, cost.Format(zones[r.Zone], catalog.Currency))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "   %-20s %s\n", "Total", cost.Format(cost.Total(roots), catalog.Currency))
+	if unpriced > 0 {
+		fmt.Fprintf(os.Stderr, "   %d change(s) could not be priced; see --json or the markdown summary.\n", unpriced)
+	}
+	fmt.Fprintln(os.Stderr)
+}
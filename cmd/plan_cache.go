@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+	"github.com/kjourdan1/lzctl/internal/plancache"
+	"github.com/kjourdan1/lzctl/internal/state"
+	lztemplate "github.com/kjourdan1/lzctl/internal/template"
+)
+
+// planCache computes plan cache keys for the roots of one repository.
+type planCache struct {
+	repo string
+	tool string
+	cfg  *config.LZConfig
+	mgr  *state.Manager
+}
+
+// newPlanCache returns the plan cache for repo, or an error explaining why
+// caching is unavailable (no readable lzctl.yaml to locate the state backend).
+func newPlanCache(repo string, tf orchestrator.Runner) (*planCache, error) {
+	cfg, err := configCache()
+	if err != nil {
+		return nil, fmt.Errorf("plan cache disabled: %w", err)
+	}
+	return &planCache{repo: repo, tool: tf.Tool(), cfg: cfg, mgr: newStateManager(cfg)}, nil
+}
+
+// key returns the cache key of r: hashes of the root's inputs, the shared
+// platform files it reads, the policy definitions for governance, its
+// provider lock file and its lzctl.yaml slice, plus the current version of
+// its remote state blob and of every state it reads through
+// terraform_remote_state.
+func (c *planCache) key(r localRoot) (plancache.Key, error) {
+	dir := filepath.Join(c.repo, r.Dir)
+	k := plancache.Key{Tool: c.tool}
+
+	var err error
+	if k.Inputs, err = hashRootInputs(c.repo, r); err != nil {
+		return k, err
+	}
+	if shared := filepath.Join(c.repo, "platform", "shared"); r.Kind == rootKindPlatform && dirExists(shared) {
+		if k.Shared, err = orchestrator.HashInputs(shared); err != nil {
+			return k, err
+		}
+	}
+	if policies := filepath.Join(c.repo, "policies"); r.Kind == rootKindPlatform && r.Name == "governance" && dirExists(policies) {
+		if k.Policies, err = orchestrator.HashDir(policies); err != nil {
+			return k, err
+		}
+	}
+	if lock := filepath.Join(dir, ".terraform.lock.hcl"); fileExistsLocal(lock) {
+		if k.LockFile, err = orchestrator.HashFile(lock); err != nil {
+			return k, err
+		}
+	}
+	if k.Config, err = hashJSON(rootConfigSlice(c.cfg, r)); err != nil {
+		return k, err
+	}
+
+	stateKey, err := stateKeyFor(c.repo, r)
+	if err != nil {
+		return k, err
+	}
+	if k.StateVersion, err = c.mgr.BlobVersion(stateKey); err != nil {
+		return k, err
+	}
+
+	refs, err := orchestrator.ParseRemoteStateRefs(dir)
+	if err != nil {
+		return k, err
+	}
+	for _, ref := range refs {
+		if ref.Key == stateKey {
+			continue
+		}
+		if _, seen := k.Upstream[ref.Key]; seen {
+			continue
+		}
+		version, err := c.mgr.BlobVersion(ref.Key)
+		if err != nil {
+			return k, err
+		}
+		if k.Upstream == nil {
+			k.Upstream = map[string]string{}
+		}
+		k.Upstream[ref.Key] = version
+	}
+	return k, nil
+}
+
+// rootConfigSlice returns the parts of cfg that feed root r: the settings
+// every root reads plus the root's own section, mirroring mapConfigSections.
+func rootConfigSlice(cfg *config.LZConfig, r localRoot) any {
+	slice := struct {
+		Metadata     config.Metadata     `json:"metadata"`
+		Naming       config.Naming       `json:"naming"`
+		StateBackend config.StateBackend `json:"stateBackend"`
+		Terraform    *config.Terraform   `json:"terraform,omitempty"`
+		Section      any                 `json:"section,omitempty"`
+	}{
+		Metadata:     cfg.Metadata,
+		Naming:       cfg.Spec.Naming,
+		StateBackend: cfg.Spec.StateBackend,
+		Terraform:    cfg.Spec.Terraform,
+	}
+
+	switch r.Kind {
+	case rootKindPlatform:
+		switch r.Name {
+		case "management-groups":
+			slice.Section = cfg.Spec.Platform.ManagementGroups
+		case "identity":
+			slice.Section = cfg.Spec.Platform.Identity
+		case "management":
+			slice.Section = cfg.Spec.Platform.Management
+		case "governance":
+			slice.Section = cfg.Spec.Governance
+		case "connectivity":
+			slice.Section = cfg.Spec.Platform.Connectivity
+		}
+	case rootKindLandingZone, rootKindBlueprint:
+		for _, z := range cfg.Spec.LandingZones {
+			if lztemplate.Slugify(z.Name) != r.Zone {
+				continue
+			}
+			if r.Kind == rootKindBlueprint {
+				slice.Section = z.Blueprint
+			} else {
+				z.Blueprint = nil
+				slice.Section = z
+			}
+		}
+	}
+	return slice
+}
+
+func hashJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
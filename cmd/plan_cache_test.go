@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func planCachedFlags(t *testing.T, stdout string) []bool {
+	t.Helper()
+	var payload struct {
+		TotalAdd int `json:"totalAdd"`
+		Layers   []struct {
+			Cached bool `json:"cached"`
+		} `json:"layers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	flags := make([]bool, 0, len(payload.Layers))
+	for _, l := range payload.Layers {
+		flags = append(flags, l.Cached)
+	}
+	return flags
+}
+
+func TestPlanCmd_CacheSkipsUnchangedRoots(t *testing.T) {
+	tf := useFakeTerraform(t, "Plan: 1 to add, 0 to change, 0 to destroy", 2)
+	useFakeAz(t, newFakeAz())
+	repo := initRepoForCommandTests(t)
+	args := []string{"plan", "--repo-root", repo, "--layer", "identity", "--json"}
+
+	stdout, _, err := executeCommandWithProcessIO(t, args...)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, planCachedFlags(t, stdout))
+	assert.FileExists(t, filepath.Join(repo, "platform", "identity", "tfplan.cache.json"))
+
+	stdout, stderr, err := executeCommandWithProcessIO(t, args...)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true}, planCachedFlags(t, stdout))
+	assert.Contains(t, stderr, "(cached)")
+	assert.Len(t, tf.Calls("plan"), 1, "unchanged root must not be re-planned")
+
+	var payload struct {
+		TotalAdd int `json:"totalAdd"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.Equal(t, 1, payload.TotalAdd, "cached summary is reported")
+}
+
+func TestPlanCmd_CacheInvalidation(t *testing.T) {
+	tf := useFakeTerraform(t, "Plan: 1 to add, 0 to change, 0 to destroy", 2)
+	az := useFakeAz(t, newFakeAz())
+	repo := initRepoForCommandTests(t)
+	args := []string{"plan", "--repo-root", repo, "--layer", "identity", "--json"}
+
+	_, _, err := executeCommandWithProcessIO(t, args...)
+	require.NoError(t, err)
+
+	// Remote state moved on.
+	az.SetVersion("platform-identity.tfstate", "v2")
+	stdout, _, err := executeCommandWithProcessIO(t, args...)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, planCachedFlags(t, stdout))
+
+	// Root inputs changed.
+	mainTF := filepath.Join(repo, "platform", "identity", "main.tf")
+	data, err := os.ReadFile(mainTF)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(mainTF, append(data, []byte("\n# edited\n")...), 0o644))
+	stdout, _, err = executeCommandWithProcessIO(t, args...)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, planCachedFlags(t, stdout))
+
+	// Shared provider configuration changed.
+	sharedTF := filepath.Join(repo, "platform", "shared", "providers.tf")
+	data, err = os.ReadFile(sharedTF)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(sharedTF, append(data, []byte("\n# edited\n")...), 0o644))
+	stdout, _, err = executeCommandWithProcessIO(t, args...)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, planCachedFlags(t, stdout))
+
+	// Unchanged, but forced.
+	stdout, _, err = executeCommandWithProcessIO(t, append(args, "--no-cache")...)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, planCachedFlags(t, stdout))
+
+	assert.Len(t, tf.Calls("plan"), 5)
+}
+
+func TestPlanCmd_CacheTracksUpstreamStateAndPolicies(t *testing.T) {
+	tf := useFakeTerraform(t, "Plan: 1 to add, 0 to change, 0 to destroy", 2)
+	az := useFakeAz(t, newFakeAz())
+	repo := initRepoForCommandTests(t)
+	zone := filepath.Join(repo, "landing-zones", "app")
+	require.NoError(t, os.MkdirAll(zone, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(zone, "main.tf"), []byte(`
+data "terraform_remote_state" "connectivity" {
+  config = {
+    key = "platform-connectivity.tfstate"
+  }
+}
+`), 0o644))
+	zoneArgs := []string{"plan", "--repo-root", repo, "--zone", "app", "--json"}
+	governanceArgs := []string{"plan", "--repo-root", repo, "--layer", "governance", "--json"}
+
+	for _, args := range [][]string{zoneArgs, governanceArgs} {
+		_, _, err := executeCommandWithProcessIO(t, args...)
+		require.NoError(t, err)
+		stdout, _, err := executeCommandWithProcessIO(t, args...)
+		require.NoError(t, err)
+		assert.Equal(t, []bool{true}, planCachedFlags(t, stdout))
+	}
+
+	// The hub was applied: its outputs may have changed.
+	az.SetVersion("platform-connectivity.tfstate", "v2")
+	stdout, _, err := executeCommandWithProcessIO(t, zoneArgs...)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, planCachedFlags(t, stdout), "an upstream state change invalidates the plan")
+
+	// A policy definition changed.
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "policies", "definitions"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "policies", "definitions", "deny-public-ip.json"), []byte(`{"name":"deny-public-ip"}`), 0o644))
+	stdout, _, err = executeCommandWithProcessIO(t, governanceArgs...)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, planCachedFlags(t, stdout), "policies/ feeds the governance root")
+
+	assert.Len(t, tf.Calls("plan"), 4)
+}
+
+func TestPlanCmd_CacheDisabledWithoutStateBackend(t *testing.T) {
+	tf := useFakeTerraform(t, "Plan: 0 to add, 0 to change, 0 to destroy", 0)
+	repo := initRepoForCommandTests(t)
+	args := []string{"plan", "--repo-root", repo, "--layer", "identity", "--json"}
+
+	for i := 0; i < 2; i++ {
+		stdout, _, err := executeCommandWithProcessIO(t, args...)
+		require.NoError(t, err)
+		assert.Equal(t, []bool{false}, planCachedFlags(t, stdout))
+	}
+	assert.Len(t, tf.Calls("plan"), 2)
+	assert.NoFileExists(t, filepath.Join(repo, "platform", "identity", "tfplan.cache.json"))
+}
+
+func TestRootConfigSlice_ScopesSections(t *testing.T) {
+	cfg := &config.LZConfig{Spec: config.Spec{
+		LandingZones: []config.LandingZone{{Name: "App One", Archetype: "corp", Blueprint: &config.Blueprint{Type: "paas-secure"}}},
+	}}
+	zone := localRoot{Name: "lz:app-one", Kind: rootKindLandingZone, Zone: "app-one"}
+	blueprint := localRoot{Name: "lz:app-one-blueprint", Kind: rootKindBlueprint, Zone: "app-one"}
+	identity := localRoot{Name: "identity", Kind: rootKindPlatform}
+
+	zoneBefore, _ := hashJSON(rootConfigSlice(cfg, zone))
+	bpBefore, _ := hashJSON(rootConfigSlice(cfg, blueprint))
+	idBefore, _ := hashJSON(rootConfigSlice(cfg, identity))
+
+	cfg.Spec.LandingZones[0].Blueprint.Type = "aks-platform"
+	zoneAfter, _ := hashJSON(rootConfigSlice(cfg, zone))
+	bpAfter, _ := hashJSON(rootConfigSlice(cfg, blueprint))
+	idAfter, _ := hashJSON(rootConfigSlice(cfg, identity))
+
+	assert.Equal(t, zoneBefore, zoneAfter, "blueprint changes do not touch the zone")
+	assert.NotEqual(t, bpBefore, bpAfter)
+	assert.Equal(t, idBefore, idAfter)
+
+	cfg.Spec.Naming.Convention = "custom"
+	idNaming, _ := hashJSON(rootConfigSlice(cfg, identity))
+	assert.NotEqual(t, idAfter, idNaming, "naming feeds every root")
+}
+
+func TestHashRootInputs_ZoneLeavesOutBlueprint(t *testing.T) {
+	repo := t.TempDir()
+	zone := localRoot{Name: "lz:app", Kind: rootKindLandingZone, Zone: "app", Dir: filepath.Join("landing-zones", "app")}
+	blueprint := localRoot{Name: "lz:app-blueprint", Kind: rootKindBlueprint, Zone: "app", Dir: filepath.Join("landing-zones", "app", "blueprint")}
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, blueprint.Dir), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, zone.Dir, "main.tf"), []byte("# zone\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, blueprint.Dir, "main.tf"), []byte("# blueprint\n"), 0o644))
+
+	zoneHash, err := hashRootInputs(repo, zone)
+	require.NoError(t, err)
+	blueprintHash, err := hashRootInputs(repo, blueprint)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, blueprint.Dir, "main.tf"), []byte("# blueprint, edited\n"), 0o644))
+	h, err := hashRootInputs(repo, zone)
+	require.NoError(t, err)
+	assert.Equal(t, zoneHash, h, "a blueprint edit does not invalidate the zone")
+	h, err = hashRootInputs(repo, blueprint)
+	require.NoError(t, err)
+	assert.NotEqual(t, blueprintHash, h)
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/planrules"
+	"github.com/kjourdan1/lzctl/internal/plansummary"
+)
+
+var planCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Evaluate plan guardrail rules against saved plans",
+	Long: `Evaluates the rules in .lzctl/plan-rules.yaml against the tfplan.json
+that 'lzctl plan' saved in each selected root.
+
+Each rule has a 'when' expression over one resource change (address, type,
+name, module, action, actions, layer, kind) and a severity:
+  warn  findings are printed
+  deny  findings fail the check (exit code 6)
+
+A rule with maxPerLayer reports layers with more matching changes than the
+cap. A rule with approvalLabel is silenced when that label is passed with
+--approval-label or listed in LZCTL_APPROVAL_LABELS (comma-separated).
+
+The same rules are evaluated automatically before 'lzctl apply'.`,
+	RunE: runPlanCheck,
+}
+
+var (
+	planCheckLayer     string
+	planCheckZone      string
+	planCheckBlueprint string
+	planCheckRules     string
+	planCheckLabels    []string
+)
+
+func init() {
+	planCheckCmd.Flags().StringVar(&planCheckLayer, "layer", "", "specific layer to check (default: all)")
+	planCheckCmd.Flags().StringVar(&planCheckZone, "zone", "", "landing zone to check (includes its blueprint)")
+	planCheckCmd.Flags().StringVar(&planCheckBlueprint, "blueprint", "", "landing zone whose blueprint to check")
+	planCheckCmd.Flags().StringVar(&planCheckRules, "rules", "", "rules file (default: "+planrules.DefaultPath+")")
+	planCheckCmd.Flags().StringSliceVar(&planCheckLabels, "approval-label", nil, "approval label granted for this run (repeatable)")
+
+	planCmd.AddCommand(planCheckCmd)
+}
+
+func runPlanCheck(cmd *cobra.Command, args []string) error {
+	root, err := absRepoRoot()
+	if err != nil {
+		return err
+	}
+	rulesPath := planCheckRules
+	if rulesPath == "" {
+		if rulesPath, err = repoFile(planrules.DefaultPath); err != nil {
+			return err
+		}
+	}
+	rs, err := planrules.Load(rulesPath)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	if rs == nil {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("no plan rules found at %s", rulesPath))
+	}
+
+	roots, err := resolveLocalRoots(root, rootSelection{Layer: planCheckLayer, Zone: planCheckZone, Blueprint: planCheckBlueprint})
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	roots, _, err = orderRoots(root, roots)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+
+	layers, unplanned, err := planRuleLayers(root, roots)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	labels := approvalLabels(planCheckLabels)
+	findings, err := rs.Evaluate(layers, labels)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	denied := planrules.Denied(findings)
+
+	if jsonOutput {
+		status := "ok"
+		if denied {
+			status = "denied"
+		} else if len(findings) > 0 {
+			status = "warnings"
+		}
+		checked := make([]string, len(layers))
+		for i, l := range layers {
+			checked[i] = l.Name
+		}
+		if findings == nil {
+			findings = []planrules.Finding{}
+		}
+		data, _ := json.MarshalIndent(map[string]interface{}{
+			"status":    status,
+			"rules":     len(rs.Rules),
+			"layers":    checked,
+			"unplanned": unplanned,
+			"labels":    labels,
+			"findings":  findings,
+		}, "", "  ")
+		fmt.Fprintln(os.Stdout, string(data))
+	} else {
+		color.New(color.Bold).Fprintf(os.Stderr, "🛡️  Checking %d plan rule(s) from %s\n\n", len(rs.Rules), rulesPath)
+		for _, name := range unplanned {
+			fmt.Fprintf(os.Stderr, "   ⏭️  %-20s no tfplan.json (run lzctl plan first)\n", name)
+		}
+		printPlanRuleFindings(findings)
+		fmt.Fprintln(os.Stderr)
+		switch {
+		case denied:
+			color.New(color.FgRed, color.Bold).Fprintln(os.Stderr, "❌ Plan rules denied the changes.")
+		case len(findings) > 0:
+			color.New(color.FgYellow, color.Bold).Fprintln(os.Stderr, "⚠️  Plan rules passed with warnings.")
+		default:
+			color.New(color.FgGreen, color.Bold).Fprintf(os.Stderr, "✅ Plan rules passed for %d layer(s).\n", len(layers))
+		}
+	}
+
+	if denied {
+		return exitcode.Wrap(exitcode.Policy, fmt.Errorf("%d plan rule violation(s) with deny severity", countDenied(findings)))
+	}
+	return nil
+}
+
+// planRuleLayers loads the saved tfplan.json of each root. Roots without one
+// are returned by name in unplanned.
+func planRuleLayers(repo string, roots []localRoot) (layers []planrules.Layer, unplanned []string, err error) {
+	unplanned = []string{}
+	for _, r := range roots {
+		jsonPath := filepath.Join(repo, r.Dir, "tfplan.json")
+		if !fileExistsLocal(jsonPath) {
+			unplanned = append(unplanned, r.Name)
+			continue
+		}
+		summary, err := plansummary.Load(jsonPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("layer %s: %w", r.Name, err)
+		}
+		layers = append(layers, planrules.Layer{Name: r.Name, Kind: r.Kind, Changes: summary.Resources})
+	}
+	return layers, unplanned, nil
+}
+
+// approvalLabels merges labels passed on the command line with those listed
+// in LZCTL_APPROVAL_LABELS.
+func approvalLabels(flagLabels []string) []string {
+	labels := []string{}
+	for _, l := range append(strings.Split(os.Getenv("LZCTL_APPROVAL_LABELS"), ","), flagLabels...) {
+		if l = strings.TrimSpace(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+func printPlanRuleFindings(findings []planrules.Finding) {
+	for _, f := range findings {
+		icon, c := "⚠️ ", color.New(color.FgYellow)
+		if f.Severity == planrules.SeverityDeny {
+			icon, c = "❌", color.New(color.FgRed)
+		}
+		target := f.Layer
+		if f.Address != "" {
+			target += ": " + f.Address + " (" + f.Action + ")"
+		}
+		c.Fprintf(os.Stderr, "   %s [%s] %s %s\n", icon, f.Severity, f.Rule, target)
+		fmt.Fprintf(os.Stderr, "      %s\n", f.Message)
+	}
+}
+
+func countDenied(findings []planrules.Finding) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity == planrules.SeverityDeny {
+			n++
+		}
+	}
+	return n
+}
+
+// enforcePlanRules evaluates the repository's plan rules against the saved
+// plans of roots before an apply. It returns nil when no rules file exists,
+// prints warn findings and fails with exitcode.Policy on deny findings.
+func enforcePlanRules(repo string, roots []localRoot, labels []string) error {
+	rs, err := loadPlanRules()
+	if err != nil || rs == nil {
+		return err
+	}
+	layers, unplanned, err := planRuleLayers(repo, roots)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	return reportPlanRules(rs, layers, unplanned, labels)
+}
+
+// loadPlanRules loads the repository's plan rules. It returns nil when no
+// rules file exists.
+func loadPlanRules() (*planrules.RuleSet, error) {
+	path, err := repoFile(planrules.DefaultPath)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := planrules.Load(path)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.Validation, err)
+	}
+	return rs, nil
+}
+
+func reportPlanRules(rs *planrules.RuleSet, layers []planrules.Layer, unplanned, labels []string) error {
+	findings, err := rs.Evaluate(layers, labels)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	if len(findings) == 0 && len(unplanned) == 0 {
+		return nil
+	}
+	color.New(color.Bold).Fprintf(os.Stderr, "🛡️  Plan rules (%s)\n", planrules.DefaultPath)
+	for _, name := range unplanned {
+		fmt.Fprintf(os.Stderr, "   ⏭️  %-20s not checked: no tfplan.json\n", name)
+	}
+	printPlanRuleFindings(findings)
+	fmt.Fprintln(os.Stderr)
+	if planrules.Denied(findings) {
+		return exitcode.Wrap(exitcode.Policy, fmt.Errorf("%d plan rule violation(s) with deny severity; fix the plan or pass the required --approval-label", countDenied(findings)))
+	}
+	return nil
+}
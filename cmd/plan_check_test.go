@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+)
+
+const testPlanRules = `apiVersion: lzctl/v1
+kind: PlanRules
+spec:
+  rules:
+    - name: protect-management-groups
+      severity: deny
+      when: type == "azurerm_management_group" && action in ["delete", "replace"]
+      approvalLabel: mg-change-approved
+    - name: replacement-budget
+      severity: warn
+      when: action == "replace"
+      maxPerLayer: 0
+`
+
+const mgDeletePlanJSON = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {"address": "azurerm_management_group.corp", "type": "azurerm_management_group", "name": "corp",
+     "change": {"actions": ["delete"]}},
+    {"address": "azurerm_role_assignment.owner", "type": "azurerm_role_assignment", "name": "owner",
+     "change": {"actions": ["delete", "create"]}}
+  ]
+}`
+
+// writePlanRulesFixture writes the test rules and a saved plan for the
+// management-groups layer.
+func writePlanRulesFixture(t *testing.T, repo string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".lzctl"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".lzctl", "plan-rules.yaml"), []byte(testPlanRules), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "platform", "management-groups", "tfplan.json"), []byte(mgDeletePlanJSON), 0o644))
+}
+
+type planCheckPayload struct {
+	Status    string   `json:"status"`
+	Layers    []string `json:"layers"`
+	Unplanned []string `json:"unplanned"`
+	Findings  []struct {
+		Rule     string `json:"rule"`
+		Severity string `json:"severity"`
+		Layer    string `json:"layer"`
+		Address  string `json:"address"`
+	} `json:"findings"`
+}
+
+func TestPlanCheckCmd_DenyFindingFails(t *testing.T) {
+	repo := initRepoForCommandTests(t)
+	writePlanRulesFixture(t, repo)
+
+	stdout, _, err := executeCommandWithProcessIO(t, "plan", "check", "--repo-root", repo, "--json")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Policy, exitcode.Of(err))
+
+	var payload planCheckPayload
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.Equal(t, "denied", payload.Status)
+	assert.Equal(t, []string{"management-groups"}, payload.Layers)
+	assert.Contains(t, payload.Unplanned, "connectivity")
+	require.Len(t, payload.Findings, 2)
+	assert.Equal(t, "protect-management-groups", payload.Findings[0].Rule)
+	assert.Equal(t, "azurerm_management_group.corp", payload.Findings[0].Address)
+	assert.Equal(t, "replacement-budget", payload.Findings[1].Rule)
+	assert.Equal(t, "warn", payload.Findings[1].Severity)
+}
+
+func TestPlanCheckCmd_ApprovalLabel(t *testing.T) {
+	repo := initRepoForCommandTests(t)
+	writePlanRulesFixture(t, repo)
+	t.Cleanup(func() { planCheckLabels = nil })
+
+	stdout, _, err := executeCommandWithProcessIO(t, "plan", "check", "--repo-root", repo, "--json", "--approval-label", "mg-change-approved")
+	require.NoError(t, err)
+
+	var payload planCheckPayload
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.Equal(t, "warnings", payload.Status)
+	require.Len(t, payload.Findings, 1)
+	assert.Equal(t, "replacement-budget", payload.Findings[0].Rule)
+}
+
+func TestPlanCheckCmd_ApprovalLabelFromEnv(t *testing.T) {
+	repo := initRepoForCommandTests(t)
+	writePlanRulesFixture(t, repo)
+	t.Setenv("LZCTL_APPROVAL_LABELS", "other, mg-change-approved")
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "check", "--repo-root", repo, "--layer", "management-groups")
+	require.NoError(t, err)
+}
+
+func TestPlanCheckCmd_MissingRules(t *testing.T) {
+	repo := initRepoForCommandTests(t)
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "check", "--repo-root", repo)
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "no plan rules found")
+}
+
+func TestApplyCmd_PlanRulesDenyBeforeApplying(t *testing.T) {
+	fail := false
+	tf := useFailingApplyTerraform(t, &fail)
+	repo := initRepoForCommandTests(t)
+	writePlanRulesFixture(t, repo)
+
+	_, stderr, err := executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--auto-approve")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Policy, exitcode.Of(err))
+	assert.Contains(t, stderr, "protect-management-groups")
+	assert.Empty(t, tf.Calls("apply"), "no root is applied")
+	assert.NoDirExists(t, filepath.Join(repo, ".lzctl", "runs"), "no run journal is started")
+}
+
+func TestApplyCmd_PlanRulesPassProceeds(t *testing.T) {
+	fail := false
+	tf := useFailingApplyTerraform(t, &fail)
+	repo := initRepoForCommandTests(t)
+	writePlanRulesFixture(t, repo)
+	// Only the rules are under test here; drop the destructive plan so the
+	// destruction gate does not trigger.
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "platform", "management-groups", "tfplan.json"),
+		[]byte(`{"format_version": "1.2", "resource_changes": [{"address": "azurerm_management_group.corp", "type": "azurerm_management_group", "change": {"actions": ["update"]}}]}`), 0o644))
+
+	_, _, err := executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--auto-approve")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tf.Calls("apply"))
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+)
+
+const fakeCostShowJSON = `{"format_version":"1.2","resource_changes":[` +
+	`{"address":"azurerm_firewall.hub","type":"azurerm_firewall","change":{"actions":["create"],"before":null,"after":{"sku_tier":"Standard","location":"westeurope"}}},` +
+	`{"address":"azurerm_public_ip.old","type":"azurerm_public_ip","change":{"actions":["delete"],"before":{"sku":"Standard","location":"westeurope"},"after":null}}]}`
+
+func TestPlanCmd_CostJSON(t *testing.T) {
+	useFakeTerraformWithShow(t, fakeCostShowJSON)
+	repo := initRepoForCommandTests(t)
+
+	stdout, stderr, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity", "--cost", "--json")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "Estimated monthly cost")
+
+	var payload struct {
+		Cost struct {
+			Currency       string  `json:"currency"`
+			CatalogVersion string  `json:"catalogVersion"`
+			TotalDelta     float64 `json:"totalDelta"`
+		} `json:"cost"`
+		Layers []struct {
+			Cost struct {
+				Delta     float64 `json:"delta"`
+				Resources []struct {
+					Address string  `json:"address"`
+					Delta   float64 `json:"delta"`
+				} `json:"resources"`
+			} `json:"cost"`
+		} `json:"layers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.Equal(t, "USD", payload.Cost.Currency)
+	assert.NotEmpty(t, payload.Cost.CatalogVersion)
+	assert.Equal(t, 908.85, payload.Cost.TotalDelta)
+	require.Len(t, payload.Layers, 1)
+	assert.Equal(t, 908.85, payload.Layers[0].Cost.Delta)
+	assert.Len(t, payload.Layers[0].Cost.Resources, 2)
+}
+
+func TestPlanCmd_CostMarkdownUsesOverride(t *testing.T) {
+	useFakeTerraformWithShow(t, fakeCostShowJSON)
+	repo := initRepoForCommandTests(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".lzctl"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".lzctl", "price-catalog.yaml"), []byte(`version: finops-q3
+resources:
+  azurerm_firewall:
+    prices:
+      Standard: { westeurope: 1000 }
+`), 0o644))
+	out := filepath.Join(t.TempDir(), "plan.md")
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity", "--cost", "--out", out, "--format", "markdown")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	md := string(data)
+	assert.Contains(t, md, "## lzctl plan")
+	assert.Contains(t, md, "### Estimated monthly cost")
+	assert.Contains(t, md, "| **Total** | | | **+996.35 USD** |")
+	assert.Contains(t, md, "+finops-q3")
+}
+
+func TestPlanCmd_CostRejectsInvalidCatalog(t *testing.T) {
+	repo := initRepoForCommandTests(t)
+	catalog := filepath.Join(t.TempDir(), "prices.yaml")
+	require.NoError(t, os.WriteFile(catalog, []byte("currency: EUR\n"), 0o644))
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--cost", "--price-catalog", catalog)
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+	"github.com/kjourdan1/lzctl/internal/planverify"
+)
+
+// allowedSubscriptions returns the subscriptions declared in lzctl.yaml. It
+// returns nil, and scope checks are skipped, when the config declares no
+// subscription yet (placeholders only). A config that cannot be loaded is
+// an error: the scope it would declare is unknown.
+func allowedSubscriptions() ([]string, error) {
+	cfg, err := configCache()
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.Validation, fmt.Errorf("loading config for the subscription scope check: %w", err))
+	}
+	return config.AllowedSubscriptions(cfg), nil
+}
+
+// printScopeViolations lists the out-of-scope resources of one root.
+// Callers hold outputMu when roots run concurrently.
+func printScopeViolations(layer string, violations []planverify.ScopeViolation) {
+	color.New(color.FgRed).Fprintf(os.Stderr,
+		"   ⛔ %d resource(s) in %s target subscriptions not declared in lzctl.yaml:\n", len(violations), layer)
+	for _, v := range violations {
+		fmt.Fprintf(os.Stderr, "      - %s (subscription %s)\n", v.ResourceAddr, v.SubscriptionID)
+	}
+}
+
+// scopeError is the SecurityBlock error for out-of-scope plans, naming the
+// offending addresses.
+func scopeError(violations map[string][]planverify.ScopeViolation, order []string) error {
+	var parts []string
+	for _, layer := range order {
+		for _, v := range violations[layer] {
+			parts = append(parts, fmt.Sprintf("%s: %s (%s)", layer, v.ResourceAddr, v.SubscriptionID))
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return exitcode.Wrap(exitcode.SecurityBlock, fmt.Errorf(
+		"plan targets subscriptions outside lzctl.yaml (stateBackend, platform and landing zone subscriptions): %s",
+		strings.Join(parts, ", ")))
+}
+
+// enforcePlanScope checks the saved tfplan.json of each root against the
+// subscriptions declared in lzctl.yaml before anything is applied. Roots
+// without a tfplan.json are checked from the binary plan in applyRoot.
+func enforcePlanScope(repo string, roots []localRoot) error {
+	allowed, err := allowedSubscriptions()
+	if err != nil || len(allowed) == 0 {
+		return err
+	}
+	violations := map[string][]planverify.ScopeViolation{}
+	var order []string
+	for _, r := range roots {
+		jsonPath := filepath.Join(repo, r.Dir, "tfplan.json")
+		if !fileExistsLocal(jsonPath) {
+			continue
+		}
+		vs, err := planverify.ValidateScopeFile(jsonPath, allowed)
+		if err != nil {
+			return exitcode.Wrap(exitcode.Validation, fmt.Errorf("layer %s: %w", r.Name, err))
+		}
+		if len(vs) > 0 {
+			printScopeViolations(r.Name, vs)
+			violations[r.Name] = vs
+			order = append(order, r.Name)
+		}
+	}
+	return scopeError(violations, order)
+}
+
+// verifyRootScope checks the binary plan of r with `show -json` right before
+// it is applied, so a tfplan.json edited after planning cannot hide an
+// out-of-scope change. A plan that cannot be rendered is not applied.
+func verifyRootScope(ctx context.Context, tf orchestrator.Runner, repo string, r localRoot, planFile string) error {
+	allowed, err := allowedSubscriptions()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(repo, r.Dir)
+	if len(allowed) == 0 || !fileExistsLocal(filepath.Join(dir, planFile)) {
+		return nil
+	}
+	vs, err := planverify.ValidateScope(ctx, tf, planFile, allowed, dir)
+	if err != nil {
+		return exitcode.Wrap(exitcode.SecurityBlock, fmt.Errorf("layer %s: subscription scope not verified: %w", r.Name, err))
+	}
+	if len(vs) == 0 {
+		return nil
+	}
+	outputMu.Lock()
+	printScopeViolations(r.Name, vs)
+	outputMu.Unlock()
+	return scopeError(map[string][]planverify.ScopeViolation{r.Name: vs}, []string{r.Name})
+}
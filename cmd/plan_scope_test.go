@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+	"github.com/kjourdan1/lzctl/internal/planverify"
+)
+
+const (
+	scopeTestSubscription = "11111111-1111-4111-8111-111111111111"
+	rogueSubscription     = "99999999-9999-4999-8999-999999999999"
+)
+
+// scopeShowJSON renders a plan with one role assignment scoped to sub.
+func scopeShowJSON(sub string) string {
+	return `{"format_version":"1.2",` +
+		`"planned_values":{"root_module":{"resources":[{"address":"azurerm_role_assignment.ops","values":{"scope":"/subscriptions/` + sub + `/resourceGroups/rg-ops"}}]}},` +
+		`"resource_changes":[{"address":"azurerm_role_assignment.ops","type":"azurerm_role_assignment","change":{"actions":["create"]}}]}`
+}
+
+// initScopedRepo initializes a repo whose lzctl.yaml declares
+// scopeTestSubscription as the state backend subscription.
+func initScopedRepo(t *testing.T) string {
+	t.Helper()
+	repo := initRepoForCommandTests(t)
+	cfgPath := filepath.Join(repo, "lzctl.yaml")
+	data, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(cfgPath, []byte(strings.Replace(string(data), "<subscription-id>", scopeTestSubscription, 1)), 0o644))
+	return repo
+}
+
+func TestPlanCmd_BlocksOutOfScopeSubscription(t *testing.T) {
+	useFakeTerraformWithShow(t, scopeShowJSON(rogueSubscription))
+	repo := initScopedRepo(t)
+	t.Setenv(planverify.EnvSigningKey, testPlanSigningKey)
+
+	stdout, stderr, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity", "--json")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.SecurityBlock, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "connectivity: azurerm_role_assignment.ops ("+rogueSubscription+")")
+	assert.Contains(t, stderr, "not declared in lzctl.yaml")
+
+	var payload struct {
+		Status string `json:"status"`
+		Layers []struct {
+			Signed     bool `json:"signed"`
+			OutOfScope []struct {
+				Address        string `json:"address"`
+				SubscriptionID string `json:"subscriptionId"`
+			} `json:"outOfScope"`
+		} `json:"layers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.Equal(t, "blocked", payload.Status)
+	require.Len(t, payload.Layers, 1)
+	assert.False(t, payload.Layers[0].Signed, "out-of-scope plans are not signed")
+	require.Len(t, payload.Layers[0].OutOfScope, 1)
+	assert.Equal(t, "azurerm_role_assignment.ops", payload.Layers[0].OutOfScope[0].Address)
+	assert.NoFileExists(t, filepath.Join(repo, "platform", "connectivity", "tfplan.sig.json"))
+}
+
+func TestPlanCmd_AllowsDeclaredSubscription(t *testing.T) {
+	useFakeTerraformWithShow(t, scopeShowJSON(scopeTestSubscription))
+	repo := initScopedRepo(t)
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+}
+
+func TestApplyCmd_BlocksOutOfScopePlanJSON(t *testing.T) {
+	tf := useFakeTerraformWithShow(t, scopeShowJSON(scopeTestSubscription))
+	repo := initScopedRepo(t)
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "platform", "connectivity", "tfplan.json"), []byte(scopeShowJSON(rogueSubscription)), 0o644))
+
+	_, _, err = executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.SecurityBlock, exitcode.Of(err))
+	assert.Empty(t, tf.Calls("apply"))
+	journals, _ := filepath.Glob(filepath.Join(repo, ".lzctl", "runs", "*", "journal.json"))
+	assert.Empty(t, journals, "blocked before a run journal is created")
+}
+
+func TestApplyCmd_ChecksScopeOfBinaryPlan(t *testing.T) {
+	useFakeTerraformWithShow(t, scopeShowJSON(scopeTestSubscription))
+	repo := initScopedRepo(t)
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+
+	// tfplan.json still looks in scope, but the saved plan does not.
+	tf := useFakeTerraformWithShow(t, scopeShowJSON(rogueSubscription))
+	_, _, err = executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.SecurityBlock, exitcode.Of(err))
+	assert.Contains(t, err.Error(), rogueSubscription)
+	assert.Empty(t, tf.Calls("apply"))
+}
+
+func TestApplyCmd_ChecksScopeOfFreshPlanWithoutSavedPlan(t *testing.T) {
+	tf := useFakeTerraformWithShow(t, scopeShowJSON(rogueSubscription))
+	repo := initScopedRepo(t)
+
+	_, _, err := executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.SecurityBlock, exitcode.Of(err))
+	assert.Contains(t, err.Error(), rogueSubscription)
+	assert.Empty(t, tf.Calls("apply"))
+
+	tf = useFakeTerraformWithShow(t, scopeShowJSON(scopeTestSubscription))
+	_, _, err = executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve")
+	require.NoError(t, err)
+	applies := tf.Calls("apply")
+	require.Len(t, applies, 1)
+	assert.Equal(t, applyScopePlanFile, applies[0].Args[len(applies[0].Args)-1], "the checked plan is the one applied")
+	assert.NoFileExists(t, filepath.Join(repo, "platform", "connectivity", applyScopePlanFile))
+	assert.NoFileExists(t, filepath.Join(repo, "platform", "connectivity", "tfplan"), "no saved plan is left behind")
+}
+
+func TestApplyCmd_BlocksWhenScopeCannotBeChecked(t *testing.T) {
+	useFakeTerraformWithShow(t, scopeShowJSON(scopeTestSubscription))
+	repo := initScopedRepo(t)
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+
+	tf := useFakeRunner(t, runnertest.New().Reply("show", "Error: unsupported plan format", 1))
+	_, _, err = executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.SecurityBlock, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "subscription scope not verified")
+	assert.Empty(t, tf.Calls("apply"))
+}
+
+func TestPlanCmd_ScopeNeedsFreshPlanJSON(t *testing.T) {
+	useFakeTerraformWithShow(t, scopeShowJSON(scopeTestSubscription))
+	repo := initScopedRepo(t)
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+	jsonPath := filepath.Join(repo, "platform", "connectivity", "tfplan.json")
+	require.FileExists(t, jsonPath)
+
+	useFakeRunner(t, runnertest.New().
+		Reply("plan", "Plan: 1 to add, 0 to change, 0 to destroy.", 2).
+		Reply("show", "Error: unsupported plan format", 1))
+	_, _, err = executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity", "--no-cache")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.SecurityBlock, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "produced no tfplan.json")
+	assert.NoFileExists(t, jsonPath, "the previous run's tfplan.json is not kept")
+}
+
+func TestAllowedSubscriptions_FailsOnUnreadableConfig(t *testing.T) {
+	invalidateConfigCache()
+	t.Cleanup(invalidateConfigCache)
+	cfgCacheSet, cfgCacheErr = true, errors.New("yaml: line 1: did not find expected node content")
+
+	_, err := allowedSubscriptions()
+	require.Error(t, err, "an unknown scope is not an empty one")
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+}
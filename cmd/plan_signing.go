@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/planverify"
+)
+
+// planBinding returns the context a root's plan is signed for: its name,
+// the current git commit, the hash of its lzctl.yaml slice and the hash of
+// its Terraform inputs. Fields that cannot be determined are left empty
+// and are then not checked.
+func planBinding(ctx context.Context, repo string, r localRoot) planverify.Binding {
+	b := planverify.Binding{Layer: r.Name}
+	if out, err := runGit(ctx, repo, "rev-parse", "HEAD"); err == nil {
+		b.GitCommit = strings.TrimSpace(out)
+	}
+	if cfg, err := configCache(); err == nil {
+		b.ConfigHash, _ = hashJSON(rootConfigSlice(cfg, r))
+	}
+	b.InputHash, _ = hashRootInputs(repo, r)
+	return b
+}
+
+// signRootPlan signs the saved tfplan of r with key.
+func signRootPlan(ctx context.Context, repo string, r localRoot, key *planverify.Key) error {
+	planFile := filepath.Join(repo, r.Dir, "tfplan")
+	if _, err := planverify.SignPlan(planFile, key, planBinding(ctx, repo, r)); err != nil {
+		return exitcode.Wrap(exitcode.Generic, fmt.Errorf("layer %s: signing plan: %w", r.Name, err))
+	}
+	return nil
+}
+
+// verifyRootPlan checks the signature envelope of r's saved tfplan before
+// it is applied. A present envelope that does not verify always blocks.
+// When required, a missing plan, envelope or verification key blocks too;
+// otherwise those cases are let through with a warning where relevant.
+func verifyRootPlan(ctx context.Context, repo string, r localRoot, key *planverify.Key, required bool) error {
+	planFile := filepath.Join(repo, r.Dir, "tfplan")
+	envFile := planverify.EnvelopeFile(planFile)
+
+	if !fileExistsLocal(planFile) {
+		if required {
+			return exitcode.Wrap(exitcode.SecurityBlock, fmt.Errorf(
+				"layer %s: no saved plan to verify; run lzctl plan with a signing key first", r.Name))
+		}
+		return nil
+	}
+	if !fileExistsLocal(envFile) && !required {
+		return nil
+	}
+	if key == nil {
+		if required {
+			return exitcode.Wrap(exitcode.SecurityBlock, fmt.Errorf(
+				"layer %s: signed plan required but no verification key is configured (%s or %s)",
+				r.Name, planverify.EnvVerifyKey, planverify.EnvVerifyKeyFile))
+		}
+		outputMu.Lock()
+		color.New(color.FgYellow).Fprintf(os.Stderr, "   ⚠️  %-20s plan signature not verified: no verification key\n", r.Name)
+		outputMu.Unlock()
+		return nil
+	}
+
+	env, err := planverify.VerifyPlan(planFile, key, planBinding(ctx, repo, r))
+	if errors.Is(err, planverify.ErrUnsigned) {
+		return exitcode.Wrap(exitcode.SecurityBlock, fmt.Errorf(
+			"layer %s: plan is not signed; signed plans are required (--require-signed-plan or CI mode)", r.Name))
+	}
+	if err != nil {
+		return exitcode.Wrap(exitcode.SecurityBlock, fmt.Errorf("layer %s: %w", r.Name, err))
+	}
+	if verbosity > 0 {
+		outputMu.Lock()
+		fmt.Fprintf(os.Stderr, "   🔏 %-20s plan signed by %s key %s at commit %s\n", r.Name, env.Algorithm, env.KeyID, commitOrUnknown(env.GitCommit))
+		outputMu.Unlock()
+	}
+	return nil
+}
+
+func commitOrUnknown(commit string) string {
+	if commit == "" {
+		return "(unknown)"
+	}
+	return shortCommit(commit)
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+	"github.com/kjourdan1/lzctl/internal/planverify"
+)
+
+// testPlanSigningKey is an HMAC secret long enough for planverify.ParseKey.
+var testPlanSigningKey = strings.Repeat("s", 32)
+
+const fakeCreateOnlyShowJSON = `{"format_version":"1.2","resource_changes":[` +
+	`{"address":"azurerm_resource_group.hub","type":"azurerm_resource_group","change":{"actions":["create"]}}]}`
+
+// planSignedLayer plans the connectivity layer with the test signing key
+// configured and returns the repo and the fake used for later commands.
+func planSignedLayer(t *testing.T) (string, *runnertest.Fake) {
+	t.Helper()
+	tf := useFakeTerraformWithShow(t, fakeCreateOnlyShowJSON)
+	repo := initRepoForCommandTests(t)
+	t.Setenv(planverify.EnvSigningKey, testPlanSigningKey)
+
+	stdout, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity", "--json")
+	require.NoError(t, err)
+
+	var payload struct {
+		Layers []struct {
+			Signed bool `json:"signed"`
+		} `json:"layers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	require.Len(t, payload.Layers, 1)
+	assert.True(t, payload.Layers[0].Signed)
+	return repo, tf
+}
+
+func TestPlanCmd_SignsPlanWithEnvelope(t *testing.T) {
+	repo, _ := planSignedLayer(t)
+
+	data, err := os.ReadFile(filepath.Join(repo, "platform", "connectivity", "tfplan.sig.json"))
+	require.NoError(t, err)
+	var env planverify.Envelope
+	require.NoError(t, json.Unmarshal(data, &env))
+	assert.Equal(t, planverify.AlgHMACSHA256, env.Algorithm)
+	assert.Equal(t, "connectivity", env.Layer)
+	assert.NotEmpty(t, env.ConfigHash)
+	assert.NotEmpty(t, env.InputHash)
+	assert.NotEmpty(t, env.Signature)
+}
+
+func TestApplyCmd_RequireSignedPlan_AcceptsValidSignature(t *testing.T) {
+	repo, tf := planSignedLayer(t)
+
+	_, _, err := executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve", "--require-signed-plan")
+	require.NoError(t, err)
+	assert.Len(t, tf.Calls("apply"), 1)
+}
+
+func TestApplyCmd_RequireSignedPlan_BlocksTamperedPlan(t *testing.T) {
+	repo, tf := planSignedLayer(t)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "platform", "connectivity", "tfplan"), []byte("forged"), 0o600))
+
+	_, _, err := executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve")
+	require.Error(t, err, "a signature that does not verify blocks even without --require-signed-plan")
+	assert.Equal(t, exitcode.SecurityBlock, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "SHA256 mismatch")
+	assert.Empty(t, tf.Calls("apply"))
+}
+
+func TestApplyCmd_RequireSignedPlan_BlocksEditedPlanJSON(t *testing.T) {
+	repo, tf := planSignedLayer(t)
+	// Hiding a destroy from the destructive-action gate and the plan rules.
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "platform", "connectivity", "tfplan.json"), []byte(`{"resource_changes":[]}`), 0o600))
+
+	_, _, err := executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.SecurityBlock, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "tfplan.json SHA256 mismatch")
+	assert.Empty(t, tf.Calls("apply"))
+}
+
+func TestApplyCmd_RequireSignedPlan_BlocksUnsignedPlan(t *testing.T) {
+	tf := useFakeTerraformWithShow(t, fakeCreateOnlyShowJSON)
+	repo := initRepoForCommandTests(t)
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+	t.Setenv(planverify.EnvVerifyKey, testPlanSigningKey)
+
+	_, _, err = executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve", "--require-signed-plan")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.SecurityBlock, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "not signed")
+	assert.Empty(t, tf.Calls("apply"))
+}
+
+func TestApplyCmd_CIModeRequiresVerificationKey(t *testing.T) {
+	repo, tf := planSignedLayer(t)
+	t.Setenv(planverify.EnvSigningKey, "")
+
+	_, _, err := executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve", "--ci")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.SecurityBlock, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "no verification key")
+	assert.Empty(t, tf.Calls("apply"))
+}
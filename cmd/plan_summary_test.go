@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeShowJSON = `{"format_version":"1.2","resource_changes":[` +
+	`{"address":"azurerm_resource_group.hub","type":"azurerm_resource_group","change":{"actions":["create"]}},` +
+	`{"address":"azurerm_firewall.hub","type":"azurerm_firewall","action_reason":"replace_because_cannot_update",` +
+	`"change":{"actions":["delete","create"],"replace_paths":[["location"]]}}]}`
+
+// useFakeTerraformWithShow installs a fake whose `show -json` prints
+// showJSON. The human plan line deliberately disagrees with the JSON to
+// prove counts come from the JSON plan.
+func useFakeTerraformWithShow(t *testing.T, showJSON string) *runnertest.Fake {
+	t.Helper()
+	return useFakeRunner(t, runnertest.New().
+		Reply("plan", "No changes. Your infrastructure matches the configuration.", 2).
+		Reply("show", showJSON, 0))
+}
+
+func TestPlanCmd_JSONOutput_UsesStructuredPlan(t *testing.T) {
+	useFakeTerraformWithShow(t, fakeShowJSON)
+	repo := initRepoForCommandTests(t)
+
+	stdout, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity", "--json")
+	require.NoError(t, err)
+
+	var payload struct {
+		TotalAdd     int `json:"totalAdd"`
+		TotalDestroy int `json:"totalDestroy"`
+		Layers       []struct {
+			Layer     string `json:"layer"`
+			Replace   int    `json:"replace"`
+			Resources []struct {
+				Address        string   `json:"address"`
+				Action         string   `json:"action"`
+				ReplaceReasons []string `json:"replaceReasons"`
+			} `json:"resources"`
+		} `json:"layers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.Equal(t, 2, payload.TotalAdd)
+	assert.Equal(t, 1, payload.TotalDestroy)
+	require.Len(t, payload.Layers, 1)
+	assert.Equal(t, 1, payload.Layers[0].Replace)
+	require.Len(t, payload.Layers[0].Resources, 2)
+	assert.Equal(t, "replace", payload.Layers[0].Resources[1].Action)
+	assert.Equal(t, []string{"location"}, payload.Layers[0].Resources[1].ReplaceReasons)
+	assert.FileExists(t, filepath.Join(repo, "platform", "connectivity", "tfplan.json"))
+}
+
+func TestPlanCmd_MarkdownOut(t *testing.T) {
+	useFakeTerraformWithShow(t, fakeShowJSON)
+	repo := initRepoForCommandTests(t)
+	out := filepath.Join(t.TempDir(), "plan.md")
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity", "--out", out, "--format", "markdown")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	md := string(data)
+	assert.Contains(t, md, "## lzctl plan")
+	assert.Contains(t, md, "| `connectivity` | 2 | 0 | ⚠️ 1 |")
+	assert.Contains(t, md, "`azurerm_firewall.hub`")
+}
+
+func TestPlanCmd_UnknownFormat(t *testing.T) {
+	repo := initRepoForCommandTests(t)
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--format", "html")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported --format")
+}
+
+func TestDriftCmd_JSONOutput_IncludesResources(t *testing.T) {
+	useFakeTerraformWithShow(t, fakeDriftShowJSON)
+	repo := initRepoForCommandTests(t)
+
+	stdout, _, err := executeCommandWithProcessIO(t, "drift", "--repo-root", repo, "--layer", "identity", "--json")
+	require.Error(t, err, "drift exits non-zero when drift is found")
+
+	var payload struct {
+		TotalDrift int `json:"totalDrift"`
+		Layers     []struct {
+			Resources []struct {
+				Address string `json:"address"`
+			} `json:"resources"`
+		} `json:"layers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.Equal(t, 1, payload.TotalDrift)
+	require.Len(t, payload.Layers, 1)
+	assert.Len(t, payload.Layers[0].Resources, 1)
+	assert.NoFileExists(t, filepath.Join(repo, "platform", "identity", driftPlanFile))
+	assert.NoFileExists(t, filepath.Join(repo, "platform", "identity", driftPendingPlanFile))
+}
+
+func TestApplyCmd_DryRunJSON(t *testing.T) {
+	useFakeTerraformWithShow(t, fakeShowJSON)
+	repo := initRepoForCommandTests(t)
+
+	stdout, _, err := executeCommandWithProcessIO(t, "--dry-run", "--json", "apply", "--repo-root", repo, "--layer", "management")
+	require.NoError(t, err)
+
+	var payload struct {
+		Status string `json:"status"`
+		Layers []struct {
+			Layer string `json:"layer"`
+			Add   int    `json:"add"`
+		} `json:"layers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.Equal(t, "dry-run", payload.Status)
+	require.Len(t, payload.Layers, 1)
+	assert.Equal(t, "management", payload.Layers[0].Layer)
+	assert.Equal(t, 2, payload.Layers[0].Add)
+}
+
+func TestApplyCmd_DestructionGateRendersMissingPlanJSON(t *testing.T) {
+	tf := useFakeTerraformWithShow(t, fakeShowJSON)
+	repo := initRepoForCommandTests(t)
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(repo, "platform", "connectivity", "tfplan.json")))
+
+	_, stderr, err := executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve")
+	require.Error(t, err, "deleting tfplan.json does not skip the gate")
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+	assert.Contains(t, stderr, "azurerm_firewall.hub (replace)")
+	assert.NotContains(t, err.Error(), "tfplan.json")
+	assert.Empty(t, tf.Calls("apply"))
+}
@@ -41,6 +41,7 @@ Updates the workflow state to 'deploy' in workflow.yaml.`,
 			Force:    force,
 		}
 
+		before := policyAssignmentState(root, name)
 		err = policy.Deploy(opts)
 		if err != nil {
 			return fmt.Errorf("policy deploy failed: %w", err)
@@ -49,6 +50,7 @@ Updates the workflow state to 'deploy' in workflow.yaml.`,
 		color.Green("✓ Assignment '%s' switched to Default enforcement", name)
 		fmt.Println("\n  The policy is now enforcing compliance.")
 		fmt.Println("  Non-compliant deployments will be denied.")
+		notifyPolicyStatus(cmd.Context(), root, name, before)
 		return nil
 	},
 }
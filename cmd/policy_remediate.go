@@ -33,6 +33,7 @@ Updates the workflow state to 'remediate' in workflow.yaml.`,
 			DryRun:   dryRun,
 		}
 
+		before := policyAssignmentState(root, name)
 		result, err := policy.Remediate(opts)
 		if err != nil {
 			return fmt.Errorf("policy remediate failed: %w", err)
@@ -59,6 +60,7 @@ Updates the workflow state to 'remediate' in workflow.yaml.`,
 			fmt.Printf("  3. Run: lzctl policy deploy --name %s  (when compliant)\n", name)
 		}
 
+		notifyPolicyStatus(cmd.Context(), root, name, before)
 		return nil
 	},
 }
@@ -29,6 +29,7 @@ The assignment's workflow state is updated to 'test' in workflow.yaml.`,
 			DryRun:   dryRun,
 		}
 
+		before := policyAssignmentState(root, name)
 		result, err := policy.Test(opts)
 		if err != nil {
 			return fmt.Errorf("policy test failed: %w", err)
@@ -46,6 +47,7 @@ The assignment's workflow state is updated to 'test' in workflow.yaml.`,
 		fmt.Println("\nNext steps:")
 		fmt.Println("  1. Wait 24h for compliance data to populate")
 		fmt.Printf("  2. Run: lzctl policy verify --name %s\n", name)
+		notifyPolicyStatus(cmd.Context(), root, name, before)
 		return nil
 	},
 }
@@ -31,6 +31,7 @@ Updates the workflow state to 'verify' in workflow.yaml.`,
 			Output:   outputFile,
 		}
 
+		before := policyAssignmentState(root, name)
 		report, err := policy.Verify(opts)
 		if err != nil {
 			return fmt.Errorf("policy verify failed: %w", err)
@@ -81,6 +82,7 @@ Updates the workflow state to 'verify' in workflow.yaml.`,
 			color.Cyan("\n  Report saved to: %s", outputFile)
 		}
 
+		notifyPolicyStatus(cmd.Context(), root, name, before)
 		return nil
 	},
 }
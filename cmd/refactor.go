@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/refactor"
+)
+
+var refactorCmd = &cobra.Command{
+	Use:   "refactor",
+	Short: "Rename a resource or module without recreating it",
+	Long: `Renames a resource or module of one root and records a moved block, so
+that Terraform moves the object in state instead of destroying it and
+creating it again.
+
+For a block declared in the root itself, the declaration and every
+reference to it in the root's *.tf files are rewritten. For an address
+inside a module or with an instance key, only the moved block is written.
+Moved blocks are appended to moved.tf in the root; running the same
+refactor twice does not duplicate them.
+
+Select the root with exactly one of --layer, --zone or --blueprint. Use
+--dry-run to print the moved block and the files that would change.
+
+Examples:
+  lzctl refactor --layer connectivity --from azurerm_resource_group.hub --to azurerm_resource_group.connectivity
+  lzctl refactor --zone app --from module.corp_vnet --to module.vnet
+  lzctl refactor --zone app --from 'azurerm_subnet.app[0]' --to 'azurerm_subnet.app["web"]'`,
+	RunE: runRefactor,
+}
+
+var (
+	refactorLayer     string
+	refactorZone      string
+	refactorBlueprint string
+	refactorFrom      string
+	refactorTo        string
+)
+
+func init() {
+	refactorCmd.Flags().StringVar(&refactorLayer, "layer", "", "platform layer holding the object")
+	refactorCmd.Flags().StringVar(&refactorZone, "zone", "", "landing zone holding the object")
+	refactorCmd.Flags().StringVar(&refactorBlueprint, "blueprint", "", "landing zone whose blueprint holds the object")
+	refactorCmd.Flags().StringVar(&refactorFrom, "from", "", "current address, e.g. azurerm_resource_group.hub or module.vnet")
+	refactorCmd.Flags().StringVar(&refactorTo, "to", "", "new address")
+	_ = refactorCmd.MarkFlagRequired("from")
+	_ = refactorCmd.MarkFlagRequired("to")
+
+	rootCmd.AddCommand(refactorCmd)
+}
+
+func runRefactor(cmd *cobra.Command, args []string) error {
+	selected := 0
+	for _, s := range []string{refactorLayer, refactorZone, refactorBlueprint} {
+		if strings.TrimSpace(s) != "" {
+			selected++
+		}
+	}
+	if selected != 1 {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("select the root with exactly one of --layer, --zone or --blueprint"))
+	}
+	move, err := refactor.NewMove(refactorFrom, refactorTo)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+
+	root, err := absRepoRoot()
+	if err != nil {
+		return err
+	}
+	roots, err := resolveLocalRoots(root, rootSelection{Layer: refactorLayer, Zone: refactorZone, Blueprint: refactorBlueprint})
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	// --zone selects the landing zone followed by its blueprint: keep the zone.
+	r := roots[0]
+
+	results, err := refactor.Apply(filepath.Join(root, r.Dir), []refactor.Move{move}, !dryRun)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	res := results[0]
+
+	if jsonOutput {
+		status := "ok"
+		if dryRun {
+			status = "dry-run"
+		}
+		data, _ := json.MarshalIndent(map[string]interface{}{
+			"status": status,
+			"layer":  r.Name,
+			"dir":    filepath.ToSlash(r.Dir),
+			"move":   res,
+			"block":  move.Block(),
+		}, "", "  ")
+		fmt.Fprintln(os.Stdout, string(data))
+	}
+
+	verb := "Renamed"
+	if dryRun {
+		verb = "Would rename"
+	}
+	switch {
+	case res.Renamed:
+		fmt.Fprintf(os.Stderr, "   ✏️  %s %s in %s\n", verb, move.From, strings.Join(res.Files, ", "))
+	case res.Skipped != "":
+		fmt.Fprintf(os.Stderr, "   ℹ️  %s\n", res.Skipped)
+	}
+	if res.Recorded {
+		fmt.Fprintf(os.Stderr, "   ℹ️  %s already holds this moved block\n", res.MovedFile)
+	} else {
+		fmt.Fprintf(os.Stderr, "   📝 %s/%s:\n\n", filepath.ToSlash(r.Dir), res.MovedFile)
+		for _, line := range strings.Split(strings.TrimSuffix(move.Block(), "\n"), "\n") {
+			fmt.Fprintf(os.Stderr, "      %s\n", line)
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+
+	if dryRun {
+		color.New(color.FgYellow, color.Bold).Fprintln(os.Stderr, "⚡ [DRY-RUN] Refactor simulation complete. No file was written.")
+		return nil
+	}
+	color.New(color.FgGreen, color.Bold).Fprintf(os.Stderr, "✅ %s moved to %s in %s.\n", move.From, move.To, r.Name)
+	fmt.Fprintf(os.Stderr, "\nNext: lzctl plan %s   (expect the object to be moved, not replaced)\n", refactorSelectionFlag(r))
+	return nil
+}
+
+// refactorSelectionFlag returns the plan flag selecting r.
+func refactorSelectionFlag(r localRoot) string {
+	switch r.Kind {
+	case rootKindLandingZone:
+		return "--zone " + r.Zone
+	case rootKindBlueprint:
+		return "--blueprint " + r.Zone
+	default:
+		return "--layer " + r.Name
+	}
+}
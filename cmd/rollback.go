@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -13,37 +14,102 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+	"github.com/kjourdan1/lzctl/internal/planrules"
+	"github.com/kjourdan1/lzctl/internal/plansummary"
+	"github.com/kjourdan1/lzctl/internal/state"
 )
 
 var rollbackCmd = &cobra.Command{
 	Use:   "rollback",
 	Short: "Rollback platform layers to a previous Terraform state",
-	Long: `Rolls back platform layers by replaying a previous Terraform plan.
+	Long: `Rolls back layers to the infrastructure recorded in a previous state version.
 
-Layers are processed in reverse CAF order:
+--to accepts a timestamp (YYYYMMDD-HHMMSS or RFC3339) or a snapshot tag
+created with 'lzctl state snapshot --tag'. For each layer, lzctl:
+  1. finds the state blob version current at that time (or the snapshot
+     carrying that tag) with blob versioning,
+  2. checks out the git commit that produced it (the last commit at or
+     before the version was written) into a temporary worktree,
+  3. plans that code against the current state and shows the diff.
+
+The plans are checked against the subscription scope and the plan rules
+(.lzctl/plan-rules.yaml) before anything is applied. Like plan and apply,
+each root waits up to --lock-wait for a state lease held by another run.
+
+After confirmation the plans are applied in reverse dependency order (see
+'lzctl graph'): a root is rolled back before the roots it depends on, so
+blueprints and landing zones come before the platform layers, e.g.
   5. connectivity
   4. governance
   3. management
   2. identity
   1. management-groups
 
+If an apply fails, the remaining layers are skipped and the command fails.
+What was restored (state version, commit, changes) is recorded under
+.lzctl/rollbacks/. The working tree is never modified.
+
 Use --layer to roll back a specific layer only.
+Use --dry-run to stop after showing the diff.
 Use --auto-approve to skip the interactive confirmation (CI/CD).`,
 	RunE: runRollback,
 }
 
 var (
 	rollbackLayer       string
+	rollbackTo          string
 	rollbackAutoApprove bool
+	rollbackLabels      []string
+	rollbackLockWait    time.Duration
 )
 
+// rollbackPlanFile is the plan produced from the checked-out commit.
+const rollbackPlanFile = "tfplan.rollback"
+
 func init() {
 	rollbackCmd.Flags().StringVar(&rollbackLayer, "layer", "", "specific layer to roll back")
+	rollbackCmd.Flags().StringVar(&rollbackTo, "to", "", "timestamp (YYYYMMDD-HHMMSS or RFC3339) or snapshot tag to roll back to")
 	rollbackCmd.Flags().BoolVar(&rollbackAutoApprove, "auto-approve", false, "skip confirmation")
+	rollbackCmd.Flags().StringSliceVar(&rollbackLabels, "approval-label", nil, "approval label granted for plan rules (repeatable)")
+	rollbackCmd.Flags().DurationVar(&rollbackLockWait, "lock-wait", defaultLockWait, "how long to wait for a state lease held by another run (0: fail at once)")
 
 	rootCmd.AddCommand(rollbackCmd)
 }
 
+// rollbackTarget is either a point in time or a snapshot tag.
+type rollbackTarget struct {
+	At  time.Time
+	Tag string
+}
+
+func (t rollbackTarget) String() string {
+	if t.Tag != "" {
+		return "snapshot " + t.Tag
+	}
+	return t.At.Format(time.RFC3339)
+}
+
+// rollbackEntry records the rollback of a single root.
+type rollbackEntry struct {
+	Layer       string                       `json:"layer"`
+	Kind        string                       `json:"kind"`
+	StateKey    string                       `json:"stateKey"`
+	VersionID   string                       `json:"versionId,omitempty"`
+	VersionTime string                       `json:"versionTime,omitempty"`
+	Commit      string                       `json:"commit,omitempty"`
+	Status      string                       `json:"status"` // planned | unchanged | restored | skipped | failed | dry-run
+	Reason      string                       `json:"reason,omitempty"`
+	Add         int                          `json:"add"`
+	Change      int                          `json:"change"`
+	Destroy     int                          `json:"destroy"`
+	Resources   []plansummary.ResourceChange `json:"resources,omitempty"`
+	Duration    string                       `json:"duration,omitempty"`
+
+	root localRoot
+	dir  string // root directory inside the worktree
+}
+
 func runRollback(cmd *cobra.Command, args []string) error {
 	root, err := absRepoRoot()
 	if err != nil {
@@ -54,26 +120,143 @@ func runRollback(cmd *cobra.Command, args []string) error {
 		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("--ci mode requires --auto-approve for rollback"))
 	}
 
-	if err := ensureTerraformInstalled(); err != nil {
+	target, err := parseRollbackTarget(rollbackTo)
+	if err != nil {
 		return exitcode.Wrap(exitcode.Validation, err)
 	}
 
-	layers, err := resolveLocalLayers(root, rollbackLayer)
+	tf, err := resolveRunner(cmd.Context(), root)
 	if err != nil {
 		return exitcode.Wrap(exitcode.Validation, err)
 	}
 
-	// Reverse layer order for rollback (destroy in reverse dependency order)
-	reversed := make([]string, len(layers))
-	for i, l := range layers {
-		reversed[len(layers)-1-i] = l
+	cfg, err := configCache()
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("loading config: %w", err))
+	}
+
+	roots, err := resolveLocalRoots(root, rootSelection{Layer: rollbackLayer})
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	roots, _, err = orderRoots(root, roots)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	// Reverse dependency order: dependents are rolled back before what they
+	// depend on.
+	reversed := make([]localRoot, len(roots))
+	for i, r := range roots {
+		reversed[len(roots)-1-i] = r
+	}
+
+	ctx := cmd.Context()
+	bold := color.New(color.Bold)
+	bold.Fprintf(os.Stderr, "↩️  Rolling back to %s\n\n", target)
+
+	// 1. Resolve the state version and producing commit for each root.
+	mgr := newStateManager(cfg)
+	entries := make([]*rollbackEntry, 0, len(reversed))
+	for _, r := range reversed {
+		key, keyErr := stateKeyFor(root, r)
+		if keyErr != nil {
+			return exitcode.Wrap(exitcode.Validation, fmt.Errorf("layer %s: %w", r.Name, keyErr))
+		}
+		e := &rollbackEntry{Layer: r.Name, Kind: r.Kind, StateKey: key, root: r}
+		entries = append(entries, e)
+
+		versions, listErr := mgr.ListVersions(e.StateKey)
+		if listErr != nil {
+			return exitcode.Wrap(exitcode.Azure, listErr)
+		}
+		var v state.Snapshot
+		var found bool
+		if target.Tag != "" {
+			v, found = state.VersionByTag(versions, target.Tag)
+		} else {
+			v, found = state.VersionAt(versions, target.At)
+		}
+		if !found {
+			e.Status, e.Reason = "skipped", "no state version matches "+target.String()
+			continue
+		}
+		e.VersionID = v.VersionID
+		e.VersionTime = v.CreatedAt.Format(time.RFC3339)
+
+		commit, commitErr := gitCommitAt(ctx, root, v.CreatedAt)
+		if commitErr != nil {
+			return exitcode.Wrap(exitcode.Validation, commitErr)
+		}
+		if commit == "" {
+			e.Status, e.Reason = "skipped", "no git commit at or before "+e.VersionTime
+			continue
+		}
+		e.Commit = commit
+	}
+
+	// 2. Check out each producing commit once, in a temporary worktree.
+	worktrees, cleanup, err := checkoutRollbackCommits(ctx, root, entries)
+	defer cleanup()
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+
+	// 3. Plan the old code against the current state and show the diff.
+	pending := 0
+	for _, e := range entries {
+		if e.Status != "" {
+			printRollbackEntry(e)
+			continue
+		}
+		e.dir = filepath.Join(worktrees[e.Commit], e.root.Dir)
+		if info, statErr := os.Stat(e.dir); statErr != nil || !info.IsDir() {
+			e.Status, e.Reason = "skipped", "root did not exist at commit "+shortCommit(e.Commit)
+			printRollbackEntry(e)
+			continue
+		}
+		if leaseErr := waitForRootLease(ctx, root, e.root, rollbackLockWait, 0); leaseErr != nil {
+			return leaseErr
+		}
+		if planErr := planRollbackEntry(ctx, tf, worktrees[e.Commit], e); planErr != nil {
+			e.Status = "failed"
+			printRollbackEntry(e)
+			return planErr
+		}
+		if e.Add+e.Change+e.Destroy == 0 {
+			e.Status = "unchanged"
+		} else {
+			e.Status = "planned"
+			pending++
+		}
+		printRollbackEntry(e)
+	}
+	fmt.Fprintln(os.Stderr)
+
+	if err := checkRollbackPlanRules(entries); err != nil {
+		return err
+	}
+
+	if dryRun {
+		for _, e := range entries {
+			if e.Status == "planned" {
+				e.Status = "dry-run"
+			}
+		}
+		printRollbackJSON(target, entries, "")
+		color.New(color.FgYellow, color.Bold).Fprintln(os.Stderr, "⚡ [DRY-RUN] Rollback simulation complete. No infrastructure changes were applied.")
+		return nil
+	}
+
+	if pending == 0 {
+		printRollbackJSON(target, entries, "")
+		color.New(color.FgGreen, color.Bold).Fprintln(os.Stderr, "✅ Nothing to roll back: infrastructure already matches the target.")
+		return nil
 	}
 
 	// Interactive confirmation
-	if !rollbackAutoApprove && !dryRun {
+	if !rollbackAutoApprove {
 		yellow := color.New(color.FgYellow, color.Bold)
-		yellow.Fprintln(os.Stderr, "⚠️  You are about to rollback platform layer changes")
-		fmt.Fprintf(os.Stderr, "   Layers (reverse order): %s\n", strings.Join(reversed, ", "))
+		yellow.Fprintf(os.Stderr, "⚠️  You are about to roll back %d layer(s) to %s\n", pending, target)
 		fmt.Fprintf(os.Stderr, "\n   Type 'yes' to proceed: ")
 		reader := bufio.NewReader(os.Stdin)
 		answer, _ := reader.ReadString('\n')
@@ -85,73 +268,222 @@ func runRollback(cmd *cobra.Command, args []string) error {
 		fmt.Fprintln(os.Stderr)
 	}
 
-	bold := color.New(color.Bold)
-	bold.Fprintf(os.Stderr, "↩️  Rolling back platform layers\n\n")
+	// 4. Apply in reverse dependency order. Once an apply fails, the
+	// remaining layers are left as they are.
+	bold.Fprintf(os.Stderr, "↩️  Applying rollback\n\n")
+	var applyErr error
+	var failedLayer string
+	for _, e := range entries {
+		if e.Status != "planned" {
+			continue
+		}
+		if failedLayer != "" {
+			e.Status, e.Reason = "skipped", "not applied: rollback of "+failedLayer+" failed"
+			printRollbackEntry(e)
+			continue
+		}
+		if leaseErr := waitForRootLease(ctx, root, e.root, rollbackLockWait, 0); leaseErr != nil {
+			e.Status, e.Reason = "failed", "state lease held by another run"
+			color.New(color.FgRed).Fprintf(os.Stderr, "   ❌ %s: state locked\n", e.Layer)
+			applyErr, failedLayer = leaseErr, e.Layer
+			continue
+		}
+		start := time.Now()
+		out, err := tf.Run(ctx, e.dir, "apply", "-input=false", "-no-color", rollbackPlanFile)
+		e.Duration = time.Since(start).Round(time.Millisecond).String()
+		if err != nil {
+			e.Status = "failed"
+			e.Reason = "terraform apply failed"
+			color.New(color.FgRed).Fprintf(os.Stderr, "   ❌ %s (%s): apply failed\n", e.Layer, e.Duration)
+			applyErr = exitcode.Wrap(exitcode.Terraform, fmt.Errorf("rollback layer %s: terraform apply failed (output: %s): %w", e.Layer, out, err))
+			failedLayer = e.Layer
+			continue
+		}
+		e.Status = "restored"
+		color.New(color.FgGreen).Fprintf(os.Stderr, "   ✅ %s (%s)\n", e.Layer, e.Duration)
+	}
+	fmt.Fprintln(os.Stderr)
 
-	type layerResult struct {
-		Layer    string `json:"layer"`
-		Status   string `json:"status"`
-		Duration string `json:"duration"`
-		Error    string `json:"error,omitempty"`
+	recordPath, recordErr := writeRollbackRecord(root, target, entries)
+	if recordErr != nil {
+		color.New(color.FgYellow).Fprintf(os.Stderr, "⚠️  Could not record rollback: %v\n", recordErr)
+	} else {
+		fmt.Fprintf(os.Stderr, "📁 Rollback recorded in %s\n\n", recordPath)
 	}
-	results := make([]layerResult, 0, len(reversed))
+	printRollbackJSON(target, entries, recordPath)
 
-	for _, layer := range reversed {
-		dir := filepath.Join(root, "platform", layer)
-		start := time.Now()
-		lr := layerResult{Layer: layer}
-
-		if initOut, initErr := runTerraformCmd(cmd.Context(), dir, "init", "-input=false", "-no-color"); initErr != nil {
-			lr.Status = "failed"
-			lr.Error = fmt.Sprintf("terraform init failed: %s", initOut)
-			lr.Duration = time.Since(start).Round(time.Millisecond).String()
-			results = append(results, lr)
-			color.New(color.FgRed).Fprintf(os.Stderr, "   ❌ %s: init failed\n", layer)
-			continue
+	if applyErr != nil {
+		return applyErr
+	}
+	color.New(color.FgGreen, color.Bold).Fprintln(os.Stderr, "✅ Rollback complete.")
+	return nil
+}
+
+// planRollbackEntry plans the checked-out code for e against the current
+// state and records the structured diff. Old code may target subscriptions
+// lzctl.yaml no longer declares: the plan is scope-checked like an apply.
+func planRollbackEntry(ctx context.Context, tf orchestrator.Runner, worktree string, e *rollbackEntry) error {
+	if initOut, err := tf.Run(ctx, e.dir, terraformInitArgs(e.dir, e.StateKey)...); err != nil {
+		return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("rollback layer %s: terraform init failed (output: %s): %w", e.Layer, initOut, err))
+	}
+	out, err := tf.Run(ctx, e.dir, "plan", "-input=false", "-detailed-exitcode", "-no-color", "-out="+rollbackPlanFile)
+	if err != nil && strings.Contains(out, "Error:") {
+		return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("rollback layer %s: terraform plan failed (output: %s): %w", e.Layer, out, err))
+	}
+	s := summarizePlan(ctx, tf, e.dir, rollbackPlanFile, "", out)
+	e.Add, e.Change, e.Destroy, e.Resources = s.Add, s.Change, s.Destroy, s.Resources
+	return verifyRootScope(ctx, tf, worktree, e.root, rollbackPlanFile)
+}
+
+// checkRollbackPlanRules evaluates the repository's plan rules against the
+// rollback plans, as apply does before applying.
+func checkRollbackPlanRules(entries []*rollbackEntry) error {
+	rs, err := loadPlanRules()
+	if err != nil || rs == nil {
+		return err
+	}
+	var layers []planrules.Layer
+	for _, e := range entries {
+		if e.Status == "planned" {
+			layers = append(layers, planrules.Layer{Name: e.Layer, Kind: e.Kind, Changes: e.Resources})
 		}
+	}
+	return reportPlanRules(rs, layers, nil, approvalLabels(rollbackLabels))
+}
 
-		if dryRun {
-			out, _ := runTerraformCmd(cmd.Context(), dir, "plan", "-input=false", "-no-color")
-			add, change, destroy := parsePlanSummary(out)
-			lr.Status = "dry-run"
-			lr.Duration = time.Since(start).Round(time.Millisecond).String()
-			results = append(results, lr)
-			fmt.Fprintf(os.Stderr, "   ⚡ %-20s +%d ~%d -%d (dry-run)\n", layer, add, change, destroy)
-			continue
+func printRollbackEntry(e *rollbackEntry) {
+	switch e.Status {
+	case "skipped":
+		color.New(color.FgYellow).Fprintf(os.Stderr, "   ⏭️  %-20s skipped: %s\n", e.Layer, e.Reason)
+		return
+	case "failed":
+		color.New(color.FgRed).Fprintf(os.Stderr, "   ❌ %-20s plan failed\n", e.Layer)
+		return
+	}
+	icon := "✅"
+	if e.Status == "planned" {
+		icon = "📝"
+	}
+	fmt.Fprintf(os.Stderr, "   %s %-20s +%d ~%d -%d  (state %s @ %s, commit %s)\n",
+		icon, e.Layer, e.Add, e.Change, e.Destroy, e.VersionID, e.VersionTime, shortCommit(e.Commit))
+	for _, rc := range e.Resources {
+		fmt.Fprintf(os.Stderr, "      %-8s %s\n", rc.Action, rc.Address)
+	}
+}
+
+func printRollbackJSON(target rollbackTarget, entries []*rollbackEntry, recordPath string) {
+	if !jsonOutput {
+		return
+	}
+	status := "ok"
+	for _, e := range entries {
+		if e.Status == "failed" {
+			status = "failed"
 		}
+	}
+	payload := map[string]interface{}{
+		"status":  status,
+		"dryRun":  dryRun,
+		"to":      target.String(),
+		"results": entries,
+	}
+	if recordPath != "" {
+		payload["record"] = recordPath
+	}
+	data, _ := json.MarshalIndent(payload, "", "  ")
+	fmt.Fprintln(os.Stdout, string(data))
+}
+
+// writeRollbackRecord stores what was restored under .lzctl/rollbacks/.
+func writeRollbackRecord(repo string, target rollbackTarget, entries []*rollbackEntry) (string, error) {
+	now := time.Now().UTC()
+	dir := filepath.Join(repo, ".lzctl", "rollbacks")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("creating %s: %w", dir, err)
+	}
+	record := map[string]interface{}{
+		"timestamp": now.Format(time.RFC3339),
+		"to":        target.String(),
+		"layers":    entries,
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, now.Format("20060102-150405")+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	return path, nil
+}
 
-		if applyOut, applyErr := runTerraformCmd(cmd.Context(), dir, "apply", "-auto-approve", "-input=false", "-no-color"); applyErr != nil {
-			lr.Status = "failed"
-			lr.Error = "terraform apply failed"
-			lr.Duration = time.Since(start).Round(time.Millisecond).String()
-			color.New(color.FgRed).Fprintf(os.Stderr, "   ❌ %s (%s): apply failed\n", layer, lr.Duration)
-			return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("rollback layer %s: terraform apply failed (output: %s): %w", layer, applyOut, applyErr))
+// checkoutRollbackCommits creates one detached worktree per distinct commit
+// referenced by entries and returns the path of the repo root inside each.
+// The returned cleanup removes every worktree and is always safe to call.
+func checkoutRollbackCommits(ctx context.Context, repo string, entries []*rollbackEntry) (map[string]string, func(), error) {
+	worktrees := map[string]string{}
+	var created []string
+	cleanup := func() {
+		for _, wt := range created {
+			_, _ = runGit(context.Background(), repo, "worktree", "remove", "--force", wt)
+			_ = os.RemoveAll(wt)
 		}
+	}
 
-		lr.Status = "ok"
-		lr.Duration = time.Since(start).Round(time.Millisecond).String()
-		results = append(results, lr)
-		color.New(color.FgGreen).Fprintf(os.Stderr, "   ✅ %s (%s)\n", layer, lr.Duration)
+	prefix, err := runGit(ctx, repo, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("rollback requires a git repository: %s", strings.TrimSpace(prefix))
 	}
+	prefix = strings.TrimSpace(prefix)
 
-	fmt.Fprintln(os.Stderr)
+	for _, e := range entries {
+		if e.Commit == "" {
+			continue
+		}
+		if _, ok := worktrees[e.Commit]; ok {
+			continue
+		}
+		wt, err := os.MkdirTemp("", "lzctl-rollback-")
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("creating worktree directory: %w", err)
+		}
+		created = append(created, wt)
+		if out, err := runGit(ctx, repo, "worktree", "add", "--detach", wt, e.Commit); err != nil {
+			return nil, cleanup, fmt.Errorf("checking out %s: %s", shortCommit(e.Commit), strings.TrimSpace(out))
+		}
+		worktrees[e.Commit] = filepath.Join(wt, filepath.FromSlash(prefix))
+	}
+	return worktrees, cleanup, nil
+}
 
-	if jsonOutput {
-		data, _ := json.MarshalIndent(map[string]interface{}{
-			"status":  "ok",
-			"dryRun":  dryRun,
-			"results": results,
-		}, "", "  ")
-		fmt.Fprintln(os.Stdout, string(data))
+// gitCommitAt returns the last commit on HEAD made at or before t, or "" if
+// there is none.
+func gitCommitAt(ctx context.Context, repo string, t time.Time) (string, error) {
+	out, err := runGit(ctx, repo, "rev-list", "-1", "--before="+t.UTC().Format(time.RFC3339), "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("finding the commit at %s: %s", t.Format(time.RFC3339), strings.TrimSpace(out))
 	}
+	return strings.TrimSpace(out), nil
+}
 
-	if dryRun {
-		color.New(color.FgYellow, color.Bold).Fprintln(os.Stderr, "⚡ [DRY-RUN] Rollback simulation complete. No infrastructure changes were applied.")
-	} else {
-		color.New(color.FgGreen, color.Bold).Fprintln(os.Stderr, "✅ Rollback complete.")
+func shortCommit(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
 	}
+	return sha
+}
 
-	return nil
+// parseRollbackTarget interprets --to as a timestamp when it parses as one,
+// and as a snapshot tag otherwise.
+func parseRollbackTarget(raw string) (rollbackTarget, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return rollbackTarget{}, fmt.Errorf("--to is required: pass a timestamp (YYYYMMDD-HHMMSS or RFC3339) or a snapshot tag")
+	}
+	if ts, err := parseRollbackTimestamp(raw); err == nil {
+		return rollbackTarget{At: ts}, nil
+	}
+	return rollbackTarget{Tag: raw}, nil
 }
 
 func parseRollbackTimestamp(raw string) (time.Time, error) {
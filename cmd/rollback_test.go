@@ -1,11 +1,20 @@
 package cmd
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
 )
 
 func TestParseRollbackTimestamp(t *testing.T) {
@@ -20,3 +29,248 @@ func TestParseRollbackTimestamp(t *testing.T) {
 	_, err = parseRollbackTimestamp("bad")
 	require.Error(t, err)
 }
+
+func TestParseRollbackTarget(t *testing.T) {
+	target, err := parseRollbackTarget("20260218-153045")
+	require.NoError(t, err)
+	assert.Empty(t, target.Tag)
+	assert.Equal(t, time.Date(2026, 2, 18, 15, 30, 45, 0, time.UTC), target.At)
+
+	target, err = parseRollbackTarget("pre-apply-sprint-5")
+	require.NoError(t, err)
+	assert.Equal(t, "pre-apply-sprint-5", target.Tag)
+
+	_, err = parseRollbackTarget("  ")
+	require.Error(t, err)
+}
+
+// setupRollbackRepo creates an lzctl repo committed to git at commitTime and
+// a state backend whose versions are described by listing. prepare runs
+// on the repo before the commit.
+func setupRollbackRepo(t *testing.T, commitTime time.Time, listing string, prepare ...func(repo string)) (string, *runnertest.Fake, *fakeAz) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tf := useFakeTerraformWithShow(t, fakeShowJSON)
+	repo := initRepoForCommandTests(t)
+	for _, p := range prepare {
+		p(repo)
+	}
+
+	git := func(args ...string) {
+		t.Helper()
+		c := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		c.Dir = repo
+		date := commitTime.Format(time.RFC3339)
+		c.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "baseline")
+
+	az := useFakeAz(t, newFakeAz().On("storage blob list", func(args []string) (string, error) {
+		return strings.ReplaceAll(listing, "STATEKEY", argAfter(args, "--prefix")), nil
+	}))
+	return repo, tf, az
+}
+
+const rollbackListing = `[
+	{"name": "STATEKEY", "versionId": "v1", "properties": {"lastModified": "2026-02-18T10:00:00+00:00"}},
+	{"name": "STATEKEY", "snapshot": "2026-02-18T11:00:05Z", "metadata": {"lzctl_tag": "pre-change"}, "properties": {"lastModified": "2026-02-18T11:00:00+00:00"}},
+	{"name": "STATEKEY", "versionId": "v2", "properties": {"lastModified": "2026-02-18T12:00:00+00:00"}}
+]`
+
+func TestRollbackCmd_DryRun_ShowsDiffFromProducingCommit(t *testing.T) {
+	repo, _, _ := setupRollbackRepo(t, time.Date(2026, 2, 18, 9, 0, 0, 0, time.UTC), rollbackListing)
+
+	stdout, stderr, err := executeCommandWithProcessIO(t, "--dry-run", "--json", "rollback",
+		"--repo-root", repo, "--layer", "management-groups", "--to", "20260218-103000")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "[DRY-RUN]")
+
+	var payload struct {
+		To      string `json:"to"`
+		Results []struct {
+			Layer     string `json:"layer"`
+			VersionID string `json:"versionId"`
+			Commit    string `json:"commit"`
+			Status    string `json:"status"`
+			Add       int    `json:"add"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	require.Len(t, payload.Results, 1)
+	r := payload.Results[0]
+	assert.Equal(t, "management-groups", r.Layer)
+	assert.Equal(t, "v1", r.VersionID)
+	assert.Len(t, r.Commit, 40)
+	assert.Equal(t, "dry-run", r.Status)
+	assert.Equal(t, 2, r.Add)
+	assert.NoDirExists(t, filepath.Join(repo, ".lzctl", "rollbacks"))
+}
+
+func TestRollbackCmd_SnapshotTag_AppliesAndRecords(t *testing.T) {
+	repo, _, _ := setupRollbackRepo(t, time.Date(2026, 2, 18, 9, 0, 0, 0, time.UTC), rollbackListing)
+
+	stdout, _, err := executeCommandWithProcessIO(t, "--json", "rollback",
+		"--repo-root", repo, "--layer", "identity", "--to", "pre-change", "--auto-approve")
+	require.NoError(t, err)
+
+	var payload struct {
+		Record  string `json:"record"`
+		Results []struct {
+			VersionID string `json:"versionId"`
+			Status    string `json:"status"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	require.Len(t, payload.Results, 1)
+	assert.Equal(t, "2026-02-18T11:00:05Z", payload.Results[0].VersionID)
+	assert.Equal(t, "restored", payload.Results[0].Status)
+	require.FileExists(t, payload.Record)
+	assert.Equal(t, filepath.Join(repo, ".lzctl", "rollbacks"), filepath.Dir(payload.Record))
+}
+
+func TestRollbackCmd_SkipsLayersWithoutMatchingVersion(t *testing.T) {
+	repo, _, _ := setupRollbackRepo(t, time.Date(2026, 2, 18, 9, 0, 0, 0, time.UTC), rollbackListing)
+
+	stdout, _, err := executeCommandWithProcessIO(t, "--json", "rollback",
+		"--repo-root", repo, "--layer", "identity", "--to", "2026-02-17T00:00:00Z", "--auto-approve")
+	require.NoError(t, err)
+
+	var payload struct {
+		Results []struct {
+			Status string `json:"status"`
+			Reason string `json:"reason"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	require.Len(t, payload.Results, 1)
+	assert.Equal(t, "skipped", payload.Results[0].Status)
+	assert.Contains(t, payload.Results[0].Reason, "no state version")
+}
+
+func TestRollbackCmd_ReverseDependencyOrderAndBackendKeys(t *testing.T) {
+	repo, tf, az := setupRollbackRepo(t, time.Date(2026, 2, 18, 9, 0, 0, 0, time.UTC), rollbackListing, func(repo string) {
+		for _, zone := range []string{"a", "b"} {
+			require.NoError(t, os.MkdirAll(filepath.Join(repo, "landing-zones", zone), 0o755))
+		}
+		// lz:b reads lz:a's state: b is rolled back before a, although a
+		// comes first alphabetically.
+		require.NoError(t, os.WriteFile(filepath.Join(repo, "landing-zones", "a", "backend.hcl"), []byte("key = \"custom/zone-a.tfstate\"\n"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(repo, "landing-zones", "b", "main.tf"), []byte(`
+data "terraform_remote_state" "a" {
+  config = {
+    key = "custom/zone-a.tfstate"
+  }
+}
+`), 0o644))
+	})
+	tf.On("apply", func(c runnertest.Call) (string, error) {
+		if c.Root() == "b" {
+			return "Error: apply failed", &runnertest.ExitError{Code: 1}
+		}
+		return "Apply complete!", nil
+	})
+
+	stdout, _, err := executeCommandWithProcessIO(t, "--json", "rollback",
+		"--repo-root", repo, "--to", "20260218-103000", "--auto-approve")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Terraform, exitcode.Of(err))
+
+	prefixes := az.FlagValues("storage blob list", "--prefix")
+	assert.Contains(t, prefixes, "custom/zone-a.tfstate", "the key declared in backend.hcl is used")
+	assert.NotContains(t, prefixes, "landing-zones-a.tfstate")
+
+	var payload struct {
+		Results []struct {
+			Layer  string `json:"layer"`
+			Status string `json:"status"`
+			Reason string `json:"reason"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	require.GreaterOrEqual(t, len(payload.Results), 2)
+	assert.Equal(t, "lz:b", payload.Results[0].Layer)
+	assert.Equal(t, "failed", payload.Results[0].Status)
+	assert.Equal(t, "lz:a", payload.Results[1].Layer)
+	for _, r := range payload.Results[1:] {
+		assert.Equal(t, "skipped", r.Status, r.Layer)
+		assert.Contains(t, r.Reason, "rollback of lz:b failed")
+	}
+	assert.Equal(t, []string{"b"}, tf.Roots("apply"))
+}
+
+func TestRollbackCmd_BlocksOutOfScopePlan(t *testing.T) {
+	repo, tf, _ := setupRollbackRepo(t, time.Date(2026, 2, 18, 9, 0, 0, 0, time.UTC), rollbackListing, func(repo string) {
+		cfgPath := filepath.Join(repo, "lzctl.yaml")
+		data, err := os.ReadFile(cfgPath)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(cfgPath, []byte(strings.Replace(string(data), "<subscription-id>", scopeTestSubscription, 1)), 0o644))
+	})
+	// The code of the old commit targets a subscription lzctl.yaml does not declare.
+	tf.Reply("show", scopeShowJSON(rogueSubscription), 0)
+
+	_, _, err := executeCommandWithProcessIO(t, "rollback", "--repo-root", repo, "--layer", "identity", "--to", "pre-change", "--auto-approve")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.SecurityBlock, exitcode.Of(err))
+	assert.Contains(t, err.Error(), rogueSubscription)
+	assert.Empty(t, tf.Calls("apply"))
+}
+
+func TestRollbackCmd_PlanRulesDenyBeforeApplying(t *testing.T) {
+	repo, tf, _ := setupRollbackRepo(t, time.Date(2026, 2, 18, 9, 0, 0, 0, time.UTC), rollbackListing, func(repo string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(repo, ".lzctl"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(repo, ".lzctl", "plan-rules.yaml"), []byte(testPlanRules), 0o644))
+	})
+	t.Cleanup(func() { rollbackLabels = nil })
+	// The old code deletes a management group.
+	tf.Reply("show", mgDeletePlanJSON, 0)
+
+	_, stderr, err := executeCommandWithProcessIO(t, "rollback", "--repo-root", repo, "--layer", "management-groups", "--to", "pre-change", "--auto-approve")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Policy, exitcode.Of(err))
+	assert.Contains(t, stderr, "protect-management-groups")
+	assert.Empty(t, tf.Calls("apply"))
+
+	_, _, err = executeCommandWithProcessIO(t, "rollback", "--repo-root", repo, "--layer", "management-groups", "--to", "pre-change", "--auto-approve", "--approval-label", "mg-change-approved")
+	require.NoError(t, err)
+	assert.Len(t, tf.Calls("apply"), 1)
+}
+
+func TestRollbackCmd_WaitsForStateLease(t *testing.T) {
+	repo, tf, az := setupRollbackRepo(t, time.Date(2026, 2, 18, 9, 0, 0, 0, time.UTC), rollbackListing)
+	t.Cleanup(func() { rollbackLockWait = defaultLockWait })
+	// Another run takes the lease once the rollback is planned.
+	var leaseChecks int
+	az.On("storage blob show", func(args []string) (string, error) {
+		if !strings.Contains(argAfter(args, "--query"), "lease") {
+			return az.Default(args...)
+		}
+		leaseChecks++
+		if len(tf.Calls("plan")) == 0 {
+			return az.Default(args...)
+		}
+		info := `{"ID":"8d2e","Operation":"OperationTypeApply","Who":"runner@pipeline-17","Created":"` + time.Now().Format(time.RFC3339) + `"}`
+		return `{"status":"locked","state":"leased","metadata":{"terraformlockid":"` + base64.StdEncoding.EncodeToString([]byte(info)) + `"}}`, nil
+	})
+
+	stdout, _, err := executeCommandWithProcessIO(t, "--json", "rollback", "--repo-root", repo, "--layer", "identity", "--to", "pre-change", "--auto-approve", "--lock-wait", "0")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Azure, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "locked by runner@pipeline-17")
+	assert.Equal(t, 2, leaseChecks, "the lease is checked before the plan and before the apply")
+	assert.Empty(t, tf.Calls("apply"))
+
+	var payload struct {
+		Results []struct {
+			Status string `json:"status"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	require.Len(t, payload.Results, 1)
+	assert.Equal(t, "failed", payload.Results[0].Status)
+}
@@ -9,6 +9,9 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/exitcode"
 )
 
 var (
@@ -18,6 +21,8 @@ var (
 	dryRun     bool
 	jsonOutput bool // --json flag for machine-readable output
 	ciMode     bool
+	envName    string // --env: environment overlay and tree to use
+	tenantName string // --tenant: tenant of a multi-tenant repository
 )
 
 // rootCmd is the top-level command for lzctl.
@@ -40,8 +45,9 @@ Templates generate Terraform configs under platform/ and landing-zones/.
 Compatible with Azure DevOps Services and GitHub Actions CI/CD.
 
 Workflow: init → validate → plan → apply → audit`,
-	SilenceUsage:  true,
-	SilenceErrors: true,
+	SilenceUsage:      true,
+	SilenceErrors:     true,
+	PersistentPreRunE: applyTenantCredentials,
 }
 
 // Execute runs the root command.
@@ -58,6 +64,8 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "simulate actions without changing Azure resources")
 	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "output results as JSON (machine-readable)")
 	rootCmd.PersistentFlags().BoolVar(&ciMode, "ci", false, "strict non-interactive mode (fails when required inputs are missing)")
+	rootCmd.PersistentFlags().StringVar(&envName, "env", "", "environment: merges lzctl.<env>.yaml into lzctl.yaml and uses environments/<env>/")
+	rootCmd.PersistentFlags().StringVar(&tenantName, "tenant", "", "tenant of a multi-tenant repository: uses tenants/<tenant>/ (\"all\" runs validate, drift and audit for every tenant)")
 
 	_ = viper.BindPFlag("repo_root", rootCmd.PersistentFlags().Lookup("repo-root"))
 	_ = viper.BindPFlag("dry_run", rootCmd.PersistentFlags().Lookup("dry-run"))
@@ -88,13 +96,44 @@ func initConfig() {
 	}
 }
 
+// absTenantRoot returns the absolute path to the root of the selected
+// tenant: tenants/<tenant>/ with --tenant, the repo root otherwise. A
+// tenant's lzctl.yaml, overlays and generated tree live there.
+func absTenantRoot() (string, error) {
+	root, err := filepath.Abs(repoRoot)
+	if err != nil {
+		return "", fmt.Errorf("resolving repo root %q: %w", repoRoot, err)
+	}
+	switch {
+	case tenantName == "":
+		return root, nil
+	case tenantName == config.AllTenants:
+		return "", exitcode.Wrap(exitcode.Validation, fmt.Errorf("--tenant %s is supported by validate, drift and audit only", config.AllTenants))
+	case !config.ValidTenantName(tenantName):
+		return "", exitcode.Wrap(exitcode.Validation, fmt.Errorf("invalid --tenant %q: use lowercase letters, digits and dashes", tenantName))
+	}
+	return config.TenantDir(root, tenantName), nil
+}
+
 // absRepoRoot returns the absolute path to the repo root, or an error if
 // the path cannot be resolved. This replaces the previous pattern of
 // `root, _ := filepath.Abs(repoRoot)` which silently ignored errors.
+//
+// With --tenant, it returns the tenant's root, tenants/<tenant>/ (see
+// absTenantRoot). With --env, it returns the environment's tree under that
+// root, environments/<env>/: the generated roots, plans and run logs of an
+// environment live there, while lzctl.yaml, its overlays and the
+// repository-level .lzctl files (see repoFile) stay in the tenant root.
 func absRepoRoot() (string, error) {
-	root, err := filepath.Abs(repoRoot)
+	root, err := absTenantRoot()
 	if err != nil {
-		return "", fmt.Errorf("resolving repo root %q: %w", repoRoot, err)
+		return "", err
+	}
+	if envName != "" {
+		if !config.ValidEnvironmentName(envName) {
+			return "", exitcode.Wrap(exitcode.Validation, fmt.Errorf("invalid --env %q: use lowercase letters, digits and dashes", envName))
+		}
+		return filepath.Join(root, environmentsDir, envName), nil
 	}
 	return root, nil
 }
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kjourdan1/lzctl/internal/runjournal"
+	"github.com/kjourdan1/lzctl/internal/runlog"
+)
+
+// startRunLog attaches the Terraform log archive of a run of command to ctx.
+// An empty runID starts a new run.
+func startRunLog(ctx context.Context, repo, runID, command string) (context.Context, *runlog.Archive) {
+	if runID == "" {
+		runID = runjournal.NewRunID(time.Now())
+	}
+	logs := runlog.New(repo, runID, command)
+	return runlog.NewContext(ctx, logs), logs
+}
+
+// reportRunLog points at the run's Terraform logs once the command is done,
+// when any were written and the run failed or -v was given.
+func reportRunLog(repo string, logs *runlog.Archive, runErr error) {
+	if logs == nil || logs.Len() == 0 || (runErr == nil && verbosity == 0) {
+		return
+	}
+	dir := logs.Dir()
+	if rel, err := filepath.Rel(repo, dir); err == nil {
+		dir = rel
+	}
+	fmt.Fprintf(os.Stderr, "\n📁 Terraform logs: %s\n", filepath.ToSlash(dir))
+}
+
+// runLogStream is where Terraform output is streamed: stderr at -v, nowhere
+// otherwise.
+func runLogStream() io.Writer {
+	if verbosity > 0 {
+		return os.Stderr
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+	"github.com/kjourdan1/lzctl/internal/runlog"
+)
+
+func TestPlanCmd_VerboseStreamsPrefixedOutput(t *testing.T) {
+	useFakeRunner(t, runnertest.New().
+		Reply("plan", "Refreshing state...\nNo changes. Your infrastructure matches the configuration.", 0))
+	repo := initRepoForCommandTests(t)
+
+	_, stderr, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "-v", "--parallelism", "3")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "[connectivity] Refreshing state...\n")
+	assert.Contains(t, stderr, "[identity] Terraform has been successfully initialized!\n")
+	assert.NotContains(t, stderr, "[connectivity] {", "JSON read through Output is not streamed")
+	assert.Contains(t, stderr, "📁 Terraform logs: .lzctl/runs/")
+
+	runs, err := filepath.Glob(filepath.Join(repo, ".lzctl", "runs", "*", runlog.IndexFile))
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	idx, err := runlog.LoadIndex(repo, filepath.Base(filepath.Dir(runs[0])))
+	require.NoError(t, err)
+	assert.Equal(t, "plan", idx.Command)
+	files := map[string]bool{}
+	for _, e := range idx.Logs {
+		files[e.File] = true
+	}
+	assert.True(t, files["connectivity-init.log"])
+	assert.True(t, files["connectivity-plan.log"])
+	assert.True(t, files["connectivity-show.log"])
+}
+
+func TestPlanCmd_QuietByDefault(t *testing.T) {
+	useFakeRunner(t, runnertest.New())
+	repo := initRepoForCommandTests(t)
+
+	_, stderr, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+	assert.NotContains(t, stderr, "[connectivity]")
+	assert.NotContains(t, stderr, "Terraform logs")
+}
+
+func TestApplyCmd_FailedRunKeepsLogsNextToJournal(t *testing.T) {
+	fail := true
+	useFailingApplyTerraform(t, &fail)
+	repo := initRepoForCommandTests(t)
+
+	stdout, stderr, err := executeCommandWithProcessIO(t, "--json", "apply", "--repo-root", repo, "--auto-approve")
+	require.Error(t, err)
+	var first applyJournalPayload
+	require.NoError(t, json.Unmarshal([]byte(stdout), &first))
+	assert.Contains(t, stderr, "📁 Terraform logs: .lzctl/runs/"+first.RunID)
+
+	log, err := os.ReadFile(filepath.Join(repo, ".lzctl", "runs", first.RunID, "governance-apply.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(log), "Error: boom")
+	assert.Contains(t, string(log), "# exit code: 1")
+
+	fail = false
+	_, _, err = executeCommandWithProcessIO(t, "--json", "apply", "--repo-root", repo, "--auto-approve", "--resume", first.RunID)
+	require.NoError(t, err)
+	idx, err := runlog.LoadIndex(repo, first.RunID)
+	require.NoError(t, err)
+	var applies []string
+	for _, e := range idx.Logs {
+		if strings.HasPrefix(e.File, "governance-apply") {
+			applies = append(applies, e.File)
+		}
+	}
+	assert.Equal(t, []string{"governance-apply.log", "governance-apply-2.log"}, applies, "the resumed run keeps the failed attempt's log")
+}
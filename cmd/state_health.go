@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/kjourdan1/lzctl/internal/output"
-	"github.com/kjourdan1/lzctl/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -37,7 +36,7 @@ func runStateHealth(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	mgr := state.NewManager(cfg, &azCLIAdapter{})
+	mgr := newStateManager(cfg)
 	health, err := mgr.CheckHealth()
 	if err != nil {
 		return err
@@ -6,10 +6,19 @@ import (
 	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/state"
 )
 
 const azCLIAdapterTimeout = 120 * time.Second
 
+// newStateManager builds a state manager backed by the local az CLI.
+// Tests replace it to avoid calling Azure.
+var newStateManager = func(cfg *config.LZConfig) *state.Manager {
+	return state.NewManager(cfg, &azCLIAdapter{})
+}
+
 // azCLIAdapter implements state.AzCLIRunner using the local az CLI binary.
 type azCLIAdapter struct{}
 
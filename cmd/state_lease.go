@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/state"
+)
+
+// defaultLockWait is how long plan and apply wait for a state lease held by
+// another run before giving up.
+const defaultLockWait = 10 * time.Minute
+
+// waitForRootLease checks the lease on r's state blob before the root is
+// initialised. While another run holds it, the holder is reported and the
+// check is repeated with backoff for up to wait; a lease whose Terraform
+// lock is older than breakAfter (when set) is broken. When the lease cannot
+// be read (no backend configured, az unavailable), the check is skipped and
+// Terraform's own locking applies.
+func waitForRootLease(ctx context.Context, repo string, r localRoot, wait, breakAfter time.Duration) error {
+	cfg, err := configCache()
+	if err != nil {
+		return nil
+	}
+	key, err := stateKeyFor(repo, r)
+	if err != nil {
+		return nil
+	}
+
+	_, err = newStateManager(cfg).WaitForLease(ctx, key, state.LeaseWait{
+		Timeout:    wait,
+		BreakAfter: breakAfter,
+		OnWait: func(l *state.Lease, delay time.Duration) {
+			outputMu.Lock()
+			defer outputMu.Unlock()
+			color.New(color.FgYellow).Fprintf(os.Stderr, "   ⏳ %-20s state locked by %s; checking again in %s\n",
+				r.Name, l.Holder(), delay.Round(time.Second))
+		},
+		OnBreak: func(l *state.Lease, age time.Duration) {
+			outputMu.Lock()
+			defer outputMu.Unlock()
+			color.New(color.FgYellow, color.Bold).Fprintf(os.Stderr, "   🔓 %-20s breaking stale lease held for %s by %s\n",
+				r.Name, age.Round(time.Second), l.Holder())
+		},
+	})
+
+	var held *state.LeaseHeldError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &held):
+		return exitcode.Wrap(exitcode.Azure, fmt.Errorf("layer %s: %w; retry later, raise --lock-wait, or run lzctl state unlock --key %s once the holder is gone", r.Name, err, key))
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	}
+	if verbosity > 0 {
+		outputMu.Lock()
+		fmt.Fprintf(os.Stderr, "   ℹ️  %s: lease not checked: %v\n", r.Name, err)
+		outputMu.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+)
+
+// useLeasedAz installs a state backend where every state blob carries a
+// Terraform lock taken at lockedAt, until its lease is broken.
+func useLeasedAz(t *testing.T, lockedAt time.Time) *fakeAz {
+	t.Helper()
+	az := newFakeAz()
+	var broken sync.Map
+	az.On("storage blob lease break", func(args []string) (string, error) {
+		broken.Store(argAfter(args, "--blob-name"), true)
+		return "", nil
+	})
+	az.On("storage blob show", func(args []string) (string, error) {
+		if !strings.Contains(argAfter(args, "--query"), "lease") {
+			return "", fmt.Errorf("az %s: not available in tests", strings.Join(args, " "))
+		}
+		if _, ok := broken.Load(argAfter(args, "--name")); ok {
+			return `{"status":"unlocked","state":"broken","metadata":{}}`, nil
+		}
+		info := fmt.Sprintf(`{"ID":"8d2e","Operation":"OperationTypeApply","Who":"runner@pipeline-17","Created":%q}`, lockedAt.Format(time.RFC3339))
+		return fmt.Sprintf(`{"status":"locked","state":"leased","metadata":{"terraformlockid":%q}}`, base64.StdEncoding.EncodeToString([]byte(info))), nil
+	})
+	return useFakeAz(t, az)
+}
+
+func TestPlanCmd_ReportsLeaseHolder(t *testing.T) {
+	tf := useFakeTerraform(t, "No changes.", 0)
+	repo := initRepoForCommandTests(t)
+	useLeasedAz(t, time.Now().Add(-5*time.Minute))
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--repo-root", repo, "--layer", "connectivity", "--no-cache", "--lock-wait", "0")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Azure, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "locked by runner@pipeline-17 (OperationTypeApply, lock 8d2e")
+	assert.Contains(t, err.Error(), "lzctl state unlock --key platform-connectivity.tfstate")
+	assert.Empty(t, tf.Calls("init"), "the root is not initialised while locked")
+}
+
+func TestApplyCmd_BreaksStaleLease(t *testing.T) {
+	tf := useFakeTerraform(t, "No changes.", 0)
+	repo := initRepoForCommandTests(t)
+	az := useLeasedAz(t, time.Now().Add(-3*time.Hour))
+
+	_, stderr, err := executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve", "--lock-wait", "0", "--break-stale-lock", "2h")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "breaking stale lease")
+	assert.Equal(t, []string{"platform-connectivity.tfstate"}, az.FlagValues("storage blob lease break", "--blob-name"))
+	assert.Len(t, tf.Calls("apply"), 1)
+}
+
+func TestApplyCmd_KeepsFreshLease(t *testing.T) {
+	tf := useFakeTerraform(t, "No changes.", 0)
+	repo := initRepoForCommandTests(t)
+	az := useLeasedAz(t, time.Now().Add(-10*time.Minute))
+
+	_, _, err := executeCommandWithProcessIO(t, "apply", "--repo-root", repo, "--layer", "connectivity", "--auto-approve", "--lock-wait", "0", "--break-stale-lock", "2h")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Azure, exitcode.Of(err))
+	assert.Empty(t, az.Calls("storage blob lease break"))
+	assert.Empty(t, tf.Calls("apply"))
+}
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/kjourdan1/lzctl/internal/output"
-	"github.com/kjourdan1/lzctl/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -33,7 +32,7 @@ func runStateList(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	mgr := state.NewManager(cfg, &azCLIAdapter{})
+	mgr := newStateManager(cfg)
 	states, err := mgr.ListStates()
 	if err != nil {
 		return err
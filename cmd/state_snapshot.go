@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/kjourdan1/lzctl/internal/output"
-	"github.com/kjourdan1/lzctl/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -57,7 +56,7 @@ func runStateSnapshot(cmd *cobra.Command, _ []string) error {
 		snapshotTag = fmt.Sprintf("lzctl-%s", time.Now().UTC().Format("20060102-150405"))
 	}
 
-	mgr := state.NewManager(cfg, &azCLIAdapter{})
+	mgr := newStateManager(cfg)
 
 	if snapshotAll {
 		snapshots, err := mgr.SnapshotAll(snapshotTag)
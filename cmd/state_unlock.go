@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	"github.com/kjourdan1/lzctl/internal/output"
-	"github.com/kjourdan1/lzctl/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -55,7 +54,7 @@ func runStateUnlock(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	mgr := state.NewManager(cfg, &azCLIAdapter{})
+	mgr := newStateManager(cfg)
 	if err := mgr.BreakLease(unlockStateKey); err != nil {
 		return err
 	}
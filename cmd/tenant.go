@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kjourdan1/lzctl/internal/azauth"
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	lztemplate "github.com/kjourdan1/lzctl/internal/template"
+)
+
+// applyTenantCredentials switches the process to the credential of the
+// tenant selected with --tenant before the command runs. A tenant without
+// a configuration yet (init) keeps the process credential.
+func applyTenantCredentials(_ *cobra.Command, _ []string) error {
+	if tenantName == "" || tenantName == config.AllTenants {
+		return nil
+	}
+	if _, err := absTenantRoot(); err != nil {
+		return err
+	}
+	if _, err := configCache(); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	_, err := useTenantCredentials(tenantName)
+	return err
+}
+
+// useTenantCredentials resolves the credential of tenant name and sets its
+// variables in the process environment. The returned function restores the
+// previous values. A configuration that cannot be loaded is an error: the
+// tenant's credential is unknown, and the process credential may belong to
+// another tenant.
+func useTenantCredentials(name string) (func(), error) {
+	cfg, err := configCache()
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.Validation, fmt.Errorf("tenant %s: loading config: %w", name, err))
+	}
+	creds, err := azauth.ResolveTenantCredentials(name, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if verbosity > 0 {
+		source := "the process credential"
+		if creds.Source != "" {
+			source = creds.Source
+		}
+		color.New(color.FgCyan).Fprintf(os.Stderr, "🔐 Tenant %s: %s credential from %s\n", name, creds.Method, source)
+	}
+	return setProcessEnv(creds.Env), nil
+}
+
+// setProcessEnv sets env in the process environment, so that terraform and
+// az inherit it, and returns a function restoring the previous values.
+func setProcessEnv(env map[string]string) func() {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type previous struct {
+		value string
+		set   bool
+	}
+	saved := make(map[string]previous, len(keys))
+	for _, k := range keys {
+		v, ok := os.LookupEnv(k)
+		saved[k] = previous{value: v, set: ok}
+		_ = os.Setenv(k, env[k])
+	}
+	return func() {
+		for _, k := range keys {
+			if p := saved[k]; p.set {
+				_ = os.Setenv(k, p.value)
+			} else {
+				_ = os.Unsetenv(k)
+			}
+		}
+	}
+}
+
+// runForTenants wraps the RunE of a command supporting --tenant all: run
+// once for every tenant of the repository, each with its own configuration,
+// tree and credential, then fail with the highest exit code of the tenants
+// that failed. Without --tenant all, run is called once, as is.
+func runForTenants(run func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if tenantName != config.AllTenants {
+			return run(cmd, args)
+		}
+		root, err := filepath.Abs(repoRoot)
+		if err != nil {
+			return fmt.Errorf("resolving repo root %q: %w", repoRoot, err)
+		}
+		tenants, err := config.DiscoverTenants(root)
+		if err != nil {
+			return exitcode.Wrap(exitcode.Validation, err)
+		}
+		if len(tenants) == 0 {
+			return exitcode.Wrap(exitcode.Validation, fmt.Errorf("--tenant all: no tenant found, expected %s/<name>/lzctl.yaml under %s", config.TenantsDir, root))
+		}
+
+		defer func() {
+			tenantName = config.AllTenants
+			invalidateConfigCache()
+		}()
+
+		bold := color.New(color.Bold)
+		red := color.New(color.FgRed)
+		var failed []string
+		code := exitcode.OK
+		for _, name := range tenants {
+			tenantName = name
+			invalidateConfigCache()
+			if !jsonOutput {
+				bold.Fprintf(os.Stderr, "\n🏢 Tenant %s\n\n", name)
+			}
+			if runErr := runTenant(name, cmd, args, run); runErr != nil {
+				red.Fprintf(os.Stderr, "❌ Tenant %s: %v\n", name, runErr)
+				failed = append(failed, name)
+				if c := exitcode.Of(runErr); c > code {
+					code = c
+				}
+			}
+		}
+		if len(failed) > 0 {
+			return exitcode.Wrap(code, fmt.Errorf("%d of %d tenant(s) failed: %s", len(failed), len(tenants), strings.Join(failed, ", ")))
+		}
+		return nil
+	}
+}
+
+// runTenant runs run for tenant name with the tenant's credential.
+func runTenant(name string, cmd *cobra.Command, args []string, run func(*cobra.Command, []string) error) error {
+	restore, err := useTenantCredentials(name)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	return run(cmd, args)
+}
+
+// tenantTreeFiles drops the CI/CD pipelines from the files rendered for a
+// tenant: they would not run from tenants/<tenant>/.
+func tenantTreeFiles(files []lztemplate.RenderedFile) []lztemplate.RenderedFile {
+	kept := make([]lztemplate.RenderedFile, 0, len(files))
+	for _, f := range files {
+		if !isPipelineFile(f.Path) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
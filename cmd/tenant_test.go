@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+)
+
+// initTenantRepoForCommandTests initialises a multi-tenant repo with one
+// project per tenant under tenants/<name>/.
+func initTenantRepoForCommandTests(t *testing.T, tenants ...string) string {
+	t.Helper()
+	repo := t.TempDir()
+	for _, name := range tenants {
+		_, _, err := executeCommand("init", "--tenant", name, "--tenant-id", "00000000-0000-0000-0000-000000000001", "--repo-root", repo)
+		require.NoError(t, err)
+	}
+	return repo
+}
+
+func TestInitCmd_TenantRendersTenantTree(t *testing.T) {
+	repo := initTenantRepoForCommandTests(t, "contoso")
+	tree := filepath.Join(repo, "tenants", "contoso")
+
+	assert.FileExists(t, filepath.Join(tree, "lzctl.yaml"))
+	assert.DirExists(t, filepath.Join(tree, "platform", "connectivity"))
+	assert.NoDirExists(t, filepath.Join(tree, ".github"), "pipelines only run from the repo root")
+	assert.NoFileExists(t, filepath.Join(repo, "lzctl.yaml"))
+
+	fake := useFakeTerraform(t, "Plan: 1 to add, 0 to change, 0 to destroy.", 2)
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--tenant", "contoso", "--repo-root", repo, "--layer", "connectivity")
+	require.NoError(t, err)
+	inits := fake.Calls("init")
+	require.Len(t, inits, 1)
+	assert.Equal(t, filepath.Join(tree, "platform", "connectivity"), inits[0].Dir)
+	assert.FileExists(t, filepath.Join(tree, "platform", "connectivity", "tfplan"))
+}
+
+func TestValidateCmd_TenantAllFansOut(t *testing.T) {
+	useFakeTerraform(t, "Plan: 0 to add, 0 to change, 0 to destroy", 0)
+	repo := initTenantRepoForCommandTests(t, "contoso", "fabrikam")
+
+	stdout, _, err := executeCommandWithProcessIO(t, "validate", "--tenant", "all", "--repo-root", repo, "--json")
+	require.NoError(t, err)
+
+	var tenants []string
+	dec := json.NewDecoder(strings.NewReader(stdout))
+	for {
+		var doc struct {
+			Data struct {
+				Tenant string `json:"tenant"`
+				Config string `json:"config"`
+			} `json:"data"`
+		}
+		if decErr := dec.Decode(&doc); decErr == io.EOF {
+			break
+		} else {
+			require.NoError(t, decErr)
+		}
+		tenants = append(tenants, doc.Data.Tenant)
+		assert.Equal(t, config.TenantConfigPath(repo, doc.Data.Tenant), doc.Data.Config)
+	}
+	assert.Equal(t, []string{"contoso", "fabrikam"}, tenants, "one report per tenant, in name order")
+
+	require.NoError(t, os.WriteFile(config.TenantConfigPath(repo, "contoso"), []byte("apiVersion: [\n"), 0o644))
+	_, _, err = executeCommandWithProcessIO(t, "validate", "--tenant", "all", "--repo-root", repo)
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "1 of 2 tenant(s) failed: contoso")
+}
+
+func TestTenant_AllOnlyForFanOutCommands(t *testing.T) {
+	useFakeTerraform(t, "Plan: 0 to add, 0 to change, 0 to destroy", 0)
+	repo := initTenantRepoForCommandTests(t, "contoso")
+
+	_, _, err := executeCommandWithProcessIO(t, "plan", "--tenant", "all", "--repo-root", repo)
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "supported by validate, drift and audit only")
+
+	_, _, err = executeCommandWithProcessIO(t, "validate", "--tenant", "all", "--repo-root", t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no tenant found")
+
+	_, _, err = executeCommandWithProcessIO(t, "plan", "--tenant", "../contoso", "--repo-root", repo)
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+}
+
+func TestRunForTenants_UsesEachTenantCredential(t *testing.T) {
+	repo := initTenantRepoForCommandTests(t, "contoso", "fabrikam")
+	for _, name := range []string{"AZURE_CLIENT_ID", "AZURE_CLIENT_SECRET", "AZURE_TENANT_ID", "ARM_CLIENT_ID", "ARM_CLIENT_SECRET", "ARM_TENANT_ID",
+		"AZURE_CLIENT_ID_CONTOSO", "AZURE_CLIENT_SECRET_CONTOSO", "AZURE_TENANT_ID_CONTOSO", "AZURE_CONFIG_DIR_CONTOSO",
+		"AZURE_CLIENT_ID_FABRIKAM", "AZURE_CONFIG_DIR_FABRIKAM", "AZURE_TENANT_ID_FABRIKAM", "AZURE_CONFIG_DIR"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("ARM_CLIENT_ID", "shared-client")
+	t.Setenv("AZURE_CLIENT_ID_CONTOSO", "11111111-1111-1111-1111-111111111111")
+	t.Setenv("AZURE_CONFIG_DIR_FABRIKAM", "/home/ops/.azure-fabrikam")
+
+	origRepo, origTenant := repoRoot, tenantName
+	repoRoot, tenantName = repo, config.AllTenants
+	t.Cleanup(func() { repoRoot, tenantName = origRepo, origTenant; invalidateConfigCache() })
+
+	seen := map[string][2]string{}
+	run := runForTenants(func(*cobra.Command, []string) error {
+		seen[tenantName] = [2]string{os.Getenv("ARM_CLIENT_ID"), os.Getenv("AZURE_CONFIG_DIR")}
+		return nil
+	})
+	require.NoError(t, run(&cobra.Command{}, nil))
+
+	assert.Equal(t, map[string][2]string{
+		"contoso":  {"11111111-1111-1111-1111-111111111111", ""},
+		"fabrikam": {"", "/home/ops/.azure-fabrikam"},
+	}, seen)
+	assert.Equal(t, "shared-client", os.Getenv("ARM_CLIENT_ID"), "the process credential is restored")
+	assert.Equal(t, config.AllTenants, tenantName)
+}
+
+func TestRunForTenants_FailsOnUnreadableTenantConfig(t *testing.T) {
+	repo := initTenantRepoForCommandTests(t, "contoso", "fabrikam")
+	require.NoError(t, os.WriteFile(config.TenantConfigPath(repo, "contoso"), []byte("apiVersion: [\n"), 0o644))
+
+	origRepo, origTenant := repoRoot, tenantName
+	repoRoot, tenantName = repo, config.AllTenants
+	t.Cleanup(func() { repoRoot, tenantName = origRepo, origTenant; invalidateConfigCache() })
+
+	var ran []string
+	run := runForTenants(func(*cobra.Command, []string) error {
+		ran = append(ran, tenantName)
+		return nil
+	})
+	err := run(&cobra.Command{}, nil)
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "1 of 2 tenant(s) failed: contoso")
+	assert.Equal(t, []string{"fabrikam"}, ran, "contoso does not run on the process credential")
+}
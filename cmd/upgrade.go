@@ -3,7 +3,6 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
-	"path/filepath"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -44,9 +43,9 @@ func init() {
 func runUpgrade(cmd *cobra.Command, args []string) error {
 	output.Init(verbosity > 0, jsonOutput)
 
-	absRoot, err := filepath.Abs(repoRoot)
+	absRoot, err := absRepoRoot()
 	if err != nil {
-		return fmt.Errorf("resolving repo root: %w", err)
+		return err
 	}
 
 	// Scan for module pins.
@@ -21,10 +21,16 @@ var validateCmd = &cobra.Command{
 
   1. lzctl.yaml schema validation
   2. Cross-validation (referenced files, consistency checks)
-  3. Terraform validate per platform layer (if terraform is installed)
+  3. Output contract: layer outputs read through terraform_remote_state
+     must still be declared, with the type recorded in the output registry
+     (.lzctl/outputs.json, see 'lzctl outputs') and the saved plans
+  4. Terraform validate per platform layer (if terraform is installed)
 
-Used in CI as the first gate before plan.`,
-	RunE: runValidate,
+Used in CI as the first gate before plan.
+
+With --tenant all, validates every tenant of a multi-tenant repository in
+turn (tenants/<name>/lzctl.yaml) and fails if any of them fails.`,
+	RunE: runForTenants(runValidate),
 }
 
 var (
@@ -80,7 +86,24 @@ func runValidate(cmd *cobra.Command, args []string) error {
 		checks = append(checks, check{Name: c.Name, Status: c.Status, Message: c.Message})
 	}
 
-	if err := ensureTerraformInstalled(); err != nil {
+	if roots, rootsErr := resolveLocalRoots(root, rootSelection{}); rootsErr == nil {
+		findings, contractErr := checkOutputContract(root, roots)
+		switch {
+		case contractErr != nil:
+			checks = append(checks, check{Name: "output-contract", Status: "error", Message: contractErr.Error()})
+		case len(findings) == 0:
+			checks = append(checks, check{Name: "output-contract", Status: "pass", Message: "referenced layer outputs are declared"})
+		}
+		for _, f := range findings {
+			status := "warning"
+			if contractBreaking(f) {
+				status = "error"
+			}
+			checks = append(checks, check{Name: "output-contract", Status: status, Message: f.Message()})
+		}
+	}
+
+	if tf, err := resolveRunner(cmd.Context(), root); err != nil {
 		checks = append(checks, check{Name: "terraform", Status: "warning", Message: err.Error()})
 	} else {
 		layers, layerErr := resolveLocalLayers(root, "")
@@ -94,11 +117,11 @@ func runValidate(cmd *cobra.Command, args []string) error {
 					continue
 				}
 
-				if _, initErr := runTerraformCmd(cmd.Context(), dir, "init", "-backend=false", "-input=false", "-no-color"); initErr != nil {
+				if _, initErr := tf.Run(cmd.Context(), dir, "init", "-backend=false", "-input=false", "-no-color"); initErr != nil {
 					checks = append(checks, check{Name: "terraform-" + layer, Status: "error", Message: "terraform init failed"})
 					continue
 				}
-				if _, valErr := runTerraformCmd(cmd.Context(), dir, "validate", "-no-color"); valErr != nil {
+				if _, valErr := tf.Run(cmd.Context(), dir, "validate", "-no-color"); valErr != nil {
 					checks = append(checks, check{Name: "terraform-" + layer, Status: "error", Message: "terraform validate failed"})
 				} else {
 					checks = append(checks, check{Name: "terraform-" + layer, Status: "pass", Message: "terraform validate passed"})
@@ -107,7 +130,7 @@ func runValidate(cmd *cobra.Command, args []string) error {
 				// terraform test — only when .tftest.hcl is present (generated when testing.enabled: true)
 				testFile := filepath.Join(dir, "testing.tftest.hcl")
 				if fileExistsLocal(testFile) {
-					if testOut, testErr := runTerraformCmd(cmd.Context(), dir, "test", "-no-color"); testErr != nil {
+					if testOut, testErr := tf.Run(cmd.Context(), dir, "test", "-no-color"); testErr != nil {
 						if strings.Contains(testOut, "Unrecognized command") || strings.Contains(testOut, "unrecognized command") {
 							checks = append(checks, check{Name: "tftest-" + layer, Status: "warning", Message: "terraform test requires Terraform >= 1.6 (skipped)"})
 						} else {
@@ -133,12 +156,16 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	}
 
 	if jsonOutput {
-		output.JSON(map[string]interface{}{
+		payload := map[string]interface{}{
 			"config":   configPath,
 			"checks":   checks,
 			"errors":   errorsCount,
 			"warnings": warningsCount,
-		})
+		}
+		if tenantName != "" {
+			payload["tenant"] = tenantName
+		}
+		output.JSON(payload)
 	} else {
 		fmt.Fprintf(os.Stderr, "🔎 Validating: %s\n\n", configPath)
 		for _, c := range checks {
@@ -26,6 +26,9 @@ Examples:
   lzctl workload add --name app-frontend --archetype corp \
     --address-space 10.1.0.0/24 --tag env=prod --tag team=frontend`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := refuseEnvWrite("lzctl workload add"); err != nil {
+			return err
+		}
 		name, _ := cmd.Flags().GetString("name")
 		archetype, _ := cmd.Flags().GetString("archetype")
 		addressSpace, _ := cmd.Flags().GetString("address-space")
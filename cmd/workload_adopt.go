@@ -19,6 +19,9 @@ Examples:
   lzctl workload adopt --name legacy-app --subscription <sub-id>
   lzctl workload adopt --name legacy-app --subscription <sub-id> --archetype corp`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := refuseEnvWrite("lzctl workload adopt"); err != nil {
+			return err
+		}
 		name, _ := cmd.Flags().GetString("name")
 		subscriptionID, _ := cmd.Flags().GetString("subscription")
 		archetype, _ := cmd.Flags().GetString("archetype")
@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+	"github.com/kjourdan1/lzctl/internal/plansummary"
+	"github.com/kjourdan1/lzctl/internal/state"
+	lztemplate "github.com/kjourdan1/lzctl/internal/template"
+)
+
+var workloadDecommissionCmd = &cobra.Command{
+	Use:   "decommission",
+	Short: "Destroy a landing zone and remove it from the repository",
+	Long: `Tears down a landing zone and everything lzctl generated for it:
+
+  1. snapshots the state of the blueprint and landing zone roots,
+  2. plans their destruction (blueprint first) and shows what goes away,
+  3. asks for the landing zone name to be typed as confirmation,
+  4. archives their state, as it is before the destroy, under
+     decommissioned/<timestamp>/,
+  5. destroys the blueprint, detaches the hub peering of the landing zone,
+     then destroys the landing zone root,
+  6. removes landing-zones/<name>/, the lzctl.yaml entry and the zone from
+     the pipeline matrix.
+
+The emptied state blobs are kept with their snapshots.
+
+Use --dry-run to stop after the destroy plans. In CI mode, pass the zone
+name again with --confirm instead of typing it. The Azure subscription
+itself is not cancelled.
+
+Examples:
+  lzctl workload decommission --name old-app
+  lzctl workload decommission --name old-app --ci --confirm old-app`,
+	RunE: runWorkloadDecommission,
+}
+
+// decommissionPlanFile is the destroy plan of a decommissioned root.
+const decommissionPlanFile = "tfplan.decommission"
+
+// hubPeeringAddress is the spoke-to-hub peering of generated landing zones.
+const hubPeeringAddress = "azurerm_virtual_network_peering.to_hub"
+
+// decommissionEntry is the decommission result of one root.
+type decommissionEntry struct {
+	Layer    string `json:"layer"`
+	Kind     string `json:"kind"`
+	StateKey string `json:"stateKey"`
+	Snapshot string `json:"snapshot,omitempty"`
+	Archived string `json:"archived,omitempty"`
+	Destroy  int    `json:"destroy"`
+	Status   string `json:"status"` // planned | dry-run | destroyed | failed
+
+	Resources []plansummary.ResourceChange `json:"resources,omitempty"`
+
+	root     localRoot
+	hasState bool
+}
+
+func runWorkloadDecommission(cmd *cobra.Command, args []string) (err error) {
+	if err := refuseEnvWrite("lzctl workload decommission"); err != nil {
+		return err
+	}
+	name, _ := cmd.Flags().GetString("name")
+	confirm, _ := cmd.Flags().GetString("confirm")
+
+	root, err := absRepoRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := configCache()
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("load config: %w", err))
+	}
+	zoneIndex := -1
+	for i, lz := range cfg.Spec.LandingZones {
+		if lz.Name == name {
+			zoneIndex = i
+			break
+		}
+	}
+	slug := lztemplate.Slugify(name)
+	zoneDir := filepath.Join(root, "landing-zones", slug)
+	if zoneIndex < 0 && !dirExists(zoneDir) {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("landing zone %q not found in lzctl.yaml or under %s", name, filepath.Join(root, "landing-zones")))
+	}
+	if !dryRun && effectiveCIMode() && confirm != name {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("--ci mode requires --confirm %s for decommission", name))
+	}
+	connected := zoneIndex < 0 || cfg.Spec.LandingZones[zoneIndex].Connected
+
+	var entries []*decommissionEntry
+	if dirExists(zoneDir) {
+		roots := zoneRoots(root, slug)
+		// The blueprint runs inside the landing zone: destroy it first.
+		for i := len(roots) - 1; i >= 0; i-- {
+			key, keyErr := stateKeyFor(root, roots[i])
+			if keyErr != nil {
+				return exitcode.Wrap(exitcode.Validation, keyErr)
+			}
+			entries = append(entries, &decommissionEntry{Layer: roots[i].Name, Kind: roots[i].Kind, StateKey: key, root: roots[i]})
+		}
+	}
+
+	ctx := cmd.Context()
+	var tf orchestrator.Runner
+	if len(entries) > 0 {
+		if tf, err = resolveRunner(ctx, root); err != nil {
+			return exitcode.Wrap(exitcode.Validation, err)
+		}
+	}
+	ctx, logs := startRunLog(ctx, root, "", "decommission")
+	defer func() { reportRunLog(root, logs, err) }()
+
+	bold := color.New(color.Bold)
+	bold.Fprintf(os.Stderr, "🗑️  Decommissioning landing zone %s\n\n", name)
+
+	// 1. Snapshot the state of every root that has one.
+	mgr := newStateManager(cfg)
+	stamp := time.Now().UTC().Format("20060102-150405")
+	for _, e := range entries {
+		version, verErr := mgr.BlobVersion(e.StateKey)
+		if verErr != nil {
+			return exitcode.Wrap(exitcode.Azure, verErr)
+		}
+		e.hasState = version != state.NoBlobVersion
+		if !e.hasState || dryRun {
+			continue
+		}
+		snap, snapErr := mgr.CreateSnapshot(e.StateKey, "decommission-"+slug+"-"+stamp)
+		if snapErr != nil {
+			return exitcode.Wrap(exitcode.Azure, snapErr)
+		}
+		e.Snapshot = snap.VersionID
+		fmt.Fprintf(os.Stderr, "   📸 %-24s state snapshot %s\n", e.Layer, snap.VersionID)
+	}
+
+	// 2. Plan the destruction, blueprint first.
+	for _, e := range entries {
+		if err := waitForRootLease(ctx, root, e.root, defaultLockWait, 0); err != nil {
+			return err
+		}
+		if err := planDecommission(ctx, tf, root, e); err != nil {
+			return err
+		}
+		printDecommissionEntry(e)
+	}
+	fmt.Fprintln(os.Stderr)
+
+	if dryRun {
+		for _, e := range entries {
+			e.Status = "dry-run"
+		}
+		printDecommissionJSON(name, entries, nil)
+		color.New(color.FgYellow, color.Bold).Fprintln(os.Stderr, "⚡ [DRY-RUN] Decommission simulation complete. Nothing was destroyed or removed.")
+		return nil
+	}
+
+	// 3. Typed confirmation.
+	if confirm != name {
+		color.New(color.FgRed, color.Bold).Fprintf(os.Stderr, "⚠️  This destroys landing zone %s and removes it from the repository.\n", name)
+		fmt.Fprintf(os.Stderr, "\n   Type the landing zone name to proceed: ")
+		reader := bufio.NewReader(os.Stdin)
+		answer, _ := reader.ReadString('\n')
+		if strings.TrimSpace(answer) != name {
+			fmt.Fprintln(os.Stderr, "\n❌ Decommission canceled.")
+			return nil
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+
+	// 4. Archive the state before anything is destroyed.
+	for _, e := range entries {
+		if !e.hasState {
+			continue
+		}
+		archived, archiveErr := mgr.ArchiveState(e.StateKey, state.DecommissionedPrefix+stamp+"/")
+		if archiveErr != nil {
+			printDecommissionJSON(name, entries, nil)
+			return exitcode.Wrap(exitcode.Azure, fmt.Errorf("layer %s: %w", e.Layer, archiveErr))
+		}
+		e.Archived = archived
+		fmt.Fprintf(os.Stderr, "   🗄️  %-24s state archived to %s\n", e.Layer, e.Archived)
+	}
+	fmt.Fprintln(os.Stderr)
+
+	// 5. Destroy the blueprint, then the landing zone once it is detached
+	// from the hub.
+	bold.Fprintf(os.Stderr, "🔥 Destroying\n\n")
+	for _, e := range entries {
+		dir := filepath.Join(root, e.root.Dir)
+		if e.Kind == rootKindLandingZone && connected && e.hasState {
+			if out, runErr := tf.Run(ctx, dir, "apply", "-destroy", "-input=false", "-no-color", "-auto-approve", "-target="+hubPeeringAddress); runErr != nil {
+				e.Status = "failed"
+				printDecommissionJSON(name, entries, nil)
+				return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: detaching hub peering failed (output: %s): %w", e.Layer, out, runErr))
+			}
+			fmt.Fprintf(os.Stderr, "   🔌 %-24s hub peering detached\n", e.Layer)
+			// The saved destroy plan predates the detach: plan again.
+			if err := planDecommission(ctx, tf, root, e); err != nil {
+				return err
+			}
+		}
+		if out, runErr := tf.Run(ctx, dir, "apply", "-input=false", "-no-color", decommissionPlanFile); runErr != nil {
+			e.Status = "failed"
+			printDecommissionJSON(name, entries, nil)
+			return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform destroy failed (output: %s): %w", e.Layer, out, runErr))
+		}
+		e.Status = "destroyed"
+		color.New(color.FgGreen).Fprintf(os.Stderr, "   ✅ %-24s destroyed\n", e.Layer)
+	}
+
+	// 6. Remove the generated files, the config entry and the matrix entry.
+	removed, err := removeDecommissionedZone(cfg, root, zoneIndex, zoneDir)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Generic, err)
+	}
+	for _, p := range removed {
+		fmt.Fprintf(os.Stderr, "   🧹 removed %s\n", p)
+	}
+	printDecommissionJSON(name, entries, removed)
+
+	fmt.Fprintln(os.Stderr)
+	color.New(color.FgGreen, color.Bold).Fprintf(os.Stderr, "✅ Landing zone %s decommissioned. The Azure subscription has NOT been cancelled.\n", name)
+	return nil
+}
+
+// planDecommission plans the destruction of e's root into
+// decommissionPlanFile, records the resources it destroys and checks its
+// subscription scope.
+func planDecommission(ctx context.Context, tf orchestrator.Runner, repo string, e *decommissionEntry) error {
+	dir := filepath.Join(repo, e.root.Dir)
+	if initOut, err := tf.Run(ctx, dir, terraformInitArgs(dir, e.StateKey)...); err != nil {
+		return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform init failed (output: %s): %w", e.Layer, initOut, err))
+	}
+	out, err := tf.Run(ctx, dir, "plan", "-destroy", "-input=false", "-detailed-exitcode", "-no-color", "-out="+decommissionPlanFile)
+	if err != nil && strings.Contains(out, "Error:") {
+		return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: terraform destroy plan failed (output: %s): %w", e.Layer, out, err))
+	}
+	s := summarizePlan(ctx, tf, dir, decommissionPlanFile, "", out)
+	if s.Add > 0 || s.Change > 0 {
+		return exitcode.Wrap(exitcode.Terraform, fmt.Errorf("layer %s: destroy plan would also add %d and change %d resource(s); aborting", e.Layer, s.Add, s.Change))
+	}
+	e.Destroy, e.Resources, e.Status = s.Destroy, s.Resources, "planned"
+	return verifyRootScope(ctx, tf, repo, e.root, decommissionPlanFile)
+}
+
+// removeDecommissionedZone deletes the landing zone's directory, drops it
+// from lzctl.yaml and regenerates the pipeline matrix. It returns what was
+// removed, relative to repo.
+func removeDecommissionedZone(cfg *config.LZConfig, repo string, zoneIndex int, zoneDir string) ([]string, error) {
+	var removed []string
+	if dirExists(zoneDir) {
+		if err := os.RemoveAll(zoneDir); err != nil {
+			return removed, fmt.Errorf("removing %s: %w", zoneDir, err)
+		}
+		rel, _ := filepath.Rel(repo, zoneDir)
+		removed = append(removed, filepath.ToSlash(rel)+"/")
+	}
+	if zoneIndex < 0 {
+		return removed, nil
+	}
+	cfg.Spec.LandingZones = append(cfg.Spec.LandingZones[:zoneIndex:zoneIndex], cfg.Spec.LandingZones[zoneIndex+1:]...)
+	if err := config.Save(cfg, localConfigPath()); err != nil {
+		return removed, fmt.Errorf("save config: %w", err)
+	}
+	invalidateConfigCache()
+	removed = append(removed, "lzctl.yaml: spec.landingZones entry")
+	if _, err := lztemplate.WriteLandingZoneMatrix(cfg, repo); err != nil {
+		return removed, fmt.Errorf("update landing-zone matrix: %w", err)
+	}
+	updater, err := lztemplate.NewPipelineUpdater()
+	if err != nil {
+		return removed, fmt.Errorf("create pipeline updater: %w", err)
+	}
+	if _, err := updater.UpdatePipelines(cfg, repo); err != nil {
+		return removed, fmt.Errorf("update pipelines: %w", err)
+	}
+	return removed, nil
+}
+
+func printDecommissionEntry(e *decommissionEntry) {
+	suffix := ""
+	if !e.hasState {
+		suffix = " (no state)"
+	}
+	fmt.Fprintf(os.Stderr, "   📝 %-24s -%d%s\n", e.Layer, e.Destroy, suffix)
+	for _, rc := range e.Resources {
+		fmt.Fprintf(os.Stderr, "      %-8s %s\n", rc.Action, rc.Address)
+	}
+}
+
+func printDecommissionJSON(name string, entries []*decommissionEntry, removed []string) {
+	if !jsonOutput {
+		return
+	}
+	status := "ok"
+	for _, e := range entries {
+		if e.Status == "failed" {
+			status = "failed"
+		}
+	}
+	if dryRun {
+		status = "dry-run"
+	}
+	if entries == nil {
+		entries = []*decommissionEntry{}
+	}
+	if removed == nil {
+		removed = []string{}
+	}
+	data, _ := json.MarshalIndent(map[string]interface{}{
+		"status":  status,
+		"zone":    name,
+		"roots":   entries,
+		"removed": removed,
+	}, "", "  ")
+	fmt.Fprintln(os.Stdout, string(data))
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+)
+
+const decommissionShowJSON = `{"format_version":"1.2","resource_changes":[` +
+	`{"address":"azurerm_resource_group.zone","type":"azurerm_resource_group","change":{"actions":["delete"]}},` +
+	`{"address":"azurerm_virtual_network_peering.to_hub[0]","type":"azurerm_virtual_network_peering","change":{"actions":["delete"]}}]}`
+
+// setupDecommissionRepo creates a repo with the corp-prod and online-dev
+// landing zones, a blueprint on corp-prod, and a state backend in which
+// every root has been applied.
+func setupDecommissionRepo(t *testing.T) (string, *runnertest.Fake, *fakeAz) {
+	t.Helper()
+	tf := useFakeRunner(t, runnertest.New().
+		Reply("plan", "Plan: 0 to add, 0 to change, 2 to destroy.", 2).
+		Reply("show", decommissionShowJSON, 0))
+	repo := t.TempDir()
+	_, _, err := executeCommand("init", "--from-file", writeInitInputFixture(t, t.TempDir()), "--repo-root", repo)
+	require.NoError(t, err)
+	_, _, err = executeCommand("workload", "adopt", "--name", "corp-prod", "--subscription", "11111111-1111-4111-8111-111111111111", "--address-space", "10.10.0.0/16", "--repo-root", repo)
+	require.NoError(t, err)
+	_, _, err = executeCommand("workload", "adopt", "--name", "online-dev", "--subscription", "22222222-2222-4222-8222-222222222222", "--address-space", "10.20.0.0/16", "--repo-root", repo)
+	require.NoError(t, err)
+	_, _, err = executeCommand("add-blueprint", "--repo-root", repo, "--landing-zone", "corp-prod", "--type", "paas-secure")
+	require.NoError(t, err)
+
+	az := useFakeAz(t, newFakeAz())
+	return repo, tf, az
+}
+
+func TestWorkloadDecommission_DryRunOnlyPlans(t *testing.T) {
+	repo, tf, az := setupDecommissionRepo(t)
+
+	stdout, stderr, err := executeCommandWithProcessIO(t, "--dry-run", "--json", "workload", "decommission", "--repo-root", repo, "--name", "corp-prod")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "[DRY-RUN]")
+
+	var payload struct {
+		Status string `json:"status"`
+		Roots  []struct {
+			Layer   string `json:"layer"`
+			Destroy int    `json:"destroy"`
+			Status  string `json:"status"`
+		} `json:"roots"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.Equal(t, "dry-run", payload.Status)
+	require.Len(t, payload.Roots, 2)
+	assert.Equal(t, "lz:corp-prod-blueprint", payload.Roots[0].Layer, "the blueprint is destroyed first")
+	assert.Equal(t, "lz:corp-prod", payload.Roots[1].Layer)
+	assert.Equal(t, 2, payload.Roots[1].Destroy)
+
+	for _, c := range tf.Calls("plan") {
+		assert.Contains(t, c.Args, "-destroy")
+	}
+	assert.Empty(t, tf.Calls("apply"))
+	assert.Empty(t, az.Calls("storage blob snapshot"))
+	assert.Empty(t, az.Calls("storage blob copy"))
+	assert.DirExists(t, filepath.Join(repo, "landing-zones", "corp-prod"))
+}
+
+func TestWorkloadDecommission_TearsDownAndRemovesZone(t *testing.T) {
+	repo, tf, az := setupDecommissionRepo(t)
+	const zoneKey, blueprintKey = "landing-zones-corp-prod.tfstate", "landing-zones-corp-prod-blueprint.tfstate"
+	az.SetVersion(zoneKey, "zone-applied")
+	az.SetVersion(blueprintKey, "blueprint-applied")
+	// A destroy writes an emptied state.
+	tf.On("apply", func(c runnertest.Call) (string, error) {
+		key := zoneKey
+		if c.Root() == "blueprint" {
+			key = blueprintKey
+		}
+		az.SetVersion(key, "destroyed-"+c.Root())
+		return "Apply complete!", nil
+	})
+
+	_, stderr, err := executeCommandWithProcessIO(t, "workload", "decommission", "--repo-root", repo, "--name", "corp-prod", "--confirm", "corp-prod")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "hub peering detached")
+
+	applies := tf.Calls("apply")
+	require.Len(t, applies, 3)
+	assert.Equal(t, "blueprint", applies[0].Root(), "the blueprint is destroyed first")
+	assert.Contains(t, applies[0].Args, decommissionPlanFile)
+	assert.Equal(t, "corp-prod", applies[1].Root())
+	assert.Contains(t, applies[1].Args, "-target="+hubPeeringAddress, "then the hub peering is detached")
+	assert.Equal(t, "corp-prod", applies[2].Root())
+	assert.Contains(t, applies[2].Args, decommissionPlanFile)
+
+	assert.Len(t, az.Calls("storage blob snapshot"), 2)
+	archives := az.FlagValues("storage blob copy start", "--destination-blob")
+	require.Len(t, archives, 2)
+	assert.Equal(t, "zone-applied", az.Version(archiveOf(archives, zoneKey)), "the archive holds the state from before the destroy")
+	assert.Equal(t, "blueprint-applied", az.Version(archiveOf(archives, blueprintKey)))
+	assert.Empty(t, az.Calls("storage blob delete"))
+	assert.Equal(t, 1, az.Snapshots(zoneKey), "snapshots are kept")
+	assert.Equal(t, 1, az.Snapshots(blueprintKey))
+
+	assert.NoDirExists(t, filepath.Join(repo, "landing-zones", "corp-prod"))
+	cfg, err := config.Load(filepath.Join(repo, "lzctl.yaml"))
+	require.NoError(t, err)
+	require.Len(t, cfg.Spec.LandingZones, 1)
+	assert.Equal(t, "online-dev", cfg.Spec.LandingZones[0].Name)
+	matrix, err := os.ReadFile(filepath.Join(repo, ".lzctl", "zone-matrix.json"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(matrix), "corp-prod")
+	assert.Contains(t, string(matrix), "online-dev")
+}
+
+// archiveOf returns the archived blob of key among archives.
+func archiveOf(archives []string, key string) string {
+	for _, a := range archives {
+		if strings.HasPrefix(a, "decommissioned/") && strings.HasSuffix(a, "/"+key) {
+			return a
+		}
+	}
+	return ""
+}
+
+func TestWorkloadDecommission_CIRequiresConfirm(t *testing.T) {
+	repo, tf, _ := setupDecommissionRepo(t)
+
+	_, _, err := executeCommandWithProcessIO(t, "--ci", "workload", "decommission", "--repo-root", repo, "--name", "corp-prod", "--confirm", "corp")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "--confirm corp-prod")
+	assert.Empty(t, tf.Calls("plan"))
+	assert.DirExists(t, filepath.Join(repo, "landing-zones", "corp-prod"))
+}
+
+func TestWorkloadDecommission_UnknownZone(t *testing.T) {
+	repo, _, _ := setupDecommissionRepo(t)
+
+	_, _, err := executeCommandWithProcessIO(t, "workload", "decommission", "--repo-root", repo, "--name", "missing", "--confirm", "missing")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+}
+
+func TestWorkloadDecommission_BlocksOutOfScopePlan(t *testing.T) {
+	repo, tf, az := setupDecommissionRepo(t)
+	az.SetVersion("landing-zones-corp-prod.tfstate", "zone-applied")
+	// The zone's provider points at a subscription lzctl.yaml does not declare.
+	tf.Reply("show", `{"format_version":"1.2",`+
+		`"configuration":{"provider_config":{"azurerm":{"expressions":{"subscription_id":{"constant_value":"`+rogueSubscription+`"}}}}},`+
+		`"resource_changes":[{"address":"azurerm_resource_group.zone","type":"azurerm_resource_group","change":{"actions":["delete"]}}]}`, 0)
+
+	_, _, err := executeCommandWithProcessIO(t, "workload", "decommission", "--repo-root", repo, "--name", "corp-prod", "--confirm", "corp-prod")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.SecurityBlock, exitcode.Of(err))
+	assert.Contains(t, err.Error(), rogueSubscription)
+	assert.Empty(t, tf.Calls("apply"))
+	assert.Empty(t, az.Calls("storage blob copy"), "nothing is archived")
+	assert.DirExists(t, filepath.Join(repo, "landing-zones", "corp-prod"))
+}
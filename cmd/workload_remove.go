@@ -15,11 +15,15 @@ var workloadRemoveCmd = &cobra.Command{
 	Long: `Removes a landing zone entry from lzctl.yaml. This does NOT delete the
 Azure subscription — it only removes the definition from the config file.
 
-To clean up Azure resources, run plan + apply after removing.
+To destroy the landing zone's resources as well, use
+'lzctl workload decommission' instead.
 
 Examples:
   lzctl workload remove --name old-app`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := refuseEnvWrite("lzctl workload remove"); err != nil {
+			return err
+		}
 		name, _ := cmd.Flags().GetString("name")
 
 		cfg, err := configCache()
@@ -0,0 +1,541 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+	"github.com/kjourdan1/lzctl/internal/outputs"
+	"github.com/kjourdan1/lzctl/internal/refactor"
+	"github.com/kjourdan1/lzctl/internal/state"
+	lztemplate "github.com/kjourdan1/lzctl/internal/template"
+)
+
+var workloadRenameCmd = &cobra.Command{
+	Use:   "rename",
+	Short: "Rename a landing zone without recreating its resources",
+	Long: `Renames a landing zone in lzctl.yaml and everything derived from its name,
+so that the next plan of the landing zone is a no-op:
+
+  1. moves the state blobs of the landing zone and its blueprint to the
+     keys of the new name (landing-zones-<new>.tfstate),
+  2. moves landing-zones/<name>/ to landing-zones/<new-name>/,
+  3. rewrites the blueprint backend key and the terraform_remote_state data
+     sources of every root that reads the moved states,
+  4. renames resources and modules named after the landing zone and records
+     a moved block for each in moved.tf,
+  5. renames the zone in lzctl.yaml, the output registry and the pipeline
+     matrix.
+
+Azure resource names (resource group, virtual network...) are not changed:
+renaming them would replace the resources.
+
+Use --dry-run to print what would change without touching anything.
+
+Examples:
+  lzctl workload rename --name app --new-name shop
+  lzctl workload rename --name app --new-name shop --dry-run --json`,
+	RunE: runWorkloadRename,
+}
+
+// renameEntry is the rename result of one root.
+type renameEntry struct {
+	Layer        string            `json:"layer"`
+	NewLayer     string            `json:"newLayer"`
+	Kind         string            `json:"kind"`
+	StateKey     string            `json:"stateKey"`
+	NewStateKey  string            `json:"newStateKey"`
+	StateMoved   bool              `json:"stateMoved"`
+	OldStateKept bool              `json:"oldStateKept,omitempty"` // the old blob has snapshots and was kept
+	Moves        []refactor.Result `json:"moves"`
+	Status       string            `json:"status"` // planned | dry-run | renamed | failed
+
+	root     localRoot
+	newRoot  localRoot
+	hasState bool
+	moves    []refactor.Move
+}
+
+// keyChanged reports whether the root's state key follows the zone name.
+func (e *renameEntry) keyChanged() bool {
+	return e.StateKey != e.NewStateKey
+}
+
+func runWorkloadRename(cmd *cobra.Command, args []string) error {
+	if err := refuseEnvWrite("lzctl workload rename"); err != nil {
+		return err
+	}
+	name, _ := cmd.Flags().GetString("name")
+	newName, _ := cmd.Flags().GetString("new-name")
+	if !kebabCaseRegex.MatchString(newName) {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("--new-name must be kebab-case (lowercase alphanumeric with hyphens): %q", newName))
+	}
+
+	root, err := absRepoRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := configCache()
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("load config: %w", err))
+	}
+	zoneIndex := -1
+	for i, lz := range cfg.Spec.LandingZones {
+		if lz.Name == name {
+			zoneIndex = i
+		}
+		if lz.Name == newName {
+			return exitcode.Wrap(exitcode.Validation, fmt.Errorf("landing zone %q already exists in lzctl.yaml", newName))
+		}
+	}
+	if zoneIndex < 0 {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("landing zone %q not found in lzctl.yaml", name))
+	}
+	slug, newSlug := lztemplate.Slugify(name), lztemplate.Slugify(newName)
+	if slug == newSlug {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("%q and %q map to the same directory landing-zones/%s", name, newName, slug))
+	}
+	zoneDir := filepath.Join(root, "landing-zones", slug)
+	newZoneDir := filepath.Join(root, "landing-zones", newSlug)
+	if dirExists(newZoneDir) {
+		return exitcode.Wrap(exitcode.Validation, fmt.Errorf("%s already exists", newZoneDir))
+	}
+
+	entries, err := planZoneRename(cfg, root, zoneIndex, newSlug)
+	if err != nil {
+		return err
+	}
+
+	// State keys of the zone are read by other roots through
+	// terraform_remote_state: find the files to update.
+	referrers := map[string][]string{}
+	for _, e := range entries {
+		if !e.keyChanged() {
+			continue
+		}
+		files, refErr := rewriteStateKeyReferences(root, e.StateKey, e.NewStateKey, false)
+		if refErr != nil {
+			return exitcode.Wrap(exitcode.Validation, refErr)
+		}
+		for dir, names := range files {
+			referrers[dir] = append(referrers[dir], names...)
+		}
+	}
+
+	// The moved states must not overwrite existing blobs.
+	mgr := newStateManager(cfg)
+	for _, e := range entries {
+		if !e.keyChanged() {
+			continue
+		}
+		version, verErr := mgr.BlobVersion(e.StateKey)
+		if verErr != nil {
+			return exitcode.Wrap(exitcode.Azure, verErr)
+		}
+		e.hasState = version != state.NoBlobVersion
+		if !e.hasState {
+			continue
+		}
+		if version, verErr = mgr.BlobVersion(e.NewStateKey); verErr != nil {
+			return exitcode.Wrap(exitcode.Azure, verErr)
+		}
+		if version != state.NoBlobVersion {
+			return exitcode.Wrap(exitcode.Validation, fmt.Errorf("layer %s: state blob %s already exists", e.Layer, e.NewStateKey))
+		}
+	}
+
+	bold := color.New(color.Bold)
+	bold.Fprintf(os.Stderr, "✏️  Renaming landing zone %s to %s\n\n", name, newName)
+	for _, e := range entries {
+		printRenameEntry(e)
+	}
+	dirs := make([]string, 0, len(referrers))
+	for dir := range referrers {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		fmt.Fprintf(os.Stderr, "   🔗 %-24s state key references in %s\n", dir, strings.Join(referrers[dir], ", "))
+	}
+	fmt.Fprintln(os.Stderr)
+
+	if dryRun {
+		for _, e := range entries {
+			e.Status = "dry-run"
+		}
+		printRenameJSON(name, newName, entries, nil)
+		color.New(color.FgYellow, color.Bold).Fprintln(os.Stderr, "⚡ [DRY-RUN] Rename simulation complete. Nothing was moved or written.")
+		return nil
+	}
+
+	ctx := cmd.Context()
+	for _, e := range entries {
+		if e.hasState && e.keyChanged() {
+			if err := waitForRootLease(ctx, root, e.root, defaultLockWait, 0); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 1. Move the state blobs.
+	for _, e := range entries {
+		if !e.hasState || !e.keyChanged() {
+			continue
+		}
+		moveErr := mgr.MoveState(e.StateKey, e.NewStateKey)
+		if moveErr != nil && !errors.Is(moveErr, state.ErrSnapshotsPresent) {
+			e.Status = "failed"
+			printRenameJSON(name, newName, entries, nil)
+			return exitcode.Wrap(exitcode.Azure, fmt.Errorf("layer %s: %w", e.Layer, moveErr))
+		}
+		e.StateMoved = true
+		fmt.Fprintf(os.Stderr, "   📦 %-24s state moved to %s\n", e.Layer, e.NewStateKey)
+		if moveErr != nil {
+			e.OldStateKept = true
+			color.New(color.FgYellow).Fprintf(os.Stderr, "   ⚠️  %-24s %s kept with its snapshots; delete it once they are no longer needed\n", e.Layer, e.StateKey)
+		}
+	}
+
+	// 2. to 4. Move the directory and rewrite the Terraform files.
+	updated, err := renameZoneFiles(root, zoneDir, newZoneDir, name, newName, entries)
+	if err != nil {
+		printRenameJSON(name, newName, entries, updated)
+		return exitcode.Wrap(exitcode.Generic, err)
+	}
+
+	// 5. Rename the zone in lzctl.yaml, the output registry and the pipelines.
+	cfg.Spec.LandingZones[zoneIndex].Name = newName
+	if err := config.Save(cfg, localConfigPath()); err != nil {
+		return exitcode.Wrap(exitcode.Generic, fmt.Errorf("save config: %w", err))
+	}
+	invalidateConfigCache()
+	updated = append(updated, "lzctl.yaml")
+	if renamed, regErr := renameRegistryLayers(entries); regErr != nil {
+		return exitcode.Wrap(exitcode.Generic, regErr)
+	} else if renamed {
+		updated = append(updated, outputs.DefaultPath)
+	}
+	if _, err := lztemplate.WriteLandingZoneMatrix(cfg, root); err != nil {
+		return exitcode.Wrap(exitcode.Generic, fmt.Errorf("update landing-zone matrix: %w", err))
+	}
+	updater, err := lztemplate.NewPipelineUpdater()
+	if err != nil {
+		return exitcode.Wrap(exitcode.Generic, fmt.Errorf("create pipeline updater: %w", err))
+	}
+	if _, err := updater.UpdatePipelines(cfg, root); err != nil {
+		return exitcode.Wrap(exitcode.Generic, fmt.Errorf("update pipelines: %w", err))
+	}
+
+	for _, e := range entries {
+		e.Status = "renamed"
+	}
+	for _, p := range updated {
+		fmt.Fprintf(os.Stderr, "   📝 updated %s\n", p)
+	}
+	printRenameJSON(name, newName, entries, updated)
+
+	fmt.Fprintln(os.Stderr)
+	color.New(color.FgGreen, color.Bold).Fprintf(os.Stderr, "✅ Landing zone %s renamed to %s.\n", name, newName)
+	fmt.Fprintf(os.Stderr, "\nNext: lzctl plan --zone %s   (expect no changes, only moves)\n", newName)
+	return nil
+}
+
+// planZoneRename returns the roots of the landing zone with their new names
+// and state keys, and the resources and modules to rename in each. Blocks
+// generated from the archetype or blueprint templates keep their addresses
+// whatever the zone name, so only blocks added by hand can be named after it.
+func planZoneRename(cfg *config.LZConfig, repo string, zoneIndex int, newSlug string) ([]*renameEntry, error) {
+	zone := cfg.Spec.LandingZones[zoneIndex]
+	slug := lztemplate.Slugify(zone.Name)
+	if !dirExists(filepath.Join(repo, "landing-zones", slug)) {
+		return nil, nil
+	}
+
+	generated, err := generatedAddresses(cfg, zone)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.Generic, err)
+	}
+
+	var entries []*renameEntry
+	for _, r := range zoneRoots(repo, slug) {
+		newRoot := r
+		newRoot.Zone = newSlug
+		newRoot.Name = "lz:" + newSlug
+		newRoot.Dir = filepath.Join("landing-zones", newSlug)
+		if r.Kind == rootKindBlueprint {
+			newRoot = blueprintRoot(newSlug)
+		}
+		key, keyErr := stateKeyFor(repo, r)
+		if keyErr != nil {
+			return nil, exitcode.Wrap(exitcode.Validation, keyErr)
+		}
+		// Only conventional keys follow the zone name; a custom key stays.
+		newKey := key
+		if key == rootStateKey(r) {
+			newKey = rootStateKey(newRoot)
+		}
+
+		all, movesErr := refactor.NameMoves(filepath.Join(repo, r.Dir), slug, newSlug)
+		if movesErr != nil {
+			return nil, exitcode.Wrap(exitcode.Validation, movesErr)
+		}
+		var moves []refactor.Move
+		for _, m := range all {
+			if !generated[m.From.String()] {
+				moves = append(moves, m)
+			}
+		}
+		results, applyErr := refactor.Apply(filepath.Join(repo, r.Dir), moves, false)
+		if applyErr != nil {
+			return nil, exitcode.Wrap(exitcode.Validation, applyErr)
+		}
+
+		entries = append(entries, &renameEntry{
+			Layer:       r.Name,
+			NewLayer:    newRoot.Name,
+			Kind:        r.Kind,
+			StateKey:    key,
+			NewStateKey: newKey,
+			Moves:       results,
+			Status:      "planned",
+			root:        r,
+			newRoot:     newRoot,
+			moves:       moves,
+		})
+	}
+	return entries, nil
+}
+
+// generatedAddresses returns the resources and modules lzctl generates for
+// zone from its archetype and blueprint templates.
+func generatedAddresses(cfg *config.LZConfig, zone config.LandingZone) (map[string]bool, error) {
+	engine, err := lztemplate.NewEngine()
+	if err != nil {
+		return nil, fmt.Errorf("create template engine: %w", err)
+	}
+	files, err := engine.RenderZone(cfg, zone)
+	if err != nil {
+		return nil, err
+	}
+	if zone.Blueprint != nil {
+		blueprintFiles, bpErr := engine.RenderBlueprint(zone.Name, zone.Blueprint, cfg)
+		if bpErr != nil {
+			return nil, bpErr
+		}
+		files = append(files, blueprintFiles...)
+	}
+	addrs := map[string]bool{}
+	for _, f := range files {
+		if strings.HasSuffix(f.Path, ".tf") {
+			for _, a := range refactor.Declared(f.Content) {
+				addrs[a.String()] = true
+			}
+		}
+	}
+	return addrs, nil
+}
+
+// rewriteStateKeyReferences replaces oldKey with newKey in every root of the
+// repository. It returns the files changed (or that would change when write
+// is false), keyed by root directory relative to repo.
+func rewriteStateKeyReferences(repo, oldKey, newKey string, write bool) (map[string][]string, error) {
+	roots, err := resolveLocalRoots(repo, rootSelection{})
+	if err != nil {
+		return nil, err
+	}
+	changed := map[string][]string{}
+	for _, r := range roots {
+		files, err := orchestrator.RewriteStateKey(filepath.Join(repo, r.Dir), oldKey, newKey, write)
+		if err != nil {
+			return changed, err
+		}
+		if len(files) > 0 {
+			changed[filepath.ToSlash(r.Dir)] = files
+		}
+	}
+	return changed, nil
+}
+
+// renameZoneFiles moves the landing zone directory and rewrites the files
+// that depend on the zone name. It returns the files it changed, relative
+// to repo.
+func renameZoneFiles(repo, zoneDir, newZoneDir, name, newName string, entries []*renameEntry) ([]string, error) {
+	var updated []string
+	if len(entries) == 0 {
+		return updated, nil
+	}
+	if err := os.Rename(zoneDir, newZoneDir); err != nil {
+		return updated, fmt.Errorf("moving %s: %w", zoneDir, err)
+	}
+	updated = append(updated, filepath.ToSlash(filepath.Join("landing-zones", filepath.Base(newZoneDir)))+"/")
+
+	for _, e := range entries {
+		dir := filepath.Join(repo, e.newRoot.Dir)
+		// The initialized backend still points at the old key: drop it so
+		// that the next init reads the new one.
+		if err := os.Remove(filepath.Join(dir, ".terraform", "terraform.tfstate")); err != nil && !os.IsNotExist(err) {
+			return updated, fmt.Errorf("layer %s: resetting backend: %w", e.NewLayer, err)
+		}
+		if e.keyChanged() {
+			files, err := rewriteStateKeyReferences(repo, e.StateKey, e.NewStateKey, true)
+			if err != nil {
+				return updated, err
+			}
+			for d, names := range files {
+				for _, n := range names {
+					updated = append(updated, d+"/"+n)
+				}
+			}
+		}
+		if len(e.moves) > 0 {
+			results, err := refactor.Apply(dir, e.moves, true)
+			if err != nil {
+				return updated, fmt.Errorf("layer %s: %w", e.NewLayer, err)
+			}
+			e.Moves = results
+			updated = append(updated, filepath.ToSlash(filepath.Join(e.newRoot.Dir, refactor.MovedFile)))
+		}
+		if e.Kind == rootKindLandingZone {
+			files, err := renameZoneVariable(dir, name, newName)
+			if err != nil {
+				return updated, err
+			}
+			for _, f := range files {
+				updated = append(updated, filepath.ToSlash(filepath.Join(e.newRoot.Dir, f)))
+			}
+		}
+	}
+	return updated, nil
+}
+
+var zoneNameRefRegex = regexp.MustCompile(`\bvar\.zone_name\b`)
+
+// renameZoneVariable sets the zone_name variable of a landing zone root to
+// the new name. The generated templates only declare the variable; when a
+// hand-written block reads it, the old value is kept so that the rename
+// does not change any resource.
+func renameZoneVariable(dir, name, newName string) ([]string, error) {
+	tfFiles, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, fmt.Errorf("listing terraform files in %s: %w", dir, err)
+	}
+	for _, f := range tfFiles {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f, err)
+		}
+		if zoneNameRefRegex.Match(data) {
+			color.New(color.FgYellow).Fprintf(os.Stderr, "   ⚠️  %s reads var.zone_name: kept %q to avoid changing resources\n", filepath.Base(f), name)
+			return nil, nil
+		}
+	}
+
+	quoted := regexp.QuoteMeta(name)
+	rewrites := map[string]*regexp.Regexp{
+		"terraform.tfvars": regexp.MustCompile(`(?m)^(\s*zone_name\s*=\s*")` + quoted + `(")`),
+		"variables.tf":     regexp.MustCompile(`(variable\s+"zone_name"\s*\{[^}]*?default\s*=\s*")` + quoted + `(")`),
+	}
+	var changed []string
+	for _, f := range []string{"terraform.tfvars", "variables.tf"} {
+		path := filepath.Join(dir, f)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return changed, fmt.Errorf("reading %s: %w", path, err)
+		}
+		next := rewrites[f].ReplaceAllString(string(data), "${1}"+newName+"${2}")
+		if next == string(data) {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(next), 0o644); err != nil {
+			return changed, fmt.Errorf("writing %s: %w", path, err)
+		}
+		changed = append(changed, f)
+	}
+	return changed, nil
+}
+
+// renameRegistryLayers renames the output registry entries of the renamed
+// roots. It reports whether the registry changed.
+func renameRegistryLayers(entries []*renameEntry) (bool, error) {
+	path, err := repoFile(outputs.DefaultPath)
+	if err != nil {
+		return false, err
+	}
+	reg, err := outputs.Load(path)
+	if err != nil || reg == nil {
+		return false, err
+	}
+	changed := false
+	for _, e := range entries {
+		layer, ok := reg.Layers[e.Layer]
+		if !ok {
+			continue
+		}
+		delete(reg.Layers, e.Layer)
+		layer.Key = e.NewStateKey
+		layer.Dir = filepath.ToSlash(e.newRoot.Dir)
+		reg.Layers[e.NewLayer] = layer
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, reg.Save(path)
+}
+
+func printRenameEntry(e *renameEntry) {
+	switch {
+	case !e.keyChanged():
+		fmt.Fprintf(os.Stderr, "   📝 %-24s -> %s (state key %s kept)\n", e.Layer, e.NewLayer, e.StateKey)
+	case !e.hasState:
+		fmt.Fprintf(os.Stderr, "   📝 %-24s -> %s (no state)\n", e.Layer, e.NewLayer)
+	default:
+		fmt.Fprintf(os.Stderr, "   📝 %-24s -> %s (state %s -> %s)\n", e.Layer, e.NewLayer, e.StateKey, e.NewStateKey)
+	}
+	for _, m := range e.moves {
+		fmt.Fprintf(os.Stderr, "      moved    %s -> %s\n", m.From, m.To)
+	}
+}
+
+func printRenameJSON(name, newName string, entries []*renameEntry, updated []string) {
+	if !jsonOutput {
+		return
+	}
+	status := "ok"
+	for _, e := range entries {
+		if e.Status == "failed" {
+			status = "failed"
+		}
+	}
+	if dryRun {
+		status = "dry-run"
+	}
+	if entries == nil {
+		entries = []*renameEntry{}
+	}
+	if updated == nil {
+		updated = []string{}
+	}
+	data, _ := json.MarshalIndent(map[string]interface{}{
+		"status":  status,
+		"zone":    name,
+		"newName": newName,
+		"roots":   entries,
+		"updated": updated,
+	}, "", "  ")
+	fmt.Fprintln(os.Stdout, string(data))
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/kjourdan1/lzctl/internal/exitcode"
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+)
+
+const corpProdRemoteState = `
+data "terraform_remote_state" "corp_prod" {
+  backend = "azurerm"
+  config = {
+    key = "landing-zones-corp-prod.tfstate"
+  }
+}
+`
+
+const corpProdApp = `
+module "corp_prod_app" {
+  source              = "./modules/app"
+  resource_group_name = azurerm_resource_group.zone.name
+}
+
+output "app_id" {
+  value = module.corp_prod_app.id
+}
+`
+
+// setupRenameRepo reuses the decommission repository (corp-prod with a
+// blueprint, and online-dev) and adds a hand-written module named after
+// corp-prod and a remote state read of corp-prod from online-dev. Both
+// corp-prod roots have a state blob.
+func setupRenameRepo(t *testing.T) (string, *fakeAz) {
+	t.Helper()
+	repo, _, _ := setupDecommissionRepo(t)
+	appendFile(t, filepath.Join(repo, "landing-zones", "corp-prod", "main.tf"), corpProdApp)
+	appendFile(t, filepath.Join(repo, "landing-zones", "online-dev", "main.tf"), corpProdRemoteState)
+
+	az := useFakeAz(t, newFakeAz().WithBlobs("landing-zones-corp-prod.tfstate", "landing-zones-corp-prod-blueprint.tfstate"))
+	return repo, az
+}
+
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func readRepoFile(t *testing.T, repo string, parts ...string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(append([]string{repo}, parts...)...))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestWorkloadRename_MovesStateFilesAndConfig(t *testing.T) {
+	repo, az := setupRenameRepo(t)
+
+	_, stderr, err := executeCommandWithProcessIO(t, "workload", "rename", "--repo-root", repo, "--name", "corp-prod", "--new-name", "shop-prod")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "renamed to shop-prod")
+
+	copies := az.Calls("storage blob copy")
+	require.Len(t, copies, 2)
+	assert.Contains(t, copies[0], "--destination-blob landing-zones-shop-prod.tfstate")
+	assert.Contains(t, copies[1], "--destination-blob landing-zones-shop-prod-blueprint.tfstate")
+	assert.Len(t, az.Calls("storage blob delete"), 2)
+	assert.True(t, az.Exists("landing-zones-shop-prod.tfstate"))
+	assert.False(t, az.Exists("landing-zones-corp-prod.tfstate"))
+
+	assert.NoDirExists(t, filepath.Join(repo, "landing-zones", "corp-prod"))
+	main := readRepoFile(t, repo, "landing-zones", "shop-prod", "main.tf")
+	assert.Contains(t, main, `module "shop_prod_app" {`)
+	assert.Contains(t, main, "module.shop_prod_app.id")
+	assert.Contains(t, main, `module "corp_vnet" {`, "generated blocks keep their address")
+	assert.Contains(t, main, "rg-corp-prod", "Azure resource names are unchanged")
+	moved := readRepoFile(t, repo, "landing-zones", "shop-prod", "moved.tf")
+	assert.Contains(t, moved, "from = module.corp_prod_app\n  to   = module.shop_prod_app")
+	assert.Contains(t, readRepoFile(t, repo, "landing-zones", "shop-prod", "terraform.tfvars"), `"shop-prod"`)
+	assert.Contains(t, readRepoFile(t, repo, "landing-zones", "shop-prod", "blueprint", "backend.hcl"), "landing-zones-shop-prod-blueprint.tfstate")
+	assert.Contains(t, readRepoFile(t, repo, "landing-zones", "online-dev", "main.tf"), `key = "landing-zones-shop-prod.tfstate"`)
+
+	cfg, err := config.Load(filepath.Join(repo, "lzctl.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "shop-prod", cfg.Spec.LandingZones[0].Name)
+	matrix := readRepoFile(t, repo, ".lzctl", "zone-matrix.json")
+	assert.Contains(t, matrix, "shop-prod")
+	assert.NotContains(t, matrix, "corp-prod")
+}
+
+func TestWorkloadRename_KeepsOldStateWithSnapshots(t *testing.T) {
+	repo, az := setupRenameRepo(t)
+	az.AddSnapshot("landing-zones-corp-prod.tfstate")
+
+	stdout, stderr, err := executeCommandWithProcessIO(t, "workload", "rename", "--repo-root", repo, "--name", "corp-prod", "--new-name", "shop-prod", "--json")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "landing-zones-corp-prod.tfstate kept with its snapshots")
+	assert.Contains(t, stdout, `"oldStateKept": true`)
+
+	assert.True(t, az.Exists("landing-zones-shop-prod.tfstate"))
+	assert.True(t, az.Exists("landing-zones-corp-prod.tfstate"))
+	assert.Equal(t, 1, az.Snapshots("landing-zones-corp-prod.tfstate"))
+	assert.False(t, az.Exists("landing-zones-corp-prod-blueprint.tfstate"), "blobs without snapshots are still moved")
+	assert.DirExists(t, filepath.Join(repo, "landing-zones", "shop-prod"))
+}
+
+func TestWorkloadRename_DryRunChangesNothing(t *testing.T) {
+	repo, az := setupRenameRepo(t)
+	before := readRepoFile(t, repo, "landing-zones", "online-dev", "main.tf")
+
+	stdout, stderr, err := executeCommandWithProcessIO(t, "--dry-run", "--json", "workload", "rename", "--repo-root", repo, "--name", "corp-prod", "--new-name", "shop-prod")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "[DRY-RUN]")
+	assert.Contains(t, stderr, "landing-zones/online-dev")
+
+	var payload struct {
+		Status string `json:"status"`
+		Roots  []struct {
+			Layer       string `json:"layer"`
+			NewLayer    string `json:"newLayer"`
+			NewStateKey string `json:"newStateKey"`
+			Moves       []struct {
+				Move string `json:"move"`
+			} `json:"moves"`
+		} `json:"roots"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &payload))
+	assert.Equal(t, "dry-run", payload.Status)
+	require.Len(t, payload.Roots, 2)
+	assert.Equal(t, "lz:shop-prod", payload.Roots[0].NewLayer)
+	assert.Equal(t, "landing-zones-shop-prod.tfstate", payload.Roots[0].NewStateKey)
+	require.Len(t, payload.Roots[0].Moves, 1)
+	assert.Equal(t, "module.corp_prod_app -> module.shop_prod_app", payload.Roots[0].Moves[0].Move)
+	assert.Equal(t, "lz:shop-prod-blueprint", payload.Roots[1].NewLayer)
+
+	assert.Empty(t, az.Calls("storage blob copy"))
+	assert.DirExists(t, filepath.Join(repo, "landing-zones", "corp-prod"))
+	assert.NoFileExists(t, filepath.Join(repo, "landing-zones", "corp-prod", "moved.tf"))
+	assert.Equal(t, before, readRepoFile(t, repo, "landing-zones", "online-dev", "main.tf"))
+}
+
+func TestWorkloadRename_RefusesExistingTarget(t *testing.T) {
+	repo, az := setupRenameRepo(t)
+	az.SetVersion("landing-zones-shop-prod.tfstate", "v1")
+
+	_, _, err := executeCommandWithProcessIO(t, "workload", "rename", "--repo-root", repo, "--name", "corp-prod", "--new-name", "shop-prod")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "already exists")
+	assert.Empty(t, az.Calls("storage blob copy"))
+	assert.DirExists(t, filepath.Join(repo, "landing-zones", "corp-prod"))
+
+	_, _, err = executeCommandWithProcessIO(t, "workload", "rename", "--repo-root", repo, "--name", "corp-prod", "--new-name", "online-dev")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+}
+
+func TestRefactorCmd_WritesMovedBlock(t *testing.T) {
+	useFakeRunner(t, runnertest.New())
+	repo := initRepoForCommandTests(t)
+	dir := filepath.Join(repo, "platform", "connectivity")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "refactor.tf"), []byte(`resource "azurerm_resource_group" "hub" {
+  name     = "rg-hub"
+  location = "westeurope"
+}
+
+output "hub_rg" {
+  value = azurerm_resource_group.hub.name
+}
+`), 0o644))
+
+	_, _, err := executeCommandWithProcessIO(t, "--dry-run", "refactor", "--repo-root", repo, "--layer", "connectivity", "--from", "azurerm_resource_group.hub", "--to", "azurerm_resource_group.connectivity")
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "moved.tf"))
+
+	_, stderr, err := executeCommandWithProcessIO(t, "refactor", "--repo-root", repo, "--layer", "connectivity", "--from", "azurerm_resource_group.hub", "--to", "azurerm_resource_group.connectivity")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "lzctl plan --layer connectivity")
+	tf := readRepoFile(t, repo, "platform", "connectivity", "refactor.tf")
+	assert.Contains(t, tf, `resource "azurerm_resource_group" "connectivity" {`)
+	assert.Contains(t, tf, "value = azurerm_resource_group.connectivity.name")
+	assert.Contains(t, readRepoFile(t, repo, "platform", "connectivity", "moved.tf"), "from = azurerm_resource_group.hub\n  to   = azurerm_resource_group.connectivity")
+}
+
+func TestRefactorCmd_RequiresOneRoot(t *testing.T) {
+	repo := initRepoForCommandTests(t)
+
+	_, _, err := executeCommandWithProcessIO(t, "refactor", "--repo-root", repo, "--from", "module.a", "--to", "module.b")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+
+	_, _, err = executeCommandWithProcessIO(t, "refactor", "--repo-root", repo, "--layer", "connectivity", "--from", "data.azurerm_client_config.current", "--to", "data.azurerm_client_config.me")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Validation, exitcode.Of(err))
+}
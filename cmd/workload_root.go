@@ -14,7 +14,9 @@ Sub-commands:
   add    → Add a new landing zone definition
   adopt  → Adopt an existing subscription as a landing zone
   list   → List all defined landing zones
-  remove → Remove a landing zone definition`,
+  remove       → Remove a landing zone definition
+  rename       → Rename a landing zone without recreating its resources
+  decommission → Destroy a landing zone and remove it from the repository`,
 }
 
 func init() {
@@ -24,6 +26,8 @@ func init() {
 	workloadCmd.AddCommand(workloadAdoptCmd)
 	workloadCmd.AddCommand(workloadListCmd)
 	workloadCmd.AddCommand(workloadRemoveCmd)
+	workloadCmd.AddCommand(workloadRenameCmd)
+	workloadCmd.AddCommand(workloadDecommissionCmd)
 
 	workloadAddCmd.Flags().StringP("name", "n", "", "Landing zone name (kebab-case)")
 	workloadAddCmd.Flags().String("archetype", "corp", "Archetype: corp, online, sandbox")
@@ -45,4 +49,13 @@ func init() {
 
 	workloadRemoveCmd.Flags().StringP("name", "n", "", "Landing zone name")
 	_ = workloadRemoveCmd.MarkFlagRequired("name")
+
+	workloadRenameCmd.Flags().StringP("name", "n", "", "Landing zone name")
+	workloadRenameCmd.Flags().String("new-name", "", "New landing zone name (kebab-case)")
+	_ = workloadRenameCmd.MarkFlagRequired("name")
+	_ = workloadRenameCmd.MarkFlagRequired("new-name")
+
+	workloadDecommissionCmd.Flags().StringP("name", "n", "", "Landing zone name")
+	workloadDecommissionCmd.Flags().String("confirm", "", "Landing zone name, typed again to confirm without a prompt (required in CI)")
+	_ = workloadDecommissionCmd.MarkFlagRequired("name")
 }
@@ -15,6 +15,7 @@ type Event struct {
 	Operation     string            `json:"operation"`
 	Tenant        string            `json:"tenant,omitempty"`
 	Ring          string            `json:"ring,omitempty"`
+	Environment   string            `json:"environment,omitempty"`
 	Args          []string          `json:"args"`
 	Result        string            `json:"result"`
 	ExitCode      int               `json:"exitCode"`
@@ -24,7 +25,7 @@ type Event struct {
 }
 
 func BuildEvent(args []string, result string, exitCode int, duration time.Duration) Event {
-	op, tenant, ring, repoRoot := inferFromArgs(args)
+	op, tenant, ring, env, repoRoot := inferFromArgs(args)
 	meta := map[string]string{}
 	if repoRoot != "" {
 		meta["repoRoot"] = repoRoot
@@ -37,6 +38,7 @@ func BuildEvent(args []string, result string, exitCode int, duration time.Durati
 		Operation:     op,
 		Tenant:        tenant,
 		Ring:          ring,
+		Environment:   env,
 		Args:          args,
 		Result:        result,
 		ExitCode:      exitCode,
@@ -50,7 +52,8 @@ func Write(event Event) error {
 	if err := writeUserAudit(event); err != nil {
 		return err
 	}
-	if repoRoot := event.MetadataValue("repoRoot"); repoRoot != "" && event.Tenant != "" {
+	// --tenant all fans out over every tenant and has no tenant log of its own.
+	if repoRoot := event.MetadataValue("repoRoot"); repoRoot != "" && event.Tenant != "" && event.Tenant != "all" {
 		_ = writeTenantAudit(repoRoot, event)
 	}
 	return nil
@@ -138,7 +141,7 @@ func userAuditPath() (string, error) {
 	return filepath.Join(home, ".lzctl", "audit.log"), nil
 }
 
-func inferFromArgs(args []string) (operation, tenant, ring, repoRoot string) {
+func inferFromArgs(args []string) (operation, tenant, ring, env, repoRoot string) {
 	operation = "root"
 	if len(args) > 1 {
 		for i := 1; i < len(args); i++ {
@@ -156,6 +159,8 @@ func inferFromArgs(args []string) (operation, tenant, ring, repoRoot string) {
 				tenant = args[i+1]
 			case "--ring":
 				ring = args[i+1]
+			case "--env":
+				env = args[i+1]
 			case "--repo-root":
 				repoRoot = args[i+1]
 			}
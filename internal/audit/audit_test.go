@@ -11,11 +11,12 @@ import (
 )
 
 func TestBuildEvent_InfersFieldsFromArgs(t *testing.T) {
-	event := BuildEvent([]string{"lzctl", "rollback", "--tenant", "contoso", "--ring", "wave1", "--repo-root", "C:/repo"}, "failure", 7, 1500*time.Millisecond)
+	event := BuildEvent([]string{"lzctl", "rollback", "--tenant", "contoso", "--ring", "wave1", "--env", "prod", "--repo-root", "C:/repo"}, "failure", 7, 1500*time.Millisecond)
 
 	assert.Equal(t, "rollback", event.Operation)
 	assert.Equal(t, "contoso", event.Tenant)
 	assert.Equal(t, "wave1", event.Ring)
+	assert.Equal(t, "prod", event.Environment)
 	assert.Equal(t, 7, event.ExitCode)
 	assert.Equal(t, int64(1500), event.DurationMs)
 	assert.Equal(t, "C:/repo", event.MetadataValue("repoRoot"))
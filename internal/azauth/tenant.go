@@ -0,0 +1,97 @@
+// Package azauth — file: tenant.go
+//
+// Per-tenant credential resolution for multi-tenant repositories.
+//
+// Each tenant of a repository (tenants/<name>/) can bring its own credential
+// through environment variables suffixed with the tenant name in upper snake
+// case (contoso-eu → CONTOSO_EU):
+//  1. AZURE_CLIENT_ID_<TENANT> (+ AZURE_CLIENT_SECRET_<TENANT>, AZURE_TENANT_ID_<TENANT>):
+//     a service principal of the tenant
+//  2. AZURE_CONFIG_DIR_<TENANT>: an Azure CLI profile logged in to the tenant
+//  3. otherwise the credential of the process is shared by every tenant
+//
+// The resolved variables are set for the duration of the tenant's run, so
+// Terraform (ARM_*), the Azure SDK (AZURE_*) and az (AZURE_CONFIG_DIR) all use
+// the tenant's credential.
+package azauth
+
+import (
+	"os"
+	"strings"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+)
+
+// TenantCredentials is the credential resolved for one tenant.
+type TenantCredentials struct {
+	Tenant   string            // tenant name (tenants/<name>/)
+	TenantID string            // Azure AD tenant the credential targets
+	Method   string            // "environment", "cli", "shared"
+	Source   string            // variable the credential comes from, empty when shared
+	Env      map[string]string // process environment to set while the tenant runs
+}
+
+// TenantEnvSuffix returns the suffix of the variables holding the credential
+// of tenant name: contoso-eu → CONTOSO_EU.
+func TenantEnvSuffix(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// ResolveTenantCredentials returns the credential of tenant name, whose
+// configuration is cfg.
+//
+// A tenant's service principal replaces the process one entirely: the
+// process client secret is blanked when the tenant has none, so that it is
+// never combined with the tenant's client ID. The SEC-1 pre-auth binding is
+// checked against the credential the tenant ends up with.
+func ResolveTenantCredentials(name string, cfg *config.LZConfig) (*TenantCredentials, error) {
+	suffix := "_" + TenantEnvSuffix(name)
+	creds := &TenantCredentials{Tenant: name, TenantID: strings.TrimSpace(cfg.Metadata.Tenant), Method: "shared"}
+
+	if tenantID := strings.TrimSpace(os.Getenv("AZURE_TENANT_ID" + suffix)); tenantID != "" {
+		creds.TenantID = tenantID
+	}
+
+	if clientID := strings.TrimSpace(os.Getenv("AZURE_CLIENT_ID" + suffix)); clientID != "" {
+		secret := os.Getenv("AZURE_CLIENT_SECRET" + suffix)
+		creds.Method = "environment"
+		creds.Source = "AZURE_CLIENT_ID" + suffix
+		creds.Env = map[string]string{
+			"AZURE_CLIENT_ID":     clientID,
+			"ARM_CLIENT_ID":       clientID,
+			"AZURE_CLIENT_SECRET": secret,
+			"ARM_CLIENT_SECRET":   secret,
+			"AZURE_TENANT_ID":     creds.TenantID,
+			"ARM_TENANT_ID":       creds.TenantID,
+		}
+		if expected := strings.TrimSpace(cfg.Spec.Platform.Identity.ClientID); expected != "" && !strings.EqualFold(expected, clientID) {
+			return nil, &SPNBindingError{
+				ProjectName:    cfg.Metadata.Name,
+				ExpectedClient: expected,
+				ActualClient:   clientID,
+				Stage:          "pre_auth",
+			}
+		}
+		return creds, nil
+	}
+
+	if dir := strings.TrimSpace(os.Getenv("AZURE_CONFIG_DIR" + suffix)); dir != "" {
+		// The CLI profile is the tenant's credential: blank the process
+		// service principal, which Terraform and the SDK would prefer.
+		creds.Method = "cli"
+		creds.Source = "AZURE_CONFIG_DIR" + suffix
+		creds.Env = map[string]string{
+			"AZURE_CONFIG_DIR":    dir,
+			"AZURE_CLIENT_ID":     "",
+			"ARM_CLIENT_ID":       "",
+			"AZURE_CLIENT_SECRET": "",
+			"ARM_CLIENT_SECRET":   "",
+		}
+		return creds, nil
+	}
+
+	if err := ValidateSPNBinding(cfg); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
@@ -0,0 +1,95 @@
+package azauth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kjourdan1/lzctl/internal/config"
+)
+
+// clearTenantEnv blanks the process and tenant credential variables.
+func clearTenantEnv(t *testing.T, suffix string) {
+	t.Helper()
+	for _, name := range []string{"AZURE_CLIENT_ID", "AZURE_CLIENT_SECRET", "AZURE_TENANT_ID", "AZURE_CONFIG_DIR"} {
+		t.Setenv(name, "")
+		t.Setenv(name+"_"+suffix, "")
+	}
+}
+
+func tenantConfig(clientID string) *config.LZConfig {
+	return &config.LZConfig{
+		Metadata: config.Metadata{Name: "contoso-eu", Tenant: "33333333-3333-3333-3333-333333333333"},
+		Spec: config.Spec{
+			Platform: config.Platform{Identity: config.IdentityConfig{ClientID: clientID}},
+		},
+	}
+}
+
+func TestTenantEnvSuffix(t *testing.T) {
+	if got := TenantEnvSuffix("contoso-eu"); got != "CONTOSO_EU" {
+		t.Errorf("got %q, want CONTOSO_EU", got)
+	}
+}
+
+func TestResolveTenantCredentials_ServicePrincipal(t *testing.T) {
+	clearTenantEnv(t, "CONTOSO_EU")
+	t.Setenv("AZURE_CLIENT_SECRET", "shared-secret")
+	t.Setenv("AZURE_CLIENT_ID_CONTOSO_EU", "11111111-1111-1111-1111-111111111111")
+
+	creds, err := ResolveTenantCredentials("contoso-eu", tenantConfig("11111111-1111-1111-1111-111111111111"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.Method != "environment" || creds.Source != "AZURE_CLIENT_ID_CONTOSO_EU" {
+		t.Errorf("unexpected method %q from %q", creds.Method, creds.Source)
+	}
+	if creds.Env["ARM_CLIENT_ID"] != "11111111-1111-1111-1111-111111111111" {
+		t.Errorf("ARM_CLIENT_ID = %q", creds.Env["ARM_CLIENT_ID"])
+	}
+	if creds.Env["ARM_TENANT_ID"] != "33333333-3333-3333-3333-333333333333" {
+		t.Errorf("ARM_TENANT_ID = %q, want metadata.tenant", creds.Env["ARM_TENANT_ID"])
+	}
+	if secret, ok := creds.Env["AZURE_CLIENT_SECRET"]; !ok || secret != "" {
+		t.Errorf("the shared secret must be blanked, got %q", secret)
+	}
+}
+
+func TestResolveTenantCredentials_BindingMismatch(t *testing.T) {
+	clearTenantEnv(t, "CONTOSO_EU")
+	t.Setenv("AZURE_CLIENT_ID_CONTOSO_EU", "22222222-2222-2222-2222-222222222222")
+
+	_, err := ResolveTenantCredentials("contoso-eu", tenantConfig("11111111-1111-1111-1111-111111111111"))
+	var bindingErr *SPNBindingError
+	if !errors.As(err, &bindingErr) {
+		t.Fatalf("expected SPNBindingError, got %v", err)
+	}
+
+	clearTenantEnv(t, "CONTOSO_EU")
+	t.Setenv("AZURE_CLIENT_ID", "22222222-2222-2222-2222-222222222222")
+	_, err = ResolveTenantCredentials("contoso-eu", tenantConfig("11111111-1111-1111-1111-111111111111"))
+	if !errors.As(err, &bindingErr) {
+		t.Fatalf("the shared credential is checked too, got %v", err)
+	}
+}
+
+func TestResolveTenantCredentials_CLIProfileAndShared(t *testing.T) {
+	clearTenantEnv(t, "CONTOSO_EU")
+	t.Setenv("AZURE_CONFIG_DIR_CONTOSO_EU", "/home/ops/.azure-contoso-eu")
+
+	creds, err := ResolveTenantCredentials("contoso-eu", tenantConfig(""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.Method != "cli" || creds.Env["AZURE_CONFIG_DIR"] != "/home/ops/.azure-contoso-eu" {
+		t.Errorf("unexpected credentials: %+v", creds)
+	}
+
+	clearTenantEnv(t, "CONTOSO_EU")
+	creds, err = ResolveTenantCredentials("contoso-eu", tenantConfig(""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.Method != "shared" || len(creds.Env) != 0 {
+		t.Errorf("unexpected credentials: %+v", creds)
+	}
+}
@@ -29,6 +29,18 @@ func DefaultRetryConfig() RetryConfig {
 	}
 }
 
+// Delay returns the backoff delay before retry number attempt (0-based),
+// with jitter, applying the defaults for unset fields.
+func (cfg RetryConfig) Delay(attempt int) time.Duration {
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = DefaultRetryBaseDelay
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = DefaultRetryMaxDelay
+	}
+	return backoffDelay(attempt, cfg.BaseDelay, cfg.MaxDelay)
+}
+
 // Retry executes fn with exponential backoff and jitter.
 // It retries on any error up to cfg.MaxAttempts times.
 // Returns the result of the last attempt if all retries fail.
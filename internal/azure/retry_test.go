@@ -140,3 +140,17 @@ func TestDefaultRetryConfig(t *testing.T) {
 		t.Errorf("MaxDelay = %v, want %v", cfg.MaxDelay, DefaultRetryMaxDelay)
 	}
 }
+
+func TestRetryConfig_Delay(t *testing.T) {
+	cfg := RetryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: 400 * time.Millisecond}
+	for attempt, max := range []time.Duration{100, 200, 400, 400} {
+		max *= time.Millisecond
+		d := cfg.Delay(attempt)
+		if d < max/2 || d > max {
+			t.Errorf("Delay(%d) = %s, want within [%s, %s]", attempt, d, max/2, max)
+		}
+	}
+	if d := (RetryConfig{}).Delay(0); d < DefaultRetryBaseDelay/2 || d > DefaultRetryBaseDelay {
+		t.Errorf("Delay(0) with defaults = %s", d)
+	}
+}
@@ -64,6 +64,21 @@ func ValidateCross(cfg *LZConfig, repoRoot string) ([]CrossCheck, error) {
 		}
 	}
 
+	if subs := cfg.Spec.Platform.Subscriptions; subs != nil {
+		for _, p := range []struct{ name, id string }{
+			{"management", subs.Management},
+			{"connectivity", subs.Connectivity},
+			{"identity", subs.Identity},
+		} {
+			if isPlaceholder(p.id) {
+				continue
+			}
+			if !uuidRE.MatchString(strings.TrimSpace(p.id)) {
+				add("platform-subscription-"+p.name, "error", fmt.Sprintf("platform %s subscription must be a valid UUID", p.name))
+			}
+		}
+	}
+
 	type cidrScope struct {
 		Name string
 		CIDR string
@@ -125,6 +140,14 @@ func ValidateCross(cfg *LZConfig, repoRoot string) ([]CrossCheck, error) {
 		}
 	}
 
+	seenEnvs := map[string]bool{}
+	for _, env := range cfg.Spec.Environments {
+		if seenEnvs[env.Name] {
+			add("environments", "error", fmt.Sprintf("environment %q is declared twice in spec.environments", env.Name))
+		}
+		seenEnvs[env.Name] = true
+	}
+
 	// CI/CD model validation
 	switch strings.ToLower(strings.TrimSpace(cfg.Spec.CICD.Model)) {
 	case "push", "":
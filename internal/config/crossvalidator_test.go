@@ -93,3 +93,12 @@ func hasCrossName(checks []CrossCheck, name string) bool {
 	}
 	return false
 }
+
+func TestValidateCross_DuplicateEnvironment(t *testing.T) {
+	cfg := &LZConfig{Spec: Spec{Environments: []Environment{{Name: "dev"}, {Name: "prod", Ring: 1}, {Name: "dev", Ring: 2}}}}
+
+	checks, err := ValidateCross(cfg, "")
+	require.NoError(t, err)
+	assert.True(t, hasCrossStatus(checks, "error"))
+	assert.True(t, hasCrossName(checks, "environments"))
+}
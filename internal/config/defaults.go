@@ -12,11 +12,24 @@ const (
 	DefaultCICDModel        = "push"
 )
 
+// PlatformLayerOrder is the canonical CAF platform layer order, used to
+// discover platform layers and to order generated pipelines. Orchestrated
+// commands derive the dependencies between platform layers from their
+// terraform_remote_state data sources, and fall back to this order as a
+// chain only when no layer declares any.
+var PlatformLayerOrder = []string{
+	"management-groups",
+	"identity",
+	"management",
+	"governance",
+	"connectivity",
+}
+
 // ApplyDefaults fills in default values for optional fields that were not
 // specified in the YAML. It is called after parsing and before validation.
 func ApplyDefaults(cfg *LZConfig) {
 	if cfg.APIVersion == "" {
-		cfg.APIVersion = "lzctl/v1"
+		cfg.APIVersion = CurrentAPIVersion
 	}
 	if cfg.Kind == "" {
 		cfg.Kind = "LandingZone"
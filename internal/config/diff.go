@@ -0,0 +1,98 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Section paths reported by ChangedSections.
+const (
+	SectionMetadata         = "metadata"
+	SectionManagementGroups = "spec.platform.managementGroups"
+	SectionIdentity         = "spec.platform.identity"
+	SectionManagement       = "spec.platform.management"
+	SectionConnectivity     = "spec.platform.connectivity"
+	SectionGovernance       = "spec.governance"
+	SectionNaming           = "spec.naming"
+	SectionStateBackend     = "spec.stateBackend"
+	SectionCICD             = "spec.cicd"
+	SectionTesting          = "spec.testing"
+	SectionTerraform        = "spec.terraform"
+	SectionExecution        = "spec.execution"
+	SectionNotifications    = "spec.notifications"
+)
+
+// LandingZoneSection returns the section path for a landing zone entry.
+func LandingZoneSection(name string) string {
+	return "spec.landingZones[" + name + "]"
+}
+
+// BlueprintSection returns the section path for a landing zone's blueprint.
+func BlueprintSection(name string) string {
+	return LandingZoneSection(name) + ".blueprint"
+}
+
+// ChangedSections compares two configs and returns the paths of the
+// sections that differ, sorted. Landing zones are matched by name: a zone
+// whose own fields changed (or that was added or removed) is reported as
+// LandingZoneSection, and a blueprint change as BlueprintSection.
+// A nil old config is treated as entirely new.
+func ChangedSections(oldCfg, newCfg *LZConfig) []string {
+	if oldCfg == nil {
+		oldCfg = &LZConfig{}
+	}
+	if newCfg == nil {
+		newCfg = &LZConfig{}
+	}
+
+	var changed []string
+	add := func(section string, a, b any) {
+		if !reflect.DeepEqual(a, b) {
+			changed = append(changed, section)
+		}
+	}
+	add(SectionMetadata, oldCfg.Metadata, newCfg.Metadata)
+	add(SectionManagementGroups, oldCfg.Spec.Platform.ManagementGroups, newCfg.Spec.Platform.ManagementGroups)
+	add(SectionIdentity, oldCfg.Spec.Platform.Identity, newCfg.Spec.Platform.Identity)
+	add(SectionManagement, oldCfg.Spec.Platform.Management, newCfg.Spec.Platform.Management)
+	add(SectionConnectivity, oldCfg.Spec.Platform.Connectivity, newCfg.Spec.Platform.Connectivity)
+	add(SectionGovernance, oldCfg.Spec.Governance, newCfg.Spec.Governance)
+	add(SectionNaming, oldCfg.Spec.Naming, newCfg.Spec.Naming)
+	add(SectionStateBackend, oldCfg.Spec.StateBackend, newCfg.Spec.StateBackend)
+	add(SectionCICD, oldCfg.Spec.CICD, newCfg.Spec.CICD)
+	add(SectionTesting, oldCfg.Spec.Testing, newCfg.Spec.Testing)
+	add(SectionTerraform, oldCfg.Spec.Terraform, newCfg.Spec.Terraform)
+	add(SectionExecution, oldCfg.Spec.Execution, newCfg.Spec.Execution)
+	add(SectionNotifications, oldCfg.Spec.Notifications, newCfg.Spec.Notifications)
+
+	oldZones := make(map[string]LandingZone, len(oldCfg.Spec.LandingZones))
+	for _, z := range oldCfg.Spec.LandingZones {
+		oldZones[z.Name] = z
+	}
+	newZones := make(map[string]LandingZone, len(newCfg.Spec.LandingZones))
+	for _, z := range newCfg.Spec.LandingZones {
+		newZones[z.Name] = z
+	}
+	for name, nz := range newZones {
+		oz, existed := oldZones[name]
+		if !existed {
+			changed = append(changed, LandingZoneSection(name))
+			if nz.Blueprint != nil {
+				changed = append(changed, BlueprintSection(name))
+			}
+			continue
+		}
+		ozBase, nzBase := oz, nz
+		ozBase.Blueprint, nzBase.Blueprint = nil, nil
+		add(LandingZoneSection(name), ozBase, nzBase)
+		add(BlueprintSection(name), oz.Blueprint, nz.Blueprint)
+	}
+	for name := range oldZones {
+		if _, ok := newZones[name]; !ok {
+			changed = append(changed, LandingZoneSection(name))
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedSections(t *testing.T) {
+	base := func() *LZConfig {
+		return &LZConfig{
+			Metadata: Metadata{Name: "contoso", PrimaryRegion: "westeurope"},
+			Spec: Spec{
+				Platform: Platform{Connectivity: ConnectivityConfig{Type: "hub-spoke"}},
+				LandingZones: []LandingZone{
+					{Name: "app-a", Archetype: "corp"},
+					{Name: "app-b", Archetype: "online", Blueprint: &Blueprint{Type: "paas-secure"}},
+				},
+			},
+		}
+	}
+
+	t.Run("identical", func(t *testing.T) {
+		assert.Empty(t, ChangedSections(base(), base()))
+	})
+
+	t.Run("platform and zones", func(t *testing.T) {
+		oldCfg, newCfg := base(), base()
+		newCfg.Spec.Platform.Connectivity.Type = "vwan"
+		newCfg.Spec.LandingZones[0].AddressSpace = "10.1.0.0/24"
+		newCfg.Spec.LandingZones[1].Blueprint = &Blueprint{Type: "aks-platform"}
+		newCfg.Spec.LandingZones = append(newCfg.Spec.LandingZones, LandingZone{Name: "app-c"})
+
+		assert.Equal(t, []string{
+			LandingZoneSection("app-a"),
+			BlueprintSection("app-b"),
+			LandingZoneSection("app-c"),
+			SectionConnectivity,
+		}, ChangedSections(oldCfg, newCfg))
+	})
+
+	t.Run("removed zone", func(t *testing.T) {
+		oldCfg, newCfg := base(), base()
+		newCfg.Spec.LandingZones = newCfg.Spec.LandingZones[:1]
+		assert.Equal(t, []string{LandingZoneSection("app-b")}, ChangedSections(oldCfg, newCfg))
+	})
+
+	t.Run("nil old config", func(t *testing.T) {
+		sections := ChangedSections(nil, base())
+		assert.Contains(t, sections, SectionMetadata)
+		assert.Contains(t, sections, BlueprintSection("app-b"))
+	})
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var environmentNameRE = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ValidEnvironmentName reports whether name can name an environment:
+// lowercase letters and digits separated by single dashes.
+func ValidEnvironmentName(name string) bool {
+	return environmentNameRE.MatchString(name)
+}
+
+// OverlayPath returns the overlay file of env next to the base configuration
+// at path: lzctl.yaml becomes lzctl.dev.yaml.
+func OverlayPath(path, env string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+// LoadEnv loads the configuration at path for env. The overlay
+// lzctl.<env>.yaml is merged into the base file (see Merge) before the
+// result is migrated and parsed, like Load, and Metadata.Environment is set
+// to env. An empty env loads the base file alone.
+//
+// When spec.environments is declared, env must be one of them; a declared
+// environment may omit its overlay. An undeclared environment needs one.
+func LoadEnv(path, env string) (*LZConfig, error) {
+	if env == "" {
+		return Load(path)
+	}
+	if !ValidEnvironmentName(env) {
+		return nil, fmt.Errorf("invalid environment name %q: use lowercase letters, digits and dashes", env)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file %s: %w", path, err)
+	}
+	overlayData, err := os.ReadFile(OverlayPath(path, env))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("reading overlay %s: %w", OverlayPath(path, env), err)
+	}
+	return ParseEnv(path, env, data, overlayData)
+}
+
+// ParseEnv is LoadEnv on contents already read, such as the files of an
+// older git revision: data is the base configuration at path and overlay
+// the content of its lzctl.<env>.yaml overlay, nil when there is none.
+func ParseEnv(path, env string, data, overlay []byte) (*LZConfig, error) {
+	if env == "" {
+		return parseFile(path, data)
+	}
+	if !ValidEnvironmentName(env) {
+		return nil, fmt.Errorf("invalid environment name %q: use lowercase letters, digits and dashes", env)
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing config YAML: %w", err)
+	}
+	overlayPath := OverlayPath(path, env)
+	if overlay != nil {
+		var values map[string]any
+		if err := yaml.Unmarshal(overlay, &values); err != nil {
+			return nil, fmt.Errorf("parsing overlay %s: %w", overlayPath, err)
+		}
+		doc = Merge(doc, values)
+	}
+
+	merged, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("encoding merged config: %w", err)
+	}
+	cfg, err := parseFile(path, merged)
+	if err != nil {
+		return nil, err
+	}
+
+	_, declared := cfg.EnvironmentNamed(env)
+	switch {
+	case len(cfg.Spec.Environments) > 0 && !declared:
+		return nil, fmt.Errorf("environment %q is not declared in spec.environments (declared: %s)", env, strings.Join(cfg.EnvironmentNames(), ", "))
+	case !declared && overlay == nil:
+		return nil, fmt.Errorf("environment %q has no overlay: %s not found", env, overlayPath)
+	}
+	cfg.Metadata.Environment = env
+	return cfg, nil
+}
+
+// Merge returns base with overlay merged into it; neither is modified.
+//
+//   - Mappings are merged key by key, recursively.
+//   - Lists whose items are all mappings with a name key (landing zones,
+//     notification sinks, test assertions, ...) are merged by name: an
+//     overlay item is merged into the base item of the same name, and items
+//     with a new name are appended.
+//   - Any other value, other lists included, replaces the base value.
+//   - A null value removes the key.
+func Merge(base, overlay map[string]any) map[string]any {
+	out := make(map[string]any, len(base)+len(overlay))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range overlay {
+		if v == nil {
+			delete(out, k)
+			continue
+		}
+		out[k] = mergeValue(out[k], v)
+	}
+	return out
+}
+
+func mergeValue(base, overlay any) any {
+	switch o := overlay.(type) {
+	case map[string]any:
+		if b, ok := base.(map[string]any); ok {
+			return Merge(b, o)
+		}
+	case []any:
+		if b, ok := base.([]any); ok && namedItems(b) && namedItems(o) {
+			return mergeNamed(b, o)
+		}
+	}
+	return overlay
+}
+
+// namedItems reports whether every item of list is a mapping with a name.
+func namedItems(list []any) bool {
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok || m["name"] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeNamed(base, overlay []any) []any {
+	out := make([]any, len(base), len(base)+len(overlay))
+	copy(out, base)
+	index := make(map[string]int, len(base))
+	for i, item := range base {
+		index[fmt.Sprint(item.(map[string]any)["name"])] = i
+	}
+	for _, item := range overlay {
+		m := item.(map[string]any)
+		name := fmt.Sprint(m["name"])
+		if i, ok := index[name]; ok {
+			out[i] = Merge(out[i].(map[string]any), m)
+			continue
+		}
+		index[name] = len(out)
+		out = append(out, m)
+	}
+	return out
+}
+
+// EnvironmentNamed returns the environment declared under name.
+func (c *LZConfig) EnvironmentNamed(name string) (Environment, bool) {
+	for _, e := range c.Spec.Environments {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Environment{}, false
+}
+
+// EnvironmentNames returns the names of the declared environments.
+func (c *LZConfig) EnvironmentNames() []string {
+	names := make([]string, 0, len(c.Spec.Environments))
+	for _, e := range c.Spec.Environments {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+// Rings groups the declared environments by ring, lowest ring first. The
+// environments of a ring keep their declaration order.
+func (c *LZConfig) Rings() [][]Environment {
+	byRing := map[int][]Environment{}
+	var rings []int
+	for _, e := range c.Spec.Environments {
+		if _, ok := byRing[e.Ring]; !ok {
+			rings = append(rings, e.Ring)
+		}
+		byRing[e.Ring] = append(byRing[e.Ring], e)
+	}
+	sort.Ints(rings)
+	out := make([][]Environment, 0, len(rings))
+	for _, r := range rings {
+		out = append(out, byRing[r])
+	}
+	return out
+}
+
+// StateKey returns the backend key of the state blob named key for the
+// environment the configuration was loaded for: environments sharing a
+// state backend keep their state under <env>/.
+func (c *LZConfig) StateKey(key string) string {
+	if c == nil || c.Metadata.Environment == "" {
+		return key
+	}
+	return c.Metadata.Environment + "/" + key
+}
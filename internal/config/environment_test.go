@@ -0,0 +1,176 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envBaseYAML = `apiVersion: lzctl/v1
+kind: LandingZone
+metadata:
+  name: contoso
+  tenant: contoso.onmicrosoft.com
+  primaryRegion: westeurope
+spec:
+  platform:
+    managementGroups:
+      model: caf-lite
+    connectivity:
+      type: hub-spoke
+      hub:
+        region: westeurope
+        addressSpace: 10.0.0.0/16
+  governance: {}
+  naming:
+    convention: caf
+  stateBackend:
+    resourceGroup: rg-tfstate
+    storageAccount: sttfstate
+    container: tfstate
+    subscription: 00000000-0000-0000-0000-000000000000
+  landingZones:
+    - name: app
+      subscription: 11111111-1111-1111-1111-111111111111
+      archetype: corp
+      addressSpace: 10.1.0.0/24
+      tags:
+        owner: platform
+    - name: web
+      subscription: 22222222-2222-2222-2222-222222222222
+      archetype: online
+      addressSpace: 10.2.0.0/24
+  cicd:
+    platform: github-actions
+  environments:
+    - name: dev
+    - name: prod
+      ring: 1
+`
+
+func writeEnvConfig(t *testing.T, overlays map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lzctl.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(envBaseYAML), 0o644))
+	for env, content := range overlays {
+		require.NoError(t, os.WriteFile(OverlayPath(path, env), []byte(content), 0o644))
+	}
+	return path
+}
+
+func TestOverlayPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("repo", "lzctl.dev.yaml"), OverlayPath(filepath.Join("repo", "lzctl.yaml"), "dev"))
+	assert.Equal(t, "custom.prod.yml", OverlayPath("custom.yml", "prod"))
+}
+
+func TestMerge(t *testing.T) {
+	base := map[string]any{
+		"region": "westeurope",
+		"hub":    map[string]any{"addressSpace": "10.0.0.0/16", "firewall": map[string]any{"enabled": true}},
+		"zones": []any{
+			map[string]any{"name": "app", "addressSpace": "10.1.0.0/24", "tags": map[string]any{"owner": "platform"}},
+			map[string]any{"name": "web", "addressSpace": "10.2.0.0/24"},
+		},
+		"disabled": []any{"sandbox"},
+		"bastion":  true,
+	}
+	overlay := map[string]any{
+		"hub": map[string]any{"addressSpace": "10.100.0.0/16"},
+		"zones": []any{
+			map[string]any{"name": "app", "tags": map[string]any{"env": "dev"}},
+			map[string]any{"name": "batch", "addressSpace": "10.3.0.0/24"},
+		},
+		"disabled": []any{"decommissioned"},
+		"bastion":  nil,
+	}
+
+	got := Merge(base, overlay)
+	assert.Equal(t, map[string]any{
+		"region": "westeurope",
+		"hub":    map[string]any{"addressSpace": "10.100.0.0/16", "firewall": map[string]any{"enabled": true}},
+		"zones": []any{
+			map[string]any{"name": "app", "addressSpace": "10.1.0.0/24", "tags": map[string]any{"owner": "platform", "env": "dev"}},
+			map[string]any{"name": "web", "addressSpace": "10.2.0.0/24"},
+			map[string]any{"name": "batch", "addressSpace": "10.3.0.0/24"},
+		},
+		"disabled": []any{"decommissioned"},
+	}, got)
+	assert.Equal(t, "10.0.0.0/16", base["hub"].(map[string]any)["addressSpace"], "base is not modified")
+	assert.Contains(t, base, "bastion")
+}
+
+func TestLoadEnv_MergesOverlay(t *testing.T) {
+	path := writeEnvConfig(t, map[string]string{"dev": `
+metadata:
+  tenant: contoso-dev.onmicrosoft.com
+spec:
+  stateBackend:
+    storageAccount: sttfstatedev
+  landingZones:
+    - name: app
+      subscription: 33333333-3333-3333-3333-333333333333
+      tags:
+        environment: dev
+`})
+
+	cfg, err := LoadEnv(path, "dev")
+	require.NoError(t, err)
+	assert.Equal(t, "dev", cfg.Metadata.Environment)
+	assert.Equal(t, "contoso-dev.onmicrosoft.com", cfg.Metadata.Tenant)
+	assert.Equal(t, "contoso", cfg.Metadata.Name)
+	assert.Equal(t, "sttfstatedev", cfg.Spec.StateBackend.StorageAccount)
+	assert.Equal(t, "rg-tfstate", cfg.Spec.StateBackend.ResourceGroup)
+	require.Len(t, cfg.Spec.LandingZones, 2)
+	assert.Equal(t, "33333333-3333-3333-3333-333333333333", cfg.Spec.LandingZones[0].Subscription)
+	assert.Equal(t, "10.1.0.0/24", cfg.Spec.LandingZones[0].AddressSpace)
+	assert.Equal(t, map[string]string{"owner": "platform", "environment": "dev"}, cfg.Spec.LandingZones[0].Tags)
+	assert.Equal(t, "dev/platform-connectivity.tfstate", cfg.StateKey("platform-connectivity.tfstate"))
+
+	base, err := LoadEnv(path, "")
+	require.NoError(t, err)
+	assert.Empty(t, base.Metadata.Environment)
+	assert.Equal(t, "platform-connectivity.tfstate", base.StateKey("platform-connectivity.tfstate"))
+}
+
+func TestLoadEnv_DeclaredEnvironments(t *testing.T) {
+	path := writeEnvConfig(t, map[string]string{"qa": "metadata:\n  name: contoso-qa\n"})
+
+	cfg, err := LoadEnv(path, "prod")
+	require.NoError(t, err, "a declared environment may omit its overlay")
+	assert.Equal(t, "prod", cfg.Metadata.Environment)
+
+	_, err = LoadEnv(path, "qa")
+	assert.ErrorContains(t, err, `environment "qa" is not declared in spec.environments (declared: dev, prod)`)
+
+	_, err = LoadEnv(path, "Prod")
+	assert.ErrorContains(t, err, "invalid environment name")
+}
+
+func TestLoadEnv_UndeclaredEnvironmentNeedsOverlay(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lzctl.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("apiVersion: lzctl/v1\nkind: LandingZone\nmetadata:\n  name: contoso\n"), 0o644))
+
+	_, err := LoadEnv(path, "dev")
+	assert.ErrorContains(t, err, "has no overlay")
+
+	require.NoError(t, os.WriteFile(OverlayPath(path, "dev"), []byte("metadata:\n  name: contoso-dev\n"), 0o644))
+	cfg, err := LoadEnv(path, "dev")
+	require.NoError(t, err)
+	assert.Equal(t, "contoso-dev", cfg.Metadata.Name)
+}
+
+func TestRings(t *testing.T) {
+	cfg := &LZConfig{Spec: Spec{Environments: []Environment{
+		{Name: "prod", Ring: 2}, {Name: "dev"}, {Name: "test", Ring: 1}, {Name: "sandbox"},
+	}}}
+	rings := cfg.Rings()
+	require.Len(t, rings, 3)
+	assert.Equal(t, []Environment{{Name: "dev"}, {Name: "sandbox"}}, rings[0])
+	assert.Equal(t, []Environment{{Name: "test", Ring: 1}}, rings[1])
+	assert.Equal(t, []Environment{{Name: "prod", Ring: 2}}, rings[2])
+}
@@ -8,13 +8,41 @@ import (
 )
 
 // Load reads an lzctl.yaml file, parses it into an LZConfig struct,
-// and applies default values for optional fields.
+// and applies default values for optional fields. A file with an older
+// apiVersion is migrated in memory (see SchemaMigrations) and reported in
+// LZConfig.MigratedFrom; the file itself is left to 'lzctl config migrate'.
 func Load(path string) (*LZConfig, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading config file %s: %w", path, err)
 	}
-	return Parse(data)
+	return parseFile(path, data)
+}
+
+// parseFile parses the configuration read from path. When it uses an older
+// apiVersion, it is migrated in memory first and MigratedFrom records it.
+// An unknown apiVersion is left to schema validation.
+func parseFile(path string, data []byte) (*LZConfig, error) {
+	var head struct {
+		APIVersion string `yaml:"apiVersion"`
+	}
+	if err := yaml.Unmarshal(data, &head); err != nil {
+		return Parse(data)
+	}
+	if steps, err := SchemaMigrations.Path(head.APIVersion); err != nil || len(steps) == 0 {
+		return Parse(data)
+	}
+
+	migrated, _, err := SchemaMigrations.MigrateYAML(data, head.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg, err := Parse(migrated)
+	if err != nil {
+		return nil, err
+	}
+	cfg.MigratedFrom = head.APIVersion
+	return cfg, nil
 }
 
 // Parse parses raw YAML bytes into an LZConfig struct and applies defaults.
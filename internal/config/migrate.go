@@ -0,0 +1,290 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentAPIVersion is the apiVersion of the configuration this version of
+// lzctl reads and writes.
+const CurrentAPIVersion = "lzctl/v1"
+
+// ErrUnknownAPIVersion is returned for an apiVersion that no registered
+// migration leads from.
+var ErrUnknownAPIVersion = errors.New("unknown apiVersion")
+
+// SchemaMigrations holds the migration steps of lzctl.yaml. A release that
+// changes the schema incompatibly bumps CurrentAPIVersion and registers the
+// step from the previous apiVersion, so that existing repositories can be
+// upgraded with 'lzctl config migrate':
+//
+//	func init() {
+//		SchemaMigrations.Register(MigrationStep{
+//			From:        "lzctl/v1",
+//			To:          "lzctl/v2",
+//			Description: "spec.stateBackend moves to spec.state.backend",
+//			Apply: func(doc *Document) error {
+//				_, err := doc.Move("spec.stateBackend", "spec.state.backend")
+//				return err
+//			},
+//		})
+//	}
+var SchemaMigrations = NewMigrations(CurrentAPIVersion)
+
+// MigrationStep upgrades a configuration document from apiVersion From to
+// To. Apply runs on overlays too (lzctl.<env>.yaml), which hold part of a
+// configuration only: it must not fail when a path it rewrites is absent.
+type MigrationStep struct {
+	From        string
+	To          string
+	Description string
+	Apply       func(doc *Document) error
+}
+
+// Migrations is a chain of migration steps leading to a current apiVersion.
+type Migrations struct {
+	current string
+	steps   map[string]MigrationStep // keyed by From
+}
+
+// NewMigrations returns an empty chain leading to current.
+func NewMigrations(current string) *Migrations {
+	return &Migrations{current: current, steps: map[string]MigrationStep{}}
+}
+
+// Current returns the apiVersion the chain leads to.
+func (m *Migrations) Current() string {
+	return m.current
+}
+
+// Register adds step to the chain. Steps are registered at init time, so an
+// inconsistent step is a programming error and panics.
+func (m *Migrations) Register(step MigrationStep) {
+	switch {
+	case step.From == "" || step.To == "" || step.From == step.To:
+		panic(fmt.Sprintf("config: invalid migration step %q -> %q", step.From, step.To))
+	case step.From == m.current:
+		panic(fmt.Sprintf("config: migration step from the current apiVersion %q", step.From))
+	case step.Apply == nil:
+		panic(fmt.Sprintf("config: migration step %q -> %q has no Apply function", step.From, step.To))
+	}
+	if _, dup := m.steps[step.From]; dup {
+		panic(fmt.Sprintf("config: migration step from %q registered twice", step.From))
+	}
+	m.steps[step.From] = step
+}
+
+// Path returns the steps leading from apiVersion from to the current one,
+// in order. A configuration without apiVersion, or already current, needs
+// none.
+func (m *Migrations) Path(from string) ([]MigrationStep, error) {
+	if from == "" || from == m.current {
+		return nil, nil
+	}
+	var path []MigrationStep
+	for v := from; v != m.current; {
+		step, ok := m.steps[v]
+		if !ok || len(path) >= len(m.steps) {
+			return nil, fmt.Errorf("%w %q: no migration leads to %s", ErrUnknownAPIVersion, from, m.current)
+		}
+		path = append(path, step)
+		v = step.To
+	}
+	return path, nil
+}
+
+// MigrateYAML runs the steps leading from apiVersion from to the current
+// one on the YAML document data, and returns the migrated document with the
+// steps applied. An empty from is read from the document's apiVersion;
+// overlays, which usually have none, are migrated from their base file's.
+// The document's comments and key order are kept. When no step applies,
+// data is returned unchanged.
+func (m *Migrations) MigrateYAML(data []byte, from string) ([]byte, []MigrationStep, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("parsing config YAML: %w", err)
+	}
+	if root.Kind == 0 || (root.Kind == yaml.DocumentNode && len(root.Content) == 0) {
+		return data, nil, nil
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("parsing config YAML: the document is not a mapping")
+	}
+	doc := &Document{Root: root.Content[0]}
+
+	if from == "" {
+		if n, ok := doc.Get("apiVersion"); ok {
+			from = strings.TrimSpace(n.Value)
+		}
+	}
+	steps, err := m.Path(from)
+	if err != nil || len(steps) == 0 {
+		return data, nil, err
+	}
+	for _, step := range steps {
+		if err := step.Apply(doc); err != nil {
+			return nil, nil, fmt.Errorf("migrating %s to %s: %w", step.From, step.To, err)
+		}
+		if _, ok := doc.Get("apiVersion"); ok {
+			if err := doc.Set("apiVersion", step.To); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return nil, nil, fmt.Errorf("encoding migrated config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, fmt.Errorf("encoding migrated config: %w", err)
+	}
+	return buf.Bytes(), steps, nil
+}
+
+// OverlayPaths returns the overlays next to the configuration at path
+// (lzctl.<env>.yaml for lzctl.yaml), sorted.
+func OverlayPaths(path string) ([]string, error) {
+	ext := filepath.Ext(path)
+	matches, err := filepath.Glob(strings.TrimSuffix(path, ext) + ".*" + ext)
+	if err != nil {
+		return nil, fmt.Errorf("listing overlays of %s: %w", path, err)
+	}
+	overlays := make([]string, 0, len(matches))
+	prefix := strings.TrimSuffix(filepath.Base(path), ext) + "."
+	for _, match := range matches {
+		env := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(match), prefix), ext)
+		if ValidEnvironmentName(env) {
+			overlays = append(overlays, match)
+		}
+	}
+	sort.Strings(overlays)
+	return overlays, nil
+}
+
+// Document is a configuration document being migrated. Its helpers address
+// mapping keys by dotted path (spec.platform.identity) and keep the comments
+// and key order of the file; Root gives access to anything else, such as
+// list items.
+type Document struct {
+	Root *yaml.Node // top-level mapping
+}
+
+// Get returns the value at path.
+func (d *Document) Get(path string) (*yaml.Node, bool) {
+	node := d.Root
+	for _, key := range strings.Split(path, ".") {
+		if node.Kind != yaml.MappingNode {
+			return nil, false
+		}
+		i := mappingIndex(node, key)
+		if i < 0 {
+			return nil, false
+		}
+		node = node.Content[i+1]
+	}
+	return node, true
+}
+
+// Set sets the value at path to value, encoded as YAML, creating the
+// missing parent mappings.
+func (d *Document) Set(path string, value any) error {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	return d.setNode(path, &node)
+}
+
+// Delete removes the key at path and reports whether it was present.
+func (d *Document) Delete(path string) bool {
+	parent, key, ok := d.parent(path, false)
+	if !ok {
+		return false
+	}
+	i := mappingIndex(parent, key)
+	if i < 0 {
+		return false
+	}
+	parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+	return true
+}
+
+// Move moves the value at from to to, replacing any value there, and
+// reports whether from was present. When to cannot be set, from is
+// restored and the document is left unchanged.
+func (d *Document) Move(from, to string) (bool, error) {
+	parent, key, ok := d.parent(from, false)
+	if !ok {
+		return false, nil
+	}
+	i := mappingIndex(parent, key)
+	if i < 0 {
+		return false, nil
+	}
+	pair := []*yaml.Node{parent.Content[i], parent.Content[i+1]}
+	parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+	if err := d.setNode(to, pair[1]); err != nil {
+		parent.Content = append(parent.Content[:i], append(pair, parent.Content[i:]...)...)
+		return true, err
+	}
+	return true, nil
+}
+
+func (d *Document) setNode(path string, node *yaml.Node) error {
+	parent, key, ok := d.parent(path, true)
+	if !ok {
+		return fmt.Errorf("setting %s: a parent is not a mapping", path)
+	}
+	if i := mappingIndex(parent, key); i >= 0 {
+		parent.Content[i+1] = node
+		return nil
+	}
+	parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, node)
+	return nil
+}
+
+// parent returns the mapping holding the last key of path, creating the
+// missing mappings when create is set.
+func (d *Document) parent(path string, create bool) (*yaml.Node, string, bool) {
+	keys := strings.Split(path, ".")
+	node := d.Root
+	for _, key := range keys[:len(keys)-1] {
+		if node.Kind != yaml.MappingNode {
+			return nil, "", false
+		}
+		i := mappingIndex(node, key)
+		if i < 0 {
+			if !create {
+				return nil, "", false
+			}
+			child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+			node = child
+			continue
+		}
+		node = node.Content[i+1]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, "", false
+	}
+	return node, keys[len(keys)-1], true
+}
+
+// mappingIndex returns the index of key in the content of mapping, or -1.
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// testMigrations returns a v0 → v1 → v2 chain: v1 renames spec.state to
+// spec.stateBackend, v2 drops metadata.owner.
+func testMigrations() *Migrations {
+	m := NewMigrations("lzctl/v2")
+	m.Register(MigrationStep{From: "lzctl/v1", To: "lzctl/v2", Description: "metadata.owner is removed", Apply: func(doc *Document) error {
+		doc.Delete("metadata.owner")
+		return nil
+	}})
+	m.Register(MigrationStep{From: "lzctl/v0", To: "lzctl/v1", Description: "spec.state becomes spec.stateBackend", Apply: func(doc *Document) error {
+		_, err := doc.Move("spec.state", "spec.stateBackend")
+		return err
+	}})
+	return m
+}
+
+func TestMigrations_Path(t *testing.T) {
+	m := testMigrations()
+
+	steps, err := m.Path("lzctl/v0")
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, "lzctl/v1", steps[0].To)
+	assert.Equal(t, "lzctl/v2", steps[1].To)
+
+	steps, err = m.Path("lzctl/v2")
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+	steps, err = m.Path("")
+	require.NoError(t, err)
+	assert.Empty(t, steps, "no apiVersion defaults to the current one")
+
+	_, err = m.Path("lzctl/v9")
+	assert.ErrorIs(t, err, ErrUnknownAPIVersion)
+
+	assert.Panics(t, func() {
+		m.Register(MigrationStep{From: "lzctl/v1", To: "lzctl/v2", Apply: func(*Document) error { return nil }})
+	}, "a second step from the same apiVersion")
+	assert.Panics(t, func() {
+		m.Register(MigrationStep{From: "lzctl/v2", To: "lzctl/v3", Apply: func(*Document) error { return nil }})
+	}, "a step from the current apiVersion")
+}
+
+func TestMigrateYAML_KeepsCommentsAndOrder(t *testing.T) {
+	in := `# Contoso landing zone
+apiVersion: lzctl/v0
+kind: LandingZone
+metadata:
+  name: contoso # project name
+  owner: platform-team
+spec:
+  state:
+    resourceGroup: rg-tfstate
+    storageAccount: sttfstate
+  naming:
+    convention: caf
+`
+	out, steps, err := testMigrations().MigrateYAML([]byte(in), "")
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, `# Contoso landing zone
+apiVersion: lzctl/v2
+kind: LandingZone
+metadata:
+  name: contoso # project name
+spec:
+  naming:
+    convention: caf
+  stateBackend:
+    resourceGroup: rg-tfstate
+    storageAccount: sttfstate
+`, string(out))
+
+	same, steps, err := testMigrations().MigrateYAML(out, "")
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+	assert.Equal(t, out, same, "a current document is returned unchanged")
+}
+
+func TestMigrateYAML_Overlay(t *testing.T) {
+	overlay := "spec:\n  state:\n    storageAccount: sttfstatedev\n"
+	out, steps, err := testMigrations().MigrateYAML([]byte(overlay), "lzctl/v0")
+	require.NoError(t, err)
+	assert.Len(t, steps, 2)
+	assert.Equal(t, "spec:\n  stateBackend:\n    storageAccount: sttfstatedev\n", string(out), "no apiVersion is added to an overlay")
+
+	out, _, err = testMigrations().MigrateYAML([]byte("metadata:\n  name: contoso-dev\n"), "lzctl/v0")
+	require.NoError(t, err)
+	assert.Equal(t, "metadata:\n  name: contoso-dev\n", string(out), "absent paths are skipped")
+}
+
+func TestDocument_MoveKeepsSourceOnError(t *testing.T) {
+	var root yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte("spec:\n  state:\n    storageAccount: sttfstate\n  naming: short\n"), &root))
+	doc := &Document{Root: root.Content[0]}
+
+	moved, err := doc.Move("spec.state", "spec.naming.backend")
+	require.Error(t, err, "spec.naming is not a mapping")
+	assert.True(t, moved)
+	node, ok := doc.Get("spec.state.storageAccount")
+	require.True(t, ok, "the source is restored")
+	assert.Equal(t, "sttfstate", node.Value)
+	out, err := yaml.Marshal(doc.Root)
+	require.NoError(t, err)
+	assert.Equal(t, "spec:\n    state:\n        storageAccount: sttfstate\n    naming: short\n", string(out), "the key order is kept")
+
+	moved, err = doc.Move("spec.state", "spec.stateBackend")
+	require.NoError(t, err)
+	assert.True(t, moved)
+	_, ok = doc.Get("spec.state")
+	assert.False(t, ok)
+	node, ok = doc.Get("spec.stateBackend.storageAccount")
+	require.True(t, ok)
+	assert.Equal(t, "sttfstate", node.Value)
+
+	moved, err = doc.Move("spec.missing", "spec.other")
+	require.NoError(t, err)
+	assert.False(t, moved)
+}
+
+func TestLoad_MigratesOlderAPIVersionInMemory(t *testing.T) {
+	orig := SchemaMigrations
+	SchemaMigrations = NewMigrations(CurrentAPIVersion)
+	SchemaMigrations.Register(MigrationStep{From: "lzctl/v0", To: CurrentAPIVersion, Description: "spec.state becomes spec.stateBackend", Apply: func(doc *Document) error {
+		_, err := doc.Move("spec.state", "spec.stateBackend")
+		return err
+	}})
+	t.Cleanup(func() { SchemaMigrations = orig })
+
+	path := filepath.Join(t.TempDir(), "lzctl.yaml")
+	content := "apiVersion: lzctl/v0\nkind: LandingZone\nmetadata:\n  name: contoso\nspec:\n  state:\n    storageAccount: sttfstate\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, CurrentAPIVersion, cfg.APIVersion)
+	assert.Equal(t, "sttfstate", cfg.Spec.StateBackend.StorageAccount)
+	assert.Equal(t, "lzctl/v0", cfg.MigratedFrom, "the caller reports the migration")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data), "the file is left to lzctl config migrate")
+
+	require.NoError(t, os.WriteFile(path, []byte("apiVersion: lzctl/v9\nkind: LandingZone\n"), 0o644))
+	cfg, err = Load(path)
+	require.NoError(t, err, "an unknown apiVersion is left to schema validation")
+	assert.Equal(t, "lzctl/v9", cfg.APIVersion)
+	assert.Empty(t, cfg.MigratedFrom)
+}
+
+func TestOverlayPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"lzctl.yaml", "lzctl.prod.yaml", "lzctl.dev.yaml", "lzctl.Bad_Name.yaml", "other.dev.yaml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	overlays, err := OverlayPaths(filepath.Join(dir, "lzctl.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "lzctl.dev.yaml"), filepath.Join(dir, "lzctl.prod.yaml")}, overlays)
+}
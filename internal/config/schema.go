@@ -9,6 +9,10 @@ type LZConfig struct {
 	Kind       string   `yaml:"kind" json:"kind"`             // "LandingZone"
 	Metadata   Metadata `yaml:"metadata" json:"metadata"`
 	Spec       Spec     `yaml:"spec" json:"spec"`
+
+	// MigratedFrom is the apiVersion of the file when Load migrated it in
+	// memory; empty when the file already uses the current apiVersion.
+	MigratedFrom string `yaml:"-" json:"-"`
 }
 
 // Metadata holds top-level identification and region information.
@@ -17,6 +21,7 @@ type Metadata struct {
 	Tenant          string `yaml:"tenant" json:"tenant"`
 	PrimaryRegion   string `yaml:"primaryRegion" json:"primaryRegion"`
 	SecondaryRegion string `yaml:"secondaryRegion,omitempty" json:"secondaryRegion,omitempty"`
+	Environment     string `yaml:"environment,omitempty" json:"environment,omitempty"` // set by LoadEnv; empty for the base configuration
 }
 
 // Spec contains the full landing zone specification.
@@ -28,6 +33,58 @@ type Spec struct {
 	LandingZones []LandingZone `yaml:"landingZones" json:"landingZones"`
 	CICD         CICD          `yaml:"cicd" json:"cicd"`
 	Testing      *Testing      `yaml:"testing,omitempty" json:"testing,omitempty"`
+	Terraform    *Terraform    `yaml:"terraform,omitempty" json:"terraform,omitempty"`
+	Execution    *Execution    `yaml:"execution,omitempty" json:"execution,omitempty"`
+
+	Notifications *Notifications `yaml:"notifications,omitempty" json:"notifications,omitempty"`
+	Environments  []Environment  `yaml:"environments,omitempty" json:"environments,omitempty"`
+}
+
+// Environment is a deployment environment (dev, test, prod) sharing the
+// base lzctl.yaml. Its differences live in the lzctl.<name>.yaml overlay.
+type Environment struct {
+	Name string `yaml:"name" json:"name"`
+	Ring int    `yaml:"ring,omitempty" json:"ring,omitempty"` // promotion order; ring 0 deploys first
+}
+
+// Notifications lists the sinks lzctl posts drift results, failed applies
+// and policy status changes to.
+type Notifications struct {
+	Sinks []NotificationSink `yaml:"sinks" json:"sinks"`
+}
+
+// NotificationSink is one webhook endpoint. The URL is usually a secret, so
+// it can be read from an environment variable instead of lzctl.yaml.
+type NotificationSink struct {
+	Name   string   `yaml:"name" json:"name"`
+	Type   string   `yaml:"type" json:"type"`                         // "webhook" | "teams" | "slack"
+	URL    string   `yaml:"url,omitempty" json:"url,omitempty"`       // endpoint URL
+	URLEnv string   `yaml:"urlEnv,omitempty" json:"urlEnv,omitempty"` // environment variable holding the URL
+	Events []string `yaml:"events,omitempty" json:"events,omitempty"` // "drift" | "apply-failed" | "policy-status"; all when empty
+}
+
+// Terraform selects the Terraform-compatible tool lzctl orchestrates.
+// When unset, the repository's .terraform-version file is consulted, then
+// Terraform is used.
+type Terraform struct {
+	Binary  string `yaml:"binary,omitempty" json:"binary,omitempty"`   // "terraform" | "tofu" | path to an executable
+	Version string `yaml:"version,omitempty" json:"version,omitempty"` // minimum version, e.g. "1.9.0"
+}
+
+// Execution tunes how lzctl runs the tool in each root. It does not feed
+// any root, so changing it never invalidates plans.
+type Execution struct {
+	Timeout  string            `yaml:"timeout,omitempty" json:"timeout,omitempty"`   // per-root timeout, e.g. "45m"; empty means none
+	Timeouts map[string]string `yaml:"timeouts,omitempty" json:"timeouts,omitempty"` // per-root overrides keyed by root name ("connectivity", "lz:app1")
+	Retry    *ExecutionRetry   `yaml:"retry,omitempty" json:"retry,omitempty"`
+}
+
+// ExecutionRetry is the backoff applied to runs that fail with transient
+// errors (throttling, retryable provider errors, timeouts, lease contention).
+type ExecutionRetry struct {
+	MaxAttempts int    `yaml:"maxAttempts,omitempty" json:"maxAttempts,omitempty"` // 1 disables retries
+	BaseDelay   string `yaml:"baseDelay,omitempty" json:"baseDelay,omitempty"`     // e.g. "10s"
+	MaxDelay    string `yaml:"maxDelay,omitempty" json:"maxDelay,omitempty"`       // e.g. "2m"
 }
 
 // Testing holds native Terraform test generation settings.
@@ -53,6 +110,15 @@ type Platform struct {
 	Connectivity     ConnectivityConfig     `yaml:"connectivity" json:"connectivity"`
 	Identity         IdentityConfig         `yaml:"identity" json:"identity"`
 	Management       ManagementConfig       `yaml:"management" json:"management"`
+	Subscriptions    *PlatformSubscriptions `yaml:"subscriptions,omitempty" json:"subscriptions,omitempty"`
+}
+
+// PlatformSubscriptions lists the dedicated platform subscriptions, when the
+// platform is not deployed into the state backend subscription alone.
+type PlatformSubscriptions struct {
+	Management   string `yaml:"management,omitempty" json:"management,omitempty"`
+	Connectivity string `yaml:"connectivity,omitempty" json:"connectivity,omitempty"`
+	Identity     string `yaml:"identity,omitempty" json:"identity,omitempty"`
 }
 
 // ManagementGroupsConfig defines the management group hierarchy model.
@@ -0,0 +1,33 @@
+package config
+
+import "strings"
+
+// AllowedSubscriptions returns the subscription IDs lzctl.yaml declares: the
+// state backend subscription, the platform subscriptions and every landing
+// zone subscription. IDs are lowercased and deduplicated; placeholders such
+// as "<subscription-id>" are skipped. Plans are only allowed to target these
+// subscriptions.
+func AllowedSubscriptions(cfg *LZConfig) []string {
+	if cfg == nil {
+		return nil
+	}
+	candidates := []string{cfg.Spec.StateBackend.Subscription}
+	if subs := cfg.Spec.Platform.Subscriptions; subs != nil {
+		candidates = append(candidates, subs.Management, subs.Connectivity, subs.Identity)
+	}
+	for _, zone := range cfg.Spec.LandingZones {
+		candidates = append(candidates, zone.Subscription)
+	}
+
+	seen := make(map[string]bool, len(candidates))
+	var out []string
+	for _, c := range candidates {
+		id := strings.ToLower(strings.TrimSpace(c))
+		if isPlaceholder(id) || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
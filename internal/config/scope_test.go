@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedSubscriptions(t *testing.T) {
+	cfg := &LZConfig{
+		Spec: Spec{
+			Platform: Platform{Subscriptions: &PlatformSubscriptions{
+				Connectivity: "22222222-2222-4222-8222-222222222222",
+				Identity:     "<identity-subscription>",
+			}},
+			StateBackend: StateBackend{Subscription: "00000000-0000-4000-8000-00000000000A"},
+			LandingZones: []LandingZone{
+				{Name: "corp", Subscription: "11111111-1111-4111-8111-111111111111"},
+				{Name: "online", Subscription: "<subscription-id>"},
+				{Name: "corp-b", Subscription: "22222222-2222-4222-8222-222222222222"},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"00000000-0000-4000-8000-00000000000a",
+		"22222222-2222-4222-8222-222222222222",
+		"11111111-1111-4111-8111-111111111111",
+	}, AllowedSubscriptions(cfg))
+	assert.Nil(t, AllowedSubscriptions(nil))
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// TenantsDir holds the tenants of a multi-tenant repository: each tenant has
+// its own manifest, tenants/<name>/lzctl.yaml, and its own generated tree
+// next to it.
+const TenantsDir = "tenants"
+
+// AllTenants is the tenant name selecting every tenant of the repository.
+const AllTenants = "all"
+
+// ValidTenantName reports whether name can name a tenant directory:
+// lowercase letters and digits separated by single dashes. "all" is
+// reserved for AllTenants.
+func ValidTenantName(name string) bool {
+	return name != AllTenants && environmentNameRE.MatchString(name)
+}
+
+// TenantDir returns the directory of tenant name under repoRoot.
+func TenantDir(repoRoot, name string) string {
+	return filepath.Join(repoRoot, TenantsDir, name)
+}
+
+// TenantConfigPath returns the manifest of tenant name under repoRoot.
+func TenantConfigPath(repoRoot, name string) string {
+	return filepath.Join(TenantDir(repoRoot, name), "lzctl.yaml")
+}
+
+// DiscoverTenants returns the names of the tenants under repoRoot, sorted:
+// the directories of tenants/ that hold an lzctl.yaml. A repository without
+// a tenants/ directory has none.
+func DiscoverTenants(repoRoot string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(repoRoot, TenantsDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing tenants: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() || !ValidTenantName(e.Name()) {
+			continue
+		}
+		if info, statErr := os.Stat(TenantConfigPath(repoRoot, e.Name())); statErr == nil && !info.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
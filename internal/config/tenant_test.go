@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidTenantName(t *testing.T) {
+	assert.True(t, ValidTenantName("contoso"))
+	assert.True(t, ValidTenantName("fabrikam-eu"))
+	assert.False(t, ValidTenantName("all"), "all selects every tenant")
+	assert.False(t, ValidTenantName("Contoso"))
+	assert.False(t, ValidTenantName("../contoso"))
+	assert.False(t, ValidTenantName(""))
+}
+
+func TestDiscoverTenants(t *testing.T) {
+	repo := t.TempDir()
+	names, err := DiscoverTenants(repo)
+	require.NoError(t, err)
+	assert.Empty(t, names, "no tenants/ directory")
+
+	for _, name := range []string{"fabrikam", "contoso", "draft", "Invalid"} {
+		require.NoError(t, os.MkdirAll(TenantDir(repo, name), 0o755))
+	}
+	for _, name := range []string{"fabrikam", "contoso", "Invalid"} {
+		require.NoError(t, os.WriteFile(TenantConfigPath(repo, name), []byte("apiVersion: lzctl/v1\n"), 0o644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(repo, TenantsDir, "README.md"), []byte("tenants"), 0o644))
+
+	names, err = DiscoverTenants(repo)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"contoso", "fabrikam"}, names, "directories without lzctl.yaml or with an invalid name are skipped")
+}
@@ -0,0 +1,157 @@
+// Package cost estimates monthly cost deltas from Terraform plans using a
+// local price catalog. It never calls a pricing API: the built-in catalog is
+// versioned with lzctl and can be overridden per repository, so estimates
+// are reproducible and work offline.
+package cost
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultOverridePath is the repository-relative location of the catalog
+// override maintained by FinOps.
+const DefaultOverridePath = ".lzctl/price-catalog.yaml"
+
+// Wildcard is the fallback SKU and region key in a catalog.
+const Wildcard = "*"
+
+//go:embed catalog.yaml
+var builtinCatalog []byte
+
+// Catalog maps resource types to monthly unit prices by SKU and region.
+type Catalog struct {
+	Version   string                     `yaml:"version" json:"version"`
+	Currency  string                     `yaml:"currency" json:"currency"`
+	Resources map[string]ResourcePricing `yaml:"resources" json:"-"`
+
+	// Override is the path of the override merged into the built-in
+	// catalog, if any.
+	Override string `yaml:"-" json:"override,omitempty"`
+}
+
+// ResourcePricing prices one resource type.
+type ResourcePricing struct {
+	// SKU is the attribute path holding the SKU, e.g. sku_tier or
+	// sku.0.name. Empty when the type has a single price.
+	SKU string `yaml:"sku,omitempty"`
+	// Quantity is an optional attribute path multiplying the unit price,
+	// e.g. instances.
+	Quantity string `yaml:"quantity,omitempty"`
+	// Prices maps SKU → region → monthly unit price.
+	Prices map[string]map[string]float64 `yaml:"prices"`
+}
+
+// Builtin returns the catalog shipped with lzctl.
+func Builtin() (*Catalog, error) {
+	c, err := parseCatalog(builtinCatalog)
+	if err != nil {
+		return nil, fmt.Errorf("cost: built-in catalog: %w", err)
+	}
+	return c, nil
+}
+
+// Load returns the built-in catalog merged with the override at path. A
+// missing override file is not an error.
+func Load(path string) (*Catalog, error) {
+	c, err := Builtin()
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cost: reading %s: %w", path, err)
+	}
+	override, err := parseCatalog(data)
+	if err != nil {
+		return nil, fmt.Errorf("cost: %s: %w", path, err)
+	}
+	if err := c.merge(override); err != nil {
+		return nil, fmt.Errorf("cost: %s: %w", path, err)
+	}
+	c.Override = path
+	return c, nil
+}
+
+func parseCatalog(data []byte) (*Catalog, error) {
+	var c Catalog
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing catalog: %w", err)
+	}
+	for typ, rp := range c.Resources {
+		for sku, regions := range rp.Prices {
+			for region, price := range regions {
+				if price < 0 {
+					return nil, fmt.Errorf("%s: negative price for SKU %q in %s", typ, sku, region)
+				}
+			}
+		}
+	}
+	return &c, nil
+}
+
+// merge applies o on top of c. Prices are merged per SKU and region; a
+// non-empty sku or quantity attribute in o replaces c's.
+func (c *Catalog) merge(o *Catalog) error {
+	if o.Currency != "" && o.Currency != c.Currency {
+		return fmt.Errorf("currency %s does not match the built-in catalog (%s)", o.Currency, c.Currency)
+	}
+	if o.Version != "" {
+		c.Version = c.Version + "+" + o.Version
+	} else {
+		c.Version += "+local"
+	}
+	if c.Resources == nil {
+		c.Resources = map[string]ResourcePricing{}
+	}
+	for typ, orp := range o.Resources {
+		rp := c.Resources[typ]
+		if orp.SKU != "" {
+			rp.SKU = orp.SKU
+		}
+		if orp.Quantity != "" {
+			rp.Quantity = orp.Quantity
+		}
+		if rp.Prices == nil {
+			rp.Prices = map[string]map[string]float64{}
+		}
+		for sku, regions := range orp.Prices {
+			if rp.Prices[sku] == nil {
+				rp.Prices[sku] = map[string]float64{}
+			}
+			for region, price := range regions {
+				rp.Prices[sku][region] = price
+			}
+		}
+		c.Resources[typ] = rp
+	}
+	return nil
+}
+
+// price returns the monthly unit price of a resource type for a SKU in a
+// region, falling back to the wildcard region.
+func (c *Catalog) price(typ, sku, region string) (float64, bool) {
+	rp, ok := c.Resources[typ]
+	if !ok {
+		return 0, false
+	}
+	regions, ok := rp.Prices[sku]
+	if !ok {
+		return 0, false
+	}
+	if p, ok := regions[region]; ok {
+		return p, true
+	}
+	p, ok := regions[Wildcard]
+	return p, ok
+}
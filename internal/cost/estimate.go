@@ -0,0 +1,220 @@
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ResourceCost is the estimated monthly cost of one planned resource
+// change. Only resource types present in the catalog are reported.
+type ResourceCost struct {
+	Address string  `json:"address"`
+	Type    string  `json:"type"`
+	Action  string  `json:"action"` // create | update | delete | replace
+	SKU     string  `json:"sku,omitempty"`
+	Region  string  `json:"region,omitempty"`
+	Before  float64 `json:"before"`
+	After   float64 `json:"after"`
+	Delta   float64 `json:"delta"`
+	// Unpriced explains why the change could not be priced; Before, After
+	// and Delta are then zero.
+	Unpriced string `json:"unpriced,omitempty"`
+}
+
+// Estimate is the monthly cost delta of one plan.
+type Estimate struct {
+	Currency  string         `json:"currency"`
+	Before    float64        `json:"before"`
+	After     float64        `json:"after"`
+	Delta     float64        `json:"delta"`
+	Resources []ResourceCost `json:"resources"`
+}
+
+// Unpriced returns the number of changes that could not be priced.
+func (e *Estimate) Unpriced() int {
+	n := 0
+	for _, r := range e.Resources {
+		if r.Unpriced != "" {
+			n++
+		}
+	}
+	return n
+}
+
+type planJSON struct {
+	FormatVersion   string `json:"format_version"`
+	ResourceChanges []struct {
+		Address string `json:"address"`
+		Mode    string `json:"mode"`
+		Type    string `json:"type"`
+		Change  struct {
+			Actions []string       `json:"actions"`
+			Before  map[string]any `json:"before"`
+			After   map[string]any `json:"after"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+// EstimateFile estimates the plan JSON file at path.
+func EstimateFile(path string, c *Catalog) (*Estimate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cost: reading %s: %w", path, err)
+	}
+	return EstimatePlan(data, c)
+}
+
+// EstimatePlan prices the resource changes of `terraform show -json` output.
+// The old configuration of updated, replaced and deleted resources is
+// priced from the change's before values and the new one from its after
+// values; the delta is their difference.
+func EstimatePlan(data []byte, c *Catalog) (*Estimate, error) {
+	var plan planJSON
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("cost: parsing plan JSON: %w", err)
+	}
+	if plan.FormatVersion == "" {
+		return nil, fmt.Errorf("cost: not a terraform plan JSON document (missing format_version)")
+	}
+
+	e := &Estimate{Currency: c.Currency, Resources: []ResourceCost{}}
+	for _, rc := range plan.ResourceChanges {
+		if rc.Mode == "data" {
+			continue
+		}
+		rp, known := c.Resources[rc.Type]
+		if !known {
+			continue
+		}
+		action := classify(rc.Change.Actions)
+		if action == "" {
+			continue
+		}
+
+		rcost := ResourceCost{Address: rc.Address, Type: rc.Type, Action: action}
+		if action != "create" {
+			before, sku, region, why := c.priceOf(rc.Type, rp, rc.Change.Before)
+			rcost.Before, rcost.SKU, rcost.Region, rcost.Unpriced = before, sku, region, why
+		}
+		if action != "delete" && rcost.Unpriced == "" {
+			beforeSKU := rcost.SKU
+			after, sku, region, why := c.priceOf(rc.Type, rp, rc.Change.After)
+			rcost.After, rcost.SKU, rcost.Region, rcost.Unpriced = after, sku, region, why
+			if beforeSKU != "" && sku != "" && beforeSKU != sku {
+				rcost.SKU = beforeSKU + " → " + sku
+			}
+		}
+		if rcost.Unpriced != "" {
+			rcost.Before, rcost.After = 0, 0
+		}
+		rcost.Delta = round(rcost.After - rcost.Before)
+		if action == "update" && rcost.Delta == 0 && rcost.Unpriced == "" {
+			continue // price-neutral update, e.g. tags
+		}
+
+		e.Before += rcost.Before
+		e.After += rcost.After
+		e.Resources = append(e.Resources, rcost)
+	}
+	e.Before, e.After = round(e.Before), round(e.After)
+	e.Delta = round(e.After - e.Before)
+	return e, nil
+}
+
+// priceOf prices one side of a change. It returns the reason when the
+// values do not resolve to a catalog entry.
+func (c *Catalog) priceOf(typ string, rp ResourcePricing, values map[string]any) (price float64, sku, region, unpriced string) {
+	if values == nil {
+		return 0, "", "", "no values in plan"
+	}
+	sku = Wildcard
+	if rp.SKU != "" {
+		s, ok := lookup(values, rp.SKU).(string)
+		if !ok || s == "" {
+			return 0, "", "", fmt.Sprintf("%s not known until apply", rp.SKU)
+		}
+		sku = s
+	}
+	location, _ := lookup(values, "location").(string)
+	region = NormalizeRegion(location)
+	if region == "" {
+		region = Wildcard
+	}
+	unit, ok := c.price(typ, sku, region)
+	if !ok {
+		return 0, sku, region, fmt.Sprintf("no price for SKU %s in %s", sku, region)
+	}
+	qty := 1.0
+	if rp.Quantity != "" {
+		switch q := lookup(values, rp.Quantity).(type) {
+		case float64:
+			qty = q
+		case nil:
+			return 0, sku, region, fmt.Sprintf("%s not known until apply", rp.Quantity)
+		default:
+			return 0, sku, region, fmt.Sprintf("%s is not a number", rp.Quantity)
+		}
+	}
+	if sku == Wildcard {
+		sku = ""
+	}
+	return round(unit * qty), sku, region, ""
+}
+
+// lookup resolves a dotted attribute path such as sku.0.name.
+func lookup(v any, path string) any {
+	for _, step := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[step]
+		case []any:
+			i, err := strconv.Atoi(step)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// NormalizeRegion turns "West Europe" into "westeurope".
+func NormalizeRegion(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
+
+func classify(actions []string) string {
+	hasCreate := slices.Contains(actions, "create")
+	hasDelete := slices.Contains(actions, "delete")
+	switch {
+	case hasCreate && hasDelete:
+		return "replace"
+	case hasCreate:
+		return "create"
+	case hasDelete:
+		return "delete"
+	case slices.Contains(actions, "update"):
+		return "update"
+	}
+	return ""
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Format renders an amount with an explicit sign, e.g. "+912.50 USD", or
+// "+912.50" when currency is empty.
+func Format(amount float64, currency string) string {
+	if currency == "" {
+		return fmt.Sprintf("%+.2f", amount)
+	}
+	return fmt.Sprintf("%+.2f %s", amount, currency)
+}
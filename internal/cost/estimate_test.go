@@ -0,0 +1,157 @@
+package cost
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const samplePlan = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {"address": "azurerm_firewall.hub", "mode": "managed", "type": "azurerm_firewall",
+     "change": {"actions": ["create"], "before": null, "after": {"sku_tier": "Premium", "location": "westeurope"}}},
+    {"address": "azurerm_virtual_network_gateway.vpn", "mode": "managed", "type": "azurerm_virtual_network_gateway",
+     "change": {"actions": ["update"], "before": {"sku": "VpnGw1", "location": "West Europe"}, "after": {"sku": "VpnGw2", "location": "West Europe"}}},
+    {"address": "azurerm_public_ip.old", "mode": "managed", "type": "azurerm_public_ip",
+     "change": {"actions": ["delete"], "before": {"sku": "Standard", "location": "westeurope"}, "after": null}},
+    {"address": "azurerm_public_ip.tags", "mode": "managed", "type": "azurerm_public_ip",
+     "change": {"actions": ["update"], "before": {"sku": "Standard", "location": "westeurope", "tags": {}}, "after": {"sku": "Standard", "location": "westeurope", "tags": {"a": "b"}}}},
+    {"address": "azurerm_linux_virtual_machine_scale_set.agents", "mode": "managed", "type": "azurerm_linux_virtual_machine_scale_set",
+     "change": {"actions": ["create"], "before": null, "after": {"sku": "Standard_D2s_v5", "location": "westeurope", "instances": 3}}},
+    {"address": "azurerm_bastion_host.this", "mode": "managed", "type": "azurerm_bastion_host",
+     "change": {"actions": ["create"], "before": null, "after": {"sku": "Ultra", "location": "westeurope"}}},
+    {"address": "azurerm_resource_group.rg", "mode": "managed", "type": "azurerm_resource_group",
+     "change": {"actions": ["create"], "before": null, "after": {"location": "westeurope"}}},
+    {"address": "data.azurerm_public_ip.x", "mode": "data", "type": "azurerm_public_ip",
+     "change": {"actions": ["read"], "before": null, "after": {"sku": "Standard"}}}
+  ]
+}`
+
+func TestEstimatePlan(t *testing.T) {
+	c, err := Builtin()
+	require.NoError(t, err)
+
+	e, err := EstimatePlan([]byte(samplePlan), c)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", e.Currency)
+
+	byAddr := map[string]ResourceCost{}
+	for _, r := range e.Resources {
+		byAddr[r.Address] = r
+	}
+	require.Len(t, byAddr, 5, "unknown types, data sources and price-neutral updates are skipped")
+
+	fw := byAddr["azurerm_firewall.hub"]
+	assert.Equal(t, 1277.50, fw.After)
+	assert.Equal(t, 1277.50, fw.Delta)
+	assert.Equal(t, "Premium", fw.SKU)
+
+	vpn := byAddr["azurerm_virtual_network_gateway.vpn"]
+	assert.Equal(t, 138.70, vpn.Before)
+	assert.Equal(t, 357.70, vpn.After)
+	assert.Equal(t, 219.0, vpn.Delta)
+	assert.Equal(t, "VpnGw1 → VpnGw2", vpn.SKU)
+	assert.Equal(t, "westeurope", vpn.Region)
+
+	assert.Equal(t, -3.65, byAddr["azurerm_public_ip.old"].Delta)
+
+	vmss := byAddr["azurerm_linux_virtual_machine_scale_set.agents"]
+	assert.Equal(t, 240.90, vmss.After, "regional price times instances")
+
+	bastion := byAddr["azurerm_bastion_host.this"]
+	assert.Equal(t, "no price for SKU Ultra in westeurope", bastion.Unpriced)
+	assert.Zero(t, bastion.Delta)
+	assert.Equal(t, 1, e.Unpriced())
+
+	assert.Equal(t, 142.35, e.Before)
+	assert.Equal(t, 1876.10, e.After)
+	assert.Equal(t, 1733.75, e.Delta)
+}
+
+func TestEstimatePlan_UnknownSKU(t *testing.T) {
+	c, err := Builtin()
+	require.NoError(t, err)
+
+	e, err := EstimatePlan([]byte(`{"format_version": "1.2", "resource_changes": [
+	  {"address": "azurerm_firewall.hub", "type": "azurerm_firewall",
+	   "change": {"actions": ["create"], "before": null, "after": {"location": "westeurope"}}}]}`), c)
+	require.NoError(t, err)
+	require.Len(t, e.Resources, 1)
+	assert.Equal(t, "sku_tier not known until apply", e.Resources[0].Unpriced)
+}
+
+func TestEstimatePlan_RejectsNonPlan(t *testing.T) {
+	c, err := Builtin()
+	require.NoError(t, err)
+	_, err = EstimatePlan([]byte(`{}`), c)
+	assert.Error(t, err)
+}
+
+func TestLoad_MergesOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "price-catalog.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`version: finops-7
+resources:
+  azurerm_firewall:
+    prices:
+      Premium: { westeurope: 1000 }
+  azurerm_storage_account:
+    sku: account_tier
+    prices:
+      Standard: { "*": 20 }
+`), 0o644))
+
+	c, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2026.03+finops-7", c.Version)
+	assert.Equal(t, path, c.Override)
+
+	p, ok := c.price("azurerm_firewall", "Premium", "westeurope")
+	assert.True(t, ok)
+	assert.Equal(t, 1000.0, p, "override wins for its region")
+	p, _ = c.price("azurerm_firewall", "Premium", "eastus")
+	assert.Equal(t, 1277.50, p, "built-in fallback kept")
+	assert.Equal(t, "sku_tier", c.Resources["azurerm_firewall"].SKU)
+	_, ok = c.price("azurerm_storage_account", "Standard", "eastus")
+	assert.True(t, ok, "new types can be added")
+}
+
+func TestLoad_MissingOverride(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "2026.03", c.Version)
+	assert.Empty(t, c.Override)
+}
+
+func TestLoad_RejectsCurrencyMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "price-catalog.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("currency: EUR\n"), 0o644))
+	_, err := Load(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "currency EUR")
+}
+
+func TestMarkdown(t *testing.T) {
+	c, err := Builtin()
+	require.NoError(t, err)
+	e, err := EstimatePlan([]byte(samplePlan), c)
+	require.NoError(t, err)
+
+	md := Markdown([]Root{
+		{Name: "connectivity", Estimate: e},
+		{Name: "lz:app", Zone: "app", Estimate: &Estimate{Currency: "USD", After: 10, Delta: 10}},
+		{Name: "lz:app-blueprint", Zone: "app", Estimate: &Estimate{Currency: "USD", After: 5, Delta: 5}},
+		{Name: "identity"},
+	}, c)
+
+	assert.Contains(t, md, "### Estimated monthly cost")
+	assert.Contains(t, md, "| `connectivity` | 142.35 | 1876.10 | +1733.75 |")
+	assert.Contains(t, md, "| `identity` | – | – | – |")
+	assert.Contains(t, md, "| **Total** | | | **+1748.75 USD** |")
+	assert.Contains(t, md, "| `app` | +15.00 USD |")
+	assert.Contains(t, md, "price catalog 2026.03")
+	assert.Contains(t, md, "1 change(s) could not be priced")
+}
@@ -0,0 +1,100 @@
+package cost
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Root pairs a root with its estimate for reporting. Zone is the landing
+// zone the root belongs to, empty for platform layers. A nil Estimate means
+// the root had no plan JSON to price.
+type Root struct {
+	Name     string
+	Zone     string
+	Estimate *Estimate
+}
+
+// Total sums the deltas of roots.
+func Total(roots []Root) float64 {
+	total := 0.0
+	for _, r := range roots {
+		if r.Estimate != nil {
+			total += r.Estimate.Delta
+		}
+	}
+	return round(total)
+}
+
+// ByZone sums the deltas of the roots of each landing zone (the zone and
+// its blueprint).
+func ByZone(roots []Root) map[string]float64 {
+	zones := map[string]float64{}
+	for _, r := range roots {
+		if r.Zone == "" || r.Estimate == nil {
+			continue
+		}
+		zones[r.Zone] = round(zones[r.Zone] + r.Estimate.Delta)
+	}
+	return zones
+}
+
+// Markdown renders the cost section of a PR-comment plan summary: monthly
+// deltas per root and landing zone, then the priced resources of each root.
+func Markdown(roots []Root, c *Catalog) string {
+	var sb strings.Builder
+	sb.WriteString("### Estimated monthly cost\n\n")
+	sb.WriteString("| Root | Before | After | Delta |\n")
+	sb.WriteString("|------|-------:|------:|------:|\n")
+	unpriced := 0
+	for _, r := range roots {
+		if r.Estimate == nil {
+			fmt.Fprintf(&sb, "| `%s` | – | – | – |\n", r.Name)
+			continue
+		}
+		e := r.Estimate
+		unpriced += e.Unpriced()
+		fmt.Fprintf(&sb, "| `%s` | %.2f | %.2f | %s |\n", r.Name, e.Before, e.After, Format(e.Delta, ""))
+	}
+	fmt.Fprintf(&sb, "| **Total** | | | **%s** |\n\n", Format(Total(roots), c.Currency))
+
+	zones := ByZone(roots)
+	zoneOrder := []string{}
+	for _, r := range roots {
+		if _, ok := zones[r.Zone]; ok && r.Zone != "" && !slices.Contains(zoneOrder, r.Zone) {
+			zoneOrder = append(zoneOrder, r.Zone)
+		}
+	}
+	if len(zoneOrder) > 0 {
+		sb.WriteString("| Landing zone | Delta |\n")
+		sb.WriteString("|--------------|------:|\n")
+		for _, z := range zoneOrder {
+			fmt.Fprintf(&sb, "| `%s` | %s |\n", z, Format(zones[z], c.Currency))
+		}
+		sb.WriteString("\n")
+	}
+
+	for _, r := range roots {
+		if r.Estimate == nil || len(r.Estimate.Resources) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "<details><summary><code>%s</code> — %s</summary>\n\n", r.Name, Format(r.Estimate.Delta, c.Currency))
+		sb.WriteString("| Action | Address | SKU | Region | Delta |\n")
+		sb.WriteString("|--------|---------|-----|--------|------:|\n")
+		for _, rc := range r.Estimate.Resources {
+			delta := Format(rc.Delta, "")
+			if rc.Unpriced != "" {
+				delta = "_" + rc.Unpriced + "_"
+			}
+			fmt.Fprintf(&sb, "| %s | `%s` | %s | %s | %s |\n", rc.Action, rc.Address, rc.SKU, rc.Region, delta)
+		}
+		sb.WriteString("\n</details>\n\n")
+	}
+
+	fmt.Fprintf(&sb, "_Estimated from list prices in price catalog %s (%s, 730 hours/month). Usage-based charges are not included", c.Version, c.Currency)
+	if unpriced > 0 {
+		fmt.Fprintf(&sb, "; %d change(s) could not be priced", unpriced)
+	}
+	sb.WriteString("._\n")
+	return sb.String()
+}
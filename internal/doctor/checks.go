@@ -10,8 +10,9 @@ import (
 	"fmt"
 	"os/exec"
 	"regexp"
-	"strconv"
 	"strings"
+
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
 )
 
 // Status represents the outcome of a single check.
@@ -72,7 +73,13 @@ type Summary struct {
 
 // RunAll executes all checks and returns a summary.
 func RunAll(ctx context.Context, executor CmdExecutor) Summary {
-	checks := AllChecks()
+	return RunAllFor(ctx, executor, orchestrator.DefaultToolSpec())
+}
+
+// RunAllFor executes all checks, verifying tool (Terraform or OpenTofu, as
+// selected for the repository) instead of the default Terraform binary.
+func RunAllFor(ctx context.Context, executor CmdExecutor, tool orchestrator.ToolSpec) Summary {
+	checks := AllChecksFor(tool)
 	results := make([]CheckResult, 0, len(checks))
 	for _, c := range checks {
 		r := c.Run(ctx, executor)
@@ -103,8 +110,14 @@ func buildSummary(results []CheckResult, checks []Check) Summary {
 
 // AllChecks returns the ordered list of prerequisite checks.
 func AllChecks() []Check {
+	return AllChecksFor(orchestrator.DefaultToolSpec())
+}
+
+// AllChecksFor returns the ordered list of prerequisite checks with the
+// Terraform check targeting tool.
+func AllChecksFor(tool orchestrator.ToolSpec) []Check {
 	return []Check{
-		checkTerraform(),
+		checkTerraformTool(tool),
 		checkAzCLI(),
 		checkGit(),
 		checkGH(),
@@ -121,13 +134,51 @@ func AllChecks() []Check {
 // --- Tool version checks ---
 
 func checkTerraform() Check {
+	return checkTerraformTool(orchestrator.DefaultToolSpec())
+}
+
+// checkTerraformTool verifies the Terraform-compatible binary against the
+// same minimum version that plan, apply and drift enforce.
+func checkTerraformTool(tool orchestrator.ToolSpec) Check {
 	return Check{
-		Name:     "terraform",
+		Name:     tool.Tool,
 		Category: "tool",
 		Critical: true,
 		Run: func(ctx context.Context, ex CmdExecutor) CheckResult {
-			return checkToolVersion(ctx, ex, "terraform", []string{"version", "-json"}, `"terraform_version"\s*:\s*"([^"]+)"`, "1.5.0",
-				"Install Terraform >= 1.5.0: https://developer.hashicorp.com/terraform/install")
+			out, err := ex.Run(ctx, tool.Binary, "version", "-json")
+			if err != nil {
+				return CheckResult{
+					Name:    tool.Tool,
+					Status:  StatusFail,
+					Message: fmt.Sprintf("%s not found or not in PATH", tool.Binary),
+					Fix:     tool.InstallHint(),
+				}
+			}
+			version, ok := orchestrator.ParseVersionOutput(out)
+			if !ok {
+				return CheckResult{
+					Name:    tool.Tool,
+					Status:  StatusWarn,
+					Message: fmt.Sprintf("%s found but could not parse version from output", tool.Binary),
+				}
+			}
+			if !orchestrator.VersionAtLeast(version, tool.MinVersion) {
+				return CheckResult{
+					Name:    tool.Tool,
+					Status:  StatusFail,
+					Message: fmt.Sprintf("%s %s found, but >= %s required", tool.Tool, version, tool.MinVersion),
+					Fix:     tool.InstallHint(),
+				}
+			}
+			msg := fmt.Sprintf("%s %s", tool.Tool, version)
+			if tool.Source != "" && tool.Source != "default" {
+				msg += fmt.Sprintf(" (selected by %s)", tool.Source)
+			}
+			return CheckResult{
+				Name:    tool.Tool,
+				Status:  StatusPass,
+				Message: msg,
+			}
 		},
 	}
 }
@@ -319,28 +370,7 @@ func checkToolVersion(ctx context.Context, ex CmdExecutor, tool string, args []s
 
 // semverGTE returns true if version >= min (simple major.minor.patch comparison).
 func semverGTE(version, min string) bool {
-	v := parseSemver(version)
-	m := parseSemver(min)
-	if v[0] != m[0] {
-		return v[0] > m[0]
-	}
-	if v[1] != m[1] {
-		return v[1] > m[1]
-	}
-	return v[2] >= m[2]
-}
-
-func parseSemver(s string) [3]int {
-	parts := strings.SplitN(s, ".", 3)
-	var result [3]int
-	for i := 0; i < 3 && i < len(parts); i++ {
-		// Strip any suffix (e.g. "1.5.0-rc1" → "1", "5", "0")
-		numStr := strings.SplitN(parts[i], "-", 2)[0]
-		numStr = strings.SplitN(numStr, "+", 2)[0]
-		n, _ := strconv.Atoi(numStr)
-		result[i] = n
-	}
-	return result
+	return orchestrator.VersionAtLeast(version, min)
 }
 
 // extractJSONField does a simple regex extraction for "field": "value" from JSON.
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -446,3 +447,28 @@ func TestStatusIcon(t *testing.T) {
 	assert.Equal(t, "[WARN]", StatusIcon(StatusWarn))
 	assert.Equal(t, "[SKIP]", StatusIcon(StatusSkip))
 }
+
+func TestCheckTerraformTool_OpenTofu(t *testing.T) {
+	ex := newMockExecutor()
+	ex.Set(`{"terraform_version": "1.8.2"}`, nil, "tofu", "version", "-json")
+	tool := orchestrator.ToolSpec{Tool: orchestrator.ToolOpenTofu, Binary: "tofu", MinVersion: "1.8.0", Source: ".terraform-version"}
+
+	r := checkTerraformTool(tool).Run(context.Background(), ex)
+
+	assert.Equal(t, StatusPass, r.Status)
+	assert.Equal(t, "tofu", r.Name)
+	assert.Contains(t, r.Message, "tofu 1.8.2")
+	assert.Contains(t, r.Message, ".terraform-version")
+}
+
+func TestCheckTerraformTool_PinnedVersionTooOld(t *testing.T) {
+	ex := newMockExecutor()
+	ex.Set(`{"terraform_version": "1.7.5"}`, nil, "terraform", "version", "-json")
+	tool := orchestrator.ToolSpec{Tool: orchestrator.ToolTerraform, Binary: "terraform", MinVersion: "1.9.0", Source: "lzctl.yaml"}
+
+	r := checkTerraformTool(tool).Run(context.Background(), ex)
+
+	assert.Equal(t, StatusFail, r.Status)
+	assert.Contains(t, r.Message, ">= 1.9.0")
+	assert.Contains(t, r.Fix, "Terraform >= 1.9.0")
+}
@@ -0,0 +1,214 @@
+// Package driftwatch keeps the latest drift check result of each root in
+// memory for `lzctl drift watch` and serves it over HTTP: /metrics in the
+// Prometheus text exposition format and /healthz for liveness probes.
+package driftwatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LayerResult is the outcome of one drift check of one root.
+type LayerResult struct {
+	Layer          string
+	Kind           string
+	Status         string // in-sync | drifted | pending-changes | both | error
+	DriftItems     int
+	PendingChanges int
+	Duration       time.Duration
+	Error          string
+}
+
+type layerState struct {
+	LayerResult
+	lastSuccess time.Time
+	errors      int
+}
+
+// Store holds the latest result per root plus counters across checks. It is
+// safe for concurrent use.
+type Store struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu            sync.RWMutex
+	started       time.Time
+	layers        map[string]*layerState
+	checks        int
+	checkFailures int
+	lastCheck     time.Time
+	lastCheckErr  string
+}
+
+// NewStore returns an empty store for checks run every interval.
+func NewStore(interval time.Duration) *Store {
+	s := &Store{interval: interval, now: time.Now, layers: map[string]*layerState{}}
+	s.started = s.now()
+	return s
+}
+
+// Record stores the results of one check. checkErr is set when the check
+// could not run at all (for example, the repository could not be read); the
+// previous per-root results are then kept. Roots missing from a successful
+// check are dropped.
+func (s *Store) Record(results []LayerResult, checkErr error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.checks++
+	s.lastCheck = now
+	if checkErr != nil {
+		s.checkFailures++
+		s.lastCheckErr = checkErr.Error()
+		return
+	}
+	s.lastCheckErr = ""
+
+	seen := make(map[string]bool, len(results))
+	for _, r := range results {
+		seen[r.Layer] = true
+		st, ok := s.layers[r.Layer]
+		if !ok {
+			st = &layerState{}
+			s.layers[r.Layer] = st
+		}
+		st.LayerResult = r
+		if r.Error != "" {
+			st.errors++
+		} else {
+			st.lastSuccess = now
+		}
+	}
+	for name := range s.layers {
+		if !seen[name] {
+			delete(s.layers, name)
+		}
+	}
+}
+
+// Healthy reports whether checks are still completing: the last check
+// finished within two intervals or, before the first check completes, the
+// watcher started less than two intervals ago.
+func (s *Store) Healthy() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ref := s.lastCheck
+	if ref.IsZero() {
+		ref = s.started
+	}
+	return s.now().Sub(ref) <= 2*s.interval
+}
+
+// Handler serves /metrics and /healthz.
+func (s *Store) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		s.WriteMetrics(w)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.RLock()
+		body := map[string]interface{}{
+			"checks":    s.checks,
+			"lastError": s.lastCheckErr,
+		}
+		if !s.lastCheck.IsZero() {
+			body["lastCheck"] = s.lastCheck.UTC().Format(time.RFC3339)
+		}
+		s.mu.RUnlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if s.Healthy() {
+			body["status"] = "ok"
+		} else {
+			body["status"] = "stale"
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	})
+	return mux
+}
+
+type metric struct {
+	name, help, typ string
+	samples         []sample
+}
+
+type sample struct {
+	labels [][2]string
+	value  float64
+}
+
+// WriteMetrics writes all metrics in the Prometheus text format.
+func (s *Store) WriteMetrics(w io.Writer) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.layers))
+	for name := range s.layers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	items := metric{name: "lzctl_drift_items", typ: "gauge", help: "Resources changed or deleted outside Terraform, per root, in the last check."}
+	pending := metric{name: "lzctl_drift_pending_changes", typ: "gauge", help: "Unapplied code changes per root in the last check."}
+	status := metric{name: "lzctl_drift_layer_status", typ: "gauge", help: "Current classification of each root (in-sync, drifted, pending-changes, both or error)."}
+	success := metric{name: "lzctl_drift_last_success_timestamp_seconds", typ: "gauge", help: "Unix time of the last successful check of each root (0 if never)."}
+	duration := metric{name: "lzctl_drift_plan_duration_seconds", typ: "gauge", help: "Time spent planning each root in the last check."}
+	errs := metric{name: "lzctl_drift_errors_total", typ: "counter", help: "Failed checks per root."}
+
+	for _, name := range names {
+		st := s.layers[name]
+		labels := [][2]string{{"layer", st.Layer}, {"kind", st.Kind}}
+		items.samples = append(items.samples, sample{labels, float64(st.DriftItems)})
+		pending.samples = append(pending.samples, sample{labels, float64(st.PendingChanges)})
+		status.samples = append(status.samples, sample{append(labels[:2:2], [2]string{"status", st.Status}), 1})
+		success.samples = append(success.samples, sample{labels, unixSeconds(st.lastSuccess)})
+		duration.samples = append(duration.samples, sample{labels, st.Duration.Seconds()})
+		errs.samples = append(errs.samples, sample{labels, float64(st.errors)})
+	}
+
+	metrics := []metric{
+		items, pending, status, success, duration, errs,
+		{name: "lzctl_drift_checks_total", typ: "counter", help: "Drift checks run since the watcher started.",
+			samples: []sample{{nil, float64(s.checks)}}},
+		{name: "lzctl_drift_check_failures_total", typ: "counter", help: "Drift checks that could not run at all.",
+			samples: []sample{{nil, float64(s.checkFailures)}}},
+		{name: "lzctl_drift_last_check_timestamp_seconds", typ: "gauge", help: "Unix time the last drift check finished (0 if none yet).",
+			samples: []sample{{nil, unixSeconds(s.lastCheck)}}},
+	}
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.typ)
+		for _, smp := range m.samples {
+			fmt.Fprintf(w, "%s%s %s\n", m.name, formatLabels(smp.labels), strconv.FormatFloat(smp.value, 'f', -1, 64))
+		}
+	}
+}
+
+func unixSeconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels [][2]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(labels))
+	for _, l := range labels {
+		parts = append(parts, l[0]+`="`+labelEscaper.Replace(l[1])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
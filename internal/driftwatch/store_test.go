@@ -0,0 +1,109 @@
+package driftwatch
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore returns a store whose clock is controlled by the test.
+func newTestStore(interval time.Duration) (*Store, *time.Time) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := NewStore(interval)
+	s.now = func() time.Time { return now }
+	s.started = now
+	return s, &now
+}
+
+func TestWriteMetrics(t *testing.T) {
+	s, _ := newTestStore(time.Hour)
+	s.Record([]LayerResult{
+		{Layer: "identity", Kind: "platform", Status: "in-sync", Duration: 1500 * time.Millisecond},
+		{Layer: "connectivity", Kind: "platform", Status: "both", DriftItems: 2, PendingChanges: 1, Duration: 2 * time.Second},
+		{Layer: "lz:app-one", Kind: "landing-zone", Status: "error", Error: "terraform init failed"},
+	}, nil)
+
+	var sb strings.Builder
+	s.WriteMetrics(&sb)
+	out := sb.String()
+
+	for _, want := range []string{
+		"# TYPE lzctl_drift_items gauge",
+		`lzctl_drift_items{layer="connectivity",kind="platform"} 2`,
+		`lzctl_drift_items{layer="identity",kind="platform"} 0`,
+		`lzctl_drift_pending_changes{layer="connectivity",kind="platform"} 1`,
+		`lzctl_drift_layer_status{layer="connectivity",kind="platform",status="both"} 1`,
+		`lzctl_drift_last_success_timestamp_seconds{layer="identity",kind="platform"} 1772366400`,
+		`lzctl_drift_last_success_timestamp_seconds{layer="lz:app-one",kind="landing-zone"} 0`,
+		`lzctl_drift_plan_duration_seconds{layer="identity",kind="platform"} 1.5`,
+		"# TYPE lzctl_drift_errors_total counter",
+		`lzctl_drift_errors_total{layer="lz:app-one",kind="landing-zone"} 1`,
+		"lzctl_drift_checks_total 1",
+		"lzctl_drift_check_failures_total 0",
+	} {
+		assert.Contains(t, out, want)
+	}
+	assert.Less(t, strings.Index(out, `layer="connectivity"`), strings.Index(out, `layer="identity"`), "roots are sorted")
+}
+
+func TestRecord_KeepsResultsOnCheckFailure(t *testing.T) {
+	s, _ := newTestStore(time.Hour)
+	s.Record([]LayerResult{{Layer: "identity", Kind: "platform", Status: "drifted", DriftItems: 3}}, nil)
+	s.Record(nil, errors.New("reading repository"))
+
+	var sb strings.Builder
+	s.WriteMetrics(&sb)
+	assert.Contains(t, sb.String(), `lzctl_drift_items{layer="identity",kind="platform"} 3`)
+	assert.Contains(t, sb.String(), "lzctl_drift_check_failures_total 1")
+
+	s.Record([]LayerResult{{Layer: "management", Kind: "platform", Status: "in-sync"}}, nil)
+	sb.Reset()
+	s.WriteMetrics(&sb)
+	assert.NotContains(t, sb.String(), `layer="identity"`, "removed roots are dropped")
+}
+
+func TestRecord_CountsErrorsPerLayer(t *testing.T) {
+	s, now := newTestStore(time.Hour)
+	first := *now
+	s.Record([]LayerResult{{Layer: "identity", Kind: "platform", Status: "in-sync"}}, nil)
+	*now = now.Add(time.Hour)
+	s.Record([]LayerResult{{Layer: "identity", Kind: "platform", Status: "error", Error: "boom"}}, nil)
+	*now = now.Add(time.Hour)
+	s.Record([]LayerResult{{Layer: "identity", Kind: "platform", Status: "error", Error: "boom"}}, nil)
+
+	st := s.layers["identity"]
+	assert.Equal(t, 2, st.errors)
+	assert.Equal(t, first, st.lastSuccess, "errors keep the last success time")
+}
+
+func TestHandler_Healthz(t *testing.T) {
+	s, now := newTestStore(time.Minute)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	get := func(path string) int {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, get("/healthz"), "healthy while the first check runs")
+	*now = now.Add(3 * time.Minute)
+	assert.Equal(t, http.StatusServiceUnavailable, get("/healthz"), "first check never finished")
+
+	s.Record(nil, nil)
+	assert.Equal(t, http.StatusOK, get("/healthz"))
+	*now = now.Add(90 * time.Second)
+	assert.Equal(t, http.StatusOK, get("/healthz"))
+	*now = now.Add(time.Minute)
+	assert.Equal(t, http.StatusServiceUnavailable, get("/healthz"), "checks stopped completing")
+
+	assert.Equal(t, http.StatusOK, get("/metrics"))
+}
@@ -0,0 +1,196 @@
+// Package notify posts lzctl events — drift results, failed applies and
+// policy status changes — to the webhook sinks configured under
+// spec.notifications in lzctl.yaml.
+//
+// Each sink has a payload format: "webhook" posts the Event as JSON,
+// "teams" posts an Adaptive Card message for Teams incoming webhooks and
+// workflows, and "slack" posts a Block Kit message for Slack incoming
+// webhooks. Deliveries failing with a network error, 429 or a 5xx status
+// are retried with backoff; other failures are reported at once.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/kjourdan1/lzctl/internal/azure"
+	"github.com/kjourdan1/lzctl/internal/config"
+)
+
+// Event types a sink can subscribe to.
+const (
+	EventDrift        = "drift"
+	EventApplyFailed  = "apply-failed"
+	EventPolicyStatus = "policy-status"
+)
+
+// Sink payload formats.
+const (
+	FormatWebhook = "webhook"
+	FormatTeams   = "teams"
+	FormatSlack   = "slack"
+)
+
+// Severities reported on Event.Severity.
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Fact is one name/value line of an event, e.g. a layer and its status.
+type Fact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Event is one notification.
+type Event struct {
+	Type      string    `json:"type"`
+	Severity  string    `json:"severity"`
+	Project   string    `json:"project,omitempty"` // metadata.name of the landing zone
+	Title     string    `json:"title"`
+	Summary   string    `json:"summary"`
+	Facts     []Fact    `json:"facts,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Details   any       `json:"details,omitempty"` // full report, for generic webhooks only
+}
+
+// Sink is a resolved notification endpoint.
+type Sink struct {
+	Name   string
+	Format string
+	URL    string
+	Events []string
+}
+
+// Wants reports whether the sink subscribes to eventType.
+func (s Sink) Wants(eventType string) bool {
+	return len(s.Events) == 0 || slices.Contains(s.Events, eventType)
+}
+
+// Notifier delivers events to its sinks.
+type Notifier struct {
+	Sinks      []Sink
+	HTTPClient *http.Client
+	Retry      azure.RetryConfig
+}
+
+// New resolves the sinks of cfg, reading URLs from the environment where
+// configured. It returns nil when cfg has no sinks.
+func New(cfg *config.Notifications) (*Notifier, error) {
+	if cfg == nil || len(cfg.Sinks) == 0 {
+		return nil, nil
+	}
+	n := &Notifier{
+		HTTPClient: &http.Client{Timeout: 15 * time.Second},
+		Retry:      azure.DefaultRetryConfig(),
+	}
+	for _, sc := range cfg.Sinks {
+		switch sc.Type {
+		case FormatWebhook, FormatTeams, FormatSlack:
+		default:
+			return nil, fmt.Errorf("notification sink %q: unsupported type %q (want webhook, teams or slack)", sc.Name, sc.Type)
+		}
+		url := sc.URL
+		if sc.URLEnv != "" {
+			url = os.Getenv(sc.URLEnv)
+			if url == "" {
+				return nil, fmt.Errorf("notification sink %q: environment variable %s is not set", sc.Name, sc.URLEnv)
+			}
+		}
+		if url == "" {
+			return nil, fmt.Errorf("notification sink %q: url or urlEnv is required", sc.Name)
+		}
+		n.Sinks = append(n.Sinks, Sink{Name: sc.Name, Format: sc.Type, URL: url, Events: sc.Events})
+	}
+	return n, nil
+}
+
+// Notify posts e to every sink subscribed to its type. A failing sink does
+// not stop delivery to the others; all failures are returned together.
+func (n *Notifier) Notify(ctx context.Context, e Event) error {
+	if n == nil {
+		return nil
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+	var errs []error
+	for _, s := range n.Sinks {
+		if !s.Wants(e.Type) {
+			continue
+		}
+		body, err := Payload(s.Format, e)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sink %s: %w", s.Name, err))
+			continue
+		}
+		if err := n.deliver(ctx, s.URL, body); err != nil {
+			errs = append(errs, fmt.Errorf("sink %s: %w", s.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver posts body to url, retrying transient failures with backoff
+// until n.Retry.MaxAttempts is reached or ctx is done.
+func (n *Notifier) deliver(ctx context.Context, url string, body []byte) error {
+	attempts := n.Retry.MaxAttempts
+	if attempts <= 0 {
+		attempts = azure.DefaultMaxRetryAttempts
+	}
+	for attempt := 0; ; attempt++ {
+		transient, err := n.post(ctx, url, body)
+		if err == nil || !transient || ctx.Err() != nil {
+			return err
+		}
+		if attempt+1 >= attempts {
+			return fmt.Errorf("after %d attempts: %w", attempts, err)
+		}
+		if serr := sleepContext(ctx, n.Retry.Delay(attempt)); serr != nil {
+			return err
+		}
+	}
+}
+
+// post sends body to url once. transient reports whether the failure is
+// worth retrying: a network error, 429 Too Many Requests or a 5xx status.
+func (n *Notifier) post(ctx context.Context, url string, body []byte) (transient bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "lzctl")
+
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		transient := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return transient, fmt.Errorf("webhook returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return false, nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
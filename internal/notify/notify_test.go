@@ -0,0 +1,234 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kjourdan1/lzctl/internal/azure"
+	"github.com/kjourdan1/lzctl/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a local webhook endpoint. It answers status (503 when unset)
+// to the first `failures` requests, then records the bodies it accepts.
+type recorder struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	bodies   [][]byte
+	attempts int
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+	if req.Header.Get("Content-Type") != "application/json" {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+	if r.attempts <= r.failures {
+		if r.status == 0 {
+			r.status = http.StatusServiceUnavailable
+		}
+		w.WriteHeader(r.status)
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(http.StatusOK)
+}
+
+func fastRetry() azure.RetryConfig {
+	return azure.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+}
+
+func testEvent() Event {
+	return Event{
+		Type:      EventDrift,
+		Severity:  SeverityWarning,
+		Project:   "contoso",
+		Title:     "Drift detected",
+		Summary:   "2 drift item(s) in 1 layer(s)",
+		Facts:     []Fact{{Name: "connectivity", Value: "drifted ~2 -0"}},
+		Timestamp: time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC),
+	}
+}
+
+func newTestNotifier(t *testing.T, sinks ...config.NotificationSink) *Notifier {
+	t.Helper()
+	n, err := New(&config.Notifications{Sinks: sinks})
+	require.NoError(t, err)
+	n.Retry = fastRetry()
+	return n
+}
+
+func TestNotify_PostsEachFormat(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	n := newTestNotifier(t,
+		config.NotificationSink{Name: "hook", Type: FormatWebhook, URL: srv.URL},
+		config.NotificationSink{Name: "teams", Type: FormatTeams, URL: srv.URL},
+		config.NotificationSink{Name: "slack", Type: FormatSlack, URL: srv.URL},
+	)
+	require.NoError(t, n.Notify(context.Background(), testEvent()))
+	require.Len(t, rec.bodies, 3)
+
+	var hook Event
+	require.NoError(t, json.Unmarshal(rec.bodies[0], &hook))
+	assert.Equal(t, EventDrift, hook.Type)
+	assert.Equal(t, "connectivity", hook.Facts[0].Name)
+
+	var teams struct {
+		Type        string `json:"type"`
+		Attachments []struct {
+			ContentType string `json:"contentType"`
+			Content     struct {
+				Type string           `json:"type"`
+				Body []map[string]any `json:"body"`
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	require.NoError(t, json.Unmarshal(rec.bodies[1], &teams))
+	assert.Equal(t, "message", teams.Type)
+	require.Len(t, teams.Attachments, 1)
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", teams.Attachments[0].ContentType)
+	assert.Equal(t, "AdaptiveCard", teams.Attachments[0].Content.Type)
+	assert.Equal(t, "Drift detected", teams.Attachments[0].Content.Body[0]["text"])
+	assert.Equal(t, "FactSet", teams.Attachments[0].Content.Body[2]["type"])
+
+	var slack struct {
+		Text   string           `json:"text"`
+		Blocks []map[string]any `json:"blocks"`
+	}
+	require.NoError(t, json.Unmarshal(rec.bodies[2], &slack))
+	assert.Equal(t, "Drift detected: 2 drift item(s) in 1 layer(s)", slack.Text)
+	assert.Equal(t, "header", slack.Blocks[0]["type"])
+	assert.Contains(t, string(rec.bodies[2]), "*connectivity*")
+}
+
+func TestNotify_RetriesTransientFailures(t *testing.T) {
+	rec := &recorder{failures: 2}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	n := newTestNotifier(t, config.NotificationSink{Name: "hook", Type: FormatWebhook, URL: srv.URL})
+	require.NoError(t, n.Notify(context.Background(), testEvent()))
+	assert.Equal(t, 3, rec.attempts)
+	assert.Len(t, rec.bodies, 1)
+}
+
+func TestNotify_DoesNotRetryPermanentFailures(t *testing.T) {
+	rec := &recorder{failures: 100, status: http.StatusBadRequest}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	n := newTestNotifier(t, config.NotificationSink{Name: "hook", Type: FormatWebhook, URL: srv.URL})
+	err := n.Notify(context.Background(), testEvent())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webhook returned 400")
+	assert.Equal(t, 1, rec.attempts)
+
+	rec = &recorder{failures: 1, status: http.StatusTooManyRequests}
+	srv429 := httptest.NewServer(rec)
+	defer srv429.Close()
+	n = newTestNotifier(t, config.NotificationSink{Name: "hook", Type: FormatWebhook, URL: srv429.URL})
+	require.NoError(t, n.Notify(context.Background(), testEvent()))
+	assert.Equal(t, 2, rec.attempts, "429 is retried")
+}
+
+func TestNotify_StopsWaitingWhenCancelled(t *testing.T) {
+	rec := &recorder{failures: 100}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	n := newTestNotifier(t, config.NotificationSink{Name: "hook", Type: FormatWebhook, URL: srv.URL})
+	n.Retry = azure.RetryConfig{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := n.Notify(ctx, testEvent())
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Equal(t, 1, rec.attempts)
+}
+
+func TestNotify_ReportsFailureAfterRetries(t *testing.T) {
+	failing := httptest.NewServer(&recorder{failures: 100})
+	defer failing.Close()
+	ok := &recorder{}
+	okSrv := httptest.NewServer(ok)
+	defer okSrv.Close()
+
+	n := newTestNotifier(t,
+		config.NotificationSink{Name: "broken", Type: FormatWebhook, URL: failing.URL},
+		config.NotificationSink{Name: "fine", Type: FormatSlack, URL: okSrv.URL},
+	)
+	err := n.Notify(context.Background(), testEvent())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sink broken")
+	assert.Contains(t, err.Error(), "after 3 attempts")
+	assert.Len(t, ok.bodies, 1, "other sinks still receive the event")
+}
+
+func TestNotify_FiltersByEvent(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	n := newTestNotifier(t, config.NotificationSink{Name: "applies", Type: FormatWebhook, URL: srv.URL, Events: []string{EventApplyFailed}})
+	require.NoError(t, n.Notify(context.Background(), testEvent()))
+	assert.Empty(t, rec.bodies)
+
+	e := testEvent()
+	e.Type = EventApplyFailed
+	require.NoError(t, n.Notify(context.Background(), e))
+	assert.Len(t, rec.bodies, 1)
+}
+
+func TestNew_ResolvesURLFromEnvironment(t *testing.T) {
+	t.Setenv("LZCTL_TEST_WEBHOOK", "https://hooks.example.com/abc")
+	n, err := New(&config.Notifications{Sinks: []config.NotificationSink{{Name: "teams", Type: FormatTeams, URLEnv: "LZCTL_TEST_WEBHOOK"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.example.com/abc", n.Sinks[0].URL)
+
+	_, err = New(&config.Notifications{Sinks: []config.NotificationSink{{Name: "teams", Type: FormatTeams, URLEnv: "LZCTL_TEST_UNSET"}}})
+	assert.ErrorContains(t, err, "LZCTL_TEST_UNSET is not set")
+
+	_, err = New(&config.Notifications{Sinks: []config.NotificationSink{{Name: "x", Type: "email", URL: "https://example.com"}}})
+	assert.ErrorContains(t, err, "unsupported type")
+
+	n, err = New(nil)
+	require.NoError(t, err)
+	assert.Nil(t, n)
+	assert.NoError(t, n.Notify(context.Background(), testEvent()), "a nil notifier is a no-op")
+}
+
+func TestPayload_SlackSplitsFields(t *testing.T) {
+	e := testEvent()
+	for i := 0; i < 12; i++ {
+		e.Facts = append(e.Facts, Fact{Name: "layer", Value: "drifted"})
+	}
+	data, err := Payload(FormatSlack, e)
+	require.NoError(t, err)
+	var slack struct {
+		Blocks []struct {
+			Type   string `json:"type"`
+			Fields []any  `json:"fields"`
+		} `json:"blocks"`
+	}
+	require.NoError(t, json.Unmarshal(data, &slack))
+	require.Len(t, slack.Blocks, 5, "header, summary, two field sections, context")
+	assert.Len(t, slack.Blocks[2].Fields, 10)
+	assert.Len(t, slack.Blocks[3].Fields, 3)
+}
@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// slackFieldsPerSection is Slack's limit on fields in one section block.
+const slackFieldsPerSection = 10
+
+// Payload renders e in the given sink format.
+func Payload(format string, e Event) ([]byte, error) {
+	switch format {
+	case FormatWebhook:
+		return json.Marshal(e)
+	case FormatTeams:
+		return json.Marshal(teamsPayload(e))
+	case FormatSlack:
+		return json.Marshal(slackPayload(e))
+	}
+	return nil, fmt.Errorf("unsupported payload format %q", format)
+}
+
+// teamsPayload wraps an Adaptive Card in the message envelope accepted by
+// Teams incoming webhooks and workflow webhooks.
+func teamsPayload(e Event) map[string]any {
+	body := []map[string]any{
+		{"type": "TextBlock", "text": e.Title, "weight": "Bolder", "size": "Medium", "wrap": true, "color": teamsColor(e.Severity)},
+		{"type": "TextBlock", "text": e.Summary, "wrap": true},
+	}
+	if len(e.Facts) > 0 {
+		facts := make([]map[string]string, 0, len(e.Facts))
+		for _, f := range e.Facts {
+			facts = append(facts, map[string]string{"title": f.Name, "value": f.Value})
+		}
+		body = append(body, map[string]any{"type": "FactSet", "facts": facts})
+	}
+	body = append(body, map[string]any{
+		"type": "TextBlock", "text": contextLine(e), "isSubtle": true, "size": "Small", "wrap": true,
+	})
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
+
+func teamsColor(severity string) string {
+	switch severity {
+	case SeverityError:
+		return "Attention"
+	case SeverityWarning:
+		return "Warning"
+	}
+	return "Good"
+}
+
+// slackPayload builds a Block Kit message. text is the notification
+// fallback shown where blocks are not rendered.
+func slackPayload(e Event) map[string]any {
+	blocks := []map[string]any{
+		{"type": "header", "text": map[string]any{"type": "plain_text", "text": slackIcon(e.Severity) + " " + e.Title}},
+		{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": e.Summary}},
+	}
+	for start := 0; start < len(e.Facts); start += slackFieldsPerSection {
+		end := min(start+slackFieldsPerSection, len(e.Facts))
+		fields := make([]map[string]any, 0, end-start)
+		for _, f := range e.Facts[start:end] {
+			fields = append(fields, map[string]any{"type": "mrkdwn", "text": fmt.Sprintf("*%s*\n%s", f.Name, f.Value)})
+		}
+		blocks = append(blocks, map[string]any{"type": "section", "fields": fields})
+	}
+	blocks = append(blocks, map[string]any{
+		"type":     "context",
+		"elements": []map[string]any{{"type": "mrkdwn", "text": contextLine(e)}},
+	})
+	return map[string]any{
+		"text":   e.Title + ": " + e.Summary,
+		"blocks": blocks,
+	}
+}
+
+func slackIcon(severity string) string {
+	switch severity {
+	case SeverityError:
+		return "❌"
+	case SeverityWarning:
+		return "⚠️"
+	}
+	return "ℹ️"
+}
+
+// contextLine names the source of the event: "lzctl · <project> · <time>".
+func contextLine(e Event) string {
+	parts := []string{"lzctl"}
+	if e.Project != "" {
+		parts = append(parts, e.Project)
+	}
+	parts = append(parts, e.Timestamp.UTC().Format("2006-01-02 15:04 MST"))
+	return strings.Join(parts, " · ")
+}
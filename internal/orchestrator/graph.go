@@ -0,0 +1,182 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Edge sources.
+const (
+	EdgeImplicit    = "implicit"     // zone → platform, blueprint → zone, CAF platform chain as a fallback
+	EdgeRemoteState = "remote-state" // derived from a terraform_remote_state data source
+)
+
+// Edge is a dependency: From waits for To.
+type Edge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Source string `json:"source"`
+	Key    string `json:"key,omitempty"` // state key for remote-state edges
+}
+
+// Graph is a dependency graph over named roots. Node order is preserved and
+// used to break ties when ordering.
+type Graph struct {
+	nodes []string
+	kinds map[string]string
+	edges []Edge
+}
+
+// NewGraph returns an empty graph.
+func NewGraph() *Graph {
+	return &Graph{kinds: map[string]string{}}
+}
+
+// AddNode registers a root with an optional kind label used when rendering.
+func (g *Graph) AddNode(id, kind string) {
+	if _, ok := g.kinds[id]; ok {
+		return
+	}
+	g.nodes = append(g.nodes, id)
+	g.kinds[id] = kind
+}
+
+// AddEdge records that e.From depends on e.To. Self-edges are dropped and a
+// remote-state edge supersedes an implicit edge between the same nodes.
+func (g *Graph) AddEdge(e Edge) {
+	if e.From == e.To {
+		return
+	}
+	for i, existing := range g.edges {
+		if existing.From == e.From && existing.To == e.To {
+			if existing.Source == EdgeImplicit && e.Source == EdgeRemoteState {
+				g.edges[i] = e
+			}
+			return
+		}
+	}
+	g.edges = append(g.edges, e)
+}
+
+// Edges returns the graph edges in insertion order.
+func (g *Graph) Edges() []Edge {
+	return append([]Edge(nil), g.edges...)
+}
+
+// Dependents returns every node that depends on id directly or
+// transitively, in registration order.
+func (g *Graph) Dependents(id string) []string {
+	reverse := make(map[string][]string, len(g.nodes))
+	for _, e := range g.edges {
+		reverse[e.To] = append(reverse[e.To], e.From)
+	}
+	seen := map[string]bool{}
+	var walk func(string)
+	walk = func(n string) {
+		for _, d := range reverse[n] {
+			if !seen[d] {
+				seen[d] = true
+				walk(d)
+			}
+		}
+	}
+	walk(id)
+
+	out := make([]string, 0, len(seen))
+	for _, n := range g.nodes {
+		if seen[n] {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// Nodes converts the graph into scheduler nodes, in registration order.
+func (g *Graph) Nodes() []Node {
+	deps := make(map[string][]string, len(g.nodes))
+	for _, e := range g.edges {
+		deps[e.From] = append(deps[e.From], e.To)
+	}
+	nodes := make([]Node, 0, len(g.nodes))
+	for _, id := range g.nodes {
+		nodes = append(nodes, Node{ID: id, Deps: deps[id]})
+	}
+	return nodes
+}
+
+// Order returns node IDs in execution order, failing on cycles.
+func (g *Graph) Order() ([]string, error) {
+	return TopoSort(g.Nodes())
+}
+
+// DOT renders the graph in Graphviz format. Remote-state edges are labelled
+// with the state key; implicit edges are dashed.
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph lzctl {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	for _, id := range g.nodes {
+		fmt.Fprintf(&sb, "  %q [label=%q];\n", id, nodeLabel(id, g.kinds[id]))
+	}
+	for _, e := range g.edges {
+		if e.Source == EdgeRemoteState {
+			fmt.Fprintf(&sb, "  %q -> %q [label=%q];\n", e.To, e.From, e.Key)
+		} else {
+			fmt.Fprintf(&sb, "  %q -> %q [style=dashed];\n", e.To, e.From)
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart.
+func (g *Graph) Mermaid() string {
+	ids := make(map[string]string, len(g.nodes))
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for i, id := range g.nodes {
+		ids[id] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&sb, "  %s[\"%s\"]\n", ids[id], nodeLabel(id, g.kinds[id]))
+	}
+	for _, e := range g.edges {
+		if e.Source == EdgeRemoteState {
+			fmt.Fprintf(&sb, "  %s -->|%s| %s\n", ids[e.To], e.Key, ids[e.From])
+		} else {
+			fmt.Fprintf(&sb, "  %s -.-> %s\n", ids[e.To], ids[e.From])
+		}
+	}
+	return sb.String()
+}
+
+// JSON renders the graph as nodes, edges and the resolved execution order.
+func (g *Graph) JSON() ([]byte, error) {
+	order, err := g.Order()
+	if err != nil {
+		return nil, err
+	}
+	type jsonNode struct {
+		ID   string `json:"id"`
+		Kind string `json:"kind,omitempty"`
+	}
+	nodes := make([]jsonNode, 0, len(g.nodes))
+	for _, id := range g.nodes {
+		nodes = append(nodes, jsonNode{ID: id, Kind: g.kinds[id]})
+	}
+	edges := g.edges
+	if edges == nil {
+		edges = []Edge{}
+	}
+	return json.MarshalIndent(map[string]interface{}{
+		"nodes": nodes,
+		"edges": edges,
+		"order": order,
+	}, "", "  ")
+}
+
+func nodeLabel(id, kind string) string {
+	if kind == "" {
+		return id
+	}
+	return id + " (" + kind + ")"
+}
@@ -0,0 +1,146 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemoteStateRefs(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+data "terraform_remote_state" "connectivity" {
+  backend = "azurerm"
+  config = {
+    container_name = "tfstate"
+    key            = "platform-connectivity.tfstate"
+  }
+}
+
+resource "azurerm_resource_group" "rg" {
+  name = "rg-${data.terraform_remote_state.connectivity.outputs.suffix}"
+}
+
+data "terraform_remote_state" "management" {
+  backend = "azurerm"
+  config = {
+    key = "platform-management.tfstate"
+  }
+}
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(`data "terraform_remote_state" "x" { key = "ignored" }`), 0o644))
+
+	refs, err := ParseRemoteStateRefs(dir)
+	require.NoError(t, err)
+	require.Len(t, refs, 2)
+	assert.Equal(t, RemoteStateRef{Name: "connectivity", Key: "platform-connectivity.tfstate", File: "main.tf"}, refs[0])
+	assert.Equal(t, "platform-management.tfstate", refs[1].Key)
+}
+
+func TestParseBackendKey(t *testing.T) {
+	dir := t.TempDir()
+	key, err := ParseBackendKey(dir)
+	require.NoError(t, err)
+	assert.Empty(t, key)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "backend.hcl"), []byte("container_name = \"tfstate\"\nkey = \"landing-zones-app-blueprint.tfstate\"\n"), 0o644))
+	key, err = ParseBackendKey(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "landing-zones-app-blueprint.tfstate", key)
+}
+
+func TestRewriteStateKey(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "backend.hcl"), []byte("key = \"landing-zones-app-blueprint.tfstate\"\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+data "terraform_remote_state" "zone" {
+  backend = "azurerm"
+  config = {
+    key = "landing-zones-app.tfstate"
+  }
+}
+
+data "terraform_remote_state" "other" {
+  backend = "azurerm"
+  config = {
+    key = "landing-zones-app2.tfstate"
+  }
+}
+`), 0o644))
+
+	changed, err := RewriteStateKey(dir, "landing-zones-app.tfstate", "landing-zones-shop.tfstate", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.tf"}, changed)
+	refs, err := ParseRemoteStateRefs(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "landing-zones-app.tfstate", refs[0].Key, "nothing is written when write is false")
+
+	changed, err = RewriteStateKey(dir, "landing-zones-app.tfstate", "landing-zones-shop.tfstate", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.tf"}, changed)
+	refs, err = ParseRemoteStateRefs(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "landing-zones-shop.tfstate", refs[0].Key)
+	assert.Equal(t, "landing-zones-app2.tfstate", refs[1].Key)
+
+	changed, err = RewriteStateKey(dir, "landing-zones-app-blueprint.tfstate", "landing-zones-shop-blueprint.tfstate", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend.hcl"}, changed)
+	key, err := ParseBackendKey(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "landing-zones-shop-blueprint.tfstate", key)
+}
+
+func TestGraph_RenderAndOrder(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("connectivity", "platform")
+	g.AddNode("lz:a", "landing-zone")
+	g.AddNode("lz:a-blueprint", "blueprint")
+	g.AddEdge(Edge{From: "lz:a", To: "connectivity", Source: EdgeImplicit})
+	g.AddEdge(Edge{From: "lz:a-blueprint", To: "lz:a", Source: EdgeImplicit})
+	g.AddEdge(Edge{From: "lz:a-blueprint", To: "connectivity", Source: EdgeRemoteState, Key: "platform-connectivity.tfstate"})
+	g.AddEdge(Edge{From: "lz:a", To: "connectivity", Source: EdgeRemoteState, Key: "platform-connectivity.tfstate"})
+
+	edges := g.Edges()
+	require.Len(t, edges, 3)
+	assert.Equal(t, EdgeRemoteState, edges[0].Source, "remote-state edge supersedes implicit edge")
+
+	order, err := g.Order()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"connectivity", "lz:a", "lz:a-blueprint"}, order)
+	assert.Equal(t, []string{"lz:a", "lz:a-blueprint"}, g.Dependents("connectivity"))
+	assert.Empty(t, g.Dependents("lz:a-blueprint"))
+
+	assert.Contains(t, g.DOT(), `"connectivity" -> "lz:a-blueprint" [label="platform-connectivity.tfstate"];`)
+	assert.Contains(t, g.DOT(), `"lz:a" -> "lz:a-blueprint" [style=dashed];`)
+	assert.Contains(t, g.Mermaid(), "n1 -.-> n2")
+
+	data, err := g.JSON()
+	require.NoError(t, err)
+	var payload struct {
+		Order []string `json:"order"`
+		Edges []Edge   `json:"edges"`
+	}
+	require.NoError(t, json.Unmarshal(data, &payload))
+	assert.Equal(t, order, payload.Order)
+	assert.Len(t, payload.Edges, 3)
+}
+
+func TestGraph_CycleFails(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("a", "")
+	g.AddNode("b", "")
+	g.AddEdge(Edge{From: "a", To: "b", Source: EdgeRemoteState, Key: "b.tfstate"})
+	g.AddEdge(Edge{From: "b", To: "a", Source: EdgeRemoteState, Key: "a.tfstate"})
+
+	_, err := g.Order()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cycle")
+
+	_, err = g.JSON()
+	require.Error(t, err)
+}
@@ -0,0 +1,104 @@
+package orchestrator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// inputSuffixes are the file types that feed a Terraform plan.
+var inputSuffixes = []string{".tf", ".tf.json", ".tfvars", ".tfvars.json", ".hcl"}
+
+// HashInputs returns a content hash ("sha256:<hex>") of the Terraform inputs
+// of a root: every *.tf, *.tf.json, *.tfvars, *.tfvars.json and *.hcl file
+// under dir, including local modules. The .terraform directory and the
+// provider lock file are excluded because `terraform init` writes them, so
+// the hash is stable across init runs. Plan files are outputs, not inputs.
+//
+// nested lists subdirectories of dir, relative to it, that are roots of
+// their own, such as the blueprint of a landing zone: they are skipped.
+func HashInputs(dir string, nested ...string) (string, error) {
+	skip := make(map[string]bool, len(nested))
+	for _, n := range nested {
+		skip[filepath.Join(dir, n)] = true
+	}
+	return hashTree(dir, skip, func(name string) bool {
+		if name == ".terraform.lock.hcl" {
+			return false
+		}
+		for _, suffix := range inputSuffixes {
+			if strings.HasSuffix(name, suffix) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// HashDir returns a content hash ("sha256:<hex>") of every file under dir,
+// for inputs that are not Terraform files, such as policy definitions.
+func HashDir(dir string) (string, error) {
+	return hashTree(dir, nil, func(string) bool { return true })
+}
+
+// hashTree hashes the relative path and content of the files under dir whose
+// name include accepts, skipping .terraform and .git directories and the
+// directories in skip.
+func hashTree(dir string, skip map[string]bool, include func(name string) bool) (string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (d.Name() == ".terraform" || d.Name() == ".git" || skip[path]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if include(d.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("hashing inputs of %s: %w", dir, err)
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, f := range files {
+		rel, _ := filepath.Rel(dir, f)
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+		if err := hashFile(h, f); err != nil {
+			return "", fmt.Errorf("hashing inputs of %s: %w", dir, err)
+		}
+		h.Write([]byte{0})
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashFile returns the content hash ("sha256:<hex>") of a single file.
+func HashFile(path string) (string, error) {
+	h := sha256.New()
+	if err := hashFile(h, path); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashInputs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(dir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	write("main.tf", `resource "null_resource" "a" {}`)
+	write("terraform.tfvars", `name = "a"`)
+	write("modules/net/main.tf", `variable "x" {}`)
+
+	base, err := HashInputs(dir)
+	require.NoError(t, err)
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, base)
+
+	// Files written by terraform init or plan do not change the hash.
+	write(".terraform.lock.hcl", "provider lock")
+	write(".terraform/providers/x", "binary")
+	write("tfplan", "plan")
+	write("tfplan.json", "{}")
+	write("README.md", "docs")
+	h, err := HashInputs(dir)
+	require.NoError(t, err)
+	assert.Equal(t, base, h)
+
+	write("modules/net/main.tf", `variable "y" {}`)
+	h, err = HashInputs(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, base, h)
+
+	// A nested root is not an input of its parent.
+	write("blueprint/main.tf", `resource "null_resource" "b" {}`)
+	zone, err := HashInputs(dir, "blueprint")
+	require.NoError(t, err)
+	assert.Equal(t, h, zone)
+	write("blueprint/main.tf", `resource "null_resource" "c" {}`)
+	edited, err := HashInputs(dir, "blueprint")
+	require.NoError(t, err)
+	assert.Equal(t, zone, edited, "a blueprint edit leaves the zone hash unchanged")
+	full, err := HashInputs(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, h, full)
+}
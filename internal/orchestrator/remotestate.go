@@ -0,0 +1,157 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RemoteStateRef is a `data "terraform_remote_state"` block found in a root.
+type RemoteStateRef struct {
+	Name string `json:"name"` // data source name, e.g. "connectivity"
+	Key  string `json:"key"`  // backend state key, e.g. "platform-connectivity.tfstate"
+	File string `json:"file"` // file the block was declared in, relative to the root
+}
+
+var (
+	remoteStateBlockRegex = regexp.MustCompile(`data\s+"terraform_remote_state"\s+"([^"]+)"\s*\{`)
+	backendBlockRegex     = regexp.MustCompile(`backend\s+"azurerm"\s*\{`)
+	keyAttrRegex          = regexp.MustCompile(`(?m)^\s*key\s*=\s*"([^"]+)"`)
+)
+
+// ParseRemoteStateRefs scans the top-level *.tf files of a Terraform root and
+// returns every terraform_remote_state data source together with the state
+// key it reads. Blocks without a literal key are ignored. Results are sorted
+// by file then name for deterministic graphs.
+func ParseRemoteStateRefs(dir string) ([]RemoteStateRef, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, fmt.Errorf("listing terraform files in %s: %w", dir, err)
+	}
+	sort.Strings(files)
+
+	var refs []RemoteStateRef
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f, err)
+		}
+		src := string(data)
+		for _, loc := range remoteStateBlockRegex.FindAllStringSubmatchIndex(src, -1) {
+			body := blockBody(src, loc[1]-1)
+			m := keyAttrRegex.FindStringSubmatch(body)
+			if m == nil {
+				continue
+			}
+			refs = append(refs, RemoteStateRef{
+				Name: src[loc[2]:loc[3]],
+				Key:  m[1],
+				File: filepath.Base(f),
+			})
+		}
+	}
+	return refs, nil
+}
+
+// ParseBackendKey returns the state key a root writes to, read from a
+// backend.hcl file or an azurerm backend block. It returns "" when the root
+// does not declare one (platform layers rely on the naming convention).
+func ParseBackendKey(dir string) (string, error) {
+	hcl := filepath.Join(dir, "backend.hcl")
+	if data, err := os.ReadFile(hcl); err == nil {
+		if m := keyAttrRegex.FindStringSubmatch(string(data)); m != nil {
+			return m[1], nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading %s: %w", hcl, err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return "", fmt.Errorf("listing terraform files in %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return "", fmt.Errorf("reading %s: %w", f, err)
+		}
+		src := string(data)
+		if loc := backendBlockRegex.FindStringIndex(src); loc != nil {
+			if m := keyAttrRegex.FindStringSubmatch(blockBody(src, loc[1]-1)); m != nil {
+				return m[1], nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// blockBody returns the text between the brace at open and its matching
+// closing brace. Braces inside quoted strings are ignored.
+func blockBody(src string, open int) string {
+	depth := 0
+	inString := false
+	for i := open; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return src[open+1 : i]
+			}
+		}
+	}
+	return strings.TrimPrefix(src[open:], "{")
+}
+
+// RewriteStateKey replaces the state key oldKey with newKey wherever a root
+// declares it: backend.hcl, the azurerm backend block and terraform_remote_state
+// data sources of the top-level *.tf files of dir. It returns the names of the
+// files that changed; when write is false, nothing is written and it returns
+// the files that would change.
+func RewriteStateKey(dir, oldKey, newKey string, write bool) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, fmt.Errorf("listing terraform files in %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	files = append([]string{filepath.Join(dir, "backend.hcl")}, files...)
+
+	re := regexp.MustCompile(`(?m)^(\s*key\s*=\s*")` + regexp.QuoteMeta(oldKey) + `(")`)
+	var changed []string
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return changed, fmt.Errorf("reading %s: %w", f, err)
+		}
+		updated := re.ReplaceAllString(string(data), "${1}"+newKey+"${2}")
+		if updated == string(data) {
+			continue
+		}
+		if !write {
+			changed = append(changed, filepath.Base(f))
+			continue
+		}
+		if err := os.WriteFile(f, []byte(updated), 0o644); err != nil {
+			return changed, fmt.Errorf("writing %s: %w", f, err)
+		}
+		changed = append(changed, filepath.Base(f))
+	}
+	return changed, nil
+}
@@ -0,0 +1,149 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/kjourdan1/lzctl/internal/azure"
+)
+
+// transientPatterns classify tool output as a transient failure: ARM
+// throttling, provider-level retryable errors, timeouts talking to Azure and
+// state lease contention. Each pattern is reported by its reason.
+var transientPatterns = []struct {
+	reason string
+	re     *regexp.Regexp
+}{
+	{"throttled (429)", regexp.MustCompile(`(?i)(status(\s*code)?\s*[=:]?\s*429\b|\b429 too many requests|TooManyRequests)`)},
+	{"retryable error", regexp.MustCompile(`RetryableError`)},
+	{"deadline exceeded", regexp.MustCompile(`context deadline exceeded`)},
+	{"state lease", regexp.MustCompile(`(?i)(error acquiring the state lock|LeaseAlreadyPresent|LeaseIsBreakingAndCannotBeAcquired|lease is already present)`)},
+}
+
+// changeStartedRegex matches the progress lines apply prints once it has
+// started changing resources.
+var changeStartedRegex = regexp.MustCompile(`(?m): (Creating|Modifying|Destroying|Still creating|Still modifying|Still destroying|Creation complete|Modifications complete|Destruction complete)\b`)
+
+// Transient reports whether output shows a transient failure worth
+// retrying, and why.
+func Transient(output string) (reason string, ok bool) {
+	for _, p := range transientPatterns {
+		if p.re.MatchString(output) {
+			return p.reason, true
+		}
+	}
+	return "", false
+}
+
+// ChangesStarted reports whether apply output shows that resources were
+// already being changed, i.e. that the failed apply may be partial.
+func ChangesStarted(output string) bool {
+	return changeStartedRegex.MatchString(output)
+}
+
+// PartialApplyError is returned for a transient apply failure that is not
+// retried because resources were already changed: the saved plan no longer
+// matches the state and the root has to be planned again.
+type PartialApplyError struct {
+	Reason string
+	Err    error
+}
+
+func (e *PartialApplyError) Error() string {
+	return fmt.Sprintf("%v (%s after resources were changed; not retried, re-plan before applying again)", e.Err, e.Reason)
+}
+
+func (e *PartialApplyError) Unwrap() error { return e.Err }
+
+// RetryEvent describes a retry about to happen.
+type RetryEvent struct {
+	Dir         string
+	Subcommand  string
+	Attempt     int // 1-based number of the attempt that failed
+	MaxAttempts int
+	Delay       time.Duration
+	Reason      string
+}
+
+// RetryPolicy configures WithRetry.
+type RetryPolicy struct {
+	// Retry bounds the attempts and paces them. MaxAttempts <= 1 disables
+	// retries.
+	Retry azure.RetryConfig
+	// OnRetry, when set, is called before each retry.
+	OnRetry func(RetryEvent)
+	// Sleep waits between attempts; nil uses a timer that stops when ctx is
+	// done.
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// WithRetry wraps r so that runs failing with transient output are retried
+// with backoff. An apply that failed after changing resources is never
+// retried: a PartialApplyError is returned instead. Failures caused by ctx
+// itself (cancellation or the root's timeout) are not retried either.
+func WithRetry(r Runner, p RetryPolicy) Runner {
+	if p.Retry.MaxAttempts <= 1 {
+		return r
+	}
+	if p.Sleep == nil {
+		p.Sleep = sleepContext
+	}
+	return &retryRunner{Runner: r, policy: p}
+}
+
+type retryRunner struct {
+	Runner
+	policy RetryPolicy
+}
+
+func (r *retryRunner) Run(ctx context.Context, dir string, args ...string) (string, error) {
+	return r.retry(ctx, dir, args, r.Runner.Run)
+}
+
+func (r *retryRunner) Output(ctx context.Context, dir string, args ...string) (string, error) {
+	return r.retry(ctx, dir, args, r.Runner.Output)
+}
+
+func (r *retryRunner) retry(ctx context.Context, dir string, args []string, run func(context.Context, string, ...string) (string, error)) (string, error) {
+	subcommand := ""
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+	attempts := r.policy.Retry.MaxAttempts
+	for attempt := 0; ; attempt++ {
+		out, err := run(ctx, dir, args...)
+		if err == nil || ctx.Err() != nil {
+			return out, err
+		}
+		reason, transient := Transient(out + "\n" + err.Error())
+		if !transient {
+			return out, err
+		}
+		if (subcommand == "apply" || subcommand == "destroy") && ChangesStarted(out) {
+			return out, &PartialApplyError{Reason: reason, Err: err}
+		}
+		if attempt+1 >= attempts {
+			return out, err
+		}
+		delay := r.policy.Retry.Delay(attempt)
+		if r.policy.OnRetry != nil {
+			r.policy.OnRetry(RetryEvent{Dir: dir, Subcommand: subcommand, Attempt: attempt + 1, MaxAttempts: attempts, Delay: delay, Reason: reason})
+		}
+		if serr := r.policy.Sleep(ctx, delay); serr != nil {
+			return out, err
+		}
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,124 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/azure"
+)
+
+// scriptedRunner answers successive calls with outs[i] and errs[i]; the last
+// pair repeats.
+type scriptedRunner struct {
+	outs  []string
+	errs  []error
+	calls int
+}
+
+func (r *scriptedRunner) Tool() string   { return ToolTerraform }
+func (r *scriptedRunner) Binary() string { return ToolTerraform }
+
+func (r *scriptedRunner) Run(_ context.Context, _ string, _ ...string) (string, error) {
+	i := min(r.calls, len(r.outs)-1)
+	r.calls++
+	return r.outs[i], r.errs[i]
+}
+
+func (r *scriptedRunner) Output(ctx context.Context, dir string, args ...string) (string, error) {
+	return r.Run(ctx, dir, args...)
+}
+
+var errExit1 = errors.New("exit status 1")
+
+func noSleep(context.Context, time.Duration) error { return nil }
+
+func TestTransient(t *testing.T) {
+	for out, want := range map[string]string{
+		"Error: ... StatusCode=429 -- Original Error: autorest/azure: Service returned an error.": "throttled (429)",
+		`Error: unexpected status 429 (429 Too Many Requests)`:                                    "throttled (429)",
+		"Code=\"TooManyRequests\"":                                 "throttled (429)",
+		"RetryableError: Resource is busy":                         "retryable error",
+		"Error: reading resource group: context deadline exceeded": "deadline exceeded",
+		"Error: Error acquiring the state lock":                    "state lease",
+		"Error: Invalid reference":                                 "",
+		"Plan: 429 to add, 0 to change, 0 to destroy.":             "",
+	} {
+		reason, ok := Transient(out)
+		assert.Equal(t, want, reason, out)
+		assert.Equal(t, want != "", ok, out)
+	}
+}
+
+func TestWithRetry_RetriesTransientFailures(t *testing.T) {
+	inner := &scriptedRunner{
+		outs: []string{"Error: StatusCode=429", "Error: RetryableError", "Plan: 1 to add"},
+		errs: []error{errExit1, errExit1, nil},
+	}
+	var events []RetryEvent
+	tf := WithRetry(inner, RetryPolicy{
+		Retry:   azure.RetryConfig{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Second},
+		Sleep:   noSleep,
+		OnRetry: func(e RetryEvent) { events = append(events, e) },
+	})
+
+	out, err := tf.Run(context.Background(), "/repo/platform/identity", "plan", "-out=tfplan")
+	require.NoError(t, err)
+	assert.Equal(t, "Plan: 1 to add", out)
+	assert.Equal(t, 3, inner.calls)
+	require.Len(t, events, 2)
+	assert.Equal(t, RetryEvent{Dir: "/repo/platform/identity", Subcommand: "plan", Attempt: 1, MaxAttempts: 3, Delay: events[0].Delay, Reason: "throttled (429)"}, events[0])
+	assert.Equal(t, "retryable error", events[1].Reason)
+}
+
+func TestWithRetry_GivesUpAndSkipsPermanentFailures(t *testing.T) {
+	throttled := &scriptedRunner{outs: []string{"Error: TooManyRequests"}, errs: []error{errExit1}}
+	_, err := WithRetry(throttled, RetryPolicy{Retry: azure.RetryConfig{MaxAttempts: 2}, Sleep: noSleep}).
+		Run(context.Background(), "dir", "init")
+	assert.ErrorIs(t, err, errExit1)
+	assert.Equal(t, 2, throttled.calls)
+
+	broken := &scriptedRunner{outs: []string{"Error: Unsupported argument"}, errs: []error{errExit1}}
+	_, err = WithRetry(broken, RetryPolicy{Retry: azure.RetryConfig{MaxAttempts: 3}, Sleep: noSleep}).
+		Run(context.Background(), "dir", "plan")
+	assert.ErrorIs(t, err, errExit1)
+	assert.Equal(t, 1, broken.calls)
+}
+
+func TestWithRetry_NeverRetriesPartialApply(t *testing.T) {
+	partial := &scriptedRunner{
+		outs: []string{"azurerm_resource_group.hub: Creating...\nazurerm_resource_group.hub: Creation complete after 2s\nError: StatusCode=429"},
+		errs: []error{errExit1},
+	}
+	_, err := WithRetry(partial, RetryPolicy{Retry: azure.RetryConfig{MaxAttempts: 3}, Sleep: noSleep}).
+		Run(context.Background(), "dir", "apply", "tfplan")
+	var pe *PartialApplyError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, "throttled (429)", pe.Reason)
+	assert.ErrorIs(t, err, errExit1)
+	assert.Contains(t, err.Error(), "re-plan before applying again")
+	assert.Equal(t, 1, partial.calls)
+
+	// An apply that failed before changing anything is retried.
+	early := &scriptedRunner{
+		outs: []string{"Error: Error acquiring the state lock", "Apply complete!"},
+		errs: []error{errExit1, nil},
+	}
+	_, err = WithRetry(early, RetryPolicy{Retry: azure.RetryConfig{MaxAttempts: 3}, Sleep: noSleep}).
+		Run(context.Background(), "dir", "apply", "tfplan")
+	require.NoError(t, err)
+	assert.Equal(t, 2, early.calls)
+}
+
+func TestWithRetry_StopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := &scriptedRunner{outs: []string{"Error: context deadline exceeded"}, errs: []error{errExit1}}
+	_, err := WithRetry(r, RetryPolicy{Retry: azure.RetryConfig{MaxAttempts: 3}, Sleep: noSleep}).Run(ctx, "dir", "plan")
+	assert.Error(t, err)
+	assert.Equal(t, 1, r.calls)
+}
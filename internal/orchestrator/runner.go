@@ -0,0 +1,304 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported Terraform-compatible tools.
+const (
+	ToolTerraform = "terraform"
+	ToolOpenTofu  = "tofu"
+)
+
+// Minimum versions lzctl supports for each tool.
+const (
+	MinTerraformVersion = "1.5.0"
+	MinOpenTofuVersion  = "1.6.0"
+)
+
+// VersionFile is the repository-level file pinning the tool version, as
+// written by tfenv/tenv. It may optionally name the tool first, e.g.
+// "tofu 1.8.2" or "opentofu 1.8.2".
+const VersionFile = ".terraform-version"
+
+// Runner executes Terraform-compatible CLI commands inside a root directory.
+// Implementations exist for Terraform and OpenTofu; tests use the scripted
+// fake in the runnertest package.
+type Runner interface {
+	// Tool returns the tool flavour, ToolTerraform or ToolOpenTofu.
+	Tool() string
+	// Binary returns the executable that is invoked.
+	Binary() string
+	// Run executes args in dir and returns combined stdout and stderr.
+	Run(ctx context.Context, dir string, args ...string) (string, error)
+	// Output executes args in dir and returns stdout only, so warnings on
+	// stderr cannot corrupt machine-readable output.
+	Output(ctx context.Context, dir string, args ...string) (string, error)
+}
+
+// Streamer is implemented by runners that can copy the combined output of
+// a run to w while it is produced, in addition to returning it.
+type Streamer interface {
+	RunStream(ctx context.Context, dir string, w io.Writer, args ...string) (string, error)
+}
+
+// ToolSpec describes which tool to run and the minimum version required.
+type ToolSpec struct {
+	Tool       string // ToolTerraform or ToolOpenTofu
+	Binary     string // executable name or path
+	MinVersion string // effective minimum: the tool floor or a stricter pin
+	Source     string // where the selection came from: "default", "lzctl.yaml" or VersionFile
+}
+
+// InstallHint returns an actionable install message for the tool.
+func (s ToolSpec) InstallHint() string {
+	if s.Tool == ToolOpenTofu {
+		return fmt.Sprintf("Install OpenTofu >= %s: https://opentofu.org/docs/intro/install/", s.MinVersion)
+	}
+	return fmt.Sprintf("Install Terraform >= %s: https://developer.hashicorp.com/terraform/install", s.MinVersion)
+}
+
+// DefaultToolSpec is Terraform with lzctl's minimum version.
+func DefaultToolSpec() ToolSpec {
+	return ToolSpec{Tool: ToolTerraform, Binary: ToolTerraform, MinVersion: MinTerraformVersion, Source: "default"}
+}
+
+// SelectTool resolves the tool for a repository. binary and version come from
+// lzctl.yaml (spec.terraform) and take precedence; otherwise VersionFile in
+// repo is consulted; otherwise Terraform is used. binary may be a tool name
+// ("terraform", "tofu", "opentofu") or a path to an executable, whose base
+// name decides the flavour. A pinned version is treated as a minimum and can
+// only raise the tool's floor.
+func SelectTool(binary, version, repo string) (ToolSpec, error) {
+	spec := DefaultToolSpec()
+
+	var fileTool, fileVersion string
+	if repo != "" && version == "" {
+		var err error
+		if fileTool, fileVersion, err = readVersionFile(filepath.Join(repo, VersionFile)); err != nil {
+			return ToolSpec{}, err
+		}
+	}
+
+	pinned := ""
+	switch {
+	case binary != "":
+		tool, err := toolForBinary(binary)
+		if err != nil {
+			return ToolSpec{}, err
+		}
+		spec.Tool, spec.Binary, spec.Source = tool, binary, "lzctl.yaml"
+		if !strings.ContainsAny(binary, `/\`) {
+			spec.Binary = tool // "opentofu" installs as "tofu"
+		}
+		if fileTool == "" || fileTool == tool {
+			pinned = fileVersion
+		}
+	case fileTool != "" || fileVersion != "":
+		if fileTool != "" {
+			spec.Tool, spec.Binary = fileTool, fileTool
+		}
+		pinned, spec.Source = fileVersion, VersionFile
+	}
+	if version != "" {
+		pinned, spec.Source = version, "lzctl.yaml"
+	}
+
+	spec.MinVersion = MinTerraformVersion
+	if spec.Tool == ToolOpenTofu {
+		spec.MinVersion = MinOpenTofuVersion
+	}
+	if pinned != "" {
+		if _, ok := parseVersion(pinned); !ok {
+			return ToolSpec{}, fmt.Errorf("invalid %s version %q (expected major.minor.patch)", spec.Tool, pinned)
+		}
+		if VersionAtLeast(pinned, spec.MinVersion) {
+			spec.MinVersion = pinned
+		}
+	}
+	return spec, nil
+}
+
+// readVersionFile parses VersionFile. A missing file is not an error.
+func readVersionFile(path string) (tool, version string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", nil
+		}
+		return "", "", fmt.Errorf("reading %s: %w", VersionFile, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			if tool, err = toolForBinary(fields[0]); err != nil {
+				return "", "", fmt.Errorf("%s: %w", VersionFile, err)
+			}
+			return tool, strings.TrimPrefix(fields[1], "v"), nil
+		}
+		return "", strings.TrimPrefix(fields[0], "v"), nil
+	}
+	return "", "", nil
+}
+
+func toolForBinary(binary string) (string, error) {
+	name := strings.ToLower(strings.TrimSuffix(filepath.Base(binary), ".exe"))
+	switch name {
+	case "terraform":
+		return ToolTerraform, nil
+	case "tofu", "opentofu":
+		return ToolOpenTofu, nil
+	}
+	return "", fmt.Errorf("unsupported terraform binary %q (expected terraform or tofu)", binary)
+}
+
+// NewRunner returns an os/exec backed runner for spec.
+func NewRunner(spec ToolSpec) Runner {
+	return &execRunner{tool: spec.Tool, binary: spec.Binary}
+}
+
+// execRunner shells out to the tool binary.
+type execRunner struct {
+	tool   string
+	binary string
+}
+
+func (r *execRunner) Tool() string   { return r.tool }
+func (r *execRunner) Binary() string { return r.binary }
+
+func (r *execRunner) Run(ctx context.Context, dir string, args ...string) (string, error) {
+	return r.RunStream(ctx, dir, nil, args...)
+}
+
+func (r *execRunner) RunStream(ctx context.Context, dir string, w io.Writer, args ...string) (string, error) {
+	cmd := r.command(ctx, dir, args)
+	var out bytes.Buffer
+	var dst io.Writer = &out
+	if w != nil {
+		dst = io.MultiWriter(&out, w)
+	}
+	cmd.Stdout = dst
+	cmd.Stderr = dst
+	err := cmd.Run()
+	return out.String(), err
+}
+
+func (r *execRunner) Output(ctx context.Context, dir string, args ...string) (string, error) {
+	out, err := r.command(ctx, dir, args).Output()
+	return string(out), err
+}
+
+func (r *execRunner) command(ctx context.Context, dir string, args []string) *exec.Cmd {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd := exec.CommandContext(ctx, r.binary, args...) //nolint:gosec // binary comes from repository configuration
+	cmd.Dir = dir
+	// Inherit the full environment so the tool sees the caller's PATH and
+	// credentials, and never let it prompt.
+	cmd.Env = append(os.Environ(), "TF_INPUT=false")
+	// On cancellation or timeout, interrupt the tool first so that it can
+	// stop in-flight operations and release the state lock; kill it only
+	// if it has not exited after InterruptGrace.
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = InterruptGrace
+	return cmd
+}
+
+// InterruptGrace is how long a cancelled run may take to exit after being
+// interrupted before it is killed.
+var InterruptGrace = time.Minute
+
+// LookPath checks that the runner's binary can be found.
+func LookPath(spec ToolSpec) error {
+	if _, err := exec.LookPath(spec.Binary); err != nil {
+		return fmt.Errorf("%s not found in PATH (%s)", spec.Binary, spec.InstallHint())
+	}
+	return nil
+}
+
+// versionPattern extracts the version from `<tool> version -json`. Both
+// Terraform and OpenTofu report it as "terraform_version".
+var versionPattern = regexp.MustCompile(`"terraform_version"\s*:\s*"([^"]+)"`)
+
+// ParseVersionOutput returns the version reported by `<tool> version -json`.
+func ParseVersionOutput(out string) (string, bool) {
+	var payload struct {
+		Version string `json:"terraform_version"`
+	}
+	if err := json.Unmarshal([]byte(out), &payload); err == nil && payload.Version != "" {
+		return payload.Version, true
+	}
+	if m := versionPattern.FindStringSubmatch(out); len(m) == 2 {
+		return m[1], true
+	}
+	return "", false
+}
+
+// CheckVersion runs `version -json` through r and verifies the reported
+// version is at least spec.MinVersion. It returns the detected version.
+func CheckVersion(ctx context.Context, r Runner, spec ToolSpec) (string, error) {
+	out, err := r.Output(ctx, "", "version", "-json")
+	if err != nil {
+		return "", fmt.Errorf("%s version: %w (%s)", spec.Binary, err, spec.InstallHint())
+	}
+	version, ok := ParseVersionOutput(out)
+	if !ok {
+		return "", fmt.Errorf("%s version: could not parse version from output", spec.Binary)
+	}
+	if !VersionAtLeast(version, spec.MinVersion) {
+		return version, fmt.Errorf("%s %s found, but >= %s required (%s)", spec.Tool, version, spec.MinVersion, spec.InstallHint())
+	}
+	return version, nil
+}
+
+// VersionAtLeast reports whether version >= min, comparing major.minor.patch
+// and ignoring pre-release and build suffixes.
+func VersionAtLeast(version, min string) bool {
+	v, _ := parseVersion(version)
+	m, _ := parseVersion(min)
+	for i := range v {
+		if v[i] != m[i] {
+			return v[i] > m[i]
+		}
+	}
+	return true
+}
+
+func parseVersion(s string) ([3]int, bool) {
+	var result [3]int
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	parts := strings.SplitN(s, ".", 3)
+	ok := len(parts) >= 2
+	for i := 0; i < 3 && i < len(parts); i++ {
+		// Strip any suffix (e.g. "0-rc1" → "0").
+		num := strings.SplitN(parts[i], "-", 2)[0]
+		num = strings.SplitN(num, "+", 2)[0]
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			ok = false
+		}
+		result[i] = n
+	}
+	return result, ok
+}
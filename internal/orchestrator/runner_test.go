@@ -0,0 +1,105 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectTool(t *testing.T) {
+	tests := []struct {
+		name        string
+		binary      string
+		version     string
+		versionFile string
+		want        ToolSpec
+	}{
+		{name: "default", want: ToolSpec{Tool: ToolTerraform, Binary: "terraform", MinVersion: "1.5.0", Source: "default"}},
+		{name: "config tofu", binary: "tofu", want: ToolSpec{Tool: ToolOpenTofu, Binary: "tofu", MinVersion: "1.6.0", Source: "lzctl.yaml"}},
+		{name: "config opentofu alias", binary: "opentofu", version: "1.8.0", want: ToolSpec{Tool: ToolOpenTofu, Binary: "tofu", MinVersion: "1.8.0", Source: "lzctl.yaml"}},
+		{name: "config path", binary: "/opt/tf/bin/terraform", want: ToolSpec{Tool: ToolTerraform, Binary: "/opt/tf/bin/terraform", MinVersion: "1.5.0", Source: "lzctl.yaml"}},
+		{name: "version file", versionFile: "1.9.5\n", want: ToolSpec{Tool: ToolTerraform, Binary: "terraform", MinVersion: "1.9.5", Source: VersionFile}},
+		{name: "version file with tool", versionFile: "# pinned\nopentofu v1.8.2\n", want: ToolSpec{Tool: ToolOpenTofu, Binary: "tofu", MinVersion: "1.8.2", Source: VersionFile}},
+		{name: "pin cannot lower floor", versionFile: "1.3.0", want: ToolSpec{Tool: ToolTerraform, Binary: "terraform", MinVersion: "1.5.0", Source: VersionFile}},
+		{name: "config version wins over file", version: "1.10.0", versionFile: "1.9.5", want: ToolSpec{Tool: ToolTerraform, Binary: "terraform", MinVersion: "1.10.0", Source: "lzctl.yaml"}},
+		{name: "file pin for another tool ignored", binary: "terraform", versionFile: "tofu 1.9.0", want: ToolSpec{Tool: ToolTerraform, Binary: "terraform", MinVersion: "1.5.0", Source: "lzctl.yaml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := t.TempDir()
+			if tt.versionFile != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(repo, VersionFile), []byte(tt.versionFile), 0o644))
+			}
+			got, err := SelectTool(tt.binary, tt.version, repo)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSelectTool_Errors(t *testing.T) {
+	_, err := SelectTool("pulumi", "", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported terraform binary")
+
+	_, err = SelectTool("", "latest", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid terraform version")
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	assert.True(t, VersionAtLeast("1.5.0", "1.5.0"))
+	assert.True(t, VersionAtLeast("1.10.0", "1.9.5"))
+	assert.True(t, VersionAtLeast("v1.6.0-rc1", "1.6.0"))
+	assert.False(t, VersionAtLeast("1.4.9", "1.5.0"))
+	assert.False(t, VersionAtLeast("0.15.5", "1.0.0"))
+}
+
+func TestParseVersionOutput(t *testing.T) {
+	v, ok := ParseVersionOutput(`{"terraform_version":"1.8.2","platform":"linux_amd64"}`)
+	assert.True(t, ok)
+	assert.Equal(t, "1.8.2", v)
+
+	v, ok = ParseVersionOutput("Warning: outdated\n{\"terraform_version\": \"1.9.0\"}")
+	assert.True(t, ok)
+	assert.Equal(t, "1.9.0", v)
+
+	_, ok = ParseVersionOutput("Terraform v1.9.0")
+	assert.False(t, ok)
+}
+
+// versionRunner answers `version -json` only.
+type versionRunner struct {
+	out string
+	err error
+}
+
+func (r versionRunner) Tool() string   { return ToolTerraform }
+func (r versionRunner) Binary() string { return ToolTerraform }
+func (r versionRunner) Run(ctx context.Context, dir string, args ...string) (string, error) {
+	return r.Output(ctx, dir, args...)
+}
+func (r versionRunner) Output(context.Context, string, ...string) (string, error) {
+	return r.out, r.err
+}
+
+func TestCheckVersion(t *testing.T) {
+	spec := ToolSpec{Tool: ToolTerraform, Binary: "terraform", MinVersion: "1.7.0"}
+
+	v, err := CheckVersion(context.Background(), versionRunner{out: `{"terraform_version":"1.9.0"}`}, spec)
+	require.NoError(t, err)
+	assert.Equal(t, "1.9.0", v)
+
+	_, err = CheckVersion(context.Background(), versionRunner{out: `{"terraform_version":"1.6.3"}`}, spec)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "terraform 1.6.3 found, but >= 1.7.0 required")
+
+	_, err = CheckVersion(context.Background(), versionRunner{err: errors.New("exit status 1")}, spec)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Install Terraform")
+}
@@ -0,0 +1,217 @@
+// Package runnertest provides a scriptable orchestrator.Runner for tests.
+//
+// A Fake answers every command in-process: responses are registered per
+// subcommand ("init", "plan", "show", "apply", ...) and every call is
+// recorded, so command tests no longer need shell scripts on PATH.
+//
+//	tf := runnertest.New().
+//		Reply("plan", "Plan: 1 to add, 0 to change, 0 to destroy.", 2).
+//		Reply("show", planJSON, 0)
+package runnertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+)
+
+// DefaultVersion is the version reported by `version -json` unless overridden.
+const DefaultVersion = "1.9.0"
+
+// Call is one recorded invocation.
+type Call struct {
+	Dir  string
+	Args []string
+}
+
+// Subcommand returns the first argument, e.g. "plan".
+func (c Call) Subcommand() string {
+	if len(c.Args) == 0 {
+		return ""
+	}
+	return c.Args[0]
+}
+
+// Root returns the base name of the directory the call ran in, which is the
+// layer or landing zone directory for orchestrated commands.
+func (c Call) Root() string {
+	return filepath.Base(c.Dir)
+}
+
+// Handler produces the output and error for a call.
+type Handler func(c Call) (string, error)
+
+// ExitError mimics a non-zero exit status of the tool.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string { return fmt.Sprintf("exit status %d", e.Code) }
+
+// ExitCode returns the exit status, like *exec.ExitError.
+func (e *ExitError) ExitCode() int { return e.Code }
+
+// Fake is a scripted orchestrator.Runner. It is safe for concurrent use.
+type Fake struct {
+	tool   string
+	binary string
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	calls    []Call
+}
+
+var (
+	_ orchestrator.Runner   = (*Fake)(nil)
+	_ orchestrator.Streamer = (*Fake)(nil)
+)
+
+// New returns a Terraform fake that succeeds on every subcommand with the
+// messages real Terraform prints for init, validate and apply, reports
+// DefaultVersion, and produces an empty plan. Plans write their -out file.
+func New() *Fake {
+	f := &Fake{tool: orchestrator.ToolTerraform, binary: orchestrator.ToolTerraform, handlers: map[string]Handler{}}
+	f.Reply("init", "Terraform has been successfully initialized!", 0)
+	f.Reply("validate", "Success! The configuration is valid.", 0)
+	f.Reply("plan", "No changes. Your infrastructure matches the configuration.", 0)
+	f.Reply("apply", "Apply complete! Resources: 0 added, 0 changed, 0 destroyed.", 0)
+	f.Version(DefaultVersion)
+	return f
+}
+
+// WithTool makes the fake report itself as tool (e.g. orchestrator.ToolOpenTofu).
+func (f *Fake) WithTool(tool string) *Fake {
+	f.tool, f.binary = tool, tool
+	return f
+}
+
+// On registers h for subcommand, replacing any previous handler.
+func (f *Fake) On(subcommand string, h Handler) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[subcommand] = h
+	return f
+}
+
+// Reply makes subcommand print out and exit with code.
+func (f *Fake) Reply(subcommand, out string, code int) *Fake {
+	return f.On(subcommand, func(Call) (string, error) {
+		if code != 0 {
+			return out, &ExitError{Code: code}
+		}
+		return out, nil
+	})
+}
+
+// Version makes `version -json` report version.
+func (f *Fake) Version(version string) *Fake {
+	return f.Reply("version", fmt.Sprintf(`{"terraform_version":%q,"platform":"linux_amd64"}`, version), 0)
+}
+
+// Calls returns the recorded calls, optionally filtered by subcommand.
+func (f *Fake) Calls(subcommand ...string) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []Call
+	for _, c := range f.calls {
+		if len(subcommand) == 0 || c.Subcommand() == subcommand[0] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Roots returns the root directory names of the calls to subcommand, in
+// call order.
+func (f *Fake) Roots(subcommand string) []string {
+	var roots []string
+	for _, c := range f.Calls(subcommand) {
+		roots = append(roots, c.Root())
+	}
+	return roots
+}
+
+// Tool implements orchestrator.Runner.
+func (f *Fake) Tool() string { return f.tool }
+
+// Binary implements orchestrator.Runner.
+func (f *Fake) Binary() string { return f.binary }
+
+// Run implements orchestrator.Runner.
+func (f *Fake) Run(ctx context.Context, dir string, args ...string) (string, error) {
+	return f.call(ctx, dir, args)
+}
+
+// RunStream implements orchestrator.Streamer: the output is copied to w
+// once the call returns.
+func (f *Fake) RunStream(ctx context.Context, dir string, w io.Writer, args ...string) (string, error) {
+	out, err := f.call(ctx, dir, args)
+	if w != nil {
+		_, _ = io.WriteString(w, out)
+	}
+	return out, err
+}
+
+// Output implements orchestrator.Runner. The fake has no separate stderr.
+func (f *Fake) Output(ctx context.Context, dir string, args ...string) (string, error) {
+	return f.call(ctx, dir, args)
+}
+
+func (f *Fake) call(ctx context.Context, dir string, args []string) (string, error) {
+	c := Call{Dir: dir, Args: append([]string(nil), args...)}
+	f.mu.Lock()
+	f.calls = append(f.calls, c)
+	h := f.handlers[c.Subcommand()]
+	f.mu.Unlock()
+
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+	}
+	var out string
+	var err error
+	if h != nil {
+		out, err = h(c)
+	}
+	if out != "" && !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	if c.Subcommand() == "plan" && (err == nil || exitCode(err) == 2) {
+		if writeErr := writePlanFile(c, out); writeErr != nil {
+			return out, writeErr
+		}
+	}
+	return out, err
+}
+
+// writePlanFile creates the -out file of a successful plan, as Terraform
+// does, so callers that hash or reuse the saved plan behave as in production.
+// The file holds the plan output.
+func writePlanFile(c Call, out string) error {
+	for _, a := range c.Args {
+		if name, ok := strings.CutPrefix(a, "-out="); ok {
+			path := name
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(c.Dir, name)
+			}
+			return os.WriteFile(path, []byte(out), 0o644)
+		}
+	}
+	return nil
+}
+
+func exitCode(err error) int {
+	var e interface{ ExitCode() int }
+	if errors.As(err, &e) {
+		return e.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,234 @@
+// Package orchestrator schedules lzctl Terraform roots (platform layers,
+// landing zones and blueprints) as a dependency graph.
+//
+// Roots only start once every root they depend on has completed. Roots with
+// no path between them in the graph are independent and may run
+// concurrently, bounded by a parallelism limit. A fatal error in any root
+// cancels the shared context so that in-flight siblings stop and pending
+// roots are skipped; results are always returned in the caller's node order
+// so that summaries stay deterministic regardless of completion order.
+//
+// Dependencies come from two sources: the implicit CAF baseline supplied by
+// the caller, and terraform_remote_state data sources parsed from the
+// generated HCL (a root that reads another root's state key depends on it).
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Node is a unit of work in the dependency graph.
+type Node struct {
+	ID   string
+	Deps []string // IDs of nodes that must complete successfully first
+}
+
+// Result is the outcome of a single node.
+type Result struct {
+	ID      string
+	Err     error // fatal error returned by the run function
+	Skipped bool  // not run because a dependency failed or the run was canceled
+}
+
+// RunFunc executes a single node. Returning a non-nil error is fatal: the
+// scheduler cancels ctx for all other nodes. Non-fatal failures (e.g. drift
+// checks that should not stop siblings) must be recorded by the caller and
+// reported with a nil error.
+type RunFunc func(ctx context.Context, id string) error
+
+// ErrSkipped is recorded on nodes that never ran.
+var ErrSkipped = errors.New("skipped")
+
+// Execute runs fn over nodes in dependency order with at most parallelism
+// concurrent calls (values below 1 are treated as 1). It returns one Result
+// per node in the order of nodes, and the first fatal error encountered.
+func Execute(ctx context.Context, nodes []Node, parallelism int, fn RunFunc) ([]Result, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	if _, err := TopoSort(nodes); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	index := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		index[n.ID] = i
+	}
+	pending := make([]int, len(nodes))
+	dependents := make([][]int, len(nodes))
+	for i, n := range nodes {
+		pending[i] = len(n.Deps)
+		for _, d := range n.Deps {
+			dependents[index[d]] = append(dependents[index[d]], i)
+		}
+	}
+
+	results := make([]Result, len(nodes))
+	for i, n := range nodes {
+		results[i].ID = n.ID
+	}
+
+	type done struct {
+		idx int
+		err error
+	}
+	doneCh := make(chan done)
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+	var firstErr error
+	failed := make([]bool, len(nodes))
+	finished := 0
+	running := 0
+
+	start := func(i int) {
+		running++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				doneCh <- done{idx: i, err: ErrSkipped}
+				return
+			}
+			doneCh <- done{idx: i, err: fn(ctx, nodes[i].ID)}
+		}()
+	}
+
+	// skip marks i and its transitive dependents as skipped.
+	var skip func(i int)
+	skip = func(i int) {
+		if failed[i] {
+			return
+		}
+		failed[i] = true
+		results[i].Skipped = true
+		finished++
+		for _, d := range dependents[i] {
+			skip(d)
+		}
+	}
+
+	for i := range nodes {
+		if pending[i] == 0 {
+			start(i)
+		}
+	}
+
+	for running > 0 {
+		d := <-doneCh
+		running--
+		finished++
+		switch {
+		case errors.Is(d.err, ErrSkipped):
+			results[d.idx].Skipped = true
+			failed[d.idx] = true
+		case d.err != nil:
+			results[d.idx].Err = d.err
+			failed[d.idx] = true
+			if firstErr == nil {
+				firstErr = d.err
+				cancel()
+			}
+		}
+		for _, dep := range dependents[d.idx] {
+			if failed[d.idx] {
+				skip(dep)
+				continue
+			}
+			pending[dep]--
+			if pending[dep] == 0 && !failed[dep] {
+				start(dep)
+			}
+		}
+	}
+	wg.Wait()
+
+	if firstErr == nil && finished < len(nodes) {
+		firstErr = fmt.Errorf("orchestrator: %d node(s) never became ready", len(nodes)-finished)
+	}
+	return results, firstErr
+}
+
+// TopoSort returns node IDs in a dependency-respecting order. Ties are broken
+// by the input order, so an already-ordered list is returned unchanged.
+// It fails on unknown dependencies and on cycles.
+func TopoSort(nodes []Node) ([]string, error) {
+	index := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		if _, dup := index[n.ID]; dup {
+			return nil, fmt.Errorf("orchestrator: duplicate node %q", n.ID)
+		}
+		index[n.ID] = i
+	}
+	for _, n := range nodes {
+		for _, d := range n.Deps {
+			if _, ok := index[d]; !ok {
+				return nil, fmt.Errorf("orchestrator: node %q depends on unknown node %q", n.ID, d)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(nodes))
+	order := make([]string, 0, len(nodes))
+	var stack []string
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("orchestrator: dependency cycle detected: %s", cyclePath(stack, nodes[i].ID))
+		}
+		state[i] = visiting
+		stack = append(stack, nodes[i].ID)
+		for _, d := range nodes[i].Deps {
+			if err := visit(index[d]); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[i] = visited
+		order = append(order, nodes[i].ID)
+		return nil
+	}
+
+	for i := range nodes {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+func cyclePath(stack []string, repeated string) string {
+	start := 0
+	for i, id := range stack {
+		if id == repeated {
+			start = i
+			break
+		}
+	}
+	path := append(append([]string{}, stack[start:]...), repeated)
+	out := path[0]
+	for _, id := range path[1:] {
+		out += " → " + id
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopoSort_PreservesInputOrderForIndependentNodes(t *testing.T) {
+	order, err := TopoSort([]Node{
+		{ID: "b", Deps: []string{"a"}},
+		{ID: "a"},
+		{ID: "c"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, order)
+}
+
+func TestTopoSort_Cycle(t *testing.T) {
+	_, err := TopoSort([]Node{
+		{ID: "a", Deps: []string{"c"}},
+		{ID: "b", Deps: []string{"a"}},
+		{ID: "c", Deps: []string{"b"}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency cycle detected")
+	assert.Contains(t, err.Error(), "a → c → b → a")
+}
+
+func TestTopoSort_UnknownDependency(t *testing.T) {
+	_, err := TopoSort([]Node{{ID: "a", Deps: []string{"missing"}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown node")
+}
+
+func TestExecute_RespectsDependencies(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	nodes := []Node{
+		{ID: "platform"},
+		{ID: "lz1", Deps: []string{"platform"}},
+		{ID: "lz2", Deps: []string{"platform"}},
+		{ID: "lz1-bp", Deps: []string{"lz1"}},
+	}
+
+	results, err := Execute(context.Background(), nodes, 4, func(_ context.Context, id string) error {
+		mu.Lock()
+		order = append(order, id)
+		mu.Unlock()
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	for i, r := range results {
+		assert.Equal(t, nodes[i].ID, r.ID)
+		assert.NoError(t, r.Err)
+		assert.False(t, r.Skipped)
+	}
+	assert.Equal(t, "platform", order[0])
+	assert.Less(t, indexOf(order, "lz1"), indexOf(order, "lz1-bp"))
+}
+
+func TestExecute_RunsIndependentNodesConcurrently(t *testing.T) {
+	var active, peak int32
+	nodes := []Node{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+
+	_, err := Execute(context.Background(), nodes, 2, func(_ context.Context, _ string) error {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+}
+
+func TestExecute_FatalErrorCancelsSiblingsAndSkipsDependents(t *testing.T) {
+	boom := errors.New("boom")
+	nodes := []Node{
+		{ID: "fail"},
+		{ID: "slow"},
+		{ID: "after-fail", Deps: []string{"fail"}},
+	}
+
+	results, err := Execute(context.Background(), nodes, 2, func(ctx context.Context, id string) error {
+		switch id {
+		case "fail":
+			return boom
+		case "slow":
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(2 * time.Second):
+				return nil
+			}
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, boom)
+	assert.ErrorIs(t, results[0].Err, boom)
+	assert.ErrorIs(t, results[1].Err, context.Canceled)
+	assert.True(t, results[2].Skipped)
+}
+
+func indexOf(items []string, v string) int {
+	for i, item := range items {
+		if item == v {
+			return i
+		}
+	}
+	return -1
+}
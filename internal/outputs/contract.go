@@ -0,0 +1,303 @@
+package outputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+)
+
+// Kinds of change reported by Check.
+const (
+	ChangeRemoved     = "removed"      // in the registry, no longer declared
+	ChangeUndeclared  = "undeclared"   // never declared nor registered
+	ChangeTypeChanged = "type-changed" // registered type differs from the planned one
+)
+
+var (
+	outputBlockRegex = regexp.MustCompile(`(?m)^\s*output\s+"([^"]+)"\s*\{`)
+	outputRefRegex   = regexp.MustCompile(`data\.terraform_remote_state\.([A-Za-z0-9_-]+)\.outputs(?:\.([A-Za-z_][A-Za-z0-9_-]*)|\[\s*"([^"]+)"\s*\])`)
+)
+
+// Producer is a root whose outputs are checked.
+type Producer struct {
+	Layer    string
+	Key      string            // backend state key consumers read
+	Declared []string          // outputs declared in the root's *.tf files
+	Kinds    map[string]string // output kinds produced by its current plan; nil when not planned
+}
+
+// Reference is one read of another root's output through a
+// terraform_remote_state data source.
+type Reference struct {
+	Layer      string `json:"layer"` // consuming root
+	DataSource string `json:"dataSource"`
+	Key        string `json:"key"` // state key the data source reads
+	Output     string `json:"output"`
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+}
+
+// Finding is a referenced output that would break its consumer.
+type Finding struct {
+	Producer string `json:"producer"`
+	Output   string `json:"output"`
+	Change   string `json:"change"`
+	Before   string `json:"before,omitempty"` // registered kind, for type changes
+	After    string `json:"after,omitempty"`  // planned kind, for type changes
+	Consumer string `json:"consumer"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Message describes the finding in one line.
+func (f Finding) Message() string {
+	at := fmt.Sprintf("%s (%s:%d)", f.Consumer, f.File, f.Line)
+	switch f.Change {
+	case ChangeRemoved:
+		return fmt.Sprintf("output %s.%s was removed but is still read by %s", f.Producer, f.Output, at)
+	case ChangeTypeChanged:
+		return fmt.Sprintf("output %s.%s changes from %s to %s but is read by %s", f.Producer, f.Output, f.Before, f.After, at)
+	default:
+		return fmt.Sprintf("output %s.%s is not declared but is read by %s", f.Producer, f.Output, at)
+	}
+}
+
+// Declared returns the names of the outputs declared in the top-level *.tf
+// files of a root, sorted.
+func Declared(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, fmt.Errorf("listing terraform files in %s: %w", dir, err)
+	}
+	var names []string
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f, err)
+		}
+		for _, m := range outputBlockRegex.FindAllStringSubmatch(string(data), -1) {
+			names = append(names, m[1])
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// References returns the outputs a root reads from other roots:
+// data.terraform_remote_state.<name>.outputs.<output> (or
+// .outputs["<output>"]) in its top-level *.tf files, resolved to the state
+// key of the data source. Each output is reported once per file, at its
+// first use.
+func References(dir, layer string) ([]Reference, error) {
+	sources, err := orchestrator.ParseRemoteStateRefs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, nil
+	}
+	keys := make(map[string]string, len(sources))
+	for _, s := range sources {
+		keys[s.Name] = s.Key
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, fmt.Errorf("listing terraform files in %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	var refs []Reference
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f, err)
+		}
+		src := string(data)
+		seen := map[string]bool{}
+		for _, loc := range outputRefRegex.FindAllStringSubmatchIndex(src, -1) {
+			ds := src[loc[2]:loc[3]]
+			key, ok := keys[ds]
+			if !ok {
+				continue
+			}
+			var name string
+			if loc[4] >= 0 {
+				name = src[loc[4]:loc[5]]
+			} else {
+				name = src[loc[6]:loc[7]]
+			}
+			if seen[ds+"."+name] {
+				continue
+			}
+			seen[ds+"."+name] = true
+			refs = append(refs, Reference{
+				Layer:      layer,
+				DataSource: ds,
+				Key:        key,
+				Output:     name,
+				File:       filepath.Base(f),
+				Line:       strings.Count(src[:loc[0]], "\n") + 1,
+			})
+		}
+	}
+	return refs, nil
+}
+
+// Kind reduces a Terraform type expression to string, number, bool, list
+// (list, set, tuple) or object (map, object). It returns "" for dynamic or
+// unrecognised types.
+func Kind(typ json.RawMessage) string {
+	var v any
+	if err := json.Unmarshal(typ, &v); err != nil {
+		return ""
+	}
+	switch t := v.(type) {
+	case string:
+		switch t {
+		case "string", "number", "bool":
+			return t
+		}
+	case []any:
+		if len(t) > 0 {
+			switch t[0] {
+			case "list", "set", "tuple":
+				return "list"
+			case "map", "object":
+				return "object"
+			}
+		}
+	}
+	return ""
+}
+
+// valueKind is Kind for a known JSON value.
+func valueKind(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "object"
+	}
+	return ""
+}
+
+// PlannedKinds returns the kind of each output of a plan rendered by
+// `show -json`, from its type when the plan records one and otherwise
+// from its known planned value. Outputs whose kind cannot be told are
+// left out.
+func PlannedKinds(planJSON []byte) (map[string]string, error) {
+	var plan struct {
+		PlannedValues struct {
+			Outputs map[string]struct {
+				Type  json.RawMessage `json:"type"`
+				Value any             `json:"value"`
+			} `json:"outputs"`
+		} `json:"planned_values"`
+		OutputChanges map[string]struct {
+			After        any `json:"after"`
+			AfterUnknown any `json:"after_unknown"`
+		} `json:"output_changes"`
+	}
+	if err := json.Unmarshal(planJSON, &plan); err != nil {
+		return nil, fmt.Errorf("parsing plan JSON: %w", err)
+	}
+	kinds := map[string]string{}
+	for name, o := range plan.PlannedValues.Outputs {
+		k := ""
+		if len(o.Type) > 0 {
+			k = Kind(o.Type)
+		}
+		if k == "" {
+			k = valueKind(o.Value)
+		}
+		if k != "" {
+			kinds[name] = k
+		}
+	}
+	for name, c := range plan.OutputChanges {
+		if _, ok := kinds[name]; ok || c.AfterUnknown == true {
+			continue
+		}
+		if k := valueKind(c.After); k != "" {
+			kinds[name] = k
+		}
+	}
+	return kinds, nil
+}
+
+// Check reports the references to producers' outputs that would break: an
+// output no longer declared (removed when reg still lists it, undeclared
+// otherwise), or one whose planned kind differs from its registered type.
+// References to roots that are not producers are ignored. reg may be nil.
+func Check(producers []Producer, refs []Reference, reg *Registry) []Finding {
+	byKey := make(map[string]Producer, len(producers))
+	for _, p := range producers {
+		byKey[p.Key] = p
+	}
+
+	var findings []Finding
+	for _, ref := range refs {
+		p, ok := byKey[ref.Key]
+		if !ok || p.Layer == ref.Layer {
+			continue
+		}
+		var registered *Output
+		if reg != nil {
+			if l, ok := reg.Layers[p.Layer]; ok {
+				if o, ok := l.Outputs[ref.Output]; ok {
+					registered = &o
+				}
+			}
+		}
+		f := Finding{Producer: p.Layer, Output: ref.Output, Consumer: ref.Layer, File: ref.File, Line: ref.Line}
+
+		switch {
+		case !contains(p.Declared, ref.Output) && registered != nil:
+			f.Change = ChangeRemoved
+		case !contains(p.Declared, ref.Output):
+			f.Change = ChangeUndeclared
+		case registered != nil && p.Kinds != nil:
+			before, after := Kind(registered.Type), p.Kinds[ref.Output]
+			if before == "" || after == "" || before == after {
+				continue
+			}
+			f.Change, f.Before, f.After = ChangeTypeChanged, before, after
+		default:
+			continue
+		}
+		findings = append(findings, f)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Producer != b.Producer {
+			return a.Producer < b.Producer
+		}
+		if a.Output != b.Output {
+			return a.Output < b.Output
+		}
+		return a.Consumer < b.Consumer
+	})
+	return findings
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package outputs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTF(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+}
+
+const consumerTF = `data "terraform_remote_state" "connectivity" {
+  backend = "azurerm"
+  config = {
+    key = "platform-connectivity.tfstate"
+  }
+}
+
+module "spoke" {
+  hub_vnet_id  = data.terraform_remote_state.connectivity.outputs.hub_vnet_id
+  firewall_ip  = data.terraform_remote_state.connectivity.outputs["firewall_ip"]
+  hub_vnet_rg  = data.terraform_remote_state.connectivity.outputs.hub_vnet_id
+}
+`
+
+func TestDeclaredAndReferences(t *testing.T) {
+	dir := t.TempDir()
+	writeTF(t, dir, "outputs.tf", "output \"b\" {\n  value = 1\n}\n\noutput \"a\" {\n  value = 2\n}\n")
+	declared, err := Declared(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, declared)
+
+	writeTF(t, dir, "main.tf", consumerTF)
+	refs, err := References(dir, "app")
+	require.NoError(t, err)
+	require.Len(t, refs, 2, "repeated reads of an output are reported once")
+	assert.Equal(t, Reference{Layer: "app", DataSource: "connectivity", Key: "platform-connectivity.tfstate", Output: "hub_vnet_id", File: "main.tf", Line: 9}, refs[0])
+	assert.Equal(t, "firewall_ip", refs[1].Output)
+	assert.Equal(t, 10, refs[1].Line)
+}
+
+func TestKind(t *testing.T) {
+	for typ, want := range map[string]string{
+		`"string"`:                      "string",
+		`"bool"`:                        "bool",
+		`["list","string"]`:             "list",
+		`["tuple",["string","number"]]`: "list",
+		`["map","string"]`:              "object",
+		`["object",{"id":"string"}]`:    "object",
+		`"dynamic"`:                     "",
+	} {
+		assert.Equal(t, want, Kind([]byte(typ)), typ)
+	}
+}
+
+func TestPlannedKinds(t *testing.T) {
+	kinds, err := PlannedKinds([]byte(`{
+  "planned_values": {"outputs": {
+    "hub_vnet_id": {"sensitive": false, "value": "/subscriptions/x"},
+    "firewall_ip": {"sensitive": false, "type": ["list", "string"], "value": ["10.0.0.4"]}
+  }},
+  "output_changes": {
+    "dns_zones": {"after": {"a": 1}, "after_unknown": false},
+    "pending": {"after": null, "after_unknown": true}
+  }
+}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"hub_vnet_id": "string", "firewall_ip": "list", "dns_zones": "object"}, kinds)
+}
+
+func TestCheck(t *testing.T) {
+	reg := New()
+	reg.Layers["connectivity"] = Layer{Key: "platform-connectivity.tfstate", Outputs: map[string]Output{
+		"hub_vnet_id": {Type: []byte(`"string"`)},
+		"firewall_ip": {Type: []byte(`"string"`)},
+		"legacy_id":   {Type: []byte(`"string"`)},
+	}}
+	producer := Producer{
+		Layer:    "connectivity",
+		Key:      "platform-connectivity.tfstate",
+		Declared: []string{"firewall_ip", "hub_vnet_id"},
+		Kinds:    map[string]string{"hub_vnet_id": "string", "firewall_ip": "list"},
+	}
+	ref := func(output string, line int) Reference {
+		return Reference{Layer: "app", Key: "platform-connectivity.tfstate", Output: output, File: "main.tf", Line: line}
+	}
+	refs := []Reference{
+		ref("hub_vnet_id", 9),
+		ref("firewall_ip", 10),
+		ref("legacy_id", 11),
+		ref("typo_id", 12),
+		{Layer: "app", Key: "platform-identity.tfstate", Output: "anything", File: "main.tf", Line: 13},
+	}
+
+	findings := Check([]Producer{producer}, refs, reg)
+	require.Len(t, findings, 3)
+	assert.Equal(t, ChangeTypeChanged, findings[0].Change)
+	assert.Equal(t, "output connectivity.firewall_ip changes from string to list but is read by app (main.tf:10)", findings[0].Message())
+	assert.Equal(t, ChangeRemoved, findings[1].Change)
+	assert.Equal(t, "legacy_id", findings[1].Output)
+	assert.Equal(t, ChangeUndeclared, findings[2].Change)
+	assert.Equal(t, "typo_id", findings[2].Output)
+
+	producer.Kinds = nil
+	findings = Check([]Producer{producer}, refs, nil)
+	require.Len(t, findings, 2, "without a registry or plan only undeclared outputs are reported")
+	for _, f := range findings {
+		assert.Equal(t, ChangeUndeclared, f.Change)
+	}
+}
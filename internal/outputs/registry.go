@@ -0,0 +1,122 @@
+// Package outputs keeps the registry of layer outputs and checks the output
+// contract between layers.
+//
+// Layers share data through terraform_remote_state data sources, which makes
+// every layer's outputs a public API. The registry (.lzctl/outputs.json)
+// records the outputs each root exposed the last time `lzctl outputs` ran:
+// names, types and sensitivity, never values. Check compares what a root
+// declares (and, after a plan, the types it produces) with what downstream
+// roots read from it, and reports removed or type-changed outputs that are
+// still referenced.
+package outputs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultPath is the registry location, relative to the repository root.
+const DefaultPath = ".lzctl/outputs.json"
+
+// registryVersion is the format version of Registry.
+const registryVersion = 1
+
+// Registry records the outputs of each root, keyed by root name.
+type Registry struct {
+	Version     int              `json:"version"`
+	GeneratedAt time.Time        `json:"generatedAt"`
+	Layers      map[string]Layer `json:"layers"`
+}
+
+// Layer is the registry entry of one root.
+type Layer struct {
+	Key       string            `json:"key"` // backend state key consumers read
+	Dir       string            `json:"dir"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+	Outputs   map[string]Output `json:"outputs"`
+}
+
+// Output is the contract of one output: its Terraform type expression (as
+// printed by `terraform output -json`) and whether it is sensitive.
+type Output struct {
+	Type      json.RawMessage `json:"type,omitempty"`
+	Sensitive bool            `json:"sensitive,omitempty"`
+}
+
+// New returns an empty registry.
+func New() *Registry {
+	return &Registry{Version: registryVersion, Layers: map[string]Layer{}}
+}
+
+// Load reads the registry at path. It returns (nil, nil) when the file does
+// not exist.
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading output registry: %w", err)
+	}
+	var r Registry
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parsing output registry %s: %w", path, err)
+	}
+	if r.Version != registryVersion {
+		return nil, fmt.Errorf("output registry %s: unsupported version %d", path, r.Version)
+	}
+	if r.Layers == nil {
+		r.Layers = map[string]Layer{}
+	}
+	return &r, nil
+}
+
+// Save writes the registry to path, creating its directory.
+func (r *Registry) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating registry directory: %w", err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding output registry: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing output registry: %w", err)
+	}
+	return nil
+}
+
+// ParseTerraformOutputs reads the output of `terraform output -json` and
+// keeps the type and sensitivity of each output. Values are dropped.
+func ParseTerraformOutputs(data []byte) (map[string]Output, error) {
+	var raw map[string]struct {
+		Sensitive bool            `json:"sensitive"`
+		Type      json.RawMessage `json:"type"`
+	}
+	if err := json.Unmarshal(bytes.TrimSpace(data), &raw); err != nil {
+		return nil, fmt.Errorf("parsing terraform output -json: %w", err)
+	}
+	out := make(map[string]Output, len(raw))
+	for name, o := range raw {
+		out[name] = Output{Type: compact(o.Type), Sensitive: o.Sensitive}
+	}
+	return out, nil
+}
+
+// compact normalises a JSON type expression so that equal types compare
+// equal byte for byte.
+func compact(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return raw
+	}
+	return buf.Bytes()
+}
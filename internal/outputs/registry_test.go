@@ -0,0 +1,52 @@
+package outputs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTerraformOutputs_DropsValues(t *testing.T) {
+	out, err := ParseTerraformOutputs([]byte(`{
+  "hub_vnet_id": {"sensitive": false, "type": "string", "value": "/subscriptions/x/vnet"},
+  "firewall_ips": {"sensitive": false, "type": ["list", "string"], "value": ["10.0.0.4"]},
+  "admin_key": {"sensitive": true, "type": "string", "value": "secret"}
+}`))
+	require.NoError(t, err)
+	require.Len(t, out, 3)
+	assert.JSONEq(t, `["list","string"]`, string(out["firewall_ips"].Type))
+	assert.True(t, out["admin_key"].Sensitive)
+
+	reg := New()
+	reg.Layers["connectivity"] = Layer{Key: "platform-connectivity.tfstate", Outputs: out}
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	require.NoError(t, reg.Save(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+	assert.NotContains(t, string(data), "10.0.0.4")
+}
+
+func TestLoad_RoundTripAndMissing(t *testing.T) {
+	dir := t.TempDir()
+	reg, err := Load(filepath.Join(dir, "missing.json"))
+	require.NoError(t, err)
+	assert.Nil(t, reg)
+
+	path := filepath.Join(dir, "outputs.json")
+	want := New()
+	want.Layers["identity"] = Layer{Key: "platform-identity.tfstate", Outputs: map[string]Output{"group_id": {Type: []byte(`"string"`)}}}
+	require.NoError(t, want.Save(path))
+
+	got, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "platform-identity.tfstate", got.Layers["identity"].Key)
+	assert.Equal(t, `"string"`, string(got.Layers["identity"].Outputs["group_id"].Type))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"version":9}`), 0o644))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "unsupported version 9")
+}
@@ -0,0 +1,116 @@
+// Package plancache lets lzctl skip re-planning a root whose inputs and
+// remote state are unchanged since its saved plan was produced.
+//
+// After a successful plan, Store writes <planFile>.cache.json next to the
+// plan. It holds the cache Key (content hashes of everything that feeds the
+// plan plus the state blob version), the hash of the plan file itself, the
+// structured summary and the human plan output. Lookup returns that entry
+// only when the key matches and the plan file on disk is still the one that
+// was summarized.
+package plancache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+	"github.com/kjourdan1/lzctl/internal/plansummary"
+)
+
+// fileSuffix is appended to the plan file path to name the cache entry.
+const fileSuffix = ".cache.json"
+
+// Key lists everything a plan depends on. Two plans computed from equal
+// keys are interchangeable.
+type Key struct {
+	Inputs       string `json:"inputs"`             // the root's own Terraform inputs, local modules included
+	Shared       string `json:"shared,omitempty"`   // shared backend/provider files the root reads
+	LockFile     string `json:"lockFile,omitempty"` // provider versions pinned in .terraform.lock.hcl
+	Config       string `json:"config"`             // the lzctl.yaml slice that feeds the root
+	Tool         string `json:"tool"`               // terraform or tofu
+	StateVersion string `json:"stateVersion"`       // version ID or ETag of the remote state blob
+
+	// Upstream holds the version of every state the root reads through
+	// terraform_remote_state, by state key: a new upstream output changes
+	// the plan although nothing in the root did.
+	Upstream map[string]string `json:"upstream,omitempty"`
+	Policies string            `json:"policies,omitempty"` // policies/ definitions, for the governance root
+}
+
+// Hash returns a stable digest of the key.
+func (k Key) Hash() string {
+	data, _ := json.Marshal(k)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Entry is the persisted cache record for one plan file.
+type Entry struct {
+	Key       Key                  `json:"key"`
+	Hash      string               `json:"hash"`
+	PlanHash  string               `json:"planHash"`
+	CreatedAt time.Time            `json:"createdAt"`
+	Summary   *plansummary.Summary `json:"summary"`
+	Output    string               `json:"output,omitempty"` // human plan output, for text reports
+}
+
+// Path returns the cache entry path for planFile.
+func Path(planFile string) string {
+	return planFile + fileSuffix
+}
+
+// Lookup returns the cached entry for planFile when it was stored under an
+// equal key and planFile has not been replaced since. Any read or decode
+// problem is a miss.
+func Lookup(planFile string, key Key) (*Entry, bool) {
+	data, err := os.ReadFile(Path(planFile))
+	if err != nil {
+		return nil, false
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Summary == nil {
+		return nil, false
+	}
+	if e.Hash != key.Hash() {
+		return nil, false
+	}
+	planHash, err := orchestrator.HashFile(planFile)
+	if err != nil || planHash != e.PlanHash {
+		return nil, false
+	}
+	return &e, true
+}
+
+// Store records that planFile was produced from key, summarizes as s and
+// printed output.
+func Store(planFile string, key Key, s *plansummary.Summary, output string) error {
+	planHash, err := orchestrator.HashFile(planFile)
+	if err != nil {
+		return fmt.Errorf("plancache: hashing %s: %w", planFile, err)
+	}
+	e := Entry{
+		Key:       key,
+		Hash:      key.Hash(),
+		PlanHash:  planHash,
+		CreatedAt: time.Now().UTC(),
+		Summary:   s,
+		Output:    output,
+	}
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("plancache: encoding entry: %w", err)
+	}
+	if err := os.WriteFile(Path(planFile), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("plancache: writing %s: %w", Path(planFile), err)
+	}
+	return nil
+}
+
+// Invalidate removes the cache entry for planFile, if any.
+func Invalidate(planFile string) {
+	_ = os.Remove(Path(planFile))
+}
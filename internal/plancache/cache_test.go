@@ -0,0 +1,56 @@
+package plancache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kjourdan1/lzctl/internal/plansummary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey() Key {
+	return Key{Inputs: "sha256:a", Config: "sha256:c", Tool: "terraform", StateVersion: "v1"}
+}
+
+func TestStoreAndLookup(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "tfplan")
+	require.NoError(t, os.WriteFile(planFile, []byte("plan-v1"), 0o644))
+	summary := &plansummary.Summary{Add: 2, Resources: []plansummary.ResourceChange{}}
+
+	require.NoError(t, Store(planFile, testKey(), summary, "Plan: 2 to add"))
+	assert.FileExists(t, Path(planFile))
+
+	e, ok := Lookup(planFile, testKey())
+	require.True(t, ok)
+	assert.Equal(t, 2, e.Summary.Add)
+	assert.Equal(t, "v1", e.Key.StateVersion)
+	assert.Equal(t, "Plan: 2 to add", e.Output)
+}
+
+func TestLookup_Misses(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "tfplan")
+	_, ok := Lookup(planFile, testKey())
+	assert.False(t, ok, "no entry")
+
+	require.NoError(t, os.WriteFile(planFile, []byte("plan-v1"), 0o644))
+	require.NoError(t, Store(planFile, testKey(), &plansummary.Summary{}, ""))
+
+	changedState := testKey()
+	changedState.StateVersion = "v2"
+	_, ok = Lookup(planFile, changedState)
+	assert.False(t, ok, "state version changed")
+
+	changedInputs := testKey()
+	changedInputs.Inputs = "sha256:b"
+	_, ok = Lookup(planFile, changedInputs)
+	assert.False(t, ok, "inputs changed")
+
+	require.NoError(t, os.WriteFile(planFile, []byte("plan-v2"), 0o644))
+	_, ok = Lookup(planFile, testKey())
+	assert.False(t, ok, "plan file replaced")
+
+	Invalidate(planFile)
+	assert.NoFileExists(t, Path(planFile))
+}
@@ -0,0 +1,414 @@
+package planrules
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Fields available to `when` expressions. Each is evaluated against one
+// resource change of one layer.
+var fields = map[string]func(c change) any{
+	"address": func(c change) any { return c.Address },
+	"type":    func(c change) any { return c.Type },
+	"name":    func(c change) any { return c.Name },
+	"module":  func(c change) any { return c.Module },
+	"action":  func(c change) any { return c.Action },
+	"actions": func(c change) any { return c.Actions },
+	"layer":   func(c change) any { return c.Layer },
+	"kind":    func(c change) any { return c.Kind },
+}
+
+// Expr is a compiled `when` expression.
+//
+// Grammar:
+//
+//	expr    = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | compare
+//	compare = operand [ ( "==" | "!=" | "in" | "matches" ) operand ]
+//	operand = field | string | "true" | "false" | list | "(" expr ")"
+//	list    = "[" [ operand { "," operand } ] "]"
+//
+// Strings use double or single quotes, without escapes: quote with one to
+// embed the other. `matches` takes a glob where `*`
+// matches any run of characters and `?` a single character. `in` tests
+// membership in a list; with a list field on the left (actions), it is true
+// when any element is in the right-hand list.
+type Expr struct {
+	src  string
+	root node
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string { return e.src }
+
+// Compile parses src.
+func Compile(src string) (*Expr, error) {
+	p := &parser{src: src}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("expression %q: unexpected %q", src, p.toks[p.pos].text)
+	}
+	return &Expr{src: src, root: n}, nil
+}
+
+// match evaluates the expression for c.
+func (e *Expr) match(c change) (bool, error) {
+	v, err := e.root.eval(c)
+	if err != nil {
+		return false, fmt.Errorf("expression %q: %w", e.src, err)
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q: result is %T, not a boolean", e.src, v)
+	}
+	return b, nil
+}
+
+// ── AST ─────────────────────────────────────────────────────
+
+type node interface {
+	eval(c change) (any, error)
+}
+
+type literal struct{ v any }
+
+func (l literal) eval(change) (any, error) { return l.v, nil }
+
+type fieldRef struct{ name string }
+
+func (f fieldRef) eval(c change) (any, error) { return fields[f.name](c), nil }
+
+type listNode struct{ items []node }
+
+func (l listNode) eval(c change) (any, error) {
+	out := make([]any, 0, len(l.items))
+	for _, it := range l.items {
+		v, err := it.eval(c)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+type notNode struct{ x node }
+
+func (n notNode) eval(c change) (any, error) {
+	v, err := n.x.eval(c)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("! applied to %T", v)
+	}
+	return !b, nil
+}
+
+type logicNode struct {
+	op   string // && or ||
+	l, r node
+}
+
+func (n logicNode) eval(c change) (any, error) {
+	lv, err := n.l.eval(c)
+	if err != nil {
+		return nil, err
+	}
+	lb, ok := lv.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%s applied to %T", n.op, lv)
+	}
+	if (n.op == "&&" && !lb) || (n.op == "||" && lb) {
+		return lb, nil
+	}
+	rv, err := n.r.eval(c)
+	if err != nil {
+		return nil, err
+	}
+	rb, ok := rv.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%s applied to %T", n.op, rv)
+	}
+	return rb, nil
+}
+
+type compareNode struct {
+	op   string
+	l, r node
+}
+
+func (n compareNode) eval(c change) (any, error) {
+	lv, err := n.l.eval(c)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := n.r.eval(c)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(lv, rv), nil
+	case "!=":
+		return !equal(lv, rv), nil
+	case "in":
+		list, ok := rv.([]any)
+		if !ok {
+			return nil, fmt.Errorf("right side of in must be a list")
+		}
+		if many, ok := lv.([]string); ok {
+			for _, s := range many {
+				if slices.ContainsFunc(list, func(x any) bool { return equal(s, x) }) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		return slices.ContainsFunc(list, func(x any) bool { return equal(lv, x) }), nil
+	case "matches":
+		s, lok := lv.(string)
+		pattern, rok := rv.(string)
+		if !lok || !rok {
+			return nil, fmt.Errorf("matches needs strings on both sides")
+		}
+		return globMatch(pattern, s), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+func equal(a, b any) bool {
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	}
+	return false
+}
+
+// globMatch reports whether s matches pattern, where * matches any run of
+// characters (including dots and brackets) and ? matches one character.
+func globMatch(pattern, s string) bool {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String()).MatchString(s)
+}
+
+// ── Parser ──────────────────────────────────────────────────
+
+type tokKind int
+
+const (
+	tokIdent tokKind = iota
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+}
+
+type parser struct {
+	src  string
+	toks []token
+	pos  int
+}
+
+func (p *parser) lex() error {
+	s := p.src
+	for i := 0; i < len(s); {
+		ch := rune(s[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case ch == '"' || ch == '\'':
+			end := strings.IndexByte(s[i+1:], s[i])
+			if end < 0 {
+				return fmt.Errorf("expression %q: unterminated string", p.src)
+			}
+			p.toks = append(p.toks, token{tokString, s[i+1 : i+1+end]})
+			i += end + 2
+		case unicode.IsLetter(ch) || ch == '_':
+			j := i
+			for j < len(s) && (unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j])) || s[j] == '_') {
+				j++
+			}
+			p.toks = append(p.toks, token{tokIdent, s[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, cand := range []string{"==", "!=", "&&", "||", "!", "(", ")", "[", "]", ","} {
+				if strings.HasPrefix(s[i:], cand) {
+					op = cand
+					break
+				}
+			}
+			if op == "" {
+				return fmt.Errorf("expression %q: unexpected character %q", p.src, ch)
+			}
+			p.toks = append(p.toks, token{tokOp, op})
+			i += len(op)
+		}
+	}
+	return nil
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.toks) {
+		return token{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *parser) accept(kind tokKind, text string) bool {
+	if t, ok := p.peek(); ok && t.kind == kind && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokOp, "||") {
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = logicNode{op: "||", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokOp, "&&") {
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = logicNode{op: "&&", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept(tokOp, "!") {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x: x}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (node, error) {
+	l, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	t, ok := p.peek()
+	if !ok {
+		return l, nil
+	}
+	if (t.kind == tokOp && (t.text == "==" || t.text == "!=")) || (t.kind == tokIdent && (t.text == "in" || t.text == "matches")) {
+		p.pos++
+		r, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: t.text, l: l, r: r}, nil
+	}
+	return l, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("expression %q: unexpected end", p.src)
+	}
+	p.pos++
+	switch {
+	case t.kind == tokString:
+		return literal{t.text}, nil
+	case t.kind == tokIdent && t.text == "true":
+		return literal{true}, nil
+	case t.kind == tokIdent && t.text == "false":
+		return literal{false}, nil
+	case t.kind == tokIdent:
+		if _, known := fields[t.text]; !known {
+			return nil, fmt.Errorf("expression %q: unknown field %q (want one of %s)", p.src, t.text, strings.Join(fieldNames(), ", "))
+		}
+		return fieldRef{t.text}, nil
+	case t.kind == tokOp && t.text == "(":
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(tokOp, ")") {
+			return nil, fmt.Errorf("expression %q: missing )", p.src)
+		}
+		return n, nil
+	case t.kind == tokOp && t.text == "[":
+		var items []node
+		if p.accept(tokOp, "]") {
+			return listNode{}, nil
+		}
+		for {
+			it, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, it)
+			if p.accept(tokOp, "]") {
+				return listNode{items: items}, nil
+			}
+			if !p.accept(tokOp, ",") {
+				return nil, fmt.Errorf("expression %q: expected , or ] in list", p.src)
+			}
+		}
+	}
+	return nil, fmt.Errorf("expression %q: unexpected %q", p.src, t.text)
+}
+
+func fieldNames() []string {
+	names := make([]string, 0, len(fields))
+	for n := range fields {
+		names = append(names, n)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,227 @@
+// Package planrules evaluates declarative guardrails against planned
+// resource changes.
+//
+// Rules live in .lzctl/plan-rules.yaml:
+//
+//	apiVersion: lzctl/v1
+//	kind: PlanRules
+//	spec:
+//	  rules:
+//	    - name: protect-management-groups
+//	      severity: deny
+//	      when: type == "azurerm_management_group" && action in ["delete", "replace"]
+//	    - name: firewall-policy-approval
+//	      severity: deny
+//	      when: type matches "azurerm_firewall_policy*" && action != "read"
+//	      approvalLabel: firewall-approved
+//	    - name: replacement-budget
+//	      severity: warn
+//	      when: action == "replace"
+//	      maxPerLayer: 3
+//
+// Each rule's `when` expression is evaluated against every resource change
+// of every layer (see Expr for the syntax). A rule reports each matching
+// change, unless it sets maxPerLayer (then it reports once per layer whose
+// match count exceeds the cap) or approvalLabel (then it is silent when the
+// label was supplied). deny findings block apply; warn findings are printed.
+package planrules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kjourdan1/lzctl/internal/plansummary"
+)
+
+// DefaultPath is the rules file location relative to the repository root.
+const DefaultPath = ".lzctl/plan-rules.yaml"
+
+// Severities.
+const (
+	SeverityWarn = "warn"
+	SeverityDeny = "deny"
+)
+
+// File is the on-disk rules document.
+type File struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Spec       struct {
+		Rules []Rule `yaml:"rules"`
+	} `yaml:"spec"`
+}
+
+// Rule is a single guardrail.
+type Rule struct {
+	Name          string `yaml:"name"`
+	Description   string `yaml:"description,omitempty"`
+	Severity      string `yaml:"severity"`
+	When          string `yaml:"when"`
+	ApprovalLabel string `yaml:"approvalLabel,omitempty"`
+	MaxPerLayer   *int   `yaml:"maxPerLayer,omitempty"`
+
+	expr *Expr
+}
+
+// RuleSet is a validated, compiled set of rules.
+type RuleSet struct {
+	Path  string
+	Rules []Rule
+}
+
+// Load reads and compiles the rules file at path. It returns (nil, nil) when
+// the file does not exist.
+func Load(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("planrules: reading %s: %w", path, err)
+	}
+	rs, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("planrules: %s: %w", filepath.Base(path), err)
+	}
+	rs.Path = path
+	return rs, nil
+}
+
+// Parse validates and compiles a rules document.
+func Parse(data []byte) (*RuleSet, error) {
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing rules: %w", err)
+	}
+	if f.Kind != "" && f.Kind != "PlanRules" {
+		return nil, fmt.Errorf("kind is %q, want PlanRules", f.Kind)
+	}
+
+	seen := map[string]bool{}
+	rs := &RuleSet{}
+	for i, r := range f.Spec.Rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule #%d: name is required", i+1)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("rule %q: duplicate name", r.Name)
+		}
+		seen[r.Name] = true
+		if r.Severity == "" {
+			r.Severity = SeverityDeny
+		}
+		if r.Severity != SeverityWarn && r.Severity != SeverityDeny {
+			return nil, fmt.Errorf("rule %q: severity must be %s or %s, got %q", r.Name, SeverityWarn, SeverityDeny, r.Severity)
+		}
+		if r.When == "" {
+			return nil, fmt.Errorf("rule %q: when is required", r.Name)
+		}
+		if r.MaxPerLayer != nil && *r.MaxPerLayer < 0 {
+			return nil, fmt.Errorf("rule %q: maxPerLayer must not be negative", r.Name)
+		}
+		if r.MaxPerLayer != nil && r.ApprovalLabel != "" {
+			return nil, fmt.Errorf("rule %q: maxPerLayer and approvalLabel cannot be combined", r.Name)
+		}
+		expr, err := Compile(r.When)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		r.expr = expr
+		rs.Rules = append(rs.Rules, r)
+	}
+	return rs, nil
+}
+
+// Layer is the planned changes of one root.
+type Layer struct {
+	Name    string // e.g. connectivity, lz:app-one
+	Kind    string // platform | landing-zone | blueprint
+	Changes []plansummary.ResourceChange
+}
+
+// Finding is one rule violation.
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Layer    string `json:"layer"`
+	Address  string `json:"address,omitempty"` // empty for maxPerLayer findings
+	Action   string `json:"action,omitempty"`
+	Message  string `json:"message"`
+}
+
+// change is what `when` expressions see.
+type change struct {
+	plansummary.ResourceChange
+	Layer string
+	Kind  string
+}
+
+// Evaluate runs every rule against every layer. labels are the approval
+// labels supplied for this run.
+func (rs *RuleSet) Evaluate(layers []Layer, labels []string) ([]Finding, error) {
+	if rs == nil {
+		return nil, nil
+	}
+	var findings []Finding
+	for _, r := range rs.Rules {
+		if r.ApprovalLabel != "" && slices.Contains(labels, r.ApprovalLabel) {
+			continue
+		}
+		for _, l := range layers {
+			var matched []plansummary.ResourceChange
+			for _, rc := range l.Changes {
+				ok, err := r.expr.match(change{ResourceChange: rc, Layer: l.Name, Kind: l.Kind})
+				if err != nil {
+					return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+				}
+				if ok {
+					matched = append(matched, rc)
+				}
+			}
+
+			if r.MaxPerLayer != nil {
+				if len(matched) > *r.MaxPerLayer {
+					findings = append(findings, Finding{
+						Rule:     r.Name,
+						Severity: r.Severity,
+						Layer:    l.Name,
+						Message:  fmt.Sprintf("%d matching changes, at most %d allowed", len(matched), *r.MaxPerLayer),
+					})
+				}
+				continue
+			}
+			for _, rc := range matched {
+				findings = append(findings, Finding{
+					Rule:     r.Name,
+					Severity: r.Severity,
+					Layer:    l.Name,
+					Address:  rc.Address,
+					Action:   rc.Action,
+					Message:  r.message(),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+func (r Rule) message() string {
+	msg := r.Description
+	if msg == "" {
+		msg = "matches " + r.When
+	}
+	if r.ApprovalLabel != "" {
+		msg += fmt.Sprintf(" (requires approval label %q)", r.ApprovalLabel)
+	}
+	return msg
+}
+
+// Denied reports whether any finding has deny severity.
+func Denied(findings []Finding) bool {
+	return slices.ContainsFunc(findings, func(f Finding) bool { return f.Severity == SeverityDeny })
+}
@@ -0,0 +1,190 @@
+package planrules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/plansummary"
+)
+
+const sampleRules = `apiVersion: lzctl/v1
+kind: PlanRules
+spec:
+  rules:
+    - name: protect-management-groups
+      severity: deny
+      description: management groups must not be deleted
+      when: type == "azurerm_management_group" && action in ["delete", "replace"]
+    - name: firewall-policy-approval
+      severity: deny
+      when: type matches "azurerm_firewall_policy*" && action != "read"
+      approvalLabel: firewall-approved
+    - name: replacement-budget
+      severity: warn
+      when: action == "replace"
+      maxPerLayer: 1
+`
+
+func sampleLayers() []Layer {
+	return []Layer{
+		{Name: "management-groups", Kind: "platform", Changes: []plansummary.ResourceChange{
+			{Address: "azurerm_management_group.corp", Type: "azurerm_management_group", Action: "delete", Actions: []string{"delete"}},
+			{Address: "azurerm_management_group.online", Type: "azurerm_management_group", Action: "update", Actions: []string{"update"}},
+		}},
+		{Name: "connectivity", Kind: "platform", Changes: []plansummary.ResourceChange{
+			{Address: "azurerm_firewall_policy_rule_collection_group.hub", Type: "azurerm_firewall_policy_rule_collection_group", Action: "update", Actions: []string{"update"}},
+			{Address: "azurerm_public_ip.a", Type: "azurerm_public_ip", Action: "replace", Actions: []string{"delete", "create"}},
+			{Address: "azurerm_public_ip.b", Type: "azurerm_public_ip", Action: "replace", Actions: []string{"create", "delete"}},
+		}},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	rs, err := Parse([]byte(sampleRules))
+	require.NoError(t, err)
+
+	findings, err := rs.Evaluate(sampleLayers(), nil)
+	require.NoError(t, err)
+	require.Len(t, findings, 3)
+
+	assert.Equal(t, Finding{
+		Rule: "protect-management-groups", Severity: "deny", Layer: "management-groups",
+		Address: "azurerm_management_group.corp", Action: "delete",
+		Message: "management groups must not be deleted",
+	}, findings[0])
+	assert.Equal(t, "firewall-policy-approval", findings[1].Rule)
+	assert.Contains(t, findings[1].Message, `requires approval label "firewall-approved"`)
+	assert.Equal(t, Finding{
+		Rule: "replacement-budget", Severity: "warn", Layer: "connectivity",
+		Message: "2 matching changes, at most 1 allowed",
+	}, findings[2])
+	assert.True(t, Denied(findings))
+}
+
+func TestEvaluate_ApprovalLabelSilencesRule(t *testing.T) {
+	rs, err := Parse([]byte(sampleRules))
+	require.NoError(t, err)
+
+	findings, err := rs.Evaluate(sampleLayers(), []string{"firewall-approved"})
+	require.NoError(t, err)
+	for _, f := range findings {
+		assert.NotEqual(t, "firewall-policy-approval", f.Rule)
+	}
+}
+
+func TestEvaluate_NilRuleSet(t *testing.T) {
+	var rs *RuleSet
+	findings, err := rs.Evaluate(sampleLayers(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+	assert.False(t, Denied(findings))
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"missing name":      "spec:\n  rules:\n    - when: action == \"delete\"\n",
+		"bad severity":      "spec:\n  rules:\n    - name: a\n      severity: block\n      when: action == \"delete\"\n",
+		"missing when":      "spec:\n  rules:\n    - name: a\n",
+		"unknown field":     "spec:\n  rules:\n    - name: a\n      when: resource == \"x\"\n",
+		"duplicate name":    "spec:\n  rules:\n    - name: a\n      when: true\n    - name: a\n      when: true\n",
+		"wrong kind":        "kind: Policy\n",
+		"negative cap":      "spec:\n  rules:\n    - name: a\n      when: true\n      maxPerLayer: -1\n",
+		"cap with approval": "spec:\n  rules:\n    - name: a\n      when: true\n      maxPerLayer: 1\n      approvalLabel: ok\n",
+	}
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(doc))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParse_DefaultsToDeny(t *testing.T) {
+	rs, err := Parse([]byte("spec:\n  rules:\n    - name: a\n      when: action == \"delete\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, SeverityDeny, rs.Rules[0].Severity)
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	rs, err := Load(filepath.Join(t.TempDir(), "plan-rules.yaml"))
+	require.NoError(t, err)
+	assert.Nil(t, rs)
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan-rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(sampleRules), 0o644))
+	rs, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, rs.Path)
+	assert.Len(t, rs.Rules, 3)
+}
+
+func TestExpr(t *testing.T) {
+	c := change{
+		ResourceChange: plansummary.ResourceChange{
+			Address: `module.hub.azurerm_subnet.this["AzureFirewallSubnet"]`,
+			Type:    "azurerm_subnet",
+			Name:    "this",
+			Module:  "module.hub",
+			Action:  "replace",
+			Actions: []string{"delete", "create"},
+		},
+		Layer: "connectivity",
+		Kind:  "platform",
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`type == "azurerm_subnet"`, true},
+		{`type != 'azurerm_subnet'`, false},
+		{`action in ["delete", "replace"]`, true},
+		{`actions in ["delete"]`, true},
+		{`actions in ["update"]`, false},
+		{`address matches "module.hub.*"`, true},
+		{`address matches '*["AzureFirewallSubnet"]'`, true},
+		{`address matches "azurerm_*"`, false},
+		{`name == "this" && module == "module.hub"`, true},
+		{`layer == "identity" || kind == "platform"`, true},
+		{`!(layer == "identity")`, true},
+		{`!(action == "replace") && true`, false},
+		{`action in []`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Compile(tt.expr)
+			require.NoError(t, err)
+			got, err := e.match(c)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpr_Errors(t *testing.T) {
+	for _, src := range []string{
+		`type ==`,
+		`type == "a`,
+		`(type == "a"`,
+		`type == "a" type`,
+		`action in ["a" "b"]`,
+		`type = "a"`,
+		`bogus == "a"`,
+	} {
+		_, err := Compile(src)
+		assert.Error(t, err, src)
+	}
+
+	// Type errors surface at evaluation time.
+	for _, src := range []string{`type`, `type in "abc"`, `actions matches "x"`, `!type`, `type && true`} {
+		e, err := Compile(src)
+		require.NoError(t, err, src)
+		_, err = e.match(change{})
+		assert.Error(t, err, src)
+	}
+}
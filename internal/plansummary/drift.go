@@ -0,0 +1,145 @@
+package plansummary
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// SensitiveValue replaces before/after values Terraform marks as sensitive,
+// matching Terraform's own rendering.
+const SensitiveValue = "(sensitive value)"
+
+// AttributeChange is one attribute that differs between the recorded state
+// and the real resource.
+type AttributeChange struct {
+	Path      string `json:"path"` // e.g. tags.env or subnet[0].name
+	Before    any    `json:"before"`
+	After     any    `json:"after"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+}
+
+type driftJSON struct {
+	FormatVersion string `json:"format_version"`
+	ResourceDrift []struct {
+		Address string `json:"address"`
+		Type    string `json:"type"`
+		Change  struct {
+			Actions         []string `json:"actions"`
+			Before          any      `json:"before"`
+			After           any      `json:"after"`
+			BeforeSensitive any      `json:"before_sensitive"`
+			AfterSensitive  any      `json:"after_sensitive"`
+		} `json:"change"`
+	} `json:"resource_drift"`
+}
+
+// ParseDrift builds a Summary of the out-of-band changes Terraform detected
+// while refreshing, from the resource_drift section of `terraform show -json`
+// output. It is meant for -refresh-only plans, whose resource_changes only
+// describe state updates. Each resource carries its attribute-level
+// differences with sensitive values masked.
+func ParseDrift(data []byte) (*Summary, error) {
+	var plan driftJSON
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("plansummary: parsing plan JSON: %w", err)
+	}
+	if plan.FormatVersion == "" {
+		return nil, fmt.Errorf("plansummary: not a terraform plan JSON document (missing format_version)")
+	}
+
+	s := &Summary{Resources: []ResourceChange{}}
+	for _, rd := range plan.ResourceDrift {
+		action := classify(rd.Change.Actions)
+		switch action {
+		case ActionUpdate:
+			s.Change++
+		case ActionDelete:
+			s.Destroy++
+		case ActionCreate:
+			s.Add++
+		case ActionReplace:
+			s.Add++
+			s.Destroy++
+			s.Replace++
+		default:
+			continue
+		}
+		change := ResourceChange{
+			Address: rd.Address,
+			Type:    rd.Type,
+			Action:  action,
+			Actions: rd.Change.Actions,
+		}
+		if action == ActionUpdate {
+			diffValues(nil, rd.Change.Before, rd.Change.After, rd.Change.BeforeSensitive, rd.Change.AfterSensitive, &change.Attributes)
+		}
+		s.Resources = append(s.Resources, change)
+	}
+	return s, nil
+}
+
+// diffValues appends the leaf differences between before and after to out.
+// beforeSens and afterSens mirror the value shapes with true at sensitive
+// positions; a sensitive subtree is reported as one masked change.
+func diffValues(path []any, before, after, beforeSens, afterSens any, out *[]AttributeChange) {
+	if isSensitive(beforeSens) || isSensitive(afterSens) {
+		if !reflect.DeepEqual(before, after) {
+			*out = append(*out, AttributeChange{Path: formatPath(path), Before: SensitiveValue, After: SensitiveValue, Sensitive: true})
+		}
+		return
+	}
+
+	bm, bIsMap := before.(map[string]any)
+	am, aIsMap := after.(map[string]any)
+	if bIsMap && aIsMap {
+		keys := make([]string, 0, len(bm)+len(am))
+		for k := range bm {
+			keys = append(keys, k)
+		}
+		for k := range am {
+			if _, ok := bm[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			diffValues(append(path, k), bm[k], am[k], sensitiveChild(beforeSens, k), sensitiveChild(afterSens, k), out)
+		}
+		return
+	}
+
+	bl, bIsList := before.([]any)
+	al, aIsList := after.([]any)
+	if bIsList && aIsList && len(bl) == len(al) {
+		for i := range bl {
+			diffValues(append(path, i), bl[i], al[i], sensitiveChild(beforeSens, i), sensitiveChild(afterSens, i), out)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		*out = append(*out, AttributeChange{Path: formatPath(path), Before: before, After: after})
+	}
+}
+
+func isSensitive(marker any) bool {
+	b, ok := marker.(bool)
+	return ok && b
+}
+
+// sensitiveChild returns the sensitivity marker for a map key or list index.
+func sensitiveChild(marker any, step any) any {
+	switch m := marker.(type) {
+	case map[string]any:
+		if k, ok := step.(string); ok {
+			return m[k]
+		}
+	case []any:
+		if i, ok := step.(int); ok && i < len(m) {
+			return m[i]
+		}
+	}
+	return nil
+}
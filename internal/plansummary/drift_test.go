@@ -0,0 +1,76 @@
+package plansummary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleDrift = `{
+  "format_version": "1.2",
+  "resource_drift": [
+    {"address": "azurerm_virtual_network.hub", "type": "azurerm_virtual_network",
+     "change": {"actions": ["update"],
+       "before": {"name": "vnet-hub", "tags": {"env": "prod"}, "address_space": ["10.0.0.0/16"], "secret": "old"},
+       "after":  {"name": "vnet-hub", "tags": {"env": "prod", "owner": "ops"}, "address_space": ["10.1.0.0/16"], "secret": "new"},
+       "before_sensitive": {"secret": true},
+       "after_sensitive":  {"secret": true}}},
+    {"address": "azurerm_public_ip.old", "type": "azurerm_public_ip",
+     "change": {"actions": ["delete"], "before": {"name": "pip-old"}, "after": null}},
+    {"address": "azurerm_resource_group.hub", "type": "azurerm_resource_group",
+     "change": {"actions": ["no-op"]}}
+  ],
+  "resource_changes": [
+    {"address": "azurerm_virtual_network.hub", "type": "azurerm_virtual_network",
+     "change": {"actions": ["update"]}}
+  ]
+}`
+
+func TestParseDrift_AttributeDiffs(t *testing.T) {
+	s, err := ParseDrift([]byte(sampleDrift))
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, s.Add)
+	assert.Equal(t, 1, s.Change)
+	assert.Equal(t, 1, s.Destroy)
+	require.Len(t, s.Resources, 2, "no-op drift is omitted")
+
+	vnet := s.Resources[0]
+	assert.Equal(t, "azurerm_virtual_network.hub", vnet.Address)
+	assert.Equal(t, ActionUpdate, vnet.Action)
+	assert.Equal(t, []AttributeChange{
+		{Path: "address_space[0]", Before: "10.0.0.0/16", After: "10.1.0.0/16"},
+		{Path: "secret", Before: SensitiveValue, After: SensitiveValue, Sensitive: true},
+		{Path: "tags.owner", Before: nil, After: "ops"},
+	}, vnet.Attributes)
+
+	assert.Equal(t, ActionDelete, s.Resources[1].Action)
+	assert.Empty(t, s.Resources[1].Attributes)
+}
+
+func TestParseDrift_NeverLeaksSensitiveValues(t *testing.T) {
+	doc := `{"format_version":"1.2","resource_drift":[{"address":"a.b","type":"a",
+	  "change":{"actions":["update"],
+	    "before":{"conn":{"password":"hunter2","host":"db"}},
+	    "after":{"conn":{"password":"changed","host":"db2"}},
+	    "before_sensitive":{"conn":true},"after_sensitive":{"conn":true}}}]}`
+	s, err := ParseDrift([]byte(doc))
+	require.NoError(t, err)
+	require.Len(t, s.Resources, 1)
+	assert.Equal(t, []AttributeChange{
+		{Path: "conn", Before: SensitiveValue, After: SensitiveValue, Sensitive: true},
+	}, s.Resources[0].Attributes)
+}
+
+func TestParseDrift_NoDrift(t *testing.T) {
+	s, err := ParseDrift([]byte(`{"format_version":"1.2"}`))
+	require.NoError(t, err)
+	assert.False(t, s.HasChanges())
+	assert.Empty(t, s.Resources)
+}
+
+func TestParseDrift_RejectsNonPlan(t *testing.T) {
+	_, err := ParseDrift([]byte(`{}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,90 @@
+package plansummary
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Root pairs a root name with its plan summary for rendering. A nil Summary
+// is rendered as "no plan data".
+type Root struct {
+	Name    string
+	Summary *Summary
+}
+
+var actionSymbols = map[string]string{
+	ActionCreate:  "`+` create",
+	ActionUpdate:  "`~` update",
+	ActionDelete:  "`-` delete",
+	ActionReplace: "`-/+` replace",
+	ActionRead:    "`<=` read",
+	ActionForget:  "`.` forget",
+}
+
+// Markdown renders a PR-comment friendly summary: a totals table with one
+// row per root, followed by a collapsible per-resource list for each root
+// that has changes. Destructive actions are called out explicitly.
+func Markdown(title string, roots []Root) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s\n\n", title)
+
+	totalAdd, totalChange, totalDestroy := 0, 0, 0
+	sb.WriteString("| Root | Add | Change | Destroy |\n")
+	sb.WriteString("|------|----:|-------:|--------:|\n")
+	for _, r := range roots {
+		if r.Summary == nil {
+			fmt.Fprintf(&sb, "| `%s` | – | – | – |\n", r.Name)
+			continue
+		}
+		s := r.Summary
+		totalAdd += s.Add
+		totalChange += s.Change
+		totalDestroy += s.Destroy
+		destroy := fmt.Sprintf("%d", s.Destroy)
+		if s.Destroy > 0 {
+			destroy = "⚠️ " + destroy
+		}
+		fmt.Fprintf(&sb, "| `%s` | %d | %d | %s |\n", r.Name, s.Add, s.Change, destroy)
+	}
+	fmt.Fprintf(&sb, "| **Total** | **%d** | **%d** | **%d** |\n\n", totalAdd, totalChange, totalDestroy)
+
+	if totalDestroy > 0 {
+		sb.WriteString("> ⚠️ **This plan destroys resources.** Review the deletions and replacements below before applying.\n\n")
+	}
+
+	for _, r := range roots {
+		if r.Summary == nil || len(r.Summary.Resources) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "<details><summary><code>%s</code> — %d resource change(s)</summary>\n\n", r.Name, len(r.Summary.Resources))
+		sb.WriteString("| Action | Address | Type | Replace reasons |\n")
+		sb.WriteString("|--------|---------|------|-----------------|\n")
+		for _, rc := range r.Summary.Resources {
+			symbol := actionSymbols[rc.Action]
+			if symbol == "" {
+				symbol = rc.Action
+			}
+			fmt.Fprintf(&sb, "| %s | `%s` | `%s` | %s |\n", symbol, rc.Address, rc.Type, replaceReasonCell(rc))
+		}
+		sb.WriteString("\n</details>\n\n")
+	}
+
+	if len(roots) == 0 {
+		sb.WriteString("_No roots were planned._\n")
+	}
+	return sb.String()
+}
+
+func replaceReasonCell(rc ResourceChange) string {
+	if rc.Action != ActionReplace {
+		return ""
+	}
+	parts := make([]string, 0, len(rc.ReplaceReasons)+1)
+	for _, p := range rc.ReplaceReasons {
+		parts = append(parts, "`"+p+"`")
+	}
+	if rc.ActionReason != "" {
+		parts = append(parts, strings.ReplaceAll(rc.ActionReason, "_", " "))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,172 @@
+// Package plansummary derives structured change summaries from Terraform's
+// machine-readable plan (`terraform show -json <planfile>`).
+//
+// Counts follow Terraform's own "Plan: X to add, Y to change, Z to destroy"
+// semantics: a replacement counts once as an add and once as a destroy, and
+// no-op and read actions are not counted. Unlike the human-readable summary
+// line, the JSON plan is stable across Terraform versions and output modes
+// and carries per-resource detail.
+package plansummary
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Actions reported on ResourceChange.Action.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionReplace = "replace"
+	ActionRead    = "read"
+	ActionForget  = "forget"
+)
+
+// ResourceChange is a single planned resource change.
+type ResourceChange struct {
+	Address        string   `json:"address"`
+	Type           string   `json:"type"`
+	Name           string   `json:"name,omitempty"`
+	Module         string   `json:"module,omitempty"`         // module address, empty for the root module
+	Action         string   `json:"action"`                   // create | update | delete | replace | read | forget
+	Actions        []string `json:"actions"`                  // raw Terraform actions, e.g. ["delete","create"]
+	ActionReason   string   `json:"actionReason,omitempty"`   // Terraform action_reason, e.g. replace_because_cannot_update
+	ReplaceReasons []string `json:"replaceReasons,omitempty"` // attribute paths forcing replacement
+
+	Attributes []AttributeChange `json:"attributes,omitempty"` // attribute-level differences, for drift
+}
+
+// Summary is the structured summary of one plan.
+type Summary struct {
+	Add       int              `json:"add"`
+	Change    int              `json:"change"`
+	Destroy   int              `json:"destroy"`
+	Replace   int              `json:"replace"`
+	Resources []ResourceChange `json:"resources"`
+}
+
+// Total returns the number of counted changes.
+func (s *Summary) Total() int {
+	return s.Add + s.Change + s.Destroy
+}
+
+// HasChanges reports whether the plan changes anything.
+func (s *Summary) HasChanges() bool {
+	return s.Total() > 0
+}
+
+type planJSON struct {
+	FormatVersion   string `json:"format_version"`
+	ResourceChanges []struct {
+		Address       string `json:"address"`
+		ModuleAddress string `json:"module_address"`
+		Type          string `json:"type"`
+		Name          string `json:"name"`
+		ActionReason  string `json:"action_reason"`
+		Change        struct {
+			Actions      []string `json:"actions"`
+			ReplacePaths [][]any  `json:"replace_paths"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+// Parse builds a Summary from `terraform show -json` output.
+func Parse(data []byte) (*Summary, error) {
+	var plan planJSON
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("plansummary: parsing plan JSON: %w", err)
+	}
+	if plan.FormatVersion == "" {
+		return nil, fmt.Errorf("plansummary: not a terraform plan JSON document (missing format_version)")
+	}
+
+	s := &Summary{Resources: []ResourceChange{}}
+	for _, rc := range plan.ResourceChanges {
+		action := classify(rc.Change.Actions)
+		if action == "" {
+			continue
+		}
+		switch action {
+		case ActionCreate:
+			s.Add++
+		case ActionUpdate:
+			s.Change++
+		case ActionDelete:
+			s.Destroy++
+		case ActionReplace:
+			s.Add++
+			s.Destroy++
+			s.Replace++
+		}
+		change := ResourceChange{
+			Address:      rc.Address,
+			Type:         rc.Type,
+			Name:         rc.Name,
+			Module:       rc.ModuleAddress,
+			Action:       action,
+			Actions:      rc.Change.Actions,
+			ActionReason: rc.ActionReason,
+		}
+		for _, p := range rc.Change.ReplacePaths {
+			change.ReplaceReasons = append(change.ReplaceReasons, formatPath(p))
+		}
+		s.Resources = append(s.Resources, change)
+	}
+	return s, nil
+}
+
+// Load reads and parses a plan JSON file.
+func Load(path string) (*Summary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("plansummary: reading %s: %w", path, err)
+	}
+	return Parse(data)
+}
+
+// classify collapses Terraform's action list into a single action. It
+// returns "" for no-op changes.
+func classify(actions []string) string {
+	hasCreate := slices.Contains(actions, "create")
+	hasDelete := slices.Contains(actions, "delete")
+	switch {
+	case hasCreate && hasDelete:
+		return ActionReplace
+	case hasCreate:
+		return ActionCreate
+	case hasDelete:
+		return ActionDelete
+	case slices.Contains(actions, "update"):
+		return ActionUpdate
+	case slices.Contains(actions, "read"):
+		return ActionRead
+	case slices.Contains(actions, "forget"):
+		return ActionForget
+	}
+	return ""
+}
+
+// formatPath renders a replace_paths entry such as ["tags","env"] or
+// ["subnet",0,"name"] as tags.env or subnet[0].name.
+func formatPath(steps []any) string {
+	var sb strings.Builder
+	for _, step := range steps {
+		switch v := step.(type) {
+		case string:
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(v)
+		case float64:
+			sb.WriteString("[" + strconv.Itoa(int(v)) + "]")
+		default:
+			sb.WriteString(fmt.Sprintf("[%v]", v))
+		}
+	}
+	return sb.String()
+}
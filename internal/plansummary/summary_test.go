@@ -0,0 +1,84 @@
+package plansummary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const samplePlan = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {"address": "azurerm_resource_group.hub", "type": "azurerm_resource_group",
+     "change": {"actions": ["create"]}},
+    {"address": "azurerm_virtual_network.hub", "type": "azurerm_virtual_network",
+     "change": {"actions": ["update"]}},
+    {"address": "module.fw.azurerm_firewall.this", "type": "azurerm_firewall",
+     "action_reason": "replace_because_cannot_update",
+     "change": {"actions": ["delete", "create"], "replace_paths": [["location"], ["ip_configuration", 0, "name"]]}},
+    {"address": "azurerm_public_ip.old", "type": "azurerm_public_ip",
+     "change": {"actions": ["delete"]}},
+    {"address": "azurerm_log_analytics_workspace.law", "type": "azurerm_log_analytics_workspace",
+     "change": {"actions": ["no-op"]}},
+    {"address": "data.azurerm_client_config.current", "type": "azurerm_client_config",
+     "change": {"actions": ["read"]}}
+  ]
+}`
+
+func TestParse_CountsLikeTerraform(t *testing.T) {
+	s, err := Parse([]byte(samplePlan))
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, s.Add)
+	assert.Equal(t, 1, s.Change)
+	assert.Equal(t, 2, s.Destroy)
+	assert.Equal(t, 1, s.Replace)
+	assert.Equal(t, 5, s.Total())
+	assert.True(t, s.HasChanges())
+
+	require.Len(t, s.Resources, 5, "no-op changes are omitted")
+	fw := s.Resources[2]
+	assert.Equal(t, ActionReplace, fw.Action)
+	assert.Equal(t, []string{"delete", "create"}, fw.Actions)
+	assert.Equal(t, "replace_because_cannot_update", fw.ActionReason)
+	assert.Equal(t, []string{"location", "ip_configuration[0].name"}, fw.ReplaceReasons)
+	assert.Equal(t, ActionRead, s.Resources[4].Action)
+}
+
+func TestParse_NoChanges(t *testing.T) {
+	s, err := Parse([]byte(`{"format_version":"1.2"}`))
+	require.NoError(t, err)
+	assert.False(t, s.HasChanges())
+	assert.NotNil(t, s.Resources)
+}
+
+func TestParse_RejectsNonPlanJSON(t *testing.T) {
+	_, err := Parse([]byte(`not json`))
+	require.Error(t, err)
+
+	_, err = Parse([]byte(`{}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "format_version")
+}
+
+func TestMarkdown(t *testing.T) {
+	s, err := Parse([]byte(samplePlan))
+	require.NoError(t, err)
+
+	md := Markdown("lzctl plan", []Root{
+		{Name: "connectivity", Summary: s},
+		{Name: "identity", Summary: &Summary{}},
+		{Name: "lz:app", Summary: nil},
+	})
+
+	assert.Contains(t, md, "## lzctl plan")
+	assert.Contains(t, md, "| `connectivity` | 2 | 1 | ⚠️ 2 |")
+	assert.Contains(t, md, "| `identity` | 0 | 0 | 0 |")
+	assert.Contains(t, md, "| `lz:app` | – | – | – |")
+	assert.Contains(t, md, "| **Total** | **2** | **1** | **2** |")
+	assert.Contains(t, md, "This plan destroys resources")
+	assert.Contains(t, md, "<code>connectivity</code> — 5 resource change(s)")
+	assert.Contains(t, md, "| `-/+` replace | `module.fw.azurerm_firewall.this` | `azurerm_firewall` | `location`, `ip_configuration[0].name`, replace because cannot update |")
+	assert.NotContains(t, md, "<code>identity</code>")
+}
@@ -5,11 +5,19 @@
 //   - A forged tfplan (protobuf-reconstructed) could target out-of-scope resources.
 //
 // Verification flow:
-//  1. After `terraform plan -out=tfplan`, call Sign(planFile, keyFile) to produce tfplan.sha256
-//  2. Before `terraform apply tfplan`, call Verify(planFile, sigFile) which:
-//     a. Recomputes SHA256 of tfplan
-//     b. Compares with stored value in tfplan.sha256
-//     c. (Optional) Parses tfplan JSON to validate subscription scope
+//  1. After `terraform plan -out=tfplan`, SignPlan writes tfplan.sig.json: an
+//     envelope with the plan's SHA256, layer, git commit and config hash,
+//     signed with an ed25519 private key or an HMAC-SHA256 secret.
+//  2. Before `terraform apply tfplan`, VerifyPlan checks the signature with
+//     the public key (or the HMAC secret), then recomputes the plan hash
+//     and compares the layer and config hash with the current ones.
+//  3. ValidateScope (or ValidateScopeFile on a saved tfplan.json) checks that
+//     every subscription the plan targets is declared in lzctl.yaml (see
+//     config.AllowedSubscriptions).
+//
+// Sign and Verify write and check a bare SHA256 (tfplan.sha256). Anyone who
+// can rewrite the plan can rewrite that file too, so they only detect
+// accidental corruption; use SignPlan and VerifyPlan to detect tampering.
 package planverify
 
 import (
@@ -20,10 +28,11 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"slices"
 	"strings"
+
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
 )
 
 // SigFile returns the path to the SHA256 signature file for the given plan file.
@@ -32,7 +41,7 @@ func SigFile(planFile string) string {
 }
 
 // Sign computes the SHA256 of planFile and writes it to planFile.sha256.
-// Call this immediately after `terraform plan -out=<planFile>`.
+// It detects corruption, not tampering; see SignPlan.
 func Sign(planFile string) (string, error) {
 	hash, err := sha256File(planFile)
 	if err != nil {
@@ -90,38 +99,48 @@ func Verify(planFile string) (*VerifyResult, error) {
 
 // ScopeViolation is returned when a plan targets resources outside the declared tenant scope.
 type ScopeViolation struct {
-	SubscriptionID string
-	ResourceAddr   string
+	SubscriptionID string `json:"subscriptionId"`
+	ResourceAddr   string `json:"address"`
 }
 
-// ValidateScope parses the tfplan JSON (via `terraform show -json`) and checks that
+// ValidateScope parses the tfplan JSON (via `show -json` on tf) and checks that
 // all planned subscription IDs belong to the declared set.
 //
-// allowedSubscriptions is the list of subscription IDs from tenant.Spec.Environments[*].Subscriptions.
-// planDir is the directory containing the tfplan file (terraform must be run from there).
-func ValidateScope(ctx context.Context, planFile string, allowedSubscriptions []string, planDir string) ([]ScopeViolation, error) {
+// allowedSubscriptions is the list of subscription IDs declared in lzctl.yaml
+// (config.AllowedSubscriptions): the state backend, platform and landing zone
+// subscriptions. planDir is the directory containing the tfplan file (tf must
+// be run from there).
+func ValidateScope(ctx context.Context, tf orchestrator.Runner, planFile string, allowedSubscriptions []string, planDir string) ([]ScopeViolation, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
-	// Build a set for O(1) lookup
-	allowed := make(map[string]bool, len(allowedSubscriptions))
-	for _, sub := range allowedSubscriptions {
-		allowed[strings.ToLower(sub)] = true
+	// Run `<tool> show -json <planFile>` to get the plan in JSON format
+	out, err := tf.Output(ctx, planDir, "show", "-json", filepath.Base(planFile))
+	if err != nil {
+		return nil, fmt.Errorf("planverify: running %s show -json: %w", tf.Binary(), err)
 	}
 
-	// Run `terraform show -json <planFile>` to get the plan in JSON format
-	cmd := exec.CommandContext(ctx, "terraform", "show", "-json", filepath.Base(planFile)) //nolint:gosec // input is a local file path, not user-controlled
-	cmd.Dir = planDir
-	cmd.Env = append(os.Environ(), "TF_INPUT=false")
+	return parsePlanScopeViolations([]byte(out), subscriptionSet(allowedSubscriptions))
+}
 
-	out, err := cmd.Output()
+// ValidateScopeFile is ValidateScope for a plan already rendered by
+// `show -json` (tfplan.json).
+func ValidateScopeFile(planJSONFile string, allowedSubscriptions []string) ([]ScopeViolation, error) {
+	data, err := os.ReadFile(planJSONFile)
 	if err != nil {
-		// Non-fatal for scope validation — log a warning but do not block
-		return nil, fmt.Errorf("planverify: running terraform show -json: %w (scope validation skipped)", err)
+		return nil, fmt.Errorf("planverify: reading plan JSON %s: %w", planJSONFile, err)
 	}
+	return parsePlanScopeViolations(data, subscriptionSet(allowedSubscriptions))
+}
 
-	return parsePlanScopeViolations(out, allowed)
+// subscriptionSet builds a lowercase lookup set of subscription IDs.
+func subscriptionSet(ids []string) map[string]bool {
+	allowed := make(map[string]bool, len(ids))
+	for _, sub := range ids {
+		allowed[strings.ToLower(sub)] = true
+	}
+	return allowed
 }
 
 // planJSON is a minimal representation of the terraform plan JSON output.
@@ -172,18 +191,24 @@ func parsePlanScopeViolations(planJSONBytes []byte, allowed map[string]bool) ([]
 		}
 	}
 
-	// Check resource-level subscription_id values (some resources embed it)
+	// Check resource-level subscription references: subscription_id values
+	// (some resources embed it) and the scope or parent resource IDs of role
+	// assignments, policy assignments and azapi resources.
 	// Recursively collect resources from root module and all child modules
 	allResources := collectResources(plan.PlannedValues.RootModule)
 	for _, res := range allResources {
-		if subIDVal, ok := res.Values["subscription_id"]; ok {
-			if subID, ok := subIDVal.(string); ok && subID != "" {
-				if !allowed[strings.ToLower(subID)] {
-					violations = append(violations, ScopeViolation{
-						SubscriptionID: subID,
-						ResourceAddr:   res.Address,
-					})
-				}
+		for _, attr := range scopeAttributes {
+			val, ok := res.Values[attr].(string)
+			if !ok {
+				continue
+			}
+			subID := subscriptionOf(val, attr == "subscription_id")
+			if subID != "" && !allowed[strings.ToLower(subID)] {
+				violations = append(violations, ScopeViolation{
+					SubscriptionID: subID,
+					ResourceAddr:   res.Address,
+				})
+				break
 			}
 		}
 	}
@@ -191,6 +216,30 @@ func parsePlanScopeViolations(planJSONBytes []byte, allowed map[string]bool) ([]
 	return violations, nil
 }
 
+// scopeAttributes are the resource attributes naming the subscription a
+// resource is deployed into or acts on.
+var scopeAttributes = []string{"subscription_id", "scope", "parent_id"}
+
+// subscriptionOf extracts the subscription ID from a value that is either a
+// bare ID (when bare is true) or an Azure resource ID such as
+// "/subscriptions/<id>/resourceGroups/rg". Values without a subscription
+// segment, like management group IDs, yield "".
+func subscriptionOf(value string, bare bool) string {
+	const segment = "/subscriptions/"
+	i := strings.Index(strings.ToLower(value), segment)
+	if i < 0 {
+		if bare {
+			return value
+		}
+		return ""
+	}
+	rest := value[i+len(segment):]
+	if j := strings.IndexByte(rest, '/'); j >= 0 {
+		rest = rest[:j]
+	}
+	return rest
+}
+
 // collectResources recursively gathers resources from a module and all child modules.
 func collectResources(mod planModule) []planResource {
 	resources := append([]planResource{}, mod.Resources...)
@@ -224,6 +273,11 @@ func ValidateActions(planJSONFile string) ([]ActionViolation, error) {
 	if err != nil {
 		return nil, fmt.Errorf("planverify: reading plan JSON %s: %w", planJSONFile, err)
 	}
+	return ValidateActionsJSON(data)
+}
+
+// ValidateActionsJSON is ValidateActions for plan JSON already in memory.
+func ValidateActionsJSON(data []byte) ([]ActionViolation, error) {
 	var plan struct {
 		ResourceChanges []struct {
 			Address string `json:"address"`
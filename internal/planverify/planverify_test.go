@@ -1,12 +1,17 @@
 package planverify
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
 )
 
 // makePlanActionsJSON builds a minimal terraform plan JSON for action-based testing.
@@ -388,3 +393,75 @@ func makePlanJSON(providerSubs []string, resourceSubs map[string]string) []byte
 	data, _ := json.Marshal(plan)
 	return data
 }
+
+func TestValidateScope_UsesRunner(t *testing.T) {
+	plan := makePlanJSON(nil, map[string]string{
+		"azurerm_resource_group.rg": "rogue-sub-id",
+	})
+	tf := runnertest.New().WithTool(orchestrator.ToolOpenTofu).Reply("show", string(plan), 0)
+	dir := t.TempDir()
+
+	violations, err := ValidateScope(context.Background(), tf, filepath.Join(dir, "tfplan"), []string{"SUB-A"}, dir)
+	if err != nil {
+		t.Fatalf("ValidateScope() error: %v", err)
+	}
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %d", len(violations))
+	}
+	calls := tf.Calls("show")
+	if len(calls) != 1 || calls[0].Dir != dir || calls[0].Args[len(calls[0].Args)-1] != "tfplan" {
+		t.Errorf("unexpected show calls: %+v", calls)
+	}
+}
+
+func TestValidateScope_ShowFailure(t *testing.T) {
+	tf := runnertest.New().Reply("show", "Error: no plan", 1)
+
+	_, err := ValidateScope(context.Background(), tf, "tfplan", []string{"sub-a"}, t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "show -json") {
+		t.Fatalf("expected show -json error, got %v", err)
+	}
+}
+
+func TestParsePlanScopeViolations_ResourceIDs(t *testing.T) {
+	plan := []byte(`{"planned_values":{"root_module":{"resources":[
+	  {"address":"azurerm_subscription_policy_assignment.ok","values":{"subscription_id":"/subscriptions/SUB-A"}},
+	  {"address":"azurerm_role_assignment.ok","values":{"scope":"/subscriptions/sub-a/resourceGroups/rg"}},
+	  {"address":"azurerm_management_group_policy_assignment.mg","values":{"management_group_id":"/providers/Microsoft.Management/managementGroups/corp"}},
+	  {"address":"azurerm_role_assignment.rogue","values":{"scope":"/subscriptions/rogue-sub/resourceGroups/rg"}},
+	  {"address":"azapi_resource.rogue","values":{"parent_id":"/subscriptions/rogue-sub"}}
+	]}}}`)
+
+	violations, err := parsePlanScopeViolations(plan, map[string]bool{"sub-a": true})
+	if err != nil {
+		t.Fatalf("parsePlanScopeViolations() error: %v", err)
+	}
+	if len(violations) != 2 {
+		t.Fatalf("expected 2 violations, got %+v", violations)
+	}
+	for _, v := range violations {
+		if v.SubscriptionID != "rogue-sub" {
+			t.Errorf("violation %s sub = %s, want rogue-sub", v.ResourceAddr, v.SubscriptionID)
+		}
+	}
+}
+
+func TestValidateScopeFile(t *testing.T) {
+	planJSON := filepath.Join(t.TempDir(), "tfplan.json")
+	plan := makePlanJSON([]string{"sub-a"}, map[string]string{"azurerm_resource_group.rg": "rogue-sub-id"})
+	if err := os.WriteFile(planJSON, plan, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	violations, err := ValidateScopeFile(planJSON, []string{"SUB-A"})
+	if err != nil {
+		t.Fatalf("ValidateScopeFile() error: %v", err)
+	}
+	if len(violations) != 1 || violations[0].ResourceAddr != "azurerm_resource_group.rg" {
+		t.Errorf("unexpected violations: %+v", violations)
+	}
+
+	if _, err := ValidateScopeFile(filepath.Join(t.TempDir(), "missing.json"), nil); err == nil {
+		t.Error("ValidateScopeFile() should error when the file is missing")
+	}
+}
@@ -0,0 +1,334 @@
+package planverify
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Signature algorithms.
+const (
+	AlgEd25519    = "ed25519"
+	AlgHMACSHA256 = "hmac-sha256"
+)
+
+// Environment variables holding signing and verification keys, either
+// inline or as a path to a key file.
+const (
+	EnvSigningKey     = "LZCTL_PLAN_SIGNING_KEY"
+	EnvSigningKeyFile = "LZCTL_PLAN_SIGNING_KEY_FILE"
+	EnvVerifyKey      = "LZCTL_PLAN_VERIFY_KEY"
+	EnvVerifyKeyFile  = "LZCTL_PLAN_VERIFY_KEY_FILE"
+)
+
+// envelopeVersion is the format version of Envelope.
+const envelopeVersion = 1
+
+// minHMACKeyLen is the minimum HMAC secret length in bytes.
+const minHMACKeyLen = 32
+
+// ErrUnsigned is returned by VerifyPlan when the plan has no envelope.
+var ErrUnsigned = errors.New("plan is not signed")
+
+// Envelope binds a plan file to the context it was produced in. It is
+// stored as JSON next to the plan (see EnvelopeFile).
+type Envelope struct {
+	Version    int       `json:"version"`
+	Algorithm  string    `json:"algorithm"`
+	KeyID      string    `json:"keyId"`
+	Layer      string    `json:"layer"`
+	PlanSHA256 string    `json:"planSha256"`
+	JSONSHA256 string    `json:"jsonSha256,omitempty"` // hash of tfplan.json, when it was rendered
+	GitCommit  string    `json:"gitCommit,omitempty"`
+	ConfigHash string    `json:"configHash,omitempty"` // hash of the lzctl.yaml slice feeding the layer
+	InputHash  string    `json:"inputHash,omitempty"`  // hash of the layer's Terraform inputs
+	SignedAt   time.Time `json:"signedAt"`
+	Signature  string    `json:"signature"` // base64, over the envelope with an empty signature
+}
+
+// Binding is the context a plan is signed for and checked against.
+type Binding struct {
+	Layer      string
+	GitCommit  string
+	ConfigHash string
+	InputHash  string
+}
+
+// EnvelopeFile returns the path of the signature envelope for planFile.
+func EnvelopeFile(planFile string) string {
+	return planFile + ".sig.json"
+}
+
+// JSONFile returns the path of the `show -json` rendering of planFile
+// (tfplan.json), which the envelope covers too: scope, plan rules and the
+// destructive-action gate read it instead of the binary plan.
+func JSONFile(planFile string) string {
+	return planFile + ".json"
+}
+
+// Key is a signing or verification key. An ed25519 key holding only the
+// public half can verify but not sign; an HMAC key does both.
+type Key struct {
+	Algorithm string
+	ID        string
+
+	private ed25519.PrivateKey
+	public  ed25519.PublicKey
+	secret  []byte
+}
+
+// CanSign reports whether k holds private key material.
+func (k *Key) CanSign() bool {
+	return k.private != nil || k.secret != nil
+}
+
+// ParseKey reads an ed25519 key in PEM form (PKCS#8 "PRIVATE KEY" or PKIX
+// "PUBLIC KEY", as written by `openssl genpkey -algorithm ed25519`) or an
+// HMAC-SHA256 secret of at least 32 bytes. A secret prefixed with
+// "base64:" is decoded first.
+func ParseKey(data []byte) (*Key, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		switch block.Type {
+		case "PRIVATE KEY":
+			k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("planverify: parsing private key: %w", err)
+			}
+			priv, ok := k.(ed25519.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("planverify: private key is %T, want ed25519", k)
+			}
+			pub := priv.Public().(ed25519.PublicKey)
+			return &Key{Algorithm: AlgEd25519, ID: keyID(pub), private: priv, public: pub}, nil
+		case "PUBLIC KEY":
+			k, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("planverify: parsing public key: %w", err)
+			}
+			pub, ok := k.(ed25519.PublicKey)
+			if !ok {
+				return nil, fmt.Errorf("planverify: public key is %T, want ed25519", k)
+			}
+			return &Key{Algorithm: AlgEd25519, ID: keyID(pub), public: pub}, nil
+		default:
+			return nil, fmt.Errorf("planverify: unsupported PEM block %q", block.Type)
+		}
+	}
+
+	secret := []byte(strings.TrimSpace(string(data)))
+	if enc, ok := strings.CutPrefix(string(secret), "base64:"); ok {
+		decoded, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil {
+			return nil, fmt.Errorf("planverify: decoding base64 HMAC key: %w", err)
+		}
+		secret = decoded
+	}
+	if len(secret) < minHMACKeyLen {
+		return nil, fmt.Errorf("planverify: HMAC key is %d bytes, need at least %d", len(secret), minHMACKeyLen)
+	}
+	return &Key{Algorithm: AlgHMACSHA256, ID: keyID(secret), secret: secret}, nil
+}
+
+// keyID is a short, non-secret identifier of a key: the first 8 bytes of
+// the SHA256 of the public key or HMAC secret.
+func keyID(material []byte) string {
+	sum := sha256.Sum256(material)
+	return hex.EncodeToString(sum[:8])
+}
+
+// loadKey reads a key from the inline env var, or else from the file named
+// by the file env var. It returns (nil, nil) when neither is set.
+func loadKey(inlineEnv, fileEnv string) (*Key, error) {
+	if v := os.Getenv(inlineEnv); strings.TrimSpace(v) != "" {
+		k, err := ParseKey([]byte(v))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", inlineEnv, err)
+		}
+		return k, nil
+	}
+	path := strings.TrimSpace(os.Getenv(fileEnv))
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileEnv, err)
+	}
+	k, err := ParseKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileEnv, err)
+	}
+	return k, nil
+}
+
+// LoadSigningKey returns the key configured in LZCTL_PLAN_SIGNING_KEY or
+// LZCTL_PLAN_SIGNING_KEY_FILE, or nil when plan signing is not configured.
+func LoadSigningKey() (*Key, error) {
+	k, err := loadKey(EnvSigningKey, EnvSigningKeyFile)
+	if err != nil || k == nil {
+		return k, err
+	}
+	if !k.CanSign() {
+		return nil, fmt.Errorf("planverify: %s holds a public key; signing needs the private key", EnvSigningKey)
+	}
+	return k, nil
+}
+
+// LoadVerifyKey returns the key configured in LZCTL_PLAN_VERIFY_KEY or
+// LZCTL_PLAN_VERIFY_KEY_FILE, falling back to the signing key. It returns
+// nil when neither is configured.
+func LoadVerifyKey() (*Key, error) {
+	k, err := loadKey(EnvVerifyKey, EnvVerifyKeyFile)
+	if err != nil || k != nil {
+		return k, err
+	}
+	return loadKey(EnvSigningKey, EnvSigningKeyFile)
+}
+
+// SignPlan signs planFile for b and writes the envelope to
+// EnvelopeFile(planFile).
+func SignPlan(planFile string, key *Key, b Binding) (*Envelope, error) {
+	if !key.CanSign() {
+		return nil, fmt.Errorf("planverify: key %s cannot sign", key.ID)
+	}
+	hash, err := sha256File(planFile)
+	if err != nil {
+		return nil, fmt.Errorf("planverify: computing SHA256 for %s: %w", planFile, err)
+	}
+	jsonHash, err := sha256File(JSONFile(planFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("planverify: computing SHA256 for %s: %w", JSONFile(planFile), err)
+	}
+	env := &Envelope{
+		Version:    envelopeVersion,
+		Algorithm:  key.Algorithm,
+		KeyID:      key.ID,
+		Layer:      b.Layer,
+		PlanSHA256: hash,
+		JSONSHA256: jsonHash,
+		GitCommit:  b.GitCommit,
+		ConfigHash: b.ConfigHash,
+		InputHash:  b.InputHash,
+		SignedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	payload, err := env.payload()
+	if err != nil {
+		return nil, err
+	}
+	switch key.Algorithm {
+	case AlgEd25519:
+		env.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key.private, payload))
+	case AlgHMACSHA256:
+		env.Signature = base64.StdEncoding.EncodeToString(hmacSum(key.secret, payload))
+	}
+
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("planverify: encoding envelope: %w", err)
+	}
+	if err := os.WriteFile(EnvelopeFile(planFile), append(data, '\n'), 0o600); err != nil {
+		return nil, fmt.Errorf("planverify: writing %s: %w", EnvelopeFile(planFile), err)
+	}
+	return env, nil
+}
+
+// VerifyPlan checks the envelope of planFile: its signature under key, the
+// hashes of the plan and of its JSON rendering, and every non-empty field of
+// want except GitCommit, which is informational (CI may apply from a merge
+// commit). A JSON rendering the envelope does not cover is rejected. It
+// returns ErrUnsigned when there is no envelope.
+func VerifyPlan(planFile string, key *Key, want Binding) (*Envelope, error) {
+	data, err := os.ReadFile(EnvelopeFile(planFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrUnsigned
+	}
+	if err != nil {
+		return nil, fmt.Errorf("planverify: reading envelope: %w", err)
+	}
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("planverify: parsing envelope: %w", err)
+	}
+	if env.Version != envelopeVersion {
+		return &env, fmt.Errorf("planverify: unsupported envelope version %d", env.Version)
+	}
+	if env.Algorithm != key.Algorithm || env.KeyID != key.ID {
+		return &env, fmt.Errorf("planverify: plan signed with %s key %s, verifying with %s key %s",
+			env.Algorithm, env.KeyID, key.Algorithm, key.ID)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(env.Signature)
+	if err != nil {
+		return &env, fmt.Errorf("planverify: decoding signature: %w", err)
+	}
+	payload, err := env.payload()
+	if err != nil {
+		return &env, err
+	}
+	valid := false
+	switch key.Algorithm {
+	case AlgEd25519:
+		valid = ed25519.Verify(key.public, payload, sig)
+	case AlgHMACSHA256:
+		valid = hmac.Equal(sig, hmacSum(key.secret, payload))
+	}
+	if !valid {
+		return &env, fmt.Errorf("planverify: invalid signature on %s", EnvelopeFile(planFile))
+	}
+
+	actual, err := sha256File(planFile)
+	if err != nil {
+		return &env, fmt.Errorf("planverify: computing SHA256 for %s: %w", planFile, err)
+	}
+	if actual != env.PlanSHA256 {
+		return &env, fmt.Errorf("planverify: tfplan SHA256 mismatch (signed %s, got %s)", env.PlanSHA256, actual)
+	}
+	actualJSON, err := sha256File(JSONFile(planFile))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return &env, fmt.Errorf("planverify: computing SHA256 for %s: %w", JSONFile(planFile), err)
+	case env.JSONSHA256 == "":
+		return &env, fmt.Errorf("planverify: %s is not covered by the signature", filepath.Base(JSONFile(planFile)))
+	case actualJSON != env.JSONSHA256:
+		return &env, fmt.Errorf("planverify: tfplan.json SHA256 mismatch (signed %s, got %s)", env.JSONSHA256, actualJSON)
+	}
+	for _, f := range []struct{ name, signed, want string }{
+		{"layer", env.Layer, want.Layer},
+		{"config hash", env.ConfigHash, want.ConfigHash},
+		{"input hash", env.InputHash, want.InputHash},
+	} {
+		if f.want != "" && f.signed != f.want {
+			return &env, fmt.Errorf("planverify: plan was signed for %s %q, current %s is %q", f.name, f.signed, f.name, f.want)
+		}
+	}
+	return &env, nil
+}
+
+// payload is the canonical signed form: the envelope's JSON encoding with
+// an empty signature.
+func (e Envelope) payload() ([]byte, error) {
+	e.Signature = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("planverify: encoding envelope: %w", err)
+	}
+	return data, nil
+}
+
+func hmacSum(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
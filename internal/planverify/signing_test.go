@@ -0,0 +1,226 @@
+package planverify
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ed25519PEM returns a fresh ed25519 key pair as PKCS#8 and PKIX PEM.
+func ed25519PEM(t *testing.T) (privPEM, pubPEM []byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+}
+
+func writePlan(t *testing.T) string {
+	t.Helper()
+	planFile := filepath.Join(t.TempDir(), "tfplan")
+	require.NoError(t, os.WriteFile(planFile, []byte("binary plan content"), 0o600))
+	return planFile
+}
+
+var testBinding = Binding{Layer: "connectivity", GitCommit: "abc123", ConfigHash: "sha256:cfg", InputHash: "sha256:in"}
+
+func TestSignPlan_Ed25519RoundTrip(t *testing.T) {
+	privPEM, pubPEM := ed25519PEM(t)
+	signer, err := ParseKey(privPEM)
+	require.NoError(t, err)
+	verifier, err := ParseKey(pubPEM)
+	require.NoError(t, err)
+	assert.Equal(t, signer.ID, verifier.ID)
+	assert.False(t, verifier.CanSign())
+
+	planFile := writePlan(t)
+	env, err := SignPlan(planFile, signer, testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, AlgEd25519, env.Algorithm)
+	assert.Equal(t, "abc123", env.GitCommit)
+
+	got, err := VerifyPlan(planFile, verifier, Binding{Layer: "connectivity", ConfigHash: "sha256:cfg", InputHash: "sha256:in", GitCommit: "other"})
+	require.NoError(t, err, "git commit is informational")
+	assert.Equal(t, env.PlanSHA256, got.PlanSHA256)
+
+	_, err = SignPlan(planFile, verifier, testBinding)
+	assert.Error(t, err, "public keys cannot sign")
+}
+
+func TestSignPlan_HMACRoundTrip(t *testing.T) {
+	key, err := ParseKey([]byte("base64:" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))))
+	require.NoError(t, err)
+	assert.Equal(t, AlgHMACSHA256, key.Algorithm)
+
+	planFile := writePlan(t)
+	_, err = SignPlan(planFile, key, testBinding)
+	require.NoError(t, err)
+	_, err = VerifyPlan(planFile, key, Binding{Layer: "connectivity"})
+	require.NoError(t, err)
+}
+
+func TestVerifyPlan_DetectsTampering(t *testing.T) {
+	privPEM, _ := ed25519PEM(t)
+	key, err := ParseKey(privPEM)
+	require.NoError(t, err)
+
+	t.Run("plan modified", func(t *testing.T) {
+		planFile := writePlan(t)
+		_, err := SignPlan(planFile, key, testBinding)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(planFile, []byte("forged plan"), 0o600))
+		_, err = VerifyPlan(planFile, key, Binding{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "SHA256 mismatch")
+	})
+
+	t.Run("envelope rewritten", func(t *testing.T) {
+		planFile := writePlan(t)
+		_, err := SignPlan(planFile, key, testBinding)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(planFile, []byte("forged plan"), 0o600))
+
+		// An attacker recomputes the hash but cannot re-sign.
+		data, err := os.ReadFile(EnvelopeFile(planFile))
+		require.NoError(t, err)
+		var env Envelope
+		require.NoError(t, json.Unmarshal(data, &env))
+		env.PlanSHA256, err = sha256File(planFile)
+		require.NoError(t, err)
+		data, err = json.Marshal(env)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(EnvelopeFile(planFile), data, 0o600))
+
+		_, err = VerifyPlan(planFile, key, Binding{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid signature")
+	})
+
+	t.Run("plan JSON modified", func(t *testing.T) {
+		planFile := writePlan(t)
+		require.NoError(t, os.WriteFile(JSONFile(planFile), []byte(`{"resource_changes":[]}`), 0o600))
+		env, err := SignPlan(planFile, key, testBinding)
+		require.NoError(t, err)
+		assert.NotEmpty(t, env.JSONSHA256)
+		_, err = VerifyPlan(planFile, key, Binding{})
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(JSONFile(planFile), []byte(`{"resource_changes":[],"x":1}`), 0o600))
+		_, err = VerifyPlan(planFile, key, Binding{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tfplan.json SHA256 mismatch")
+
+		require.NoError(t, os.Remove(JSONFile(planFile)))
+		_, err = VerifyPlan(planFile, key, Binding{})
+		assert.NoError(t, err, "without tfplan.json, checks run on the binary plan")
+	})
+
+	t.Run("plan JSON added after signing", func(t *testing.T) {
+		planFile := writePlan(t)
+		_, err := SignPlan(planFile, key, testBinding)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(JSONFile(planFile), []byte(`{"resource_changes":[]}`), 0o600))
+		_, err = VerifyPlan(planFile, key, Binding{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not covered by the signature")
+	})
+
+	t.Run("wrong layer", func(t *testing.T) {
+		planFile := writePlan(t)
+		_, err := SignPlan(planFile, key, testBinding)
+		require.NoError(t, err)
+		_, err = VerifyPlan(planFile, key, Binding{Layer: "identity"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `signed for layer "connectivity"`)
+	})
+
+	t.Run("config changed", func(t *testing.T) {
+		planFile := writePlan(t)
+		_, err := SignPlan(planFile, key, testBinding)
+		require.NoError(t, err)
+		_, err = VerifyPlan(planFile, key, Binding{ConfigHash: "sha256:new"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "config hash")
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		otherPEM, _ := ed25519PEM(t)
+		other, err := ParseKey(otherPEM)
+		require.NoError(t, err)
+		planFile := writePlan(t)
+		_, err = SignPlan(planFile, key, testBinding)
+		require.NoError(t, err)
+		_, err = VerifyPlan(planFile, other, Binding{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "verifying with ed25519 key "+other.ID)
+	})
+}
+
+func TestVerifyPlan_Unsigned(t *testing.T) {
+	privPEM, _ := ed25519PEM(t)
+	key, err := ParseKey(privPEM)
+	require.NoError(t, err)
+	_, err = VerifyPlan(writePlan(t), key, Binding{})
+	assert.ErrorIs(t, err, ErrUnsigned)
+}
+
+func TestParseKey_RejectsShortHMACSecret(t *testing.T) {
+	_, err := ParseKey([]byte("too-short"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at least 32")
+}
+
+func TestLoadKeys_FromEnvironment(t *testing.T) {
+	privPEM, pubPEM := ed25519PEM(t)
+	pubFile := filepath.Join(t.TempDir(), "plan-signing.pub")
+	require.NoError(t, os.WriteFile(pubFile, pubPEM, 0o600))
+
+	t.Setenv(EnvSigningKey, string(privPEM))
+	t.Setenv(EnvSigningKeyFile, "")
+	t.Setenv(EnvVerifyKey, "")
+	t.Setenv(EnvVerifyKeyFile, "")
+
+	signer, err := LoadSigningKey()
+	require.NoError(t, err)
+	require.NotNil(t, signer)
+
+	verifier, err := LoadVerifyKey()
+	require.NoError(t, err)
+	assert.True(t, verifier.CanSign(), "falls back to the signing key")
+
+	t.Setenv(EnvVerifyKeyFile, pubFile)
+	verifier, err = LoadVerifyKey()
+	require.NoError(t, err)
+	assert.False(t, verifier.CanSign())
+	assert.Equal(t, signer.ID, verifier.ID)
+
+	t.Setenv(EnvSigningKey, string(pubPEM))
+	_, err = LoadSigningKey()
+	assert.Error(t, err, "a public key cannot sign")
+}
+
+func TestLoadKeys_NotConfigured(t *testing.T) {
+	for _, env := range []string{EnvSigningKey, EnvSigningKeyFile, EnvVerifyKey, EnvVerifyKeyFile} {
+		t.Setenv(env, "")
+	}
+	k, err := LoadSigningKey()
+	require.NoError(t, err)
+	assert.Nil(t, k)
+	k, err = LoadVerifyKey()
+	require.NoError(t, err)
+	assert.Nil(t, k)
+}
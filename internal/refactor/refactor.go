@@ -0,0 +1,370 @@
+// Package refactor renames resources and modules of a Terraform root without
+// destroying them.
+//
+// A rename rewrites the block declaration and every reference to it in the
+// root's *.tf files, then records a `moved {}` block in moved.tf so that
+// Terraform moves the existing state entry to the new address instead of
+// planning a destroy and a create.
+package refactor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MovedFile is the file of a root that holds the moved blocks written by
+// lzctl.
+const MovedFile = "moved.tf"
+
+// movedHeader starts a moved.tf created by lzctl.
+const movedHeader = `# Managed by lzctl refactor. Each moved block tells Terraform that an object
+# was renamed, so that it is moved in state instead of destroyed and recreated.
+# Keep these blocks until every state using this root has been applied.
+`
+
+// Address is a resource or module address as used in moved blocks, e.g.
+// azurerm_resource_group.zone, module.vnet or module.hub.azurerm_subnet.app["a"].
+type Address struct {
+	Module string // module path of the object, e.g. "module.hub"; empty at the root
+	Type   string // resource type; empty for a module call
+	Name   string
+	Key    string // instance key including brackets, e.g. `[0]`; empty when absent
+}
+
+var (
+	identPattern   = `[A-Za-z_][A-Za-z0-9_-]*`
+	keyPattern     = `(?:\[(?:[0-9]+|"[^"]*")\])`
+	addressSegment = regexp.MustCompile(`^(` + identPattern + `)\.(` + identPattern + `)(` + keyPattern + `?)`)
+)
+
+// ParseAddress parses a resource or module address. Data sources cannot be
+// moved and are rejected.
+func ParseAddress(s string) (Address, error) {
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return Address{}, fmt.Errorf("empty address")
+	}
+	var modules []string
+	for {
+		m := addressSegment.FindStringSubmatch(rest)
+		if m == nil {
+			return Address{}, fmt.Errorf("invalid address %q", s)
+		}
+		rest = rest[len(m[0]):]
+		if rest == "" {
+			a := Address{Module: strings.Join(modules, "."), Name: m[2], Key: m[3]}
+			switch m[1] {
+			case "module":
+			case "data":
+				return Address{}, fmt.Errorf("invalid address %q: data sources cannot be moved", s)
+			default:
+				a.Type = m[1]
+			}
+			return a, nil
+		}
+		if m[1] != "module" || rest[0] != '.' {
+			return Address{}, fmt.Errorf("invalid address %q", s)
+		}
+		modules = append(modules, m[0])
+		rest = rest[1:]
+	}
+}
+
+// String returns the address in Terraform syntax.
+func (a Address) String() string {
+	kind := "module"
+	if a.Type != "" {
+		kind = a.Type
+	}
+	s := kind + "." + a.Name + a.Key
+	if a.Module != "" {
+		s = a.Module + "." + s
+	}
+	return s
+}
+
+// IsModule reports whether a is a module call.
+func (a Address) IsModule() bool {
+	return a.Type == ""
+}
+
+// declared reports whether a names a block declared in the root itself: no
+// module path and no instance key.
+func (a Address) declared() bool {
+	return a.Module == "" && a.Key == ""
+}
+
+// Move renames the object at From to To.
+type Move struct {
+	From Address
+	To   Address
+}
+
+// NewMove parses from and to and checks that Terraform can move one to the
+// other: both are resources of the same type, or both are module calls.
+func NewMove(from, to string) (Move, error) {
+	f, err := ParseAddress(from)
+	if err != nil {
+		return Move{}, err
+	}
+	t, err := ParseAddress(to)
+	if err != nil {
+		return Move{}, err
+	}
+	if f == t {
+		return Move{}, fmt.Errorf("%s: source and destination are the same address", from)
+	}
+	if f.IsModule() != t.IsModule() {
+		return Move{}, fmt.Errorf("cannot move %s to %s: a module call can only move to another module call", f, t)
+	}
+	if f.Type != t.Type {
+		return Move{}, fmt.Errorf("cannot move %s to %s: resource types differ", f, t)
+	}
+	return Move{From: f, To: t}, nil
+}
+
+// Block returns the moved block of m.
+func (m Move) Block() string {
+	return fmt.Sprintf("moved {\n  from = %s\n  to   = %s\n}\n", m.From, m.To)
+}
+
+// Result describes what Apply changed in a root.
+type Result struct {
+	Move      string   `json:"move"`            // "from -> to"
+	Renamed   bool     `json:"renamed"`         // the block declaration was renamed
+	Files     []string `json:"files,omitempty"` // *.tf files rewritten, relative to the root
+	MovedFile string   `json:"movedFile"`       // file holding the moved block
+	Recorded  bool     `json:"alreadyRecorded"` // the moved block was already present
+	Skipped   string   `json:"skip,omitempty"`  // reason no block was renamed
+}
+
+// Apply renames the object of each move in the root at dir and records a
+// moved block for it in moved.tf. When write is false, nothing is written
+// and the results describe what would change.
+//
+// The declaration is renamed only for objects declared in the root itself
+// (no module path, no instance key). When the declaration already carries
+// the new name, only the moved block is written.
+func Apply(dir string, moves []Move, write bool) ([]Result, error) {
+	files, err := loadFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	existing := existingMoves(files[MovedFile])
+
+	results := make([]Result, 0, len(moves))
+	var added []string
+	for _, m := range moves {
+		r := Result{Move: m.From.String() + " -> " + m.To.String(), MovedFile: MovedFile}
+		if m.From.declared() && m.To.declared() {
+			from, to := declarationRegex(m.From), declarationRegex(m.To)
+			switch {
+			case anyMatch(files, from):
+				if anyMatch(files, to) {
+					return nil, fmt.Errorf("cannot move %s to %s in %s: both are declared", m.From, m.To, dir)
+				}
+				r.Renamed = true
+				r.Files = renameBlock(files, m)
+			case anyMatch(files, to):
+				r.Skipped = m.To.String() + " is already declared"
+			default:
+				return nil, fmt.Errorf("neither %s nor %s is declared in %s", m.From, m.To, dir)
+			}
+		} else {
+			r.Skipped = "only the moved block is written for addresses inside modules or with instance keys"
+		}
+		if existing[m.From.String()+" -> "+m.To.String()] {
+			r.Recorded = true
+		} else {
+			added = append(added, m.Block())
+			existing[m.From.String()+" -> "+m.To.String()] = true
+		}
+		results = append(results, r)
+	}
+
+	if len(added) > 0 {
+		content, ok := files[MovedFile]
+		if !ok {
+			content = movedHeader
+		}
+		for _, b := range added {
+			if !strings.HasSuffix(content, "\n\n") {
+				content += "\n"
+			}
+			content += b
+		}
+		files[MovedFile] = content
+	}
+
+	if !write {
+		return results, nil
+	}
+	changed := map[string]bool{}
+	for _, r := range results {
+		for _, f := range r.Files {
+			changed[f] = true
+		}
+	}
+	if len(added) > 0 {
+		changed[MovedFile] = true
+	}
+	names := make([]string, 0, len(changed))
+	for f := range changed {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+	for _, f := range names {
+		if err := os.WriteFile(filepath.Join(dir, f), []byte(files[f]), 0o644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", filepath.Join(dir, f), err)
+		}
+	}
+	return results, nil
+}
+
+// NameMoves returns a move for every resource and module declared in the
+// root at dir whose name contains old, either as written or with dashes
+// turned into underscores. The new name substitutes repl the same way. It is
+// used when a landing zone is renamed, so that blocks named after the zone
+// follow it.
+func NameMoves(dir, old, repl string) ([]Move, error) {
+	files, err := loadFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	underscored := strings.ReplaceAll(old, "-", "_")
+	replacer := strings.NewReplacer(old, repl, underscored, strings.ReplaceAll(repl, "-", "_"))
+
+	seen := map[string]bool{}
+	var moves []Move
+	for _, name := range sortedNames(files) {
+		if name == MovedFile {
+			continue
+		}
+		for _, from := range Declared(files[name]) {
+			if seen[from.String()] || (!strings.Contains(from.Name, old) && !strings.Contains(from.Name, underscored)) {
+				continue
+			}
+			seen[from.String()] = true
+			to := from
+			to.Name = replacer.Replace(from.Name)
+			moves = append(moves, Move{From: from, To: to})
+		}
+	}
+	return moves, nil
+}
+
+// Declared returns the resources and module calls declared in content, the
+// text of a *.tf file, in order of appearance.
+func Declared(content string) []Address {
+	var addrs []Address
+	for _, m := range blockRegex.FindAllStringSubmatch(content, -1) {
+		if m[1] == "module" {
+			addrs = append(addrs, Address{Name: m[4]})
+		} else {
+			addrs = append(addrs, Address{Type: m[2], Name: m[3]})
+		}
+	}
+	return addrs
+}
+
+var blockRegex = regexp.MustCompile(`(?m)^\s*(resource|module)\s+(?:"([^"]+)"\s+"([^"]+)"|"([^"]+)")\s*\{`)
+
+// loadFiles reads the top-level *.tf files of dir, keyed by file name.
+func loadFiles(dir string) (map[string]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, fmt.Errorf("listing terraform files in %s: %w", dir, err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no terraform files found in %s", dir)
+	}
+	files := make(map[string]string, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", p, err)
+		}
+		files[filepath.Base(p)] = string(data)
+	}
+	return files, nil
+}
+
+func sortedNames(files map[string]string) []string {
+	names := make([]string, 0, len(files))
+	for f := range files {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var movedRegex = regexp.MustCompile(`moved\s*\{[^}]*?from\s*=\s*(\S+)[^}]*?to\s*=\s*(\S+)[^}]*\}`)
+
+// existingMoves returns the moves already recorded in moved.tf content.
+func existingMoves(content string) map[string]bool {
+	moves := map[string]bool{}
+	for _, m := range movedRegex.FindAllStringSubmatch(content, -1) {
+		moves[m[1]+" -> "+m[2]] = true
+	}
+	return moves
+}
+
+// declarationRegex matches the block declaring a.
+func declarationRegex(a Address) *regexp.Regexp {
+	if a.IsModule() {
+		return regexp.MustCompile(`(?m)^(\s*module\s+")` + regexp.QuoteMeta(a.Name) + `("\s*\{)`)
+	}
+	return regexp.MustCompile(`(?m)^(\s*resource\s+"` + regexp.QuoteMeta(a.Type) + `"\s+")` + regexp.QuoteMeta(a.Name) + `("\s*\{)`)
+}
+
+// referenceRegex matches references to a, e.g. azurerm_resource_group.zone
+// in azurerm_resource_group.zone.name, but not in other_azurerm_resource_group.zone
+// or azurerm_resource_group.zone_b.
+func referenceRegex(a Address) *regexp.Regexp {
+	kind := "module"
+	if !a.IsModule() {
+		kind = a.Type
+	}
+	return regexp.MustCompile(`(^|[^A-Za-z0-9_.-])(` + regexp.QuoteMeta(kind) + `\.)` + regexp.QuoteMeta(a.Name) + `([^A-Za-z0-9_-]|$)`)
+}
+
+func anyMatch(files map[string]string, re *regexp.Regexp) bool {
+	for name, content := range files {
+		if name != MovedFile && re.MatchString(content) {
+			return true
+		}
+	}
+	return false
+}
+
+// renameBlock rewrites the declaration of m.From and every reference to it
+// in files, except moved.tf whose earlier blocks must keep their addresses.
+// It returns the names of the files that changed.
+func renameBlock(files map[string]string, m Move) []string {
+	decl, ref := declarationRegex(m.From), referenceRegex(m.From)
+	var changed []string
+	for _, name := range sortedNames(files) {
+		if name == MovedFile {
+			continue
+		}
+		content := decl.ReplaceAllString(files[name], "${1}"+m.To.Name+"${2}")
+		// Adjacent references share the separator between them; repeat
+		// until every one has been replaced.
+		for {
+			next := ref.ReplaceAllString(content, "${1}${2}"+m.To.Name+"${3}")
+			if next == content {
+				break
+			}
+			content = next
+		}
+		if content != files[name] {
+			files[name] = content
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
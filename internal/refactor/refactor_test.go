@@ -0,0 +1,171 @@
+package refactor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mainTF = `resource "azurerm_resource_group" "zone" {
+  name     = "rg-app"
+  location = "westeurope"
+}
+
+module "app_vnet" {
+  source              = "Azure/avm-res-network-virtualnetwork/azurerm"
+  resource_group_name = azurerm_resource_group.zone.name
+  location            = azurerm_resource_group.zone.location
+}
+
+data "azurerm_resource_group" "zone" {
+  name = "rg-other"
+}
+
+resource "azurerm_resource_group" "zone_b" {
+  name = "${azurerm_resource_group.zone.name}-b"
+}
+`
+
+const outputsTF = `output "vnet_id" {
+  value = module.app_vnet.resource_id
+}
+
+output "rg" {
+  value = [azurerm_resource_group.zone.id,azurerm_resource_group.zone.name]
+}
+`
+
+func writeRoot(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(mainTF), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "outputs.tf"), []byte(outputsTF), 0o644))
+	return dir
+}
+
+func read(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Address
+	}{
+		{"azurerm_resource_group.zone", Address{Type: "azurerm_resource_group", Name: "zone"}},
+		{"module.app_vnet", Address{Name: "app_vnet"}},
+		{`module.hub["a"].azurerm_subnet.app[0]`, Address{Module: `module.hub["a"]`, Type: "azurerm_subnet", Name: "app", Key: "[0]"}},
+		{"module.a.module.b", Address{Module: "module.a", Name: "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseAddress(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.in, got.String())
+		})
+	}
+
+	for _, bad := range []string{"", "zone", "data.azurerm_client_config.current", "azurerm_subnet.a.azurerm_subnet.b", "module.a[x]"} {
+		_, err := ParseAddress(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestNewMove_RejectsIncompatibleAddresses(t *testing.T) {
+	_, err := NewMove("azurerm_resource_group.zone", "module.zone")
+	assert.ErrorContains(t, err, "module call")
+	_, err = NewMove("azurerm_resource_group.zone", "azurerm_storage_account.zone")
+	assert.ErrorContains(t, err, "types differ")
+	_, err = NewMove("module.a", "module.a")
+	assert.ErrorContains(t, err, "same address")
+}
+
+func TestApply_RenamesBlockAndReferences(t *testing.T) {
+	dir := writeRoot(t)
+	rg, err := NewMove("azurerm_resource_group.zone", "azurerm_resource_group.workload")
+	require.NoError(t, err)
+	vnet, err := NewMove("module.app_vnet", "module.shop_vnet")
+	require.NoError(t, err)
+
+	results, err := Apply(dir, []Move{rg, vnet}, true)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Renamed)
+	assert.Equal(t, []string{"main.tf", "outputs.tf"}, results[0].Files)
+
+	main := read(t, dir, "main.tf")
+	assert.Contains(t, main, `resource "azurerm_resource_group" "workload" {`)
+	assert.Contains(t, main, "resource_group_name = azurerm_resource_group.workload.name")
+	assert.Contains(t, main, `"${azurerm_resource_group.workload.name}-b"`)
+	assert.Contains(t, main, `resource "azurerm_resource_group" "zone_b" {`, "similar names are kept")
+	assert.Contains(t, main, `data "azurerm_resource_group" "zone" {`, "data sources are kept")
+	assert.Contains(t, main, `module "shop_vnet" {`)
+	outputs := read(t, dir, "outputs.tf")
+	assert.Contains(t, outputs, "[azurerm_resource_group.workload.id,azurerm_resource_group.workload.name]")
+	assert.Contains(t, outputs, "module.shop_vnet.resource_id")
+
+	moved := read(t, dir, MovedFile)
+	assert.Contains(t, moved, "moved {\n  from = azurerm_resource_group.zone\n  to   = azurerm_resource_group.workload\n}\n")
+	assert.Contains(t, moved, "moved {\n  from = module.app_vnet\n  to   = module.shop_vnet\n}\n")
+}
+
+func TestApply_IsIdempotent(t *testing.T) {
+	dir := writeRoot(t)
+	m, err := NewMove("module.app_vnet", "module.shop_vnet")
+	require.NoError(t, err)
+	_, err = Apply(dir, []Move{m}, true)
+	require.NoError(t, err)
+	first := read(t, dir, MovedFile)
+
+	results, err := Apply(dir, []Move{m}, true)
+	require.NoError(t, err)
+	assert.False(t, results[0].Renamed)
+	assert.True(t, results[0].Recorded)
+	assert.Equal(t, first, read(t, dir, MovedFile))
+}
+
+func TestApply_DryRunWritesNothing(t *testing.T) {
+	dir := writeRoot(t)
+	m, err := NewMove("module.app_vnet", "module.shop_vnet")
+	require.NoError(t, err)
+	results, err := Apply(dir, []Move{m}, false)
+	require.NoError(t, err)
+	assert.True(t, results[0].Renamed)
+	assert.Equal(t, mainTF, read(t, dir, "main.tf"))
+	assert.NoFileExists(t, filepath.Join(dir, MovedFile))
+}
+
+func TestApply_UnknownAddress(t *testing.T) {
+	dir := writeRoot(t)
+	m, err := NewMove("module.missing", "module.other")
+	require.NoError(t, err)
+	_, err = Apply(dir, []Move{m}, true)
+	assert.ErrorContains(t, err, "neither module.missing nor module.other is declared")
+
+	m, err = NewMove("module.hub.azurerm_subnet.a", "module.hub.azurerm_subnet.b")
+	require.NoError(t, err)
+	results, err := Apply(dir, []Move{m}, true)
+	require.NoError(t, err)
+	assert.NotEmpty(t, results[0].Skipped)
+	assert.Contains(t, read(t, dir, MovedFile), "from = module.hub.azurerm_subnet.a")
+}
+
+func TestNameMoves(t *testing.T) {
+	dir := writeRoot(t)
+	moves, err := NameMoves(dir, "app", "shop")
+	require.NoError(t, err)
+	require.Len(t, moves, 1)
+	assert.Equal(t, "module.app_vnet", moves[0].From.String())
+	assert.Equal(t, "module.shop_vnet", moves[0].To.String())
+
+	moves, err = NameMoves(dir, "corp-prod", "shop")
+	require.NoError(t, err)
+	assert.Empty(t, moves)
+}
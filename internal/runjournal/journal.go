@@ -0,0 +1,230 @@
+// Package runjournal records orchestrated lzctl runs under .lzctl/runs/ so
+// that an interrupted multi-layer apply can be resumed.
+//
+// Each run gets its own directory, .lzctl/runs/<run-id>/, holding
+// journal.json. The journal lists every root in execution order with the
+// hash of its Terraform inputs, the hash of the saved plan it applied, its
+// status and timestamps. It is rewritten atomically after every state
+// change, so a crash leaves the last consistent view on disk.
+package runjournal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Layer and run statuses.
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// journalFile is the journal's file name inside a run directory.
+const journalFile = "journal.json"
+
+// Layer is the journal entry for one root.
+type Layer struct {
+	Name       string     `json:"layer"`
+	Kind       string     `json:"kind"`
+	Zone       string     `json:"zone,omitempty"`
+	Dir        string     `json:"dir"`
+	InputHash  string     `json:"inputHash"`          // hash of the root's Terraform inputs
+	PlanHash   string     `json:"planHash,omitempty"` // hash of the saved tfplan applied, if any
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Journal is the persisted record of one run. Methods are safe for
+// concurrent use by roots running in parallel.
+type Journal struct {
+	RunID      string      `json:"runId"`
+	Command    string      `json:"command"`
+	Status     string      `json:"status"`
+	StartedAt  time.Time   `json:"startedAt"`
+	FinishedAt *time.Time  `json:"finishedAt,omitempty"`
+	ResumedAt  []time.Time `json:"resumedAt,omitempty"`
+	Layers     []Layer     `json:"layers"`
+
+	mu   sync.Mutex
+	path string
+	now  func() time.Time
+}
+
+// Dir returns the directory holding all run directories of a repository.
+func Dir(repo string) string {
+	return filepath.Join(repo, ".lzctl", "runs")
+}
+
+// RunDir returns the directory of a single run.
+func RunDir(repo, runID string) string {
+	return filepath.Join(Dir(repo), runID)
+}
+
+// NewRunID returns a sortable, unique run ID such as 20260218-153045-1a2b3c.
+func NewRunID(now time.Time) string {
+	var b [3]byte
+	_, _ = rand.Read(b[:])
+	return now.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(b[:])
+}
+
+// Create starts a journal for command with the given layers (all pending)
+// and writes it to disk.
+func Create(repo, command string, layers []Layer) (*Journal, error) {
+	now := time.Now().UTC()
+	j := &Journal{
+		RunID:     NewRunID(now),
+		Command:   command,
+		Status:    StatusRunning,
+		StartedAt: now,
+		Layers:    make([]Layer, len(layers)),
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+	for i, l := range layers {
+		l.Status = StatusPending
+		j.Layers[i] = l
+	}
+	j.path = filepath.Join(RunDir(repo, j.RunID), journalFile)
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+		return nil, fmt.Errorf("runjournal: creating run directory: %w", err)
+	}
+	if err := j.save(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Load reads the journal of an existing run.
+func Load(repo, runID string) (*Journal, error) {
+	path := filepath.Join(RunDir(repo, runID), journalFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("runjournal: run %q not found under %s", runID, Dir(repo))
+		}
+		return nil, fmt.Errorf("runjournal: reading %s: %w", path, err)
+	}
+	j := &Journal{}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("runjournal: parsing %s: %w", path, err)
+	}
+	j.path = path
+	j.now = func() time.Time { return time.Now().UTC() }
+	return j, nil
+}
+
+// Path returns the journal file path.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Layer returns a copy of the entry for name.
+func (j *Journal) Layer(name string) (Layer, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if i := j.index(name); i >= 0 {
+		return j.Layers[i], true
+	}
+	return Layer{}, false
+}
+
+// Resume marks the run as running again and records the resume time.
+func (j *Journal) Resume() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Status = StatusRunning
+	j.FinishedAt = nil
+	j.ResumedAt = append(j.ResumedAt, j.now())
+	return j.save()
+}
+
+// Start marks a layer as running and records the hash of the plan it applies.
+func (j *Journal) Start(name, planHash string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	i := j.index(name)
+	if i < 0 {
+		return fmt.Errorf("runjournal: unknown layer %q", name)
+	}
+	now := j.now()
+	j.Layers[i].Status = StatusRunning
+	j.Layers[i].PlanHash = planHash
+	j.Layers[i].Error = ""
+	j.Layers[i].StartedAt = &now
+	j.Layers[i].FinishedAt = nil
+	return j.save()
+}
+
+// Finish marks a layer as succeeded, or failed when err is non-nil.
+func (j *Journal) Finish(name string, err error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	i := j.index(name)
+	if i < 0 {
+		return fmt.Errorf("runjournal: unknown layer %q", name)
+	}
+	now := j.now()
+	j.Layers[i].FinishedAt = &now
+	if err != nil {
+		j.Layers[i].Status = StatusFailed
+		j.Layers[i].Error = err.Error()
+	} else {
+		j.Layers[i].Status = StatusSucceeded
+	}
+	return j.save()
+}
+
+// Complete closes the run: succeeded when every layer succeeded, failed
+// otherwise. Layers still marked running (interrupted) become failed.
+func (j *Journal) Complete() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := j.now()
+	j.FinishedAt = &now
+	j.Status = StatusSucceeded
+	for i := range j.Layers {
+		if j.Layers[i].Status == StatusRunning {
+			j.Layers[i].Status = StatusFailed
+			j.Layers[i].Error = "interrupted"
+			j.Layers[i].FinishedAt = &now
+		}
+		if j.Layers[i].Status != StatusSucceeded {
+			j.Status = StatusFailed
+		}
+	}
+	return j.save()
+}
+
+func (j *Journal) index(name string) int {
+	for i, l := range j.Layers {
+		if l.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// save writes the journal atomically. Callers hold j.mu.
+func (j *Journal) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("runjournal: encoding journal: %w", err)
+	}
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("runjournal: writing %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return fmt.Errorf("runjournal: writing %s: %w", j.path, err)
+	}
+	return nil
+}
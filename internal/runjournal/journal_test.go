@@ -0,0 +1,91 @@
+package runjournal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal_Lifecycle(t *testing.T) {
+	repo := t.TempDir()
+	j, err := Create(repo, "apply", []Layer{
+		{Name: "management-groups", Kind: "platform", Dir: "platform/management-groups", InputHash: "sha256:a"},
+		{Name: "identity", Kind: "platform", Dir: "platform/identity", InputHash: "sha256:b"},
+		{Name: "governance", Kind: "platform", Dir: "platform/governance", InputHash: "sha256:c"},
+	})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(RunDir(repo, j.RunID), "journal.json"))
+	assert.Equal(t, StatusRunning, j.Status)
+
+	require.NoError(t, j.Start("management-groups", "sha256:plan"))
+	require.NoError(t, j.Finish("management-groups", nil))
+	require.NoError(t, j.Start("identity", ""))
+	require.NoError(t, j.Finish("identity", errors.New("terraform apply failed")))
+	require.NoError(t, j.Complete())
+
+	loaded, err := Load(repo, j.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, loaded.Status)
+	require.Len(t, loaded.Layers, 3)
+	assert.Equal(t, StatusSucceeded, loaded.Layers[0].Status)
+	assert.Equal(t, "sha256:plan", loaded.Layers[0].PlanHash)
+	assert.NotNil(t, loaded.Layers[0].StartedAt)
+	assert.NotNil(t, loaded.Layers[0].FinishedAt)
+	assert.Equal(t, StatusFailed, loaded.Layers[1].Status)
+	assert.Equal(t, "terraform apply failed", loaded.Layers[1].Error)
+	assert.Equal(t, StatusPending, loaded.Layers[2].Status)
+
+	require.NoError(t, loaded.Resume())
+	require.Len(t, loaded.ResumedAt, 1)
+	require.NoError(t, loaded.Start("identity", ""))
+	require.NoError(t, loaded.Finish("identity", nil))
+	require.NoError(t, loaded.Start("governance", ""))
+	require.NoError(t, loaded.Finish("governance", nil))
+	require.NoError(t, loaded.Complete())
+	assert.Equal(t, StatusSucceeded, loaded.Status)
+	assert.Empty(t, loaded.Layers[1].Error)
+}
+
+func TestJournal_CompleteMarksInterruptedLayersFailed(t *testing.T) {
+	j, err := Create(t.TempDir(), "apply", []Layer{{Name: "identity"}})
+	require.NoError(t, err)
+	require.NoError(t, j.Start("identity", ""))
+	require.NoError(t, j.Complete())
+
+	l, ok := j.Layer("identity")
+	require.True(t, ok)
+	assert.Equal(t, StatusFailed, l.Status)
+	assert.Equal(t, "interrupted", l.Error)
+}
+
+func TestJournal_UnknownLayerAndRun(t *testing.T) {
+	repo := t.TempDir()
+	j, err := Create(repo, "apply", nil)
+	require.NoError(t, err)
+	require.Error(t, j.Start("missing", ""))
+
+	_, err = Load(repo, "does-not-exist")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	require.NoError(t, os.WriteFile(filepath.Join(RunDir(repo, j.RunID), "journal.json"), []byte("{"), 0o644))
+	_, err = Load(repo, j.RunID)
+	require.Error(t, err)
+}
+
+func TestNewRunID_Unique(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		id := NewRunID(testTime)
+		assert.False(t, seen[id], id)
+		seen[id] = true
+		assert.Regexp(t, `^20260218-153045-[0-9a-f]{6}$`, id)
+	}
+}
+
+var testTime = time.Date(2026, 2, 18, 15, 30, 45, 0, time.UTC)
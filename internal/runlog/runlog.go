@@ -0,0 +1,175 @@
+// Package runlog archives the output of every Terraform invocation of an
+// lzctl run and streams it, prefixed with the root it belongs to, while it is
+// produced.
+//
+// Logs are written next to the run journal, one file per invocation:
+// .lzctl/runs/<run-id>/<layer>-<step>.log, where step is the subcommand
+// (init, plan, apply, show...). index.json in the same directory lists every
+// log with its arguments, exit code and duration, so a failed CI run can be
+// diagnosed from the uploaded directory alone.
+package runlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kjourdan1/lzctl/internal/runjournal"
+)
+
+// IndexFile is the name of the run index inside a run directory.
+const IndexFile = "index.json"
+
+// Entry describes one archived invocation.
+type Entry struct {
+	Layer     string    `json:"layer"`
+	Step      string    `json:"step"`
+	File      string    `json:"file"`
+	Dir       string    `json:"dir"`
+	Args      []string  `json:"args"`
+	StartedAt time.Time `json:"startedAt"`
+	Duration  string    `json:"duration"`
+	ExitCode  int       `json:"exitCode"` // -1 when the tool did not exit normally
+	Error     string    `json:"error,omitempty"`
+}
+
+// Index is the content of index.json.
+type Index struct {
+	RunID     string    `json:"runId"`
+	Command   string    `json:"command"`
+	StartedAt time.Time `json:"startedAt"`
+	Logs      []Entry   `json:"logs"`
+}
+
+// Archive collects the logs of one run. The run directory is only created
+// once the first log is recorded. Methods are safe for concurrent use.
+type Archive struct {
+	dir  string
+	repo string
+
+	mu    sync.Mutex
+	index Index
+	seen  map[string]int
+}
+
+// New returns the archive of run runID of command in repo. When the run
+// already has logs (a resumed apply), new logs are added after them.
+func New(repo, runID, command string) *Archive {
+	a := &Archive{
+		dir:   runjournal.RunDir(repo, runID),
+		repo:  repo,
+		index: Index{RunID: runID, Command: command, StartedAt: time.Now().UTC(), Logs: []Entry{}},
+		seen:  map[string]int{},
+	}
+	if idx, err := LoadIndex(repo, runID); err == nil {
+		a.index.StartedAt = idx.StartedAt
+		a.index.Logs = append(a.index.Logs, idx.Logs...)
+		for _, e := range idx.Logs {
+			a.seen[logBase(e.Layer, e.Step)]++
+		}
+	}
+	return a
+}
+
+// Dir returns the run directory holding the logs.
+func (a *Archive) Dir() string {
+	return a.dir
+}
+
+// Len returns the number of logs recorded so far, including those of
+// earlier attempts of a resumed run.
+func (a *Archive) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.index.Logs)
+}
+
+// Record writes the output of one invocation to its log file and adds it to
+// the index. e.File is set by Record; e.Dir is stored relative to the
+// repository when possible. Repeated steps of a layer (a retried plan, a
+// second show) get a numeric suffix: connectivity-plan-2.log.
+func (a *Archive) Record(e Entry, output string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	base := logBase(e.Layer, e.Step)
+	a.seen[base]++
+	if n := a.seen[base]; n > 1 {
+		base = fmt.Sprintf("%s-%d", base, n)
+	}
+	e.File = base + ".log"
+	if rel, err := filepath.Rel(a.repo, e.Dir); err == nil && !strings.HasPrefix(rel, "..") {
+		e.Dir = filepath.ToSlash(rel)
+	}
+
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		return fmt.Errorf("runlog: creating %s: %w", a.dir, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# layer: %s\n# dir: %s\n# args: %s\n# started: %s\n\n", e.Layer, e.Dir, strings.Join(e.Args, " "), e.StartedAt.Format(time.RFC3339))
+	b.WriteString(output)
+	if output != "" && !strings.HasSuffix(output, "\n") {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "\n# exit code: %d, duration: %s\n", e.ExitCode, e.Duration)
+	if e.Error != "" {
+		fmt.Fprintf(&b, "# error: %s\n", e.Error)
+	}
+	if err := os.WriteFile(filepath.Join(a.dir, e.File), []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("runlog: writing %s: %w", e.File, err)
+	}
+
+	a.index.Logs = append(a.index.Logs, e)
+	return a.saveIndex()
+}
+
+// saveIndex writes index.json atomically. Callers hold a.mu.
+func (a *Archive) saveIndex() error {
+	data, err := json.MarshalIndent(a.index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("runlog: encoding index: %w", err)
+	}
+	path := filepath.Join(a.dir, IndexFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("runlog: writing %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("runlog: writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadIndex reads the index of an existing run.
+func LoadIndex(repo, runID string) (*Index, error) {
+	path := filepath.Join(runjournal.RunDir(repo, runID), IndexFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("runlog: reading %s: %w", path, err)
+	}
+	idx := &Index{}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("runlog: parsing %s: %w", path, err)
+	}
+	return idx, nil
+}
+
+func logBase(layer, step string) string {
+	return sanitize(layer) + "-" + sanitize(step)
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sanitize turns a root name such as "lz:app" into a file name fragment.
+func sanitize(s string) string {
+	s = unsafeChars.ReplaceAllString(s, "_")
+	if s == "" {
+		return "run"
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package runlog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kjourdan1/lzctl/internal/orchestrator/runnertest"
+)
+
+func TestArchive_RecordWritesLogsAndIndex(t *testing.T) {
+	repo := t.TempDir()
+	a := New(repo, "20260218-153045-abcdef", "plan")
+	assert.NoDirExists(t, a.Dir(), "created on the first log")
+
+	dir := filepath.Join(repo, "landing-zones", "app")
+	require.NoError(t, a.Record(Entry{Layer: "lz:app", Step: "plan", Dir: dir, Args: []string{"plan", "-no-color"}}, "Plan: 1 to add"))
+	require.NoError(t, a.Record(Entry{Layer: "lz:app", Step: "plan", Dir: dir, ExitCode: 1, Error: "exit status 1"}, "Error: boom\n"))
+
+	first, err := os.ReadFile(filepath.Join(a.Dir(), "lz_app-plan.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(first), "# args: plan -no-color")
+	assert.Contains(t, string(first), "Plan: 1 to add\n")
+	second, err := os.ReadFile(filepath.Join(a.Dir(), "lz_app-plan-2.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(second), "# exit code: 1")
+	assert.Contains(t, string(second), "# error: exit status 1")
+
+	idx, err := LoadIndex(repo, "20260218-153045-abcdef")
+	require.NoError(t, err)
+	assert.Equal(t, "plan", idx.Command)
+	require.Len(t, idx.Logs, 2)
+	assert.Equal(t, "lz_app-plan.log", idx.Logs[0].File)
+	assert.Equal(t, "landing-zones/app", idx.Logs[0].Dir)
+	assert.Equal(t, 1, idx.Logs[1].ExitCode)
+	assert.Equal(t, 2, a.Len())
+}
+
+func TestLineWriter_PrefixesCompleteLines(t *testing.T) {
+	var out bytes.Buffer
+	lw := NewLineWriter(&out, nil, "identity")
+	_, _ = lw.Write([]byte("Initializing"))
+	assert.Empty(t, out.String(), "partial lines are held back")
+	_, _ = lw.Write([]byte(" the backend...\nTerraform has been"))
+	_, _ = lw.Write([]byte(" successfully initialized!"))
+	require.NoError(t, lw.Flush())
+	assert.Equal(t, "[identity] Initializing the backend...\n[identity] Terraform has been successfully initialized!\n", out.String())
+}
+
+func TestLineWriter_ConcurrentLayersDoNotMixLines(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, layer := range []string{"identity", "management", "connectivity"} {
+		wg.Add(1)
+		go func(layer string) {
+			defer wg.Done()
+			lw := NewLineWriter(&out, &mu, layer)
+			for i := 0; i < 200; i++ {
+				_, _ = fmt.Fprintf(lw, "line %d of %s\n", i, layer)
+			}
+		}(layer)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 600)
+	for _, line := range lines {
+		layer := strings.TrimSuffix(strings.TrimPrefix(strings.SplitN(line, " ", 2)[0], "["), "]")
+		assert.True(t, strings.HasSuffix(line, " of "+layer), line)
+	}
+}
+
+func TestWrap_StreamsRunAndArchivesEveryCall(t *testing.T) {
+	repo := t.TempDir()
+	fake := runnertest.New().
+		Reply("plan", "Plan: 1 to add, 0 to change, 0 to destroy.\nmore", 2).
+		Reply("show", `{"format_version":"1.2"}`, 0)
+	var stream bytes.Buffer
+	tf := Wrap(fake, &stream, nil)
+
+	a := New(repo, "run-1", "plan")
+	ctx := WithLayer(NewContext(context.Background(), a), "connectivity")
+	dir := filepath.Join(repo, "platform", "connectivity")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	_, err := tf.Run(ctx, dir, "plan", "-out=tfplan")
+	require.Error(t, err)
+	_, err = tf.Output(ctx, dir, "show", "-json", "tfplan")
+	require.NoError(t, err)
+
+	assert.Equal(t, "[connectivity] Plan: 1 to add, 0 to change, 0 to destroy.\n[connectivity] more\n", stream.String(), "Output calls are not streamed")
+	idx, err := LoadIndex(repo, "run-1")
+	require.NoError(t, err)
+	require.Len(t, idx.Logs, 2)
+	assert.Equal(t, "connectivity-plan.log", idx.Logs[0].File)
+	assert.Equal(t, 2, idx.Logs[0].ExitCode)
+	assert.Equal(t, "connectivity-show.log", idx.Logs[1].File)
+}
+
+func TestWrap_WithoutArchiveOrStream(t *testing.T) {
+	fake := runnertest.New()
+	out, err := Wrap(fake, nil, nil).Run(context.Background(), t.TempDir(), "init")
+	require.NoError(t, err)
+	assert.Contains(t, out, "successfully initialized")
+}
@@ -0,0 +1,185 @@
+package runlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kjourdan1/lzctl/internal/orchestrator"
+)
+
+type archiveKey struct{}
+
+type layerKey struct{}
+
+// NewContext returns a context whose runner calls are recorded to a.
+func NewContext(ctx context.Context, a *Archive) context.Context {
+	return context.WithValue(ctx, archiveKey{}, a)
+}
+
+// FromContext returns the archive attached to ctx, or nil.
+func FromContext(ctx context.Context) *Archive {
+	a, _ := ctx.Value(archiveKey{}).(*Archive)
+	return a
+}
+
+// WithLayer returns a context whose runner calls belong to layer: their
+// streamed lines are prefixed with it and their logs named after it.
+func WithLayer(ctx context.Context, layer string) context.Context {
+	return context.WithValue(ctx, layerKey{}, layer)
+}
+
+// LayerFrom returns the layer set by WithLayer, or "".
+func LayerFrom(ctx context.Context) string {
+	l, _ := ctx.Value(layerKey{}).(string)
+	return l
+}
+
+// LineWriter writes complete lines to w, each prefixed with "[layer] ".
+// Every line is written while holding mu, so the lines of roots running in
+// parallel interleave but never mix. Call Flush to emit a trailing partial
+// line.
+type LineWriter struct {
+	w      io.Writer
+	mu     sync.Locker
+	prefix string
+	buf    bytes.Buffer
+}
+
+// NewLineWriter returns a LineWriter for layer. mu may be nil when w is not
+// shared.
+func NewLineWriter(w io.Writer, mu sync.Locker, layer string) *LineWriter {
+	if mu == nil {
+		mu = &sync.Mutex{}
+	}
+	return &LineWriter{w: w, mu: mu, prefix: "[" + layer + "] "}
+}
+
+// Write implements io.Writer.
+func (lw *LineWriter) Write(p []byte) (int, error) {
+	lw.buf.Write(p)
+	for {
+		i := bytes.IndexByte(lw.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := lw.buf.Next(i + 1)
+		if err := lw.emit(line); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// Flush writes any buffered partial line.
+func (lw *LineWriter) Flush() error {
+	if lw.buf.Len() == 0 {
+		return nil
+	}
+	line := append(lw.buf.Next(lw.buf.Len()), '\n')
+	return lw.emit(line)
+}
+
+func (lw *LineWriter) emit(line []byte) error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	_, err := io.WriteString(lw.w, lw.prefix+string(line))
+	return err
+}
+
+// Wrap returns a runner that records every call of r to the archive found
+// in the call's context, and, when stream is non-nil, copies the output of
+// Run calls to stream line by line with the layer prefix while holding mu.
+// Output calls (machine-readable JSON) are archived but never streamed.
+// Failing to write a log never fails the call.
+func Wrap(r orchestrator.Runner, stream io.Writer, mu sync.Locker) orchestrator.Runner {
+	if mu == nil {
+		mu = &sync.Mutex{}
+	}
+	return &runner{Runner: r, stream: stream, mu: mu}
+}
+
+type runner struct {
+	orchestrator.Runner
+	stream io.Writer
+	mu     sync.Locker
+}
+
+func (r *runner) Run(ctx context.Context, dir string, args ...string) (string, error) {
+	layer := layerName(ctx, dir)
+	start := time.Now()
+	var out string
+	var err error
+	if r.stream == nil {
+		out, err = r.Runner.Run(ctx, dir, args...)
+	} else {
+		lw := NewLineWriter(r.stream, r.mu, layer)
+		if s, ok := r.Runner.(orchestrator.Streamer); ok {
+			out, err = s.RunStream(ctx, dir, lw, args...)
+		} else {
+			out, err = r.Runner.Run(ctx, dir, args...)
+			_, _ = io.WriteString(lw, out)
+		}
+		_ = lw.Flush()
+	}
+	record(ctx, layer, dir, args, start, out, err)
+	return out, err
+}
+
+func (r *runner) Output(ctx context.Context, dir string, args ...string) (string, error) {
+	start := time.Now()
+	out, err := r.Runner.Output(ctx, dir, args...)
+	logged := out
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		logged += string(exitErr.Stderr)
+	}
+	record(ctx, layerName(ctx, dir), dir, args, start, logged, err)
+	return out, err
+}
+
+func record(ctx context.Context, layer, dir string, args []string, start time.Time, out string, err error) {
+	a := FromContext(ctx)
+	if a == nil {
+		return
+	}
+	step := "run"
+	if len(args) > 0 {
+		step = args[0]
+	}
+	e := Entry{
+		Layer:     layer,
+		Step:      step,
+		Dir:       dir,
+		Args:      append([]string(nil), args...),
+		StartedAt: start.UTC(),
+		Duration:  time.Since(start).Round(time.Millisecond).String(),
+		ExitCode:  exitCode(err),
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	_ = a.Record(e, out)
+}
+
+func layerName(ctx context.Context, dir string) string {
+	if l := LayerFrom(ctx); l != "" {
+		return l
+	}
+	return filepath.Base(dir)
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var e interface{ ExitCode() int }
+	if errors.As(err, &e) {
+		return e.ExitCode()
+	}
+	return -1
+}
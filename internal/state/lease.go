@@ -0,0 +1,223 @@
+package state
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kjourdan1/lzctl/internal/azure"
+)
+
+// terraformLockMetadata is the blob metadata key in which the azurerm
+// backend stores the lock info of the current lease holder (base64 JSON).
+const terraformLockMetadata = "terraformlockid"
+
+// DefaultLeaseBackoff paces the checks of WaitForLease.
+var DefaultLeaseBackoff = azure.RetryConfig{BaseDelay: 5 * time.Second, MaxDelay: time.Minute}
+
+// LockInfo is the lock metadata Terraform writes on a leased state blob.
+type LockInfo struct {
+	ID        string    `json:"ID"`
+	Operation string    `json:"Operation"`
+	Info      string    `json:"Info,omitempty"`
+	Who       string    `json:"Who"`
+	Version   string    `json:"Version,omitempty"`
+	Created   time.Time `json:"Created"`
+	Path      string    `json:"Path,omitempty"`
+}
+
+// Lease is the lease status of a state blob.
+type Lease struct {
+	Key    string    `json:"key"`
+	Status string    `json:"status"`          // "locked" | "unlocked"
+	State  string    `json:"state,omitempty"` // "available" | "leased" | "expired" | "breaking" | "broken"
+	Lock   *LockInfo `json:"lock,omitempty"`  // nil when the holder left no Terraform lock metadata
+}
+
+// Locked reports whether the blob is leased.
+func (l *Lease) Locked() bool {
+	return strings.EqualFold(l.Status, "locked")
+}
+
+// Age returns how long the lock has been held, from Terraform's lock
+// metadata. ok is false when the lock time is unknown.
+func (l *Lease) Age(now time.Time) (age time.Duration, ok bool) {
+	if l.Lock == nil || l.Lock.Created.IsZero() {
+		return 0, false
+	}
+	return now.Sub(l.Lock.Created), true
+}
+
+// Holder describes the lock holder, e.g.
+// "alice@build-42 (OperationTypeApply, lock 1f0c…, since 2026-03-01T10:00:00Z)".
+func (l *Lease) Holder() string {
+	if l.Lock == nil {
+		return "unknown holder (no Terraform lock metadata)"
+	}
+	who := l.Lock.Who
+	if who == "" {
+		who = "unknown holder"
+	}
+	var details []string
+	if l.Lock.Operation != "" {
+		details = append(details, l.Lock.Operation)
+	}
+	if l.Lock.ID != "" {
+		details = append(details, "lock "+l.Lock.ID)
+	}
+	if !l.Lock.Created.IsZero() {
+		details = append(details, "since "+l.Lock.Created.UTC().Format(time.RFC3339))
+	}
+	if len(details) == 0 {
+		return who
+	}
+	return fmt.Sprintf("%s (%s)", who, strings.Join(details, ", "))
+}
+
+// Lease reads the lease status of a state blob and, when it is leased, the
+// Terraform lock metadata of the holder. A blob that does not exist yet is
+// reported as unlocked.
+func (m *Manager) Lease(stateKey string) (*Lease, error) {
+	sb := m.cfg.Spec.StateBackend
+	args := []string{
+		"storage", "blob", "show",
+		"--account-name", sb.StorageAccount,
+		"--container-name", sb.Container,
+		"--name", stateKey,
+		"--subscription", sb.Subscription,
+		"--auth-mode", "login",
+		"--query", "{status: properties.lease.status, state: properties.lease.state, metadata: metadata}",
+		"--output", "json",
+	}
+	out, err := m.cli.Run(args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "BlobNotFound") || strings.Contains(err.Error(), "does not exist") {
+			return &Lease{Key: stateKey, Status: "unlocked"}, nil
+		}
+		return nil, fmt.Errorf("reading lease of %s: %w", stateKey, err)
+	}
+
+	var blob struct {
+		Status   string            `json:"status"`
+		State    string            `json:"state"`
+		Metadata map[string]string `json:"metadata"`
+	}
+	if err := json.Unmarshal([]byte(out), &blob); err != nil {
+		return nil, fmt.Errorf("parsing lease of %s: %w", stateKey, err)
+	}
+	l := &Lease{Key: stateKey, Status: strings.ToLower(blob.Status), State: strings.ToLower(blob.State)}
+	if l.Locked() {
+		l.Lock = parseLockInfo(blob.Metadata)
+	}
+	return l, nil
+}
+
+// parseLockInfo decodes the Terraform lock metadata, ignoring values that
+// do not decode: the lease status alone is authoritative.
+func parseLockInfo(metadata map[string]string) *LockInfo {
+	for k, v := range metadata {
+		if !strings.EqualFold(k, terraformLockMetadata) {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil
+		}
+		var info LockInfo
+		if err := json.Unmarshal(data, &info); err != nil {
+			return nil
+		}
+		return &info
+	}
+	return nil
+}
+
+// LeaseWait configures WaitForLease.
+type LeaseWait struct {
+	// Timeout bounds the wait for a held lease; 0 fails on the first check.
+	Timeout time.Duration
+	// Backoff paces the checks (MaxAttempts is ignored). Zero uses
+	// DefaultLeaseBackoff.
+	Backoff azure.RetryConfig
+	// BreakAfter breaks a lease whose Terraform lock is older than this.
+	// 0 never breaks; leases without lock metadata are never broken.
+	BreakAfter time.Duration
+	// OnWait, when set, is called before each wait.
+	OnWait func(l *Lease, delay time.Duration)
+	// OnBreak, when set, is called before a stale lease is broken.
+	OnBreak func(l *Lease, age time.Duration)
+}
+
+// LeaseHeldError is returned by WaitForLease when the lease is still held
+// at the end of the wait.
+type LeaseHeldError struct {
+	Lease  *Lease
+	Waited time.Duration
+}
+
+func (e *LeaseHeldError) Error() string {
+	return fmt.Sprintf("state %s is locked by %s (waited %s)", e.Lease.Key, e.Lease.Holder(), e.Waited.Round(time.Second))
+}
+
+// Test hooks.
+var (
+	now   = time.Now
+	sleep = func(ctx context.Context, d time.Duration) error {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			return nil
+		}
+	}
+)
+
+// WaitForLease returns once the state blob is not leased. While it is, the
+// lease is checked again with backoff until w.Timeout elapses, and a stale
+// lease is broken (at most once) when w.BreakAfter allows it.
+func (m *Manager) WaitForLease(ctx context.Context, stateKey string, w LeaseWait) (*Lease, error) {
+	if w.Backoff == (azure.RetryConfig{}) {
+		w.Backoff = DefaultLeaseBackoff
+	}
+	start := now()
+	broken := false
+	for attempt := 0; ; attempt++ {
+		l, err := m.Lease(stateKey)
+		if err != nil {
+			return nil, err
+		}
+		if !l.Locked() {
+			return l, nil
+		}
+		if w.BreakAfter > 0 && !broken {
+			if age, ok := l.Age(now()); ok && age >= w.BreakAfter {
+				if w.OnBreak != nil {
+					w.OnBreak(l, age)
+				}
+				if err := m.BreakLease(stateKey); err != nil {
+					return l, err
+				}
+				broken = true
+				continue
+			}
+		}
+
+		waited := now().Sub(start)
+		remaining := w.Timeout - waited
+		if remaining <= 0 {
+			return l, &LeaseHeldError{Lease: l, Waited: waited}
+		}
+		delay := min(w.Backoff.Delay(attempt), remaining)
+		if w.OnWait != nil {
+			w.OnWait(l, delay)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return l, err
+		}
+	}
+}
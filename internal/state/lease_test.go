@@ -0,0 +1,158 @@
+package state
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// leaseCLI answers successive `storage blob show` calls from shows (the last
+// one repeats) and counts lease breaks.
+type leaseCLI struct {
+	shows  []string
+	calls  int
+	breaks int
+}
+
+func (c *leaseCLI) Run(args ...string) (string, error) {
+	if len(args) > 3 && args[2] == "lease" && args[3] == "break" {
+		c.breaks++
+		return "", nil
+	}
+	i := min(c.calls, len(c.shows)-1)
+	c.calls++
+	if c.shows[i] == "" {
+		return "", errors.New("ErrorCode:BlobNotFound")
+	}
+	return c.shows[i], nil
+}
+
+func lockedBlob(who string, created time.Time) string {
+	info := fmt.Sprintf(`{"ID":"1f0c","Operation":"OperationTypeApply","Who":%q,"Created":%q}`, who, created.Format(time.RFC3339))
+	return fmt.Sprintf(`{"status":"locked","state":"leased","metadata":{"Terraformlockid":%q}}`,
+		base64.StdEncoding.EncodeToString([]byte(info)))
+}
+
+const unlockedBlob = `{"status":"unlocked","state":"available","metadata":{}}`
+
+// fakeClock replaces now and sleep; sleeping advances the clock.
+func fakeClock(t *testing.T, start time.Time) *[]time.Duration {
+	t.Helper()
+	current := start
+	var slept []time.Duration
+	origNow, origSleep := now, sleep
+	now = func() time.Time { return current }
+	sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		current = current.Add(d)
+		return nil
+	}
+	t.Cleanup(func() { now, sleep = origNow, origSleep })
+	return &slept
+}
+
+func TestLease_ParsesHolder(t *testing.T) {
+	created := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	m := NewManager(testConfig(), &leaseCLI{shows: []string{lockedBlob("alice@build-42", created)}})
+
+	l, err := m.Lease("platform-connectivity.tfstate")
+	require.NoError(t, err)
+	assert.True(t, l.Locked())
+	require.NotNil(t, l.Lock)
+	assert.Equal(t, "alice@build-42 (OperationTypeApply, lock 1f0c, since 2026-03-01T10:00:00Z)", l.Holder())
+	age, ok := l.Age(created.Add(90 * time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, 90*time.Minute, age)
+}
+
+func TestLease_MissingBlobIsUnlocked(t *testing.T) {
+	m := NewManager(testConfig(), &leaseCLI{shows: []string{""}})
+	l, err := m.Lease("landing-zones-app.tfstate")
+	require.NoError(t, err)
+	assert.False(t, l.Locked())
+}
+
+func TestWaitForLease_WaitsUntilReleased(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	slept := fakeClock(t, start)
+	cli := &leaseCLI{shows: []string{lockedBlob("ci", start), lockedBlob("ci", start), unlockedBlob}}
+	m := NewManager(testConfig(), cli)
+
+	var waits int
+	l, err := m.WaitForLease(context.Background(), "platform-connectivity.tfstate", LeaseWait{
+		Timeout: time.Hour,
+		OnWait:  func(*Lease, time.Duration) { waits++ },
+	})
+	require.NoError(t, err)
+	assert.False(t, l.Locked())
+	assert.Equal(t, 2, waits)
+	assert.Len(t, *slept, 2)
+	assert.Zero(t, cli.breaks)
+}
+
+func TestWaitForLease_TimesOutWithHolder(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	slept := fakeClock(t, start)
+	m := NewManager(testConfig(), &leaseCLI{shows: []string{lockedBlob("bob@laptop", start)}})
+
+	_, err := m.WaitForLease(context.Background(), "platform-connectivity.tfstate", LeaseWait{Timeout: 2 * time.Minute})
+	var held *LeaseHeldError
+	require.ErrorAs(t, err, &held)
+	assert.Contains(t, err.Error(), "locked by bob@laptop")
+	assert.Contains(t, err.Error(), "waited 2m0s")
+
+	var total time.Duration
+	for _, d := range *slept {
+		total += d
+	}
+	assert.Equal(t, 2*time.Minute, total, "the last wait is capped by the timeout")
+}
+
+func TestWaitForLease_NoWaitFailsImmediately(t *testing.T) {
+	slept := fakeClock(t, time.Now())
+	m := NewManager(testConfig(), &leaseCLI{shows: []string{lockedBlob("ci", time.Now())}})
+
+	_, err := m.WaitForLease(context.Background(), "k.tfstate", LeaseWait{})
+	var held *LeaseHeldError
+	require.ErrorAs(t, err, &held)
+	assert.Empty(t, *slept)
+}
+
+func TestWaitForLease_BreaksStaleLease(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	fakeClock(t, start)
+	cli := &leaseCLI{shows: []string{lockedBlob("ci", start.Add(-3*time.Hour)), unlockedBlob}}
+	m := NewManager(testConfig(), cli)
+
+	var brokeAt time.Duration
+	l, err := m.WaitForLease(context.Background(), "k.tfstate", LeaseWait{
+		BreakAfter: 2 * time.Hour,
+		OnBreak:    func(_ *Lease, age time.Duration) { brokeAt = age },
+	})
+	require.NoError(t, err)
+	assert.False(t, l.Locked())
+	assert.Equal(t, 1, cli.breaks)
+	assert.Equal(t, 3*time.Hour, brokeAt)
+}
+
+func TestWaitForLease_KeepsFreshOrUnknownLeases(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	fakeClock(t, start)
+
+	fresh := &leaseCLI{shows: []string{lockedBlob("ci", start.Add(-time.Minute))}}
+	_, err := NewManager(testConfig(), fresh).WaitForLease(context.Background(), "k.tfstate", LeaseWait{BreakAfter: time.Hour})
+	require.Error(t, err)
+	assert.Zero(t, fresh.breaks)
+
+	unknown := &leaseCLI{shows: []string{`{"status":"locked","state":"leased","metadata":{}}`}}
+	_, err = NewManager(testConfig(), unknown).WaitForLease(context.Background(), "k.tfstate", LeaseWait{BreakAfter: time.Nanosecond})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no Terraform lock metadata")
+	assert.Zero(t, unknown.breaks)
+}
@@ -6,6 +6,7 @@
 //   - List: Enumerate state files in the backend for visibility
 //   - Restore: Recover a specific state version (using blob versioning)
 //   - Audit: Check state backend health (versioning, soft-delete, encryption)
+//   - Lease: Report, wait for and break state locks held by other runs
 //
 // The state backend is always Azure Storage (azurerm), using blob lease locking
 // to prevent concurrent writes (equivalent to DynamoDB locking in AWS).
@@ -18,7 +19,9 @@ package state
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -110,12 +113,11 @@ func (m *Manager) ListStates() ([]StateFile, error) {
 		if !strings.HasSuffix(b.Name, ".tfstate") {
 			continue
 		}
-		mod, _ := time.Parse(time.RFC1123, b.Properties.LastModified)
 		states = append(states, StateFile{
 			Key:          b.Name,
 			Layer:        stateKeyToLayer(b.Name),
 			Size:         b.Properties.ContentLength,
-			LastModified: mod,
+			LastModified: parseBlobTime(b.Properties.LastModified),
 			LeaseStatus:  b.Properties.LeaseStatus,
 			VersionID:    b.VersionID,
 		})
@@ -137,6 +139,11 @@ func (m *Manager) CreateSnapshot(stateKey, tag string) (*Snapshot, error) {
 		"--auth-mode", "login",
 		"--output", "json",
 	}
+	if tag != "" {
+		// Stored as snapshot metadata so that ListVersions (and rollback --to)
+		// can find the snapshot by tag later.
+		args = append(args, "--metadata", snapshotTagMetadata+"="+tag)
+	}
 	out, err := m.cli.Run(args...)
 	if err != nil {
 		return nil, fmt.Errorf("creating snapshot for %s: %w", stateKey, err)
@@ -183,7 +190,9 @@ func (m *Manager) SnapshotAll(tag string) ([]Snapshot, error) {
 	return snapshots, nil
 }
 
-// ListVersions lists all versions of a specific state file (requires blob versioning).
+// ListVersions lists all versions and snapshots of a specific state file
+// (requires blob versioning), oldest first. Snapshots created by
+// CreateSnapshot carry their tag.
 func (m *Manager) ListVersions(stateKey string) ([]Snapshot, error) {
 	sb := m.cfg.Spec.StateBackend
 	args := []string{
@@ -191,7 +200,7 @@ func (m *Manager) ListVersions(stateKey string) ([]Snapshot, error) {
 		"--account-name", sb.StorageAccount,
 		"--container-name", sb.Container,
 		"--prefix", stateKey,
-		"--include", "v", // include versions
+		"--include", "vsm", // versions, snapshots and metadata
 		"--subscription", sb.Subscription,
 		"--auth-mode", "login",
 		"--output", "json",
@@ -202,8 +211,10 @@ func (m *Manager) ListVersions(stateKey string) ([]Snapshot, error) {
 	}
 
 	var blobs []struct {
-		Name       string `json:"name"`
-		VersionID  string `json:"versionId"`
+		Name       string            `json:"name"`
+		VersionID  string            `json:"versionId"`
+		Snapshot   string            `json:"snapshot"`
+		Metadata   map[string]string `json:"metadata"`
 		Properties struct {
 			ContentLength int64  `json:"contentLength"`
 			LastModified  string `json:"lastModified"`
@@ -218,17 +229,55 @@ func (m *Manager) ListVersions(stateKey string) ([]Snapshot, error) {
 		if b.Name != stateKey {
 			continue
 		}
-		mod, _ := time.Parse(time.RFC1123, b.Properties.LastModified)
+		versionID := b.VersionID
+		if versionID == "" {
+			versionID = b.Snapshot
+		}
 		versions = append(versions, Snapshot{
 			Key:       b.Name,
-			VersionID: b.VersionID,
-			CreatedAt: mod,
+			VersionID: versionID,
+			CreatedAt: parseBlobTime(b.Properties.LastModified),
 			Size:      b.Properties.ContentLength,
+			Tag:       b.Metadata[snapshotTagMetadata],
 		})
 	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].CreatedAt.Before(versions[j].CreatedAt)
+	})
 	return versions, nil
 }
 
+// VersionAt returns the version that was current at t: the newest version
+// written at or before t.
+func VersionAt(versions []Snapshot, t time.Time) (Snapshot, bool) {
+	var found Snapshot
+	ok := false
+	for _, v := range versions {
+		if v.CreatedAt.IsZero() || v.CreatedAt.After(t) {
+			continue
+		}
+		if !ok || !v.CreatedAt.Before(found.CreatedAt) {
+			found, ok = v, true
+		}
+	}
+	return found, ok
+}
+
+// VersionByTag returns the newest snapshot carrying tag.
+func VersionByTag(versions []Snapshot, tag string) (Snapshot, bool) {
+	var found Snapshot
+	ok := false
+	for _, v := range versions {
+		if v.Tag != tag {
+			continue
+		}
+		if !ok || !v.CreatedAt.Before(found.CreatedAt) {
+			found, ok = v, true
+		}
+	}
+	return found, ok
+}
+
 // CheckHealth validates the state backend security posture.
 // Returns actionable findings if the backend deviates from best practices:
 //   - Blob versioning enabled (audit trail, rollback)
@@ -401,6 +450,49 @@ func (m *Manager) CheckHealth() (*BackendHealth, error) {
 	return health, nil
 }
 
+// NoBlobVersion is reported by BlobVersion for a state blob that does not
+// exist yet (a root that was never applied).
+const NoBlobVersion = "none"
+
+// BlobVersion returns an identifier of the current content of a state blob:
+// its version ID when blob versioning is enabled, otherwise its ETag. Both
+// change on every write, so an unchanged value means the state is unchanged.
+func (m *Manager) BlobVersion(stateKey string) (string, error) {
+	sb := m.cfg.Spec.StateBackend
+	args := []string{
+		"storage", "blob", "show",
+		"--account-name", sb.StorageAccount,
+		"--container-name", sb.Container,
+		"--name", stateKey,
+		"--subscription", sb.Subscription,
+		"--auth-mode", "login",
+		"--query", "{versionId: versionId, etag: properties.etag}",
+		"--output", "json",
+	}
+	out, err := m.cli.Run(args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "BlobNotFound") || strings.Contains(err.Error(), "does not exist") {
+			return NoBlobVersion, nil
+		}
+		return "", fmt.Errorf("reading version of %s: %w", stateKey, err)
+	}
+
+	var blob struct {
+		VersionID string `json:"versionId"`
+		ETag      string `json:"etag"`
+	}
+	if err := json.Unmarshal([]byte(out), &blob); err != nil {
+		return "", fmt.Errorf("parsing blob properties of %s: %w", stateKey, err)
+	}
+	switch {
+	case blob.VersionID != "":
+		return blob.VersionID, nil
+	case blob.ETag != "":
+		return strings.Trim(blob.ETag, `"`), nil
+	}
+	return "", fmt.Errorf("blob %s reports neither a version ID nor an ETag", stateKey)
+}
+
 // BreakLease force-releases a stuck blob lease (for recovery from failed pipelines).
 func (m *Manager) BreakLease(stateKey string) error {
 	sb := m.cfg.Spec.StateBackend
@@ -419,6 +511,105 @@ func (m *Manager) BreakLease(stateKey string) error {
 	return nil
 }
 
+// DecommissionedPrefix is the blob name prefix under which the state of
+// decommissioned roots is archived.
+const DecommissionedPrefix = "decommissioned/"
+
+// ArchiveState copies a state blob to prefix+stateKey. The original blob and
+// its snapshots are left in place. It returns the name of the archived
+// blob.
+func (m *Manager) ArchiveState(stateKey, prefix string) (string, error) {
+	dest := prefix + stateKey
+	if err := m.copyBlob(stateKey, dest); err != nil {
+		return dest, fmt.Errorf("archiving %s: %w", stateKey, err)
+	}
+	return dest, nil
+}
+
+// ErrSnapshotsPresent is returned by MoveState when the state was copied to
+// its new key but the old blob was kept because it has snapshots, which
+// lzctl never deletes.
+var ErrSnapshotsPresent = errors.New("blob has snapshots")
+
+// MoveState moves a state blob to a new key, as when the root it belongs to
+// is renamed. It refuses to overwrite an existing blob at newKey. Previous
+// versions of the old blob stay available through blob versioning. When the
+// old blob has snapshots, it is kept and ErrSnapshotsPresent is returned.
+func (m *Manager) MoveState(oldKey, newKey string) error {
+	version, err := m.BlobVersion(newKey)
+	if err != nil {
+		return fmt.Errorf("moving %s: %w", oldKey, err)
+	}
+	if version != NoBlobVersion {
+		return fmt.Errorf("moving %s: state blob %s already exists", oldKey, newKey)
+	}
+	if err := m.copyBlob(oldKey, newKey); err != nil {
+		return fmt.Errorf("moving %s: %w", oldKey, err)
+	}
+	if err := m.deleteBlob(oldKey); err != nil {
+		return fmt.Errorf("moving %s: copied to %s, but %w", oldKey, newKey, err)
+	}
+	return nil
+}
+
+// copyBlob copies src to dest synchronously.
+func (m *Manager) copyBlob(src, dest string) error {
+	sb := m.cfg.Spec.StateBackend
+	args := []string{
+		"storage", "blob", "copy", "start",
+		"--account-name", sb.StorageAccount,
+		"--destination-container", sb.Container,
+		"--destination-blob", dest,
+		"--source-container", sb.Container,
+		"--source-blob", src,
+		"--requires-sync", "true",
+		"--subscription", sb.Subscription,
+		"--auth-mode", "login",
+		"--output", "json",
+	}
+	if _, err := m.cli.Run(args...); err != nil {
+		return fmt.Errorf("copying to %s: %w", dest, err)
+	}
+	return nil
+}
+
+// deleteBlob deletes the base blob name. Its snapshots are never deleted:
+// while it has any, Azure refuses the deletion and ErrSnapshotsPresent is
+// returned.
+func (m *Manager) deleteBlob(name string) error {
+	sb := m.cfg.Spec.StateBackend
+	args := []string{
+		"storage", "blob", "delete",
+		"--account-name", sb.StorageAccount,
+		"--container-name", sb.Container,
+		"--name", name,
+		"--subscription", sb.Subscription,
+		"--auth-mode", "login",
+	}
+	if _, err := m.cli.Run(args...); err != nil {
+		if strings.Contains(err.Error(), "SnapshotsPresent") {
+			return fmt.Errorf("%s was kept: %w", name, ErrSnapshotsPresent)
+		}
+		return fmt.Errorf("deleting %s: %w", name, err)
+	}
+	return nil
+}
+
+// snapshotTagMetadata is the blob metadata key holding a snapshot's tag.
+const snapshotTagMetadata = "lzctl_tag"
+
+// parseBlobTime parses blob timestamps, which the az CLI reports as RFC 3339
+// and the REST API as RFC 1123. Unparsable values yield the zero time.
+func parseBlobTime(v string) time.Time {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC()
+	}
+	if t, err := time.Parse(time.RFC1123, v); err == nil {
+		return t.UTC()
+	}
+	return time.Time{}
+}
+
 // stateKeyToLayer converts a state key like "platform-connectivity.tfstate"
 // to a human-readable layer name like "connectivity".
 func stateKeyToLayer(key string) string {
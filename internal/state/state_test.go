@@ -1,7 +1,9 @@
 package state
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/kjourdan1/lzctl/internal/config"
 	"github.com/stretchr/testify/assert"
@@ -24,6 +26,16 @@ func newMockCLI() *mockCLI {
 
 func (m *mockCLI) Run(args ...string) (string, error) {
 	m.calls = append(m.calls, args)
+	if len(args) > 2 {
+		// A three-word key ("storage blob show") takes precedence.
+		key := args[0] + " " + args[1] + " " + args[2]
+		if err, ok := m.errors[key]; ok {
+			return "", err
+		}
+		if resp, ok := m.responses[key]; ok {
+			return resp, nil
+		}
+	}
 	key := args[0]
 	if len(args) > 1 {
 		key = args[0] + " " + args[1]
@@ -126,3 +138,133 @@ func TestNewManager(t *testing.T) {
 	assert.NotNil(t, mgr)
 	assert.Equal(t, cfg, mgr.cfg)
 }
+
+func TestCreateSnapshot_StoresTagAsMetadata(t *testing.T) {
+	cli := newMockCLI()
+	cli.responses["storage blob"] = `{"snapshot": "2026-02-19T10:00:00Z"}`
+
+	mgr := NewManager(testConfig(), cli)
+	_, err := mgr.CreateSnapshot("platform-connectivity.tfstate", "pre-apply")
+	require.NoError(t, err)
+	require.Len(t, cli.calls, 1)
+	assert.Contains(t, cli.calls[0], "lzctl_tag=pre-apply")
+}
+
+func TestListVersions_SortsAndReadsTags(t *testing.T) {
+	cli := newMockCLI()
+	cli.responses["storage blob"] = `[
+		{"name": "platform-connectivity.tfstate", "versionId": "v2", "properties": {"lastModified": "2026-02-18T12:00:00+00:00"}},
+		{"name": "platform-connectivity.tfstate", "snapshot": "2026-02-18T11:00:05Z", "metadata": {"lzctl_tag": "pre-apply"}, "properties": {"lastModified": "2026-02-18T11:00:00+00:00"}},
+		{"name": "platform-connectivity.tfstate", "versionId": "v1", "properties": {"lastModified": "Wed, 18 Feb 2026 10:00:00 GMT"}},
+		{"name": "platform-connectivity.tfstate.bak", "versionId": "x", "properties": {"lastModified": "2026-02-18T09:00:00+00:00"}}
+	]`
+
+	mgr := NewManager(testConfig(), cli)
+	versions, err := mgr.ListVersions("platform-connectivity.tfstate")
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, "v1", versions[0].VersionID)
+	assert.Equal(t, "2026-02-18T11:00:05Z", versions[1].VersionID)
+	assert.Equal(t, "pre-apply", versions[1].Tag)
+	assert.Equal(t, "v2", versions[2].VersionID)
+}
+
+func TestVersionAtAndByTag(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2026, 2, 18, h, 0, 0, 0, time.UTC) }
+	versions := []Snapshot{
+		{VersionID: "v1", CreatedAt: at(10)},
+		{VersionID: "s1", CreatedAt: at(11), Tag: "pre-apply"},
+		{VersionID: "v2", CreatedAt: at(12)},
+	}
+
+	v, ok := VersionAt(versions, at(11).Add(30*time.Minute))
+	require.True(t, ok)
+	assert.Equal(t, "s1", v.VersionID)
+
+	v, ok = VersionAt(versions, at(12))
+	require.True(t, ok)
+	assert.Equal(t, "v2", v.VersionID)
+
+	_, ok = VersionAt(versions, at(9))
+	assert.False(t, ok)
+
+	v, ok = VersionByTag(versions, "pre-apply")
+	require.True(t, ok)
+	assert.Equal(t, "s1", v.VersionID)
+
+	_, ok = VersionByTag(versions, "missing")
+	assert.False(t, ok)
+}
+
+func TestBlobVersion(t *testing.T) {
+	cli := newMockCLI()
+	cli.responses["storage blob"] = `{"versionId": "2026-02-18T12:00:00.1234567Z", "etag": "\"0x8DC\""}`
+	mgr := NewManager(testConfig(), cli)
+	v, err := mgr.BlobVersion("platform-identity.tfstate")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-02-18T12:00:00.1234567Z", v)
+	assert.Contains(t, cli.calls[0], "show")
+
+	cli.responses["storage blob"] = `{"versionId": null, "etag": "\"0x8DC\""}`
+	v, err = mgr.BlobVersion("platform-identity.tfstate")
+	require.NoError(t, err)
+	assert.Equal(t, "0x8DC", v)
+
+	delete(cli.responses, "storage blob")
+	cli.errors["storage blob"] = assert.AnError
+	_, err = mgr.BlobVersion("platform-identity.tfstate")
+	require.Error(t, err)
+
+	cli.errors["storage blob"] = errors.New("az storage blob: ErrorCode:BlobNotFound")
+	v, err = mgr.BlobVersion("platform-identity.tfstate")
+	require.NoError(t, err)
+	assert.Equal(t, NoBlobVersion, v)
+}
+
+func TestArchiveState_Copies(t *testing.T) {
+	cli := newMockCLI()
+	mgr := NewManager(testConfig(), cli)
+	dest, err := mgr.ArchiveState("landing-zones-app.tfstate", DecommissionedPrefix+"20260218-120000/")
+	require.NoError(t, err)
+	assert.Equal(t, "decommissioned/20260218-120000/landing-zones-app.tfstate", dest)
+	require.Len(t, cli.calls, 1, "the original blob and its snapshots are kept")
+	assert.Contains(t, cli.calls[0], "copy")
+	assert.Contains(t, cli.calls[0], dest)
+
+	cli = newMockCLI()
+	cli.errors["storage blob"] = assert.AnError
+	_, err = NewManager(testConfig(), cli).ArchiveState("landing-zones-app.tfstate", DecommissionedPrefix)
+	require.Error(t, err)
+}
+
+func TestMoveState_CopiesThenDeletes(t *testing.T) {
+	cli := newMockCLI()
+	cli.errors["storage blob show"] = errors.New("ErrorCode:BlobNotFound")
+	mgr := NewManager(testConfig(), cli)
+	require.NoError(t, mgr.MoveState("landing-zones-app.tfstate", "landing-zones-shop.tfstate"))
+	require.Len(t, cli.calls, 3)
+	assert.Contains(t, cli.calls[0], "show")
+	assert.Contains(t, cli.calls[1], "copy")
+	assert.Contains(t, cli.calls[1], "landing-zones-shop.tfstate")
+	assert.Contains(t, cli.calls[2], "delete")
+	assert.Contains(t, cli.calls[2], "landing-zones-app.tfstate")
+	assert.NotContains(t, cli.calls[2], "--delete-snapshots", "snapshots are never deleted")
+}
+
+func TestMoveState_KeepsBlobWithSnapshots(t *testing.T) {
+	cli := newMockCLI()
+	cli.errors["storage blob show"] = errors.New("ErrorCode:BlobNotFound")
+	cli.errors["storage blob delete"] = errors.New("ErrorCode:SnapshotsPresent")
+	err := NewManager(testConfig(), cli).MoveState("landing-zones-app.tfstate", "landing-zones-shop.tfstate")
+	require.ErrorIs(t, err, ErrSnapshotsPresent)
+	assert.Contains(t, err.Error(), "copied to landing-zones-shop.tfstate")
+}
+
+func TestMoveState_RefusesToOverwrite(t *testing.T) {
+	cli := newMockCLI()
+	cli.responses["storage blob show"] = `{"versionId": "v1"}`
+	err := NewManager(testConfig(), cli).MoveState("landing-zones-app.tfstate", "landing-zones-shop.tfstate")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+	assert.Len(t, cli.calls, 1)
+}
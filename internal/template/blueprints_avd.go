@@ -31,7 +31,7 @@ data "terraform_remote_state" "management" {
     resource_group_name  = %q
     storage_account_name = %q
     container_name       = %q
-    key                  = "platform-management.tfstate"
+    key                  = %q
     subscription_id      = %q
     use_azuread_auth     = true
   }
@@ -152,6 +152,7 @@ resource "azurerm_monitor_diagnostic_setting" "host_pool" {
 		cfg.Spec.StateBackend.ResourceGroup,
 		cfg.Spec.StateBackend.StorageAccount,
 		cfg.Spec.StateBackend.Container,
+		cfg.StateKey("platform-management.tfstate"),
 		cfg.Spec.StateBackend.Subscription,
 		slug, slug, slug, slug, slug, slug, slug,
 	)
@@ -75,6 +75,11 @@ func (e *Engine) RenderAll(cfg *config.LZConfig) ([]RenderedFile, error) {
 		}
 	}
 
+	if !isPullMode && len(cfg.Spec.Environments) > 0 {
+		tpl, out := promotionPipeline(cfg)
+		templateToPath = append(templateToPath, struct{ TemplatePath, OutputPath string }{TemplatePath: tpl, OutputPath: out})
+	}
+
 	if strings.EqualFold(cfg.Spec.Platform.ManagementGroups.Model, "caf-lite") {
 		templateToPath = append(templateToPath,
 			struct{ TemplatePath, OutputPath string }{TemplatePath: "platform/management-groups/caf-lite/main.tf.tmpl", OutputPath: "platform/management-groups/main.tf"},
@@ -152,6 +157,7 @@ func (e *Engine) RenderAll(cfg *config.LZConfig) ([]RenderedFile, error) {
 			struct{ TemplatePath, OutputPath string }{TemplatePath: baseTpl + "/variables.tf.tmpl", OutputPath: baseOut + "/variables.tf"},
 			struct{ TemplatePath, OutputPath string }{TemplatePath: baseTpl + "/terraform.tfvars.tmpl", OutputPath: baseOut + "/terraform.tfvars"},
 		)
+		files = append(files, RenderedFile{Path: baseOut + "/backend.hcl", Content: renderZoneBackendHCL(cfg, zone.Name)})
 
 		if zone.Blueprint != nil {
 			blueprintFiles, bpErr := e.RenderBlueprint(zone.Name, zone.Blueprint, cfg)
@@ -216,20 +222,11 @@ func (e *Engine) RenderAll(cfg *config.LZConfig) ([]RenderedFile, error) {
 	return files, nil
 }
 
-// cafLayerOrder is the canonical CAF platform layer dependency order.
-var cafLayerOrder = []string{
-	"management-groups",
-	"identity",
-	"management",
-	"governance",
-	"connectivity",
-}
-
 // activeLayers returns the platform layers that are active for the given config,
 // following the CAF dependency order.
 func activeLayers(cfg *config.LZConfig) []string {
-	layers := make([]string, 0, len(cafLayerOrder))
-	for _, l := range cafLayerOrder {
+	layers := make([]string, 0, len(config.PlatformLayerOrder))
+	for _, l := range config.PlatformLayerOrder {
 		if l == "connectivity" && strings.EqualFold(strings.TrimSpace(cfg.Spec.Platform.Connectivity.Type), "none") {
 			continue
 		}
@@ -338,16 +335,17 @@ func renderPaasSecureBlueprintMainTF(cfg *config.LZConfig, zoneName string) stri
     resource_group_name  = %q
     storage_account_name = %q
     container_name       = %q
-    key                  = "platform-management.tfstate"
+    key                  = %q
     subscription_id      = %q
     use_azuread_auth     = true
   }
 }
-`, cfg.Spec.StateBackend.ResourceGroup, cfg.Spec.StateBackend.StorageAccount, cfg.Spec.StateBackend.Container, cfg.Spec.StateBackend.Subscription)
+`, cfg.Spec.StateBackend.ResourceGroup, cfg.Spec.StateBackend.StorageAccount, cfg.Spec.StateBackend.Container, cfg.StateKey("platform-management.tfstate"), cfg.Spec.StateBackend.Subscription)
 
 	return fmt.Sprintf(`# Generated by lzctl blueprint catalog (paas-secure)
 terraform {
   required_version = ">= 1.5.0"
+  backend "azurerm" {}
 }
 
 %s
@@ -532,13 +530,23 @@ keyvault_soft_delete_retention_days = %d
 }
 
 func renderBlueprintBackendHCL(cfg *config.LZConfig, zoneName string) string {
+	return renderBackendHCL(cfg, "landing-zones-"+Slugify(zoneName)+"-blueprint.tfstate")
+}
+
+// renderZoneBackendHCL renders the backend.hcl completing the azurerm
+// backend block of a landing zone root.
+func renderZoneBackendHCL(cfg *config.LZConfig, zoneName string) string {
+	return renderBackendHCL(cfg, "landing-zones-"+Slugify(zoneName)+".tfstate")
+}
+
+func renderBackendHCL(cfg *config.LZConfig, key string) string {
 	return fmt.Sprintf(`resource_group_name  = %q
 storage_account_name = %q
 container_name       = %q
 key                  = %q
 subscription_id      = %q
 use_azuread_auth     = true
-`, cfg.Spec.StateBackend.ResourceGroup, cfg.Spec.StateBackend.StorageAccount, cfg.Spec.StateBackend.Container, "landing-zones-"+Slugify(zoneName)+"-blueprint.tfstate", cfg.Spec.StateBackend.Subscription)
+`, cfg.Spec.StateBackend.ResourceGroup, cfg.Spec.StateBackend.StorageAccount, cfg.Spec.StateBackend.Container, cfg.StateKey(key), cfg.Spec.StateBackend.Subscription)
 }
 
 func asStringMap(overrides map[string]any, key string) map[string]any {
@@ -584,10 +592,9 @@ func (e *Engine) RenderTests(cfg *config.LZConfig) ([]RenderedFile, error) {
 		return nil, nil
 	}
 
-	platformLayers := []string{"management-groups", "identity", "management", "governance", "connectivity"}
-	files := make([]RenderedFile, 0, len(platformLayers)+len(cfg.Spec.LandingZones))
+	files := make([]RenderedFile, 0, len(config.PlatformLayerOrder)+len(cfg.Spec.LandingZones))
 
-	for _, layer := range platformLayers {
+	for _, layer := range config.PlatformLayerOrder {
 		assertions := filterAssertions(cfg.Spec.Testing.Assertions, layer)
 		ctx := map[string]interface{}{
 			"Config":     cfg,
@@ -664,7 +671,7 @@ func (e *Engine) RenderZone(cfg *config.LZConfig, zone config.LandingZone) ([]Re
 		"Zone":    zone,
 	}
 
-	files := make([]RenderedFile, 0, len(templateToPath))
+	files := make([]RenderedFile, 0, len(templateToPath)+1)
 	for _, item := range templateToPath {
 		var sb strings.Builder
 		t, err := texttemplate.New(path.Base(item.TemplatePath)).Funcs(e.funcMap).ParseFS(templatefs.FS, item.TemplatePath)
@@ -679,6 +686,7 @@ func (e *Engine) RenderZone(cfg *config.LZConfig, zone config.LandingZone) ([]Re
 			Content: sb.String(),
 		})
 	}
+	files = append(files, RenderedFile{Path: baseOut + "/backend.hcl", Content: renderZoneBackendHCL(cfg, zone.Name)})
 
 	return files, nil
 }
@@ -765,12 +773,12 @@ func renderAKSPlatformMainTF(cfg *config.LZConfig, zoneName string, aksCfg confi
     resource_group_name  = %q
     storage_account_name = %q
     container_name       = %q
-    key                  = "platform-management.tfstate"
+    key                  = %q
     subscription_id      = %q
     use_azuread_auth     = true
   }
 }
-`, cfg.Spec.StateBackend.ResourceGroup, cfg.Spec.StateBackend.StorageAccount, cfg.Spec.StateBackend.Container, cfg.Spec.StateBackend.Subscription)
+`, cfg.Spec.StateBackend.ResourceGroup, cfg.Spec.StateBackend.StorageAccount, cfg.Spec.StateBackend.Container, cfg.StateKey("platform-management.tfstate"), cfg.Spec.StateBackend.Subscription)
 
 	return fmt.Sprintf(`# Generated by lzctl blueprint catalog (aks-platform)
 # secure-by-default: private cluster, Defender for Containers, Azure Policy add-on
@@ -782,6 +790,7 @@ terraform {
       version = "~> 3.80"
     }
   }
+  backend "azurerm" {}
 }
 
 %s
@@ -1018,3 +1018,20 @@ func TestFilterAssertions(t *testing.T) {
 		assert.Nil(t, result)
 	})
 }
+
+func TestRenderZone_BackendConfig(t *testing.T) {
+	engine, err := NewEngine()
+	require.NoError(t, err)
+
+	for _, archetype := range []string{"corp", "online", "sandbox"} {
+		files, err := engine.RenderZone(sampleConfig(), config.LandingZone{Name: "App One", Archetype: archetype, AddressSpace: "10.1.0.0/24"})
+		require.NoError(t, err)
+
+		content := map[string]string{}
+		for _, f := range files {
+			content[f.Path] = f.Content
+		}
+		assert.Contains(t, content["landing-zones/app-one/main.tf"], `backend "azurerm" {}`, archetype)
+		assert.Contains(t, content["landing-zones/app-one/backend.hcl"], `key                  = "landing-zones-app-one.tfstate"`, archetype)
+	}
+}
@@ -23,6 +23,7 @@ func HelperFuncMap() texttemplate.FuncMap {
 		"dnsZoneRef":     DNSZoneRef,
 		"deref":          DerefBool,
 		"sub":            func(a, b int) int { return a - b },
+		"underscore":     func(s string) string { return strings.ReplaceAll(s, "-", "_") },
 	}
 }
 
@@ -64,12 +65,12 @@ func ConnectivityRemoteState(cfg *config.LZConfig) string {
     resource_group_name  = %q
     storage_account_name = %q
     container_name       = %q
-    key                  = "platform-connectivity.tfstate"
+    key                  = %q
     subscription_id      = %q
     use_azuread_auth     = true
   }
 }
-`, cfg.Spec.StateBackend.ResourceGroup, cfg.Spec.StateBackend.StorageAccount, cfg.Spec.StateBackend.Container, cfg.Spec.StateBackend.Subscription)
+`, cfg.Spec.StateBackend.ResourceGroup, cfg.Spec.StateBackend.StorageAccount, cfg.Spec.StateBackend.Container, cfg.StateKey("platform-connectivity.tfstate"), cfg.Spec.StateBackend.Subscription)
 }
 
 // ToJSON marshals a value to a compact JSON string for template rendering.
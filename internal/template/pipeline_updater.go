@@ -82,6 +82,11 @@ func (e *Engine) RenderPipelines(cfg *config.LZConfig) ([]RenderedFile, error) {
 		)
 	}
 
+	if cfg.Spec.CICD.EffectiveModel() != "pull" && len(cfg.Spec.Environments) > 0 {
+		tpl, out := promotionPipeline(cfg)
+		mappings = append(mappings, templateMapping{tpl, out})
+	}
+
 	ctx := map[string]interface{}{
 		"Config":  cfg,
 		"Version": "v0.1.0-dev",
@@ -102,6 +107,17 @@ func (e *Engine) RenderPipelines(cfg *config.LZConfig) ([]RenderedFile, error) {
 	return files, nil
 }
 
+// promotionPipeline returns the template and output path of the pipeline
+// deploying the declared environments ring by ring.
+func promotionPipeline(cfg *config.LZConfig) (string, string) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Spec.CICD.Platform)) {
+	case "azure-devops", "azuredevops":
+		return "pipelines/azuredevops/promote.yml.tmpl", ".azuredevops/pipelines/promote.yml"
+	default:
+		return "pipelines/github/promote.yml.tmpl", ".github/workflows/promote.yml"
+	}
+}
+
 // GenerateZoneMatrix creates a YAML matrix include snippet for landing zone
 // pipeline steps. Blueprint layers are appended after their parent zone entry
 // so that the CI/CD matrix respects the dependency order (LZ → blueprint).
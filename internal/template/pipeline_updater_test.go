@@ -1,6 +1,7 @@
 package template
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/kjourdan1/lzctl/internal/config"
@@ -110,3 +111,47 @@ func TestPipelineUpdater_DryRun(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotEmpty(t, paths)
 }
+
+func TestRenderPipelines_PromotionByRing(t *testing.T) {
+	cfg := &config.LZConfig{
+		Spec: config.Spec{
+			CICD: config.CICD{Platform: "github-actions", BranchPolicy: config.BranchPolicy{MainBranch: "main"}},
+			Environments: []config.Environment{
+				{Name: "prod", Ring: 2}, {Name: "dev"}, {Name: "test", Ring: 1}, {Name: "test-eu", Ring: 1},
+			},
+		},
+	}
+	engine, err := NewEngine()
+	require.NoError(t, err)
+
+	files, err := engine.RenderPipelines(cfg)
+	require.NoError(t, err)
+	promote := findFile(files, ".github/workflows/promote.yml")
+	require.NotNil(t, promote)
+	assert.Contains(t, promote.Content, "lzctl apply --env dev --ci --auto-approve")
+	assert.Contains(t, promote.Content, "  test-eu:\n    name: \"ring 1: test-eu\"\n    needs: [dev]")
+	assert.Contains(t, promote.Content, "  prod:\n    name: \"ring 2: prod\"\n    needs: [test, test-eu]")
+	assert.Less(t, strings.Index(promote.Content, "  dev:"), strings.Index(promote.Content, "  prod:"))
+
+	cfg.Spec.CICD.Platform = "azure-devops"
+	files, err = engine.RenderPipelines(cfg)
+	require.NoError(t, err)
+	promote = findFile(files, ".azuredevops/pipelines/promote.yml")
+	require.NotNil(t, promote)
+	assert.Contains(t, promote.Content, "  - stage: test_eu\n")
+	assert.Contains(t, promote.Content, "dependsOn: [test, test_eu]")
+
+	cfg.Spec.Environments = nil
+	files, err = engine.RenderPipelines(cfg)
+	require.NoError(t, err)
+	assert.Nil(t, findFile(files, ".azuredevops/pipelines/promote.yml"))
+}
+
+func findFile(files []RenderedFile, path string) *RenderedFile {
+	for i := range files {
+		if files[i].Path == path {
+			return &files[i]
+		}
+	}
+	return nil
+}
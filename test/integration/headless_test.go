@@ -38,6 +38,7 @@ func installFakeTerraformForIntegration(t *testing.T) string {
 			"if \"%1\"==\"init\" ( echo Terraform initialized & exit /b 0 )\r\n" +
 			"if \"%1\"==\"validate\" ( echo Success! The configuration is valid. & exit /b 0 )\r\n" +
 			"if \"%1\"==\"plan\" ( echo Plan: 0 to add, 0 to change, 0 to destroy & exit /b 0 )\r\n" +
+			"if \"%1\"==\"version\" ( echo {\"terraform_version\":\"1.9.0\"} & exit /b 0 )\r\n" +
 			"exit /b 0\r\n"
 		require.NoError(t, os.WriteFile(filepath.Join(binDir, "terraform.bat"), []byte(script), 0o644))
 	} else {
@@ -46,6 +47,7 @@ func installFakeTerraformForIntegration(t *testing.T) string {
 			"  init) echo \"Terraform initialized\"; exit 0 ;;\n" +
 			"  validate) echo \"Success! The configuration is valid.\"; exit 0 ;;\n" +
 			"  plan) echo \"Plan: 0 to add, 0 to change, 0 to destroy\"; exit 0 ;;\n" +
+			"  version) echo '{\"terraform_version\":\"1.9.0\"}'; exit 0 ;;\n" +
 			"esac\n" +
 			"exit 0\n"
 		path := filepath.Join(binDir, "terraform")